@@ -22,7 +22,129 @@ var (
 
 // Rating
 var (
-	ErrCustomerRatingMismatched     = errors.New("Customer does not own this rating")
-	ErrPhotographerRatingMismatched = errors.New("Photographer does not own this rating")
-	ErrAlreadyReviewed              = errors.New("Customer has already reviewed this photographer")
+	ErrAlreadyReviewed = errors.New("Customer has already reviewed this photographer")
+)
+
+// Promotion
+var (
+	ErrPromotionNotFound        = errors.New("Promotion code not found")
+	ErrPromotionInactive        = errors.New("Promotion code is not active")
+	ErrPromotionExpired         = errors.New("Promotion code is not valid at this time")
+	ErrPromotionNotApplicable   = errors.New("Promotion code cannot be applied to this subpackage")
+	ErrPromotionUsageExceeded   = errors.New("Promotion code has reached its usage limit")
+	ErrPromotionCodeDuplicate   = errors.New("Promotion code already exists")
+	ErrPromotionOverPlatformFee = errors.New("Platform promotion cannot take more than the platform fee off")
+)
+
+// Payment
+var (
+	ErrTipAppointmentNotCompleted = errors.New("Tip can only be given to a completed appointment")
+	ErrTipPhotographerNoAccount   = errors.New("Photographer has not set up a payment account yet")
+	// ErrExtraPhotoPhotographerNoAccount is returned when extra photos cannot be charged to the photographer
+	ErrExtraPhotoPhotographerNoAccount = errors.New("Photographer has not set up a payment account to sell extra photos yet")
+	ErrExtraPhotoPaymentPaid           = errors.New("Extra photos are already paid, the selection is being submitted")
+	// ErrCheckoutCompleted is returned when a checkout to close was paid meanwhile, the callers tell what was paid
+	ErrCheckoutCompleted = errors.New("Checkout is already paid")
+)
+
+// Upload
+var (
+	ErrUploadNotFound = errors.New("Upload not found")
+	ErrUploadExpired  = errors.New("Upload has expired")
+	ErrUploadInvalid  = errors.New("Upload is not a pending upload for this purpose")
+)
+
+// Package
+var (
+	ErrPackagePhotoNotFound = errors.New("Photo not found in the package")
+	ErrPackagePhotoOrder    = errors.New("Photo order must list every photo of the package exactly once")
+)
+
+// Gallery
+var (
+	ErrGalleryNotFound                = errors.New("Gallery not found")
+	ErrGalleryExists                  = errors.New("Gallery already exists for this appointment")
+	ErrGalleryPhotoNotFound           = errors.New("Photo not found in the gallery")
+	ErrGalleryAppointmentNotCompleted = errors.New("Gallery can only be created for a completed appointment")
+	ErrGalleryNotAvailable            = errors.New("Gallery is available after it is delivered and the payment is paid")
+	ErrGalleryArchived                = errors.New("Gallery has expired and is archived")
+	ErrGalleryEmpty                   = errors.New("Gallery has no photos to deliver")
+	ErrGalleryExpireTime              = errors.New("Gallery expire time must be in the future")
+)
+
+// Watermark
+var (
+	ErrWatermarkEmpty = errors.New("Watermark must have a text or a logo")
+)
+
+// Proofing
+var (
+	ErrProofNotFound         = errors.New("Proof not found in the gallery")
+	ErrProofingNotOpen       = errors.New("Proofing is not open for this gallery")
+	ErrProofingEmpty         = errors.New("Gallery has no proofs to select from")
+	ErrProofSelectionLocked  = errors.New("Proof selection is already submitted")
+	ErrProofSelectionInvalid = errors.New("Proof selection must list existing proofs at most once")
+	ErrProofSelectionEmpty   = errors.New("Proof selection must have at least one photo")
+	ErrProofSelectionLimit   = errors.New("Proof selection is over the limit of the subpackage and extra photos are not for sale")
+)
+
+// Storage
+var (
+	ErrStorageQuotaExceeded = errors.New("Storage quota is exceeded, delete photos to upload more")
+)
+
+// Photo moderation
+var (
+	ErrPhotoModerationNotFound = errors.New("Held photo not found")
+	ErrPhotoModerationReviewed = errors.New("Held photo is already reviewed")
+)
+
+// Verification
+var (
+	ErrVerificationNotFound    = errors.New("Verification not found")
+	ErrVerificationPending     = errors.New("A verification is already waiting for review")
+	ErrVerificationReviewed    = errors.New("Verification is already reviewed")
+	ErrVerifiedBankDetails     = errors.New("Bank details of a verified photographer are changed by submitting a new verification")
+	ErrPhotographerNotVerified = errors.New("Photographer is not verified")
+	ErrBankAccountInvalid      = errors.New("Bank account number does not match the format of the bank")
+)
+
+// Phone
+var (
+	ErrPhoneInvalid         = errors.New("Phone number is invalid")
+	ErrPhoneAlreadyVerified = errors.New("Phone number is already verified")
+	ErrPhoneInUse           = errors.New("Phone number is verified by another account")
+	ErrPhoneOTPRateLimited  = errors.New("Too many codes requested, try again later")
+	ErrPhoneOTPExpired      = errors.New("Code has expired, request a new one")
+	ErrPhoneOTPInvalid      = errors.New("Code is incorrect")
+	ErrPhoneOTPAttempts     = errors.New("Too many incorrect codes, request a new one")
+	ErrPhoneNotVerified     = errors.New("A verified phone number is required")
+)
+
+// Not found, also returned for the objects a user is not allowed to see
+var (
+	ErrUserNotFound        = errors.New("User not found")
+	ErrAppointmentNotFound = errors.New("Appointment not found")
+	ErrPaymentNotFound     = errors.New("Payment not found")
+	ErrPackageNotFound     = errors.New("Package not found")
+	ErrSubpackageNotFound  = errors.New("Subpackage not found")
+	ErrRatingNotFound      = errors.New("Rating not found")
+)
+
+// Account
+var (
+	ErrAccountActiveAppointments   = errors.New("Account has pending or accepted appointments")
+	ErrAccountDeletionNotScheduled = errors.New("Account deletion is not scheduled")
+)
+
+// Admin
+var (
+	ErrAdminSelf                  = errors.New("Admins cannot change their own role or suspend themselves")
+	ErrUserSuspended              = errors.New("Account is suspended")
+	ErrUserBanned                 = errors.New("Account is banned")
+	ErrSuspensionExpiry           = errors.New("A suspension must expire in the future and a ban cannot expire")
+	ErrSessionRevoked             = errors.New("Session was revoked, sign in again")
+	ErrAppointmentStatusUnchanged = errors.New("Appointment already has this status")
+	ErrAppointmentDeleteCompleted = errors.New("A completed appointment cannot be deleted, force its status instead")
+	ErrAppointmentDeletePaid      = errors.New("A paid appointment cannot be deleted, refund it first")
 )
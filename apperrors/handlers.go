@@ -15,13 +15,79 @@ func HandleError(c *gin.Context, err error, message string) {
 		ErrAppointmentStatusInvalid,
 		ErrTimeOverlapped,
 		ErrAlreadyReviewed,
-		ErrCustomerRatingMismatched,
-		ErrPhotographerRatingMismatched:
+		ErrPromotionNotFound,
+		ErrPromotionInactive,
+		ErrPromotionExpired,
+		ErrPromotionNotApplicable,
+		ErrPromotionUsageExceeded,
+		ErrPromotionCodeDuplicate,
+		ErrPromotionOverPlatformFee,
+		ErrTipAppointmentNotCompleted,
+		ErrTipPhotographerNoAccount,
+		ErrExtraPhotoPhotographerNoAccount,
+		ErrExtraPhotoPaymentPaid,
+		ErrCheckoutCompleted,
+		ErrUploadNotFound,
+		ErrUploadExpired,
+		ErrUploadInvalid,
+		ErrPackagePhotoOrder,
+		ErrGalleryExists,
+		ErrGalleryAppointmentNotCompleted,
+		ErrGalleryArchived,
+		ErrGalleryEmpty,
+		ErrGalleryExpireTime,
+		ErrProofingNotOpen,
+		ErrProofingEmpty,
+		ErrProofSelectionLocked,
+		ErrProofSelectionInvalid,
+		ErrProofSelectionEmpty,
+		ErrProofSelectionLimit,
+		ErrWatermarkEmpty,
+		ErrPhotoModerationReviewed,
+		ErrAdminSelf,
+		ErrSuspensionExpiry,
+		ErrAccountActiveAppointments,
+		ErrAccountDeletionNotScheduled,
+		ErrVerificationPending,
+		ErrVerificationReviewed,
+		ErrVerifiedBankDetails,
+		ErrBankAccountInvalid,
+		ErrPhoneInvalid,
+		ErrPhoneAlreadyVerified,
+		ErrPhoneInUse,
+		ErrPhoneOTPExpired,
+		ErrPhoneOTPInvalid,
+		ErrPhoneOTPAttempts,
+		ErrAppointmentStatusUnchanged,
+		ErrAppointmentDeleteCompleted,
+		ErrAppointmentDeletePaid:
 		statusCode = http.StatusBadRequest
-	case ErrUnauthorized:
+	case ErrUnauthorized,
+		ErrSessionRevoked:
 		statusCode = http.StatusUnauthorized
-	case ErrForbidden:
+	case ErrForbidden,
+		ErrGalleryNotAvailable,
+		ErrStorageQuotaExceeded,
+		ErrUserSuspended,
+		ErrUserBanned,
+		ErrPhotographerNotVerified,
+		ErrPhoneNotVerified:
 		statusCode = http.StatusForbidden
+	case ErrPhoneOTPRateLimited:
+		statusCode = http.StatusTooManyRequests
+	case ErrPackagePhotoNotFound,
+		ErrGalleryNotFound,
+		ErrGalleryPhotoNotFound,
+		ErrProofNotFound,
+		ErrPhotoModerationNotFound,
+		ErrVerificationNotFound,
+		ErrUserNotFound,
+		ErrAppointmentNotFound,
+		ErrPaymentNotFound,
+		ErrPackageNotFound,
+		ErrSubpackageNotFound,
+		ErrRatingNotFound:
+		statusCode = http.StatusNotFound
 	default:
 		statusCode = http.StatusInternalServerError
 	}
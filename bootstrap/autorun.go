@@ -2,8 +2,11 @@ package bootstrap
 
 import (
 	"context"
+	"log"
 	"os"
 	"time"
+
+	"github.com/Bualoi-s-Dev/backend/services"
 )
 
 func AutoUpdate(ctx context.Context, serverService *ServerServices) error {
@@ -17,8 +20,52 @@ func AutoUpdate(ctx context.Context, serverService *ServerServices) error {
 	ticker := time.NewTicker(tickerTime) // Runs every 15 minutes
 	defer ticker.Stop()
 
+	gcOpts, gcEnabled := storageGCOptionsFromEnv()
+	gcTicker := time.NewTicker(24 * time.Hour)
+	defer gcTicker.Stop()
+
 	for {
-		<-ticker.C
-		go serverService.appointmentService.AutoUpdateAppointmentStatus(ctx)
+		select {
+		case <-ticker.C:
+			go serverService.appointmentService.AutoUpdateAppointmentStatus(ctx)
+			go serverService.uploadService.CleanupExpired(ctx)
+			go serverService.galleryService.ArchiveExpired(ctx)
+			go serverService.accountService.DeleteDue(ctx)
+		case <-gcTicker.C:
+			go serverService.auditService.PurgeExpired(ctx)
+			go serverService.phoneService.PurgeExpired(ctx)
+			if gcEnabled {
+				go serverService.storageGCService.RunScheduled(ctx, gcOpts)
+			}
+		}
+	}
+}
+
+// storageGCOptionsFromEnv reads STORAGE_GC_MODE (off, dry-run, delete or quarantine, default dry-run)
+// and STORAGE_GC_GRACE_PERIOD (a duration, default 24h)
+func storageGCOptionsFromEnv() (services.StorageGCOptions, bool) {
+	opts := services.StorageGCOptions{
+		Mode:                services.StorageGCQuarantine,
+		DryRun:              true,
+		GracePeriod:         services.DefaultStorageGCGracePeriod,
+		QuarantineRetention: services.DefaultStorageGCQuarantineRetention,
+	}
+	switch mode := os.Getenv("STORAGE_GC_MODE"); mode {
+	case "off":
+		return opts, false
+	case "", "dry-run":
+	case string(services.StorageGCDelete), string(services.StorageGCQuarantine):
+		opts.Mode = services.StorageGCMode(mode)
+		opts.DryRun = false
+	default:
+		log.Println("Unknown STORAGE_GC_MODE", mode, "running the storage GC in dry-run")
+	}
+	if grace := os.Getenv("STORAGE_GC_GRACE_PERIOD"); grace != "" {
+		if d, err := time.ParseDuration(grace); err == nil {
+			opts.GracePeriod = d
+		} else {
+			log.Println("Invalid STORAGE_GC_GRACE_PERIOD", grace, err)
+		}
 	}
+	return opts, true
 }
@@ -1,11 +1,20 @@
 package bootstrap
 
 import (
+	"context"
+	"crypto/rand"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"firebase.google.com/go/auth"
 	"github.com/Bualoi-s-Dev/backend/configs"
 	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/encryption"
 	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
 	"github.com/Bualoi-s-Dev/backend/routes"
 	"github.com/Bualoi-s-Dev/backend/services"
 	validators "github.com/Bualoi-s-Dev/backend/validator"
@@ -14,10 +23,14 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/stripe/stripe-go/v81"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
+	authRepo "github.com/Bualoi-s-Dev/backend/repositories/auth"
 	database "github.com/Bualoi-s-Dev/backend/repositories/database"
 	firebase "github.com/Bualoi-s-Dev/backend/repositories/firebase"
 	s3 "github.com/Bualoi-s-Dev/backend/repositories/s3"
+	sms "github.com/Bualoi-s-Dev/backend/repositories/sms"
+	storage "github.com/Bualoi-s-Dev/backend/repositories/storage"
 	stripeRepo "github.com/Bualoi-s-Dev/backend/repositories/stripe"
 )
 
@@ -25,7 +38,7 @@ type ServerRepositories struct {
 	packageRepo     *database.PackageRepository
 	userRepo        *database.UserRepository
 	appointmentRepo *database.AppointmentRepository
-	s3Repo          *s3.S3Repository
+	storageRepo     storage.StorageRepository
 	firebaseRepo    *firebase.FirebaseRepository
 }
 
@@ -35,6 +48,12 @@ type ServerServices struct {
 	appointmentService *services.AppointmentService
 	s3Service          *services.S3Service
 	firebaseService    *services.FirebaseService
+	uploadService      *services.UploadService
+	storageGCService   *services.StorageGCService
+	galleryService     *services.GalleryService
+	auditService       *services.AuditService
+	accountService     *services.AccountService
+	phoneService       *services.PhoneService
 }
 
 func SetupServer(client *mongo.Database, isTesting bool) (*gin.Engine, *ServerRepositories, *ServerServices) {
@@ -48,8 +67,14 @@ func SetupServer(client *mongo.Database, isTesting bool) (*gin.Engine, *ServerRe
 
 	r.Use(configs.EnableCORS())
 
-	authClient := configs.InitializeFirebaseAuth()
+	tokenVerifier := NewTokenVerifier()
+	var authClient *auth.Client
+	if firebaseVerifier, ok := tokenVerifier.(*authRepo.FirebaseVerifier); ok {
+		authClient = firebaseVerifier.Client
+	}
+	localVerifier, _ := tokenVerifier.(*authRepo.LocalVerifier)
 	stripe.Key = os.Getenv("STRIPE_SECRET_KEY")
+	SetupFieldEncryption()
 
 	// Validator
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
@@ -62,42 +87,109 @@ func SetupServer(client *mongo.Database, isTesting bool) (*gin.Engine, *ServerRe
 	busyTimeCollection := client.Collection("BusyTime")
 	paymentCollection := client.Collection("Payment")
 	ratingCollection := client.Collection("Rating")
+	promotionCollection := client.Collection("Promotion")
+	promotionRedemptionCollection := client.Collection("PromotionRedemption")
+	paymentJobCollection := client.Collection("PaymentJob")
+	uploadCollection := client.Collection("Upload")
+	galleryCollection := client.Collection("Gallery")
+	notificationCollection := client.Collection("Notification")
+	photoHashCollection := client.Collection("PhotoHash")
+	photoModerationCollection := client.Collection("PhotoModeration")
+	verificationCollection := client.Collection("PhotographerVerification")
+	phoneOTPCollection := client.Collection("PhoneOTP")
+	// The changes are read back as documents instead of key value lists
+	auditLogCollection := client.Collection("AuditLog", options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
 
 	packageRepo := database.NewPackageRepository(packageCollection)
 	subpackageRepo := database.NewSubpackageRepository(subpackageCollection)
 	userRepo := database.NewUserRepository(userCollection)
 	appointmentRepo := database.NewAppointmentRepository(appointmentCollection, busyTimeCollection)
 	busyTimeRepo := database.NewBusyTimeRepository(busyTimeCollection)
-	s3Repo := s3.NewS3Repository()
+	storageRepo := NewStorageRepository()
 	firebaseRepo := firebase.NewFirebaseRepository(authClient)
 	paymentRepo := database.NewPaymentRepository(paymentCollection, appointmentCollection)
 	stripeRepo := stripeRepo.NewStripeRepository()
 	ratingRepo := database.NewRatingRepository(ratingCollection)
+	promotionRepo := database.NewPromotionRepository(promotionCollection, promotionRedemptionCollection)
+	paymentJobRepo := database.NewPaymentJobRepository(paymentJobCollection)
+	uploadRepo := database.NewUploadRepository(uploadCollection)
+	galleryRepo := database.NewGalleryRepository(galleryCollection)
+	notificationRepo := database.NewNotificationRepository(notificationCollection)
+	photoHashRepo := database.NewPhotoHashRepository(photoHashCollection)
+	photoModerationRepo := database.NewPhotoModerationRepository(photoModerationCollection)
+	verificationRepo := database.NewVerificationRepository(verificationCollection)
+	phoneOTPRepo := database.NewPhoneOTPRepository(phoneOTPCollection)
+	auditLogRepo := database.NewAuditLogRepository(auditLogCollection)
+	ensureIndexes(promotionRepo, photoHashRepo)
 
-	s3Service := services.NewS3Service(s3Repo)
-	firebaseService := services.NewFirebaseService(firebaseRepo)
-	subpackageService := services.NewSubpackageService(subpackageRepo, packageRepo, busyTimeRepo, appointmentRepo)
-	packageService := services.NewPackageService(packageRepo, s3Service, subpackageService, userRepo)
+	auditService := services.NewAuditService(auditLogRepo, auditLogRetentionFromEnv())
+	s3Service := services.NewS3Service(storageRepo)
+	uploadService := services.NewUploadService(uploadRepo, s3Service)
+	storageGCService := services.NewStorageGCService(s3Service, packageRepo, userRepo, photoModerationRepo)
+	firebaseService := services.NewFirebaseService(firebaseRepo, localVerifier)
+	subpackageService := services.NewSubpackageService(subpackageRepo, packageRepo, busyTimeRepo, appointmentRepo, userRepo)
+	watermarkService := services.NewWatermarkService(userRepo, packageRepo, uploadService, s3Service)
+	notificationService := services.NewNotificationService(notificationRepo)
+	photoModerationService := services.NewPhotoModerationService(photoModerationRepo, photoHashRepo, packageRepo, s3Service, watermarkService, notificationService)
+	packageService := services.NewPackageService(packageRepo, s3Service, subpackageService, userRepo, uploadService, watermarkService, photoModerationService)
 	ratingService := services.NewRatingService(ratingRepo)
-	userService := services.NewUserService(userRepo, s3Service, packageService, subpackageService, authClient, ratingService)
+	storageQuotaService := services.NewStorageQuotaService(userRepo, packageRepo, galleryRepo, photoModerationRepo, storageQuotaFromEnv())
+	s3Service.Quota = storageQuotaService
+	userService := services.NewUserService(userRepo, s3Service, packageService, subpackageService, tokenVerifier, ratingService, uploadService, storageQuotaService)
 	busyTimeService := services.NewBusyTimeService(busyTimeRepo, subpackageRepo, packageRepo)
 	paymentService := services.NewPaymentService(paymentRepo, userRepo, appointmentRepo, stripeRepo)
-	appointmentService := services.NewAppointmentService(appointmentRepo, packageRepo, subpackageRepo, busyTimeRepo, userRepo, paymentService)
+	paymentJobService := services.NewPaymentJobService(paymentJobRepo, paymentRepo, paymentService)
+	promotionService := services.NewPromotionService(promotionRepo, packageRepo)
+	galleryService := services.NewGalleryService(galleryRepo, paymentRepo, uploadService, s3Service)
+	proofingService := services.NewProofingService(galleryRepo, uploadService, s3Service, paymentService, notificationService, watermarkService)
+	paymentService.OnPaid(models.PaymentExtraPhotos, proofingService.CompleteExtraPhotoPayment)
+	appointmentService := services.NewAppointmentService(appointmentRepo, packageRepo, subpackageRepo, busyTimeRepo, userRepo, busyTimeService, paymentService, paymentJobService, promotionService)
+	verificationService := services.NewVerificationService(verificationRepo, userRepo, uploadService, s3Service, notificationService)
+	phoneService := services.NewPhoneService(phoneOTPRepo, userRepo, NewSMSSender(), phoneOTPSecretFromEnv())
+	adminService := services.NewAdminService(userRepo, appointmentRepo, paymentRepo, ratingRepo, packageService, busyTimeService, promotionService, paymentJobService, notificationService, tokenVerifier)
+	accountService := services.NewAccountService(userRepo, packageRepo, subpackageRepo, busyTimeRepo, appointmentRepo, paymentRepo, ratingRepo, notificationRepo, phoneOTPRepo, galleryRepo,
+		packageService, subpackageService, busyTimeService, verificationService, galleryService, s3Service, tokenVerifier, auditService, accountDeletionGracePeriodFromEnv())
+	packageService.Audit = auditService
+	subpackageService.Audit = auditService
+	busyTimeService.Audit = auditService
+	appointmentService.Audit = auditService
+	paymentService.Audit = auditService
+	ratingService.Audit = auditService
+	userService.Audit = auditService
+	adminService.Audit = auditService
+	verificationService.Audit = auditService
+	phoneService.Audit = auditService
+	appointmentService.RequireVerifiedPhone = os.Getenv("BOOKING_REQUIRES_VERIFIED_PHONE") == "true"
+	if appointmentService.RequireVerifiedPhone && !phoneService.Enabled() {
+		log.Fatalln("BOOKING_REQUIRES_VERIFIED_PHONE needs phone verification, set PHONE_OTP_SECRET and SMS_PROVIDER")
+	}
 
 	packageController := controllers.NewPackageController(packageService, s3Service, userService, subpackageService)
 	subPackageController := controllers.NewSubpackageController(subpackageService, packageService)
 	appointmentController := controllers.NewAppointmentController(appointmentService, subpackageService, busyTimeService)
-	userController := controllers.NewUserController(userService, s3Service, busyTimeService, authClient)
+	userController := controllers.NewUserController(userService, s3Service, busyTimeService, tokenVerifier)
 	BusyTimeController := controllers.NewBusyTimeController(busyTimeService)
 	internalController := controllers.NewInternalController(firebaseService, s3Service)
 	paymentController := controllers.NewPaymentController(paymentService, appointmentService, packageService)
+	paymentJobController := controllers.NewPaymentJobController(paymentJobService)
 	RatingController := controllers.NewRatingController(ratingService, userService)
+	promotionController := controllers.NewPromotionController(promotionService, packageService, subpackageService)
+	uploadController := controllers.NewUploadController(uploadService)
+	galleryController := controllers.NewGalleryController(galleryService, appointmentService)
+	proofingController := controllers.NewProofingController(proofingService, galleryController)
+	notificationController := controllers.NewNotificationController(notificationService)
+	watermarkController := controllers.NewWatermarkController(watermarkService)
+	photoModerationController := controllers.NewPhotoModerationController(photoModerationService)
+	adminController := controllers.NewAdminController(adminService)
+	accountController := controllers.NewAccountController(accountService)
+	verificationController := controllers.NewVerificationController(verificationService)
+	phoneController := controllers.NewPhoneController(phoneService)
 
 	serverRepositories := &ServerRepositories{
 		packageRepo:     packageRepo,
 		userRepo:        userRepo,
 		appointmentRepo: appointmentRepo,
-		s3Repo:          s3Repo,
+		storageRepo:     storageRepo,
 		firebaseRepo:    firebaseRepo,
 	}
 	serverServices := &ServerServices{
@@ -106,6 +198,12 @@ func SetupServer(client *mongo.Database, isTesting bool) (*gin.Engine, *ServerRe
 		appointmentService: appointmentService,
 		s3Service:          s3Service,
 		firebaseService:    firebaseService,
+		uploadService:      uploadService,
+		storageGCService:   storageGCService,
+		galleryService:     galleryService,
+		auditService:       auditService,
+		accountService:     accountService,
+		phoneService:       phoneService,
 	}
 
 	rateLimiter := middleware.NewRateLimiter(50, 5)
@@ -116,15 +214,264 @@ func SetupServer(client *mongo.Database, isTesting bool) (*gin.Engine, *ServerRe
 
 	// Add routes
 	routes.InternalRoutes(r, internalController)
+	if local, ok := storageRepo.(*storage.LocalRepository); ok {
+		routes.StorageRoutes(r, controllers.NewStorageController(local))
+	}
 
-	r.Use(middleware.FirebaseAuthMiddleware(authClient, client.Collection("User"), userService))
+	r.Use(middleware.AuthMiddleware(tokenVerifier, client.Collection("User"), userService))
+	r.Use(middleware.AuditMiddleware())
 
 	routes.PackageRoutes(r, packageController, userService)
 	routes.SubpackageRoutes(r, subPackageController, userService)
 	routes.UserRoutes(r, userController, RatingController, userService)
 	routes.AppointmentRoutes(r, appointmentController, userService)
 	routes.BusyTimeRoutes(r, BusyTimeController, userService)
-	routes.PaymentRoutes(r, paymentController, userService)
+	routes.PaymentRoutes(r, paymentController, paymentJobController, userService)
+	routes.PromotionRoutes(r, promotionController, userService)
+	routes.UploadRoutes(r, uploadController, userService)
+	routes.GalleryRoutes(r, galleryController, userService)
+	routes.ProofingRoutes(r, proofingController, userService)
+	routes.NotificationRoutes(r, notificationController, userService)
+	routes.WatermarkRoutes(r, watermarkController, userService)
+	routes.PhotoModerationRoutes(r, photoModerationController, userService)
+	routes.AdminRoutes(r, adminController, userService)
+	routes.AccountRoutes(r, accountController)
+	routes.VerificationRoutes(r, verificationController, userService)
+	if phoneService.Enabled() {
+		routes.PhoneRoutes(r, phoneController)
+	}
 
 	return r, serverRepositories, serverServices
 }
+
+// storageQuotaFromEnv reads STORAGE_QUOTA_BYTES and STORAGE_QUOTA_PHOTOS, the quota of every photographer,
+// 0 is unlimited
+func storageQuotaFromEnv() services.StorageQuota {
+	quota := services.DefaultStorageQuota
+	if value := os.Getenv("STORAGE_QUOTA_BYTES"); value != "" {
+		if bytes, err := strconv.ParseInt(value, 10, 64); err == nil && bytes >= 0 {
+			quota.Bytes = bytes
+		} else {
+			log.Println("Invalid STORAGE_QUOTA_BYTES", value)
+		}
+	}
+	if value := os.Getenv("STORAGE_QUOTA_PHOTOS"); value != "" {
+		if photos, err := strconv.Atoi(value); err == nil && photos >= 0 {
+			quota.Photos = photos
+		} else {
+			log.Println("Invalid STORAGE_QUOTA_PHOTOS", value)
+		}
+	}
+	return quota
+}
+
+// auditLogRetentionFromEnv reads AUDIT_LOG_RETENTION, how long the audit log is kept as a duration, 0 keeps it forever
+func auditLogRetentionFromEnv() time.Duration {
+	value := os.Getenv("AUDIT_LOG_RETENTION")
+	if value == "" {
+		return services.DefaultAuditLogRetention
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention < 0 {
+		log.Println("Invalid AUDIT_LOG_RETENTION", value)
+		return services.DefaultAuditLogRetention
+	}
+	return retention
+}
+
+// accountDeletionGracePeriodFromEnv reads ACCOUNT_DELETION_GRACE_PERIOD, how long a deleted account can be restored as
+// a duration
+func accountDeletionGracePeriodFromEnv() time.Duration {
+	value := os.Getenv("ACCOUNT_DELETION_GRACE_PERIOD")
+	if value == "" {
+		return services.DefaultAccountDeletionGracePeriod
+	}
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil || gracePeriod < 0 {
+		log.Println("Invalid ACCOUNT_DELETION_GRACE_PERIOD", value)
+		return services.DefaultAccountDeletionGracePeriod
+	}
+	return gracePeriod
+}
+
+// SetupFieldEncryption loads the keys the sensitive fields are encrypted with at rest, from the provider of
+// NewKeyProvider. The fields are stored in plain text without keys.
+func SetupFieldEncryption() {
+	keys, err := NewKeyProvider().Keys(context.Background())
+	if err != nil {
+		log.Fatalln("Failed to load the field encryption keys:", err)
+	}
+	if len(keys) == 0 {
+		if os.Getenv("APP_MODE") == "production" {
+			log.Println("WARNING: No field encryption key is set in production, the bank accounts, phones and Stripe IDs are stored in plain text")
+		} else {
+			log.Println("No field encryption key is set, the sensitive fields are stored in plain text")
+		}
+		return
+	}
+	keyring, err := encryption.NewKeyring(keys)
+	if err != nil {
+		log.Fatalln("Invalid field encryption keys:", err)
+	}
+	encryption.SetFieldKeyring(keyring)
+}
+
+// NewKeyProvider selects where the field encryption keys are from with FIELD_ENCRYPTION_KEY_PROVIDER, "env" reads
+// FIELD_ENCRYPTION_KEY, "file" reads the file at FIELD_ENCRYPTION_KEY_FILE and "secretmanager" reads the Secret Manager
+// secret FIELD_ENCRYPTION_KEY_SECRET_NAME. It defaults to the environment, which has no key until one is set.
+func NewKeyProvider() encryption.KeyProvider {
+	switch provider := os.Getenv("FIELD_ENCRYPTION_KEY_PROVIDER"); provider {
+	case "", "env":
+		return encryption.NewEnvKeyProvider("FIELD_ENCRYPTION_KEY")
+	case "file":
+		path := os.Getenv("FIELD_ENCRYPTION_KEY_FILE")
+		if path == "" {
+			log.Fatalln("FIELD_ENCRYPTION_KEY_FILE is not set")
+		}
+		return encryption.NewFileKeyProvider(path)
+	case "secretmanager":
+		secretName := os.Getenv("FIELD_ENCRYPTION_KEY_SECRET_NAME")
+		if secretName == "" {
+			log.Fatalln("FIELD_ENCRYPTION_KEY_SECRET_NAME is not set")
+		}
+		return encryption.NewSecretManagerKeyProvider(secretName)
+	default:
+		log.Fatalln("Unknown FIELD_ENCRYPTION_KEY_PROVIDER", provider)
+		return nil
+	}
+}
+
+// phoneOTPSecretFromEnv reads PHONE_OTP_SECRET, the key of the HMAC of the phone codes. A random key is used in
+// development when it is empty, elsewhere phone verification is turned off until it is set.
+func phoneOTPSecretFromEnv() []byte {
+	secret := []byte(os.Getenv("PHONE_OTP_SECRET"))
+	if len(secret) == 0 {
+		if os.Getenv("APP_MODE") != "development" {
+			log.Println("WARNING: PHONE_OTP_SECRET is not set, phone verification is turned off")
+			return nil
+		}
+		// The codes sent are only valid until the server restarts
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalln("Failed to generate phone OTP secret:", err)
+		}
+	}
+	return secret
+}
+
+// NewSMSSender selects the sender of the text messages from SMS_PROVIDER, "log" or empty writes them to the log for
+// development. In production the codes would never reach the users, so there is no sender and phone verification
+// is turned off
+func NewSMSSender() sms.SMSSender {
+	switch provider := os.Getenv("SMS_PROVIDER"); provider {
+	case "", "log":
+		if os.Getenv("APP_MODE") == "production" {
+			log.Println("WARNING: SMS_PROVIDER does not send the messages, phone verification is turned off")
+			return nil
+		}
+		return sms.NewLogSender()
+	default:
+		log.Fatalln("Unknown SMS_PROVIDER", provider)
+		return nil
+	}
+}
+
+// indexedRepository creates the indexes its queries or constraints rely on
+type indexedRepository interface {
+	EnsureIndexes(ctx context.Context) error
+}
+
+// ensureIndexes creates the indexes on start, creating an index that exists is a no-op
+func ensureIndexes(repos ...indexedRepository) {
+	for _, repo := range repos {
+		if err := repo.EnsureIndexes(context.Background()); err != nil {
+			log.Printf("[ERROR] Failed to create the indexes of %T: %v", repo, err)
+		}
+	}
+}
+
+// NewTokenVerifier selects the verifier of the ID tokens from AUTH_PROVIDER, "local" signs and verifies the tokens
+// itself with the RSA key at LOCAL_AUTH_PRIVATE_KEY_PATH or the HMAC secret LOCAL_AUTH_SECRET, anything else uses Firebase.
+// The local verifier is only allowed when APP_MODE is development or test, its accounts are registered from
+// LOCAL_AUTH_ACCOUNTS, a comma separated list of email:password.
+func NewTokenVerifier() authRepo.TokenVerifier {
+	if os.Getenv("AUTH_PROVIDER") == "local" {
+		if mode := os.Getenv("APP_MODE"); mode != "development" && mode != "test" {
+			log.Fatalln("AUTH_PROVIDER=local is only allowed when APP_MODE is development or test, not", mode)
+		}
+		verifier := newLocalVerifier()
+		registerLocalAccounts(verifier, os.Getenv("LOCAL_AUTH_ACCOUNTS"))
+		return verifier
+	}
+
+	return authRepo.NewFirebaseVerifier(configs.InitializeFirebaseAuth())
+}
+
+func newLocalVerifier() *authRepo.LocalVerifier {
+	if path := os.Getenv("LOCAL_AUTH_PRIVATE_KEY_PATH"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalln("Failed to read local auth private key:", err)
+		}
+		key, err := authRepo.ParseRSAPrivateKey(data)
+		if err != nil {
+			log.Fatalln("Failed to parse local auth private key:", err)
+		}
+		return authRepo.NewRSAVerifier(key)
+	}
+	secret := []byte(os.Getenv("LOCAL_AUTH_SECRET"))
+	if len(secret) == 0 {
+		// Tokens are only valid until the server restarts
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalln("Failed to generate local auth secret:", err)
+		}
+	}
+	return authRepo.NewHMACVerifier(secret)
+}
+
+func registerLocalAccounts(verifier *authRepo.LocalVerifier, accounts string) {
+	for _, account := range strings.Split(accounts, ",") {
+		account = strings.TrimSpace(account)
+		if account == "" {
+			continue
+		}
+		email, password, ok := strings.Cut(account, ":")
+		if !ok {
+			log.Fatalln("Invalid LOCAL_AUTH_ACCOUNTS entry, expected email:password")
+		}
+		if _, err := verifier.Register(email, password); err != nil {
+			log.Fatalln("Failed to register local account", email, err)
+		}
+	}
+}
+
+// NewStorageRepository selects the blob storage from STORAGE_BACKEND, "local" keeps the files on disk
+// in LOCAL_STORAGE_DIR and signs upload URLs with LOCAL_STORAGE_SECRET, anything else uses the S3 compatible bucket
+func NewStorageRepository() storage.StorageRepository {
+	if os.Getenv("STORAGE_BACKEND") == "local" {
+		dir := os.Getenv("LOCAL_STORAGE_DIR")
+		if dir == "" {
+			dir = "storage"
+		}
+		secret := []byte(os.Getenv("LOCAL_STORAGE_SECRET"))
+		if len(secret) == 0 {
+			// Presigned URLs are only valid until the server restarts
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				log.Fatalln("Failed to generate local storage secret:", err)
+			}
+		}
+		repo, err := storage.NewLocalRepository(dir, "/storage", secret)
+		if err != nil {
+			log.Fatalln("Failed to create local storage:", err)
+		}
+		return repo
+	}
+
+	repo, err := s3.NewS3Repository()
+	if err != nil {
+		log.Fatalln("Failed to create S3 storage:", err)
+	}
+	return repo
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/Bualoi-s-Dev/backend/bootstrap"
+	"github.com/Bualoi-s-Dev/backend/configs"
+	authRepo "github.com/Bualoi-s-Dev/backend/repositories/auth"
+)
+
+// Prints an ID token accepted by a server running with AUTH_PROVIDER=local and the same LOCAL_AUTH_SECRET
+// or LOCAL_AUTH_PRIVATE_KEY_PATH.
+// Usage:
+//
+//	go run ./cmd/authtoken -email photographer@example.com
+//	go run ./cmd/authtoken -email customer@example.com -ttl 24h
+func main() {
+	emailFlag := flag.String("email", "", "email of the user")
+	ttlFlag := flag.Duration("ttl", authRepo.DefaultLocalTokenDuration, "how long the token is valid")
+	flag.Parse()
+
+	if *emailFlag == "" {
+		log.Fatalln("-email is required")
+	}
+
+	configs.LoadEnv()
+	if configs.GetEnv("AUTH_PROVIDER") != "local" {
+		log.Fatalln("AUTH_PROVIDER must be local to mint tokens")
+	}
+	if configs.GetEnv("LOCAL_AUTH_SECRET") == "" && configs.GetEnv("LOCAL_AUTH_PRIVATE_KEY_PATH") == "" {
+		log.Fatalln("LOCAL_AUTH_SECRET or LOCAL_AUTH_PRIVATE_KEY_PATH must be set, the server would not accept a token of a random secret")
+	}
+
+	verifier := bootstrap.NewTokenVerifier().(*authRepo.LocalVerifier)
+	token, err := verifier.MintToken(*emailFlag, *ttlFlag)
+	if err != nil {
+		log.Fatalf("Error minting token: %v", err)
+	}
+	fmt.Println(token)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/bootstrap"
+	"github.com/Bualoi-s-Dev/backend/configs"
+	database "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"github.com/Bualoi-s-Dev/backend/services"
+)
+
+// Marks the photographers who joined before the photographer verification and never submitted one as legacy, they stay
+// listed and booked but get no verified badge and no payouts until their verification is approved. Run it once when
+// deploying the verification, with the time of the deployment so that the photographers who joined after it still
+// have to be verified to be listed.
+// Usage:
+//
+//	go run ./cmd/grandfatherphotographers -before 2025-03-01T00:00:00+07:00                  report how many would be marked
+//	go run ./cmd/grandfatherphotographers -before 2025-03-01T00:00:00+07:00 -dry-run=false   mark them
+func main() {
+	beforeFlag := flag.String("before", "", "mark the photographers created before this RFC 3339 time")
+	dryRunFlag := flag.Bool("dry-run", true, "only report the photographers to mark")
+	flag.Parse()
+
+	if *beforeFlag == "" {
+		log.Fatalln("-before is required")
+	}
+	before, err := time.Parse(time.RFC3339, *beforeFlag)
+	if err != nil {
+		log.Fatalf("Invalid -before time: %v", err)
+	}
+
+	databaseName := "PhotoMatch"
+	configs.LoadEnv()
+	if configs.GetEnv("APP_MODE") == "development" {
+		databaseName = "PhotoMatch_Dev"
+	}
+	client := configs.ConnectMongoDB().Database(databaseName)
+	bootstrap.SetupFieldEncryption()
+
+	service := &services.VerificationService{
+		UserRepo: database.NewUserRepository(client.Collection("User")),
+		Audit:    services.NewAuditService(database.NewAuditLogRepository(client.Collection("AuditLog")), 0),
+	}
+	count, err := service.Grandfather(context.Background(), before, *dryRunFlag)
+	if err != nil {
+		log.Fatalf("Error marking photographers after %d: %v", count, err)
+	}
+	if *dryRunFlag {
+		fmt.Printf("%d photographers would be marked as legacy\n", count)
+	} else {
+		fmt.Printf("%d photographers marked as legacy\n", count)
+	}
+}
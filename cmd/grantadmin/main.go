@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/Bualoi-s-Dev/backend/bootstrap"
+	"github.com/Bualoi-s-Dev/backend/configs"
+	database "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"github.com/Bualoi-s-Dev/backend/services"
+)
+
+// Makes a user an admin, for the first admin of the platform since only an admin can change roles in the back office.
+// The user must have signed in once so that their account exists.
+// Usage:
+//
+//	go run ./cmd/grantadmin -email admin@example.com
+func main() {
+	emailFlag := flag.String("email", "", "email of the user")
+	flag.Parse()
+
+	if *emailFlag == "" {
+		log.Fatalln("-email is required")
+	}
+
+	databaseName := "PhotoMatch"
+	configs.LoadEnv()
+	if configs.GetEnv("APP_MODE") == "development" {
+		databaseName = "PhotoMatch_Dev"
+	}
+	client := configs.ConnectMongoDB().Database(databaseName)
+	bootstrap.SetupFieldEncryption()
+
+	service := &services.AdminService{
+		UserRepo: database.NewUserRepository(client.Collection("User")),
+		Auth:     bootstrap.NewTokenVerifier(),
+		Audit:    services.NewAuditService(database.NewAuditLogRepository(client.Collection("AuditLog")), 0),
+	}
+	user, err := service.GrantAdmin(context.Background(), *emailFlag)
+	if err != nil {
+		log.Fatalf("Error granting admin to %s: %v", *emailFlag, err)
+	}
+	fmt.Printf("%s (%s) is an admin, their next ID token has the role\n", user.Email, user.ID.Hex())
+}
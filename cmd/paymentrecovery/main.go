@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/Bualoi-s-Dev/backend/bootstrap"
+	"github.com/Bualoi-s-Dev/backend/configs"
+	database "github.com/Bualoi-s-Dev/backend/repositories/database"
+	stripeRepo "github.com/Bualoi-s-Dev/backend/repositories/stripe"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stripe/stripe-go/v81"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Lists completed appointments without a valid payment and re-drives their payment creation.
+// Usage:
+//
+//	go run ./cmd/paymentrecovery                    list the appointments
+//	go run ./cmd/paymentrecovery -appointment <id>  re-drive one appointment
+//	go run ./cmd/paymentrecovery -all               re-drive every listed appointment
+func main() {
+	appointmentIdFlag := flag.String("appointment", "", "appointment id to re-drive")
+	allFlag := flag.Bool("all", false, "re-drive every completed appointment without a valid payment")
+	flag.Parse()
+
+	databaseName := "PhotoMatch"
+	configs.LoadEnv()
+	if configs.GetEnv("APP_MODE") == "development" {
+		databaseName = "PhotoMatch_Dev"
+	}
+	client := configs.ConnectMongoDB().Database(databaseName)
+	stripe.Key = configs.GetEnv("STRIPE_SECRET_KEY")
+	bootstrap.SetupFieldEncryption()
+
+	appointmentRepo := database.NewAppointmentRepository(client.Collection("Appointment"), client.Collection("BusyTime"))
+	paymentRepo := database.NewPaymentRepository(client.Collection("Payment"), client.Collection("Appointment"))
+	userRepo := database.NewUserRepository(client.Collection("User"))
+	paymentJobRepo := database.NewPaymentJobRepository(client.Collection("PaymentJob"))
+	paymentService := services.NewPaymentService(paymentRepo, userRepo, appointmentRepo, stripeRepo.NewStripeRepository())
+	paymentJobService := services.NewPaymentJobService(paymentJobRepo, paymentRepo, paymentService)
+
+	ctx := context.Background()
+	if *appointmentIdFlag != "" {
+		appointmentId, err := primitive.ObjectIDFromHex(*appointmentIdFlag)
+		if err != nil {
+			log.Fatalf("Invalid appointment id: %v", err)
+		}
+		redrive(ctx, paymentJobService, appointmentId)
+		return
+	}
+
+	items, err := paymentJobService.ListUnpaidCompleted(ctx)
+	if err != nil {
+		log.Fatalf("Error listing appointments: %v", err)
+	}
+	fmt.Printf("%d completed appointments without a valid payment\n", len(items))
+	for _, item := range items {
+		attempts := 0
+		if item.Job != nil {
+			attempts = item.Job.Attempts
+		}
+		fmt.Printf("%s\t%s\tattempts=%d\n", item.Appointment.ID.Hex(), item.Reason, attempts)
+		if *allFlag {
+			redrive(ctx, paymentJobService, item.Appointment.ID)
+		}
+	}
+}
+
+func redrive(ctx context.Context, service *services.PaymentJobService, appointmentId primitive.ObjectID) {
+	job, err := service.Redrive(ctx, appointmentId)
+	if err != nil {
+		log.Fatalf("Error re-driving appointment %s: %v", appointmentId.Hex(), err)
+	}
+	fmt.Printf("Re-drive %s: %s %s\n", appointmentId.Hex(), job.Status, job.LastError)
+}
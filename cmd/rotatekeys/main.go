@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Bualoi-s-Dev/backend/bootstrap"
+	"github.com/Bualoi-s-Dev/backend/configs"
+	"github.com/Bualoi-s-Dev/backend/models"
+	database "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"github.com/Bualoi-s-Dev/backend/services"
+)
+
+// Re-encrypts the encrypted fields with the primary field encryption key. To rotate the key, put a new key first in the
+// keys, deploy, run this and remove the old key once it reports nothing left to re-encrypt. It also encrypts the values
+// stored before their field was encrypted, and redacts these fields in the audit log entries recorded before the audit
+// log redacted them.
+// Usage:
+//
+//	go run ./cmd/rotatekeys                  report how many documents would be re-encrypted
+//	go run ./cmd/rotatekeys -dry-run=false   re-encrypt them
+//	go run ./cmd/rotatekeys -json            print the report as JSON
+func main() {
+	dryRunFlag := flag.Bool("dry-run", true, "only report the documents to re-encrypt")
+	jsonFlag := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	databaseName := "PhotoMatch"
+	configs.LoadEnv()
+	if configs.GetEnv("APP_MODE") == "development" {
+		databaseName = "PhotoMatch_Dev"
+	}
+	client := configs.ConnectMongoDB().Database(databaseName)
+	bootstrap.SetupFieldEncryption()
+
+	collection := func(name string, model interface{}) services.EncryptedCollection {
+		return services.EncryptedCollection{
+			Name:   name,
+			Repo:   database.NewEncryptedFieldRepository(client.Collection(name)),
+			Fields: services.EncryptedFields(model),
+		}
+	}
+	service := services.NewFieldEncryptionService(
+		collection("User", models.User{}),
+		collection("PhotographerVerification", models.PhotographerVerification{}),
+		collection("PhoneOTP", models.PhoneOTP{}),
+	)
+	service.AuditLogRepo = database.NewAuditLogRepository(client.Collection("AuditLog"))
+	service.AuditedEntities = []services.AuditedEntity{
+		{EntityType: models.AuditUser, Fields: services.EncryptedFields(models.User{})},
+		{EntityType: models.AuditVerification, Fields: services.EncryptedFields(models.PhotographerVerification{})},
+	}
+
+	report, err := service.Rotate(context.Background(), *dryRunFlag)
+	if err != nil {
+		log.Fatalf("Error rotating the field encryption key: %v", err)
+	}
+
+	if *jsonFlag {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("Error printing report: %v", err)
+		}
+		return
+	}
+	fmt.Printf("Primary key %s\n", report.PrimaryKeyID)
+	for _, c := range report.Collections {
+		if report.DryRun {
+			fmt.Printf("%s: scanned %d, %d would be re-encrypted\n", c.Name, c.Scanned, c.Rotated)
+		} else {
+			fmt.Printf("%s: scanned %d, %d re-encrypted, %d changed meanwhile\n", c.Name, c.Scanned, c.Rotated, c.Conflicts)
+		}
+	}
+	if report.DryRun {
+		fmt.Printf("AuditLog: %d would be redacted\n", report.AuditRedacted)
+	} else {
+		fmt.Printf("AuditLog: %d redacted\n", report.AuditRedacted)
+	}
+	for _, e := range report.Errors {
+		fmt.Println("Error:", e)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Bualoi-s-Dev/backend/bootstrap"
+	"github.com/Bualoi-s-Dev/backend/configs"
+	database "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"github.com/Bualoi-s-Dev/backend/services"
+)
+
+// Finds the objects under package/ and profile/ that no package or user references.
+// Usage:
+//
+//	go run ./cmd/storagegc                                report what would be collected
+//	go run ./cmd/storagegc -dry-run=false                 move the orphans to quarantine/
+//	go run ./cmd/storagegc -dry-run=false -mode delete    delete the orphans
+//	go run ./cmd/storagegc -grace 72h -json               change the grace period, print the full report
+func main() {
+	dryRunFlag := flag.Bool("dry-run", true, "only report the orphaned objects")
+	modeFlag := flag.String("mode", string(services.StorageGCQuarantine), "quarantine or delete")
+	graceFlag := flag.Duration("grace", services.DefaultStorageGCGracePeriod, "keep objects modified within this period")
+	retentionFlag := flag.Duration("retention", services.DefaultStorageGCQuarantineRetention, "purge quarantined objects older than this")
+	jsonFlag := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	mode := services.StorageGCMode(*modeFlag)
+	if mode != services.StorageGCQuarantine && mode != services.StorageGCDelete {
+		log.Fatalf("Invalid mode %q", *modeFlag)
+	}
+
+	databaseName := "PhotoMatch"
+	configs.LoadEnv()
+	if configs.GetEnv("APP_MODE") == "development" {
+		databaseName = "PhotoMatch_Dev"
+	}
+	client := configs.ConnectMongoDB().Database(databaseName)
+	bootstrap.SetupFieldEncryption()
+
+	s3Service := services.NewS3Service(bootstrap.NewStorageRepository())
+	packageRepo := database.NewPackageRepository(client.Collection("Package"))
+	userRepo := database.NewUserRepository(client.Collection("User"))
+	moderationRepo := database.NewPhotoModerationRepository(client.Collection("PhotoModeration"))
+	gcService := services.NewStorageGCService(s3Service, packageRepo, userRepo, moderationRepo)
+
+	report, err := gcService.Run(context.Background(), services.StorageGCOptions{
+		Mode:                mode,
+		DryRun:              *dryRunFlag,
+		GracePeriod:         *graceFlag,
+		QuarantineRetention: *retentionFlag,
+	})
+	if err != nil {
+		log.Fatalf("Error running storage GC: %v", err)
+	}
+
+	if *jsonFlag {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("Error printing report: %v", err)
+		}
+		return
+	}
+	fmt.Printf("Scanned %d objects: %d referenced, %d recent, %d orphaned (%d bytes)\n",
+		report.Scanned, report.Referenced, report.Recent, len(report.Orphans), report.OrphanBytes)
+	for _, object := range report.Orphans {
+		fmt.Printf("%s\t%d\t%s\n", object.Key, object.Size, object.LastModified.Format("2006-01-02 15:04:05"))
+	}
+	if report.DryRun {
+		fmt.Printf("Dry run, %d orphans would be %sd and %d quarantined objects purged\n", len(report.Orphans), report.Mode, report.Purged)
+	} else {
+		fmt.Printf("%d orphans %sd, %d quarantined objects purged\n", report.Collected, report.Mode, report.Purged)
+	}
+	for _, e := range report.Errors {
+		fmt.Println("Error:", e)
+	}
+}
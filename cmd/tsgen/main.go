@@ -16,14 +16,20 @@ func main() {
 
 	// Add models
 	converter.
+		Add(models.Image{}).
 		Add(models.Package{}).
 		Add(dto.PackageRequest{}).
 		Add(dto.PackageResponse{}).
+		Add(dto.PackagePhotoAddRequest{}).
+		Add(dto.PackagePhotoOrderRequest{}).
+		Add(dto.PackagePhotoCaptionRequest{}).
 		AddEnum(models.ValidPackageTypes)
 	converter.
 		Add(dto.UserRequest{}).
 		Add(dto.UserResponse{}).
+		Add(dto.StorageUsageResponse{}).
 		Add(dto.CheckProviderResponse{}).
+		Add(models.UserDeletion{}).
 		AddEnum(models.ValidUserRoles).
 		AddEnum(models.ValidBankNames)
 	converter.
@@ -45,10 +51,80 @@ func main() {
 	converter.
 		Add(dto.RatingRequest{}).
 		Add(dto.RatingResponse{})
+	converter.
+		Add(models.Promotion{}).
+		Add(models.AppointmentDiscount{}).
+		Add(dto.PromotionRequest{}).
+		Add(dto.PromotionPreviewResponse{}).
+		AddEnum(models.ValidPromotionScopes).
+		AddEnum(models.ValidDiscountTypes)
 	converter.
 		Add(dto.PaymentResponse{}).
 		Add(dto.PaymentURL{}).
-		AddEnum(models.ValidPaymentStatus)
+		Add(dto.TipRequest{}).
+		Add(models.PaymentJob{}).
+		Add(dto.PaymentRecoveryResponse{}).
+		AddEnum(models.ValidPaymentStatus).
+		AddEnum(models.ValidPaymentTypes).
+		AddEnum(models.ValidPaymentJobStatus)
+	converter.
+		Add(dto.UploadRequest{}).
+		Add(dto.UploadResponse{}).
+		AddEnum(models.ValidUploadPurposes)
+	converter.
+		Add(dto.GalleryPhotoRequest{}).
+		Add(dto.GalleryExpireRequest{}).
+		Add(dto.GalleryResponse{}).
+		Add(dto.GalleryPhotoResponse{}).
+		Add(models.GalleryProof{}).
+		Add(models.ProofSelection{}).
+		Add(dto.ProofSelectionRequest{}).
+		Add(dto.ProofResponse{}).
+		Add(dto.ProofPhotoResponse{}).
+		AddEnum(models.ValidGalleryStatus).
+		AddEnum(models.ValidProofSelectionStatus)
+	converter.
+		Add(dto.WatermarkRequest{}).
+		Add(dto.WatermarkResponse{}).
+		AddEnum(models.ValidWatermarkPositions)
+	converter.
+		Add(models.Notification{}).
+		AddEnum(models.ValidNotificationTypes)
+	converter.
+		Add(models.PhotoModeration{}).
+		Add(models.PhotoMatch{}).
+		Add(dto.PhotoModerationRejectRequest{}).
+		AddEnum(models.ValidPhotoModerationStatus)
+	converter.
+		Add(models.PhotographerVerification{}).
+		Add(dto.VerificationRequest{}).
+		Add(dto.VerificationResponse{}).
+		AddEnum(models.ValidVerificationStatus).
+		AddEnum(models.ValidIdentityDocumentTypes)
+	converter.
+		Add(dto.PhoneOTPRequest{}).
+		Add(dto.PhoneOTPResponse{}).
+		Add(dto.PhoneVerifyRequest{}).
+		Add(dto.PhoneVerifyResponse{})
+	converter.
+		Add(models.UserSuspension{}).
+		Add(models.AppointmentStatusOverride{}).
+		Add(models.PaymentTotals{}).
+		Add(dto.AdminUserRoleRequest{}).
+		Add(dto.AdminReasonRequest{}).
+		Add(dto.AdminSuspendRequest{}).
+		Add(dto.AdminAppointmentStatusRequest{}).
+		Add(dto.AdminUserListResponse{}).
+		Add(dto.AdminAppointmentListResponse{}).
+		Add(dto.AdminPaymentListResponse{}).
+		Add(dto.AdminStatsResponse{}).
+		AddEnum(models.ValidSuspensionTypes)
+	converter.
+		Add(models.AuditLog{}).
+		Add(models.AuditChange{}).
+		Add(dto.AdminAuditLogListResponse{}).
+		AddEnum(models.ValidAuditEntityTypes).
+		AddEnum(models.ValidAuditActions)
 
 	// Change to interface
 	converter.CreateInterface = true
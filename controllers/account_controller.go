@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountController struct {
+	Service *services.AccountService
+}
+
+func NewAccountController(service *services.AccountService) *AccountController {
+	return &AccountController{Service: service}
+}
+
+// ExportAccount godoc
+// @Tags User
+// @Summary Download the personal data of the user
+// @Description A ZIP of the profile, packages, subpackages, busy times, appointments, payments and ratings of the user as
+// @Description JSON files, with the uploaded profile picture, watermark logo and package photos
+// @Produce application/zip
+// @Success 200 {file} file
+// @Failure 500 {object} string "Internal Server Error"
+// @Router /user/me/export [get]
+func (ctrl *AccountController) ExportAccount(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	export, err := ctrl.Service.Export(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account, " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": services.AccountExportName(user)}))
+	c.Status(http.StatusOK)
+	if err := ctrl.Service.WriteExport(c.Writer, export); err != nil {
+		// The headers are sent already, the client gets a truncated archive
+		log.Println("Failed to stream the export of user", user.ID.Hex(), err)
+		c.Abort()
+	}
+}
+
+// DeleteAccount godoc
+// @Tags User
+// @Summary Delete the account of the user
+// @Description The account is deleted after a grace period, until then it can be restored. The appointments are anonymized,
+// @Description the payments are kept for the accounting and the rest of the personal data is deleted. An account with a
+// @Description pending or accepted appointment cannot be deleted.
+// @Success 200 {object} models.User
+// @Failure 400 {object} string "Bad Request"
+// @Router /user/me [delete]
+func (ctrl *AccountController) DeleteAccount(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	user, err := ctrl.Service.RequestDeletion(c.Request.Context(), user)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to delete account")
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// RestoreAccount godoc
+// @Tags User
+// @Summary Cancel the deletion of the account of the user
+// @Success 200 {object} models.User
+// @Failure 400 {object} string "Bad Request"
+// @Router /user/me/restore [post]
+func (ctrl *AccountController) RestoreAccount(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	user, err := ctrl.Service.CancelDeletion(c.Request.Context(), user)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to restore account")
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
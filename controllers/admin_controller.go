@@ -0,0 +1,476 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const adminMaxPageLimit = 100
+
+type AdminController struct {
+	Service *services.AdminService
+}
+
+func NewAdminController(service *services.AdminService) *AdminController {
+	return &AdminController{Service: service}
+}
+
+// GetUsers godoc
+// @Tags Admin
+// @Summary Search the users
+// @Param search query string false "Part of the email or the name"
+// @Param role query string false "Role of the users"
+// @Param page query int false "Page number, default is 1"
+// @Param limit query int false "Limit number of items per page, default is 10, at most 100"
+// @Success 200 {object} dto.AdminUserListResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /admin/users [get]
+func (ctrl *AdminController) GetUsers(c *gin.Context) {
+	role := models.UserRole(c.Query("role"))
+	if role != "" && !isValidUserRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+	page, limit := getAdminPagination(c)
+
+	users, total, err := ctrl.Service.SearchUsers(c.Request.Context(), c.Query("search"), role, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users, " + err.Error()})
+		return
+	}
+	for i := range users {
+		users[i] = *services.MaskedUser(&users[i])
+	}
+	c.JSON(http.StatusOK, dto.AdminUserListResponse{Users: users, Pagination: adminPagination(page, limit, total)})
+}
+
+// GetUser godoc
+// @Tags Admin
+// @Summary Get any user
+// @Param id path string true "User ID"
+// @Success 200 {object} models.User
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/users/{id} [get]
+func (ctrl *AdminController) GetUser(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+	user, err := ctrl.Service.GetUser(c.Request.Context(), id)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch user")
+		return
+	}
+	c.JSON(http.StatusOK, services.MaskedUser(user))
+}
+
+// UpdateUserRole godoc
+// @Tags Admin
+// @Summary Change the role of a user
+// @Param id path string true "User ID"
+// @Param request body dto.AdminUserRoleRequest true "Role Request"
+// @Success 200 {object} models.User
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/users/{id}/role [patch]
+func (ctrl *AdminController) UpdateUserRole(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+	var req dto.AdminUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	admin := middleware.GetUserFromContext(c)
+	user, err := ctrl.Service.SetUserRole(c.Request.Context(), admin, id, req.Role)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to change role")
+		return
+	}
+	c.JSON(http.StatusOK, services.MaskedUser(user))
+}
+
+// SuspendUser godoc
+// @Tags Admin
+// @Summary Suspend or ban a user
+// @Description The user is signed out and cannot use the API until the suspension expires or they are unsuspended, a ban never expires
+// @Param id path string true "User ID"
+// @Param request body dto.AdminSuspendRequest true "Suspend Request"
+// @Success 200 {object} models.User
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/users/{id}/suspend [post]
+func (ctrl *AdminController) SuspendUser(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+	var req dto.AdminSuspendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	admin := middleware.GetUserFromContext(c)
+	user, err := ctrl.Service.SuspendUser(c.Request.Context(), admin, id, &req)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to suspend user")
+		return
+	}
+	c.JSON(http.StatusOK, services.MaskedUser(user))
+}
+
+// UnsuspendUser godoc
+// @Tags Admin
+// @Summary Lift the suspension of a user
+// @Param id path string true "User ID"
+// @Success 200 {object} models.User
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/users/{id}/unsuspend [post]
+func (ctrl *AdminController) UnsuspendUser(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+	user, err := ctrl.Service.UnsuspendUser(c.Request.Context(), id)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to unsuspend user")
+		return
+	}
+	c.JSON(http.StatusOK, services.MaskedUser(user))
+}
+
+// RevokeUserSessions godoc
+// @Tags Admin
+// @Summary Sign a user out of every device
+// @Description The ID tokens of a sign in before now are refused, the user has to sign in again
+// @Param id path string true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/users/{id}/revoke-sessions [post]
+func (ctrl *AdminController) RevokeUserSessions(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+	admin := middleware.GetUserFromContext(c)
+	user, err := ctrl.Service.RevokeUserSessions(c.Request.Context(), admin, id)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to revoke sessions")
+		return
+	}
+	c.JSON(http.StatusOK, services.MaskedUser(user))
+}
+
+// GetAppointments godoc
+// @Tags Admin
+// @Summary Get the appointments of the platform, the newest first
+// @Param status query string false "Status of the appointments"
+// @Param userId query string false "Customer or photographer ID"
+// @Param page query int false "Page number, default is 1"
+// @Param limit query int false "Limit number of items per page, default is 10, at most 100"
+// @Success 200 {object} dto.AdminAppointmentListResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /admin/appointments [get]
+func (ctrl *AdminController) GetAppointments(c *gin.Context) {
+	status := models.AppointmentStatus(c.Query("status"))
+	if status != "" && !isValidAppointmentStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	var userId *primitive.ObjectID
+	if c.Query("userId") != "" {
+		id, err := primitive.ObjectIDFromHex(c.Query("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		userId = &id
+	}
+	page, limit := getAdminPagination(c)
+
+	items, total, err := ctrl.Service.SearchAppointments(c.Request.Context(), status, userId, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch appointments, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.AdminAppointmentListResponse{Appointments: items, Pagination: adminPagination(page, limit, total)})
+}
+
+// GetAppointment godoc
+// @Tags Admin
+// @Summary Get any appointment
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} models.Appointment
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/appointments/{id} [get]
+func (ctrl *AdminController) GetAppointment(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid appointment ID")
+	if !ok {
+		return
+	}
+	appointment, err := ctrl.Service.GetAppointment(c.Request.Context(), id)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch appointment")
+		return
+	}
+	c.JSON(http.StatusOK, appointment)
+}
+
+// ForceAppointmentStatus godoc
+// @Tags Admin
+// @Summary Change the status of an appointment
+// @Description Any status can be set, the customer and the photographer get a notification with the reason
+// @Param id path string true "Appointment ID"
+// @Param request body dto.AdminAppointmentStatusRequest true "Status Request"
+// @Success 200 {object} models.Appointment
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/appointments/{id}/status [patch]
+func (ctrl *AdminController) ForceAppointmentStatus(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid appointment ID")
+	if !ok {
+		return
+	}
+	var req dto.AdminAppointmentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	admin := middleware.GetUserFromContext(c)
+	appointment, err := ctrl.Service.ForceAppointmentStatus(c.Request.Context(), admin, id, &req)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to change appointment status")
+		return
+	}
+	c.JSON(http.StatusOK, appointment)
+}
+
+// GetPayments godoc
+// @Tags Admin
+// @Summary Get the payments of the platform, the newest first
+// @Param status query string false "Status of the customer payment"
+// @Param appointmentId query string false "Appointment ID"
+// @Param page query int false "Page number, default is 1"
+// @Param limit query int false "Limit number of items per page, default is 10, at most 100"
+// @Success 200 {object} dto.AdminPaymentListResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /admin/payments [get]
+func (ctrl *AdminController) GetPayments(c *gin.Context) {
+	status := models.PaymentStatus(c.Query("status"))
+	if status != "" && !isValidPaymentStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	var appointmentId *primitive.ObjectID
+	if c.Query("appointmentId") != "" {
+		id, err := primitive.ObjectIDFromHex(c.Query("appointmentId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+			return
+		}
+		appointmentId = &id
+	}
+	page, limit := getAdminPagination(c)
+
+	items, total, err := ctrl.Service.SearchPayments(c.Request.Context(), status, appointmentId, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payments, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.AdminPaymentListResponse{Payments: items, Pagination: adminPagination(page, limit, total)})
+}
+
+// GetPayment godoc
+// @Tags Admin
+// @Summary Get any payment
+// @Param id path string true "Payment ID"
+// @Success 200 {object} models.Payment
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/payments/{id} [get]
+func (ctrl *AdminController) GetPayment(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid payment ID")
+	if !ok {
+		return
+	}
+	payment, err := ctrl.Service.GetPayment(c.Request.Context(), id)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch payment")
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
+
+// RemovePackage godoc
+// @Tags Admin
+// @Summary Remove a package
+// @Description The package and its photos are deleted, the photographer gets a notification with the reason
+// @Param id path string true "Package ID"
+// @Param request body dto.AdminReasonRequest true "Reason Request"
+// @Success 200 {object} string "Package was removed successfully"
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/packages/{id} [delete]
+func (ctrl *AdminController) RemovePackage(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid package ID")
+	if !ok {
+		return
+	}
+	var req dto.AdminReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	if err := ctrl.Service.RemovePackage(c.Request.Context(), id, req.Reason); err != nil {
+		apperrors.HandleError(c, err, "Failed to remove package")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Package was removed successfully"})
+}
+
+// RemoveRating godoc
+// @Tags Admin
+// @Summary Remove a review
+// @Description The customer who wrote it gets a notification with the reason
+// @Param id path string true "Rating ID"
+// @Param request body dto.AdminReasonRequest true "Reason Request"
+// @Success 200 {object} string "Rating was removed successfully"
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/ratings/{id} [delete]
+func (ctrl *AdminController) RemoveRating(c *gin.Context) {
+	id, ok := getAdminObjectID(c, "Invalid rating ID")
+	if !ok {
+		return
+	}
+	var req dto.AdminReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	if err := ctrl.Service.RemoveRating(c.Request.Context(), id, req.Reason); err != nil {
+		apperrors.HandleError(c, err, "Failed to remove rating")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rating was removed successfully"})
+}
+
+// GetStats godoc
+// @Tags Admin
+// @Summary Get the statistics of the platform
+// @Success 200 {object} dto.AdminStatsResponse
+// @Router /admin/stats [get]
+func (ctrl *AdminController) GetStats(c *gin.Context) {
+	stats, err := ctrl.Service.GetStats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statistics, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetAuditLog godoc
+// @Tags Admin
+// @Summary Get the change history of an entity
+// @Description Every change of the entity with who made it, the latest first
+// @Param entityType path string true "Package, Subpackage, BusyTime, Appointment, Payment, Rating or User"
+// @Param id path string true "Entity ID"
+// @Param page query int false "Page number, default is 1"
+// @Param limit query int false "Limit number of items per page, default is 10, at most 100"
+// @Success 200 {object} dto.AdminAuditLogListResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /admin/audit/{entityType}/{id} [get]
+func (ctrl *AdminController) GetAuditLog(c *gin.Context) {
+	entityType := models.AuditEntityType(c.Param("entityType"))
+	if !isValidAuditEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity type"})
+		return
+	}
+	id, ok := getAdminObjectID(c, "Invalid entity ID")
+	if !ok {
+		return
+	}
+	page, limit := getAdminPagination(c)
+
+	logs, total, err := ctrl.Service.GetAuditLog(c.Request.Context(), entityType, id, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.AdminAuditLogListResponse{Logs: logs, Pagination: adminPagination(page, limit, total)})
+}
+
+func getAdminObjectID(c *gin.Context, message string) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return primitive.NilObjectID, false
+	}
+	return id, true
+}
+
+// getAdminPagination reads page and limit, page starts at 1 and limit is between 1 and adminMaxPageLimit
+func getAdminPagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > adminMaxPageLimit {
+		limit = adminMaxPageLimit
+	}
+	return page, limit
+}
+
+func adminPagination(page, limit, total int) dto.Pagination {
+	return dto.Pagination{Page: page, Limit: limit, MaxPage: (total + limit - 1) / limit, Total: total}
+}
+
+func isValidUserRole(role models.UserRole) bool {
+	for _, valid := range models.ValidUserRoles {
+		if valid.Value == role {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidAppointmentStatus(status models.AppointmentStatus) bool {
+	for _, valid := range models.ValidAppointmentStatus {
+		if valid.Value == status {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidAuditEntityType(entityType models.AuditEntityType) bool {
+	for _, valid := range models.ValidAuditEntityTypes {
+		if valid.Value == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidPaymentStatus(status models.PaymentStatus) bool {
+	for _, valid := range models.ValidPaymentStatus {
+		if valid.Value == status {
+			return true
+		}
+	}
+	return false
+}
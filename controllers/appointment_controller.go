@@ -10,6 +10,7 @@ import (
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/middleware"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -187,7 +188,7 @@ func (a *AppointmentController) GetAllAppointmentDetail(c *gin.Context) {
 // @Description Retrieve all available appointments detail that the user can see from the database
 // @Param id path string true "Appointment ID"
 // @Success 200 {object} dto.AppointmentDetail
-// @Failure 401 {object} string "Unauthorized"
+// @Failure 404 {object} string "Not Found"
 // @Router /appointment/detail/{id} [get]
 func (a *AppointmentController) GetAppointmentDetailById(c *gin.Context) {
 	user := middleware.GetUserFromContext(c)
@@ -217,7 +218,7 @@ func (a *AppointmentController) GetAppointmentDetailById(c *gin.Context) {
 // @Param id path string true "Appointment ID"
 // @Success 200 {object} dto.AppointmentResponse
 // @Failure 400 {object} string "Invalid appointment id"
-// @Failure 401 {object} string "Unauthorized"
+// @Failure 404 {object} string "Not Found"
 // @Failure 500 {object} string "Internal Server Error"
 // @Router /appointment/{id} [get]
 func (a *AppointmentController) GetAppointmentById(c *gin.Context) {
@@ -247,11 +248,16 @@ func (a *AppointmentController) GetAppointmentById(c *gin.Context) {
 // @Success 200 {object} dto.CreateAppointmentResponse
 // @Failure 400 {object} string "Invalid appointment id"
 // @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden, the phone is not verified when it is required or the photographer is not verified"
 // @Failure 500 {object} string "Internal Server Error"
 // @Router /appointment/{subpackageId} [post]
 func (a *AppointmentController) CreateAppointment(c *gin.Context) {
 	// user
 	user := middleware.GetUserFromContext(c)
+	if err := a.AppointmentService.CheckCanBook(user); err != nil {
+		apperrors.HandleError(c, err, "Cannot create appointment")
+		return
+	}
 
 	loc, _ := time.LoadLocation("Asia/Bangkok")
 	// request
@@ -262,6 +268,10 @@ func (a *AppointmentController) CreateAppointment(c *gin.Context) {
 		apperrors.HandleError(c, err, "Cannot get subpackageId from param")
 		return
 	}
+	if err := a.AppointmentService.CheckBookable(c.Request.Context(), subpackageId); err != nil {
+		apperrors.HandleError(c, err, "Cannot create appointment")
+		return
+	}
 	var req dto.AppointmentStrictRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
@@ -319,7 +329,8 @@ func (a *AppointmentController) CreateAppointment(c *gin.Context) {
 // @Param id path string true "Appointment ID"
 // @Success 200 {object} dto.AppointmentResponse
 // @Failure 400 {object} string "Invalid appointment id"
-// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
 // @Failure 500 {object} string "Internal Server Error"
 // @Router /appointment/status/{id} [patch]
 func (a *AppointmentController) UpdateAppointmentStatus(c *gin.Context) {
@@ -347,7 +358,8 @@ func (a *AppointmentController) UpdateAppointmentStatus(c *gin.Context) {
 		return
 	}
 
-	appointment, err := a.AppointmentService.GetAppointmentById(c.Request.Context(), user, appointmentId)
+	// Only the photographer can accept the appointment
+	appointment, err := a.AppointmentService.GetAuthorized(c.Request.Context(), user, appointmentId, policies.AppointmentStatusAction(req.Status))
 	if err != nil {
 		apperrors.HandleError(c, err, "Cannot get the appointment from this appointmentId")
 		return
@@ -370,13 +382,6 @@ func (a *AppointmentController) UpdateAppointmentStatus(c *gin.Context) {
 		return
 	}
 
-	// TODO: refactor in route later
-	// Customer can't accept appointment
-	if user.Role == models.Customer && req.Status == models.AppointmentAccepted {
-		apperrors.HandleError(c, apperrors.ErrUnauthorized, "Customer can't accepted an appointment.")
-		return
-	}
-
 	validStatus := false
 	if appointment.Status == models.AppointmentPending && req.Status == models.AppointmentAccepted { // accept pending status
 		validStatus = true // from "Pending" to "Accepted" => isValid = true (reserve a photographer busyTime)
@@ -406,23 +411,22 @@ func (a *AppointmentController) UpdateAppointmentStatus(c *gin.Context) {
 // DeleteAppointment godoc
 // @Tags Appointment
 // @Summary Delete appointment
-// @Description Delete a specific appointment by its ID
+// @Description Cancel a specific appointment by its ID on behalf of an admin, it is kept with the override. A completed or paid appointment cannot be deleted.
 // @Param id path string true "Appointment ID"
 // @Success 200 {object} string "Appointment was deleted successfully"
-// @Failure 400 {object} string "Invalid appointment id"
-// @Failure 401 {object} string "Unauthorized"
+// @Failure 400 {object} string "Invalid appointment id, or the appointment is completed or paid"
+// @Failure 404 {object} string "Not Found"
 // @Failure 500 {object} string "Internal Server Error"
 // @Router /appointment/{id} [delete]
-func (a *AppointmentController) DeleteAppointment(c *gin.Context) { // only admin
+func (a *AppointmentController) DeleteAppointment(c *gin.Context) {
 	user := middleware.GetUserFromContext(c)
-
 	appointmentId, err := getIDFromParam(c)
 	if err != nil {
 		apperrors.HandleError(c, err, "Cannot get appointmentId from param.")
 		return
 	}
 
-	if err := a.AppointmentService.DeleteAppointment(c.Request.Context(), appointmentId, user); err != nil {
+	if err := a.AppointmentService.DeleteAppointment(c.Request.Context(), user, appointmentId); err != nil {
 		apperrors.HandleError(c, err, "Cannot delete this appointment.")
 		return
 	}
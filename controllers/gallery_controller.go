@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GalleryController struct {
+	Service            *services.GalleryService
+	AppointmentService *services.AppointmentService
+}
+
+func NewGalleryController(service *services.GalleryService, appointmentService *services.AppointmentService) *GalleryController {
+	return &GalleryController{Service: service, AppointmentService: appointmentService}
+}
+
+// GetGallery godoc
+// @Tags Gallery
+// @Summary Get the delivery gallery of an appointment
+// @Description The photographer always sees the gallery, the customer sees it once it is delivered and the payment is paid.
+// @Description Photo URLs are signed and expire after an hour, archived galleries have no URL.
+// @Param appointmentId path string true "Appointment ID"
+// @Success 200 {object} dto.GalleryResponse
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
+// @Router /gallery/{appointmentId} [get]
+func (ctrl *GalleryController) GetGallery(c *gin.Context) {
+	appointment, ok := ctrl.getAppointment(c)
+	if !ok {
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.GetGallery(c.Request.Context(), user, appointment)
+	ctrl.respondGallery(c, http.StatusOK, gallery, err)
+}
+
+// DownloadGallery godoc
+// @Tags Gallery
+// @Summary Download every photo of a gallery as a ZIP
+// @Description The ZIP is streamed as it is generated from the storage. Large galleries also keep a pre-built ZIP in the storage,
+// @Description once it is built the request is redirected to its signed URL, which supports range requests to resume the download.
+// @Param appointmentId path string true "Appointment ID"
+// @Produce application/zip
+// @Success 200 {file} file
+// @Success 302 {string} string "Redirect to the pre-built archive"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
+// @Router /gallery/{appointmentId}/download [get]
+func (ctrl *GalleryController) DownloadGallery(c *gin.Context) {
+	appointment, ok := ctrl.getAppointment(c)
+	if !ok {
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.GetGallery(c.Request.Context(), user, appointment)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch gallery")
+		return
+	}
+	if gallery.Status == models.GalleryArchived {
+		apperrors.HandleError(c, apperrors.ErrGalleryArchived, "Failed to download gallery")
+		return
+	}
+	if len(gallery.Photos) == 0 {
+		apperrors.HandleError(c, apperrors.ErrGalleryEmpty, "Failed to download gallery")
+		return
+	}
+
+	url, prebuilt, err := ctrl.Service.GetDownloadArchiveURL(gallery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign archive URL, " + err.Error()})
+		return
+	}
+	if prebuilt {
+		c.Redirect(http.StatusFound, url)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": services.GalleryArchiveName(gallery)}))
+	c.Status(http.StatusOK)
+	if err := ctrl.Service.WriteArchive(c.Writer, gallery); err != nil {
+		// The headers are sent already, the client gets a truncated archive
+		log.Println("Failed to stream the archive of gallery", gallery.ID.Hex(), err)
+		c.Abort()
+	}
+}
+
+// CreateGallery godoc
+// @Tags Gallery
+// @Summary Create the delivery gallery of a completed appointment
+// @Param appointmentId path string true "Appointment ID"
+// @Success 201 {object} dto.GalleryResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /gallery/{appointmentId} [post]
+func (ctrl *GalleryController) CreateGallery(c *gin.Context) {
+	appointment, ok := ctrl.getAppointment(c)
+	if !ok {
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.CreateGallery(c.Request.Context(), user, appointment)
+	ctrl.respondGallery(c, http.StatusCreated, gallery, err)
+}
+
+// AddGalleryPhotos godoc
+// @Tags Gallery
+// @Summary Add photos to a gallery
+// @Description Move confirmed GalleryPhoto uploads into the gallery, the files are kept original
+// @Param appointmentId path string true "Appointment ID"
+// @Param request body dto.GalleryPhotoRequest true "Gallery Photo Request"
+// @Success 200 {object} dto.GalleryResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /gallery/{appointmentId}/photos [post]
+func (ctrl *GalleryController) AddGalleryPhotos(c *gin.Context) {
+	gallery, ok := ctrl.getOwnedGallery(c)
+	if !ok {
+		return
+	}
+
+	var req dto.GalleryPhotoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.AddPhotos(c.Request.Context(), user, gallery, req.UploadIds)
+	ctrl.respondGallery(c, http.StatusOK, gallery, err)
+}
+
+// RemoveGalleryPhoto godoc
+// @Tags Gallery
+// @Summary Remove a photo from a gallery
+// @Param appointmentId path string true "Appointment ID"
+// @Param photoId path string true "Photo ID"
+// @Success 200 {object} dto.GalleryResponse
+// @Failure 404 {object} string "Not Found"
+// @Router /gallery/{appointmentId}/photos/{photoId} [delete]
+func (ctrl *GalleryController) RemoveGalleryPhoto(c *gin.Context) {
+	gallery, ok := ctrl.getOwnedGallery(c)
+	if !ok {
+		return
+	}
+	photoId, err := primitive.ObjectIDFromHex(c.Param("photoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+		return
+	}
+
+	gallery, err = ctrl.Service.RemovePhoto(c.Request.Context(), gallery, photoId)
+	ctrl.respondGallery(c, http.StatusOK, gallery, err)
+}
+
+// DeliverGallery godoc
+// @Tags Gallery
+// @Summary Deliver a gallery to the customer
+// @Param appointmentId path string true "Appointment ID"
+// @Success 200 {object} dto.GalleryResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /gallery/{appointmentId}/deliver [post]
+func (ctrl *GalleryController) DeliverGallery(c *gin.Context) {
+	gallery, ok := ctrl.getOwnedGallery(c)
+	if !ok {
+		return
+	}
+
+	gallery, err := ctrl.Service.Deliver(c.Request.Context(), gallery)
+	ctrl.respondGallery(c, http.StatusOK, gallery, err)
+}
+
+// UpdateGalleryExpireTime godoc
+// @Tags Gallery
+// @Summary Change when a gallery expires and is archived
+// @Param appointmentId path string true "Appointment ID"
+// @Param request body dto.GalleryExpireRequest true "Gallery Expire Request"
+// @Success 200 {object} dto.GalleryResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /gallery/{appointmentId}/expire [patch]
+func (ctrl *GalleryController) UpdateGalleryExpireTime(c *gin.Context) {
+	gallery, ok := ctrl.getOwnedGallery(c)
+	if !ok {
+		return
+	}
+
+	var req dto.GalleryExpireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	gallery, err := ctrl.Service.UpdateExpireTime(c.Request.Context(), gallery, req.ExpireTime)
+	ctrl.respondGallery(c, http.StatusOK, gallery, err)
+}
+
+func (ctrl *GalleryController) getAppointment(c *gin.Context) (*models.Appointment, bool) {
+	appointmentId, err := primitive.ObjectIDFromHex(c.Param("appointmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return nil, false
+	}
+	user := middleware.GetUserFromContext(c)
+	appointment, err := ctrl.AppointmentService.GetAppointmentById(c.Request.Context(), user, appointmentId)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch appointment")
+		return nil, false
+	}
+	return appointment, true
+}
+
+func (ctrl *GalleryController) getOwnedGallery(c *gin.Context) (*models.Gallery, bool) {
+	appointment, ok := ctrl.getAppointment(c)
+	if !ok {
+		return nil, false
+	}
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.GetOwnedGallery(c.Request.Context(), user, appointment)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch gallery")
+		return nil, false
+	}
+	return gallery, true
+}
+
+func (ctrl *GalleryController) respondGallery(c *gin.Context, status int, gallery *models.Gallery, err error) {
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to process gallery")
+		return
+	}
+	res, err := ctrl.Service.MapToGalleryResponse(gallery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign photo URLs, " + err.Error()})
+		return
+	}
+	c.JSON(status, res)
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationController struct {
+	Service *services.NotificationService
+}
+
+func NewNotificationController(service *services.NotificationService) *NotificationController {
+	return &NotificationController{Service: service}
+}
+
+// GetNotifications godoc
+// @Tags Notification
+// @Summary Get the latest notifications of the user
+// @Success 200 {object} []models.Notification
+// @Failure 500 {object} string "Internal Server Error"
+// @Router /notification [get]
+func (ctrl *NotificationController) GetNotifications(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	items, err := ctrl.Service.GetByUserId(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// ReadNotification godoc
+// @Tags Notification
+// @Summary Mark a notification as read
+// @Param id path string true "Notification ID"
+// @Success 200 {object} string "OK"
+// @Failure 404 {object} string "Not Found"
+// @Router /notification/{id}/read [patch]
+func (ctrl *NotificationController) ReadNotification(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+	user := middleware.GetUserFromContext(c)
+	found, err := ctrl.Service.MarkRead(c.Request.Context(), id, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification, " + err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
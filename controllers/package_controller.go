@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/middleware"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -148,13 +150,12 @@ func (ctrl *PackageController) GetRecommendedPackages(c *gin.Context) {
 // @Param id path string true "Package ID"
 // @Success 200 {object} dto.PackageResponse
 // @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
 // @Router /package/{id} [get]
 // @x-order 2
 func (ctrl *PackageController) GetOnePackage(c *gin.Context) {
-	id := c.Param("id")
-	item, err := ctrl.Service.GetById(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to fetch item, " + err.Error()})
+	item, ok := ctrl.getAuthorizedPackage(c, policies.ActionRead)
+	if !ok {
 		return
 	}
 
@@ -187,12 +188,13 @@ func (ctrl *PackageController) CreateOnePackage(c *gin.Context) {
 	}
 	if err := ctrl.S3Service.VerifyMultipleBase64(*itemInput.Photos); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request Image, " + err.Error()})
+		return
 	}
 
 	user := middleware.GetUserFromContext(c)
 	item, err := ctrl.Service.CreateOne(c.Request.Context(), &itemInput, user.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item, " + err.Error()})
+		apperrors.HandleError(c, err, "Failed to create item")
 		return
 	}
 
@@ -211,24 +213,13 @@ func (ctrl *PackageController) CreateOnePackage(c *gin.Context) {
 // @Param request body dto.PackageRequest true "Replace Package Request"
 // @Success 200 {object} dto.PackageResponse
 // @Failure 400 {object} string "Bad Request"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
 // @Router /package/{id} [patch]
 // @x-order 4
 func (ctrl *PackageController) UpdateOnePackage(c *gin.Context) {
 	id := c.Param("id")
-	err := ctrl.Service.CheckPackageExist(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID, " + err.Error()})
-		return
-	}
-	// Check the owner, only the owner can update the package
-	user := middleware.GetUserFromContext(c)
-	isOwner, err := ctrl.Service.CheckOwner(c.Request.Context(), user, id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check owner, " + err.Error()})
-		return
-	}
-	if !isOwner {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not own the package"})
+	if _, ok := ctrl.getAuthorizedPackage(c, policies.ActionUpdate); !ok {
 		return
 	}
 
@@ -240,12 +231,13 @@ func (ctrl *PackageController) UpdateOnePackage(c *gin.Context) {
 	if updates.Photos != nil && len(*updates.Photos) > 0 {
 		if err := ctrl.S3Service.VerifyMultipleBase64(*updates.Photos); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request Image, " + err.Error()})
+			return
 		}
 	}
 
 	item, err := ctrl.Service.UpdateOne(c.Request.Context(), id, &updates)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update item, " + err.Error()})
+		apperrors.HandleError(c, err, "Failed to update item")
 		return
 	}
 
@@ -264,24 +256,13 @@ func (ctrl *PackageController) UpdateOnePackage(c *gin.Context) {
 // @Param id path string true "Package ID"
 // @Success 200 {object} string "OK"
 // @Failure 400 {object} string "Bad Request"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
 // @Router /package/{id} [delete]
 // @x-order 5
 func (ctrl *PackageController) DeleteOnePackage(c *gin.Context) {
 	id := c.Param("id")
-	err := ctrl.Service.CheckPackageExist(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID, " + err.Error()})
-		return
-	}
-	// Check the owner, only the owner can delete the package
-	user := middleware.GetUserFromContext(c)
-	isOwner, err := ctrl.Service.CheckOwner(c.Request.Context(), user, id)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to check owner, " + err.Error()})
-		return
-	}
-	if !isOwner {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not own the package"})
+	if _, ok := ctrl.getAuthorizedPackage(c, policies.ActionDelete); !ok {
 		return
 	}
 
@@ -325,3 +306,170 @@ func (ctrl *PackageController) DeleteOnePackage(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Item deleted successfully"})
 }
+
+// AddPackagePhotos godoc
+// @Tags Package
+// @Summary Add photos to a package
+// @Description Append base64 photos or confirmed uploads after the current photos, the existing photos are kept
+// @Param id path string true "Package ID"
+// @Param request body dto.PackagePhotoAddRequest true "Add Photos Request"
+// @Success 200 {object} dto.PackageResponse
+// @Failure 400 {object} string "Bad Request"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
+// @Router /package/{id}/photos [post]
+func (ctrl *PackageController) AddPackagePhotos(c *gin.Context) {
+	pkg, ok := ctrl.getAuthorizedPackage(c, policies.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req dto.PackagePhotoAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	if req.Photos == nil && req.PhotoUploadIds == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, photos or photoUploadIds is required"})
+		return
+	}
+	if req.Photos != nil {
+		if err := ctrl.S3Service.VerifyMultipleBase64(*req.Photos); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request Image, " + err.Error()})
+			return
+		}
+	}
+
+	item, err := ctrl.Service.AddPhotos(c.Request.Context(), pkg, &req)
+	ctrl.respondPackage(c, item, err)
+}
+
+// RemovePackagePhoto godoc
+// @Tags Package
+// @Summary Remove a photo from a package
+// @Param id path string true "Package ID"
+// @Param photoId path string true "Photo ID"
+// @Success 200 {object} dto.PackageResponse
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
+// @Router /package/{id}/photos/{photoId} [delete]
+func (ctrl *PackageController) RemovePackagePhoto(c *gin.Context) {
+	pkg, ok := ctrl.getAuthorizedPackage(c, policies.ActionUpdate)
+	if !ok {
+		return
+	}
+	photoId, err := primitive.ObjectIDFromHex(c.Param("photoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+		return
+	}
+
+	item, err := ctrl.Service.RemovePhoto(c.Request.Context(), pkg, photoId)
+	ctrl.respondPackage(c, item, err)
+}
+
+// ReorderPackagePhotos godoc
+// @Tags Package
+// @Summary Reorder the photos of a package
+// @Description The ids must list every photo of the package exactly once, the first photo becomes the cover
+// @Param id path string true "Package ID"
+// @Param request body dto.PackagePhotoOrderRequest true "Photo Order Request"
+// @Success 200 {object} dto.PackageResponse
+// @Failure 400 {object} string "Bad Request"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
+// @Router /package/{id}/photos/order [put]
+func (ctrl *PackageController) ReorderPackagePhotos(c *gin.Context) {
+	pkg, ok := ctrl.getAuthorizedPackage(c, policies.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req dto.PackagePhotoOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	item, err := ctrl.Service.ReorderPhotos(c.Request.Context(), pkg, req.PhotoIds)
+	ctrl.respondPackage(c, item, err)
+}
+
+// SetPackageCoverPhoto godoc
+// @Tags Package
+// @Summary Set the cover photo of a package
+// @Description Move the photo to the front of the package photos
+// @Param id path string true "Package ID"
+// @Param photoId path string true "Photo ID"
+// @Success 200 {object} dto.PackageResponse
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
+// @Router /package/{id}/photos/{photoId}/cover [put]
+func (ctrl *PackageController) SetPackageCoverPhoto(c *gin.Context) {
+	pkg, ok := ctrl.getAuthorizedPackage(c, policies.ActionUpdate)
+	if !ok {
+		return
+	}
+	photoId, err := primitive.ObjectIDFromHex(c.Param("photoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+		return
+	}
+
+	item, err := ctrl.Service.SetCoverPhoto(c.Request.Context(), pkg, photoId)
+	ctrl.respondPackage(c, item, err)
+}
+
+// UpdatePackagePhotoCaption godoc
+// @Tags Package
+// @Summary Update the caption of a package photo
+// @Param id path string true "Package ID"
+// @Param photoId path string true "Photo ID"
+// @Param request body dto.PackagePhotoCaptionRequest true "Photo Caption Request"
+// @Success 200 {object} dto.PackageResponse
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
+// @Router /package/{id}/photos/{photoId} [patch]
+func (ctrl *PackageController) UpdatePackagePhotoCaption(c *gin.Context) {
+	pkg, ok := ctrl.getAuthorizedPackage(c, policies.ActionUpdate)
+	if !ok {
+		return
+	}
+	photoId, err := primitive.ObjectIDFromHex(c.Param("photoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+		return
+	}
+
+	var req dto.PackagePhotoCaptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	item, err := ctrl.Service.UpdatePhotoCaption(c.Request.Context(), pkg, photoId, req.Caption)
+	ctrl.respondPackage(c, item, err)
+}
+
+func (ctrl *PackageController) getAuthorizedPackage(c *gin.Context, action policies.Action) (*models.Package, bool) {
+	user := middleware.GetUserFromContext(c)
+	pkg, err := ctrl.Service.GetAuthorized(c.Request.Context(), user, c.Param("id"), action)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch package")
+		return nil, false
+	}
+	return pkg, true
+}
+
+func (ctrl *PackageController) respondPackage(c *gin.Context, item *models.Package, err error) {
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to update package photos")
+		return
+	}
+	res, err := ctrl.Service.MappedToPackageResponse(c.Request.Context(), item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to map item, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
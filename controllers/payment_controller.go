@@ -7,10 +7,13 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/middleware"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 	"github.com/stripe/stripe-go/v81"
@@ -62,20 +65,16 @@ func (ctrl *PaymentController) GetAllOwnedPayments(c *gin.Context) {
 // @Description Retrieve a payment from given id which is owned by the user in the jwt
 // @Param id path string true "Payment ID"
 // @Success 200 {object} dto.PaymentResponse
-// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
 // @Router /payment/{id} [get]
 func (ctrl *PaymentController) GetPaymentById(c *gin.Context) {
 	id := c.Param("id")
 	user := middleware.GetUserFromContext(c)
 
 	oid, _ := primitive.ObjectIDFromHex(id)
-	payment, err := ctrl.Service.GetPaymentById(c.Request.Context(), oid)
+	payment, err := ctrl.Service.GetAuthorized(c.Request.Context(), user, oid, policies.ActionRead)
 	if err != nil {
-		if err.Error() == "mongo: no documents in result" {
-			c.JSON(404, gin.H{"error": "Payment not found"})
-			return
-		}
-		c.JSON(500, gin.H{"error": err.Error()})
+		apperrors.HandleError(c, err, "Failed to fetch payment")
 		return
 	}
 
@@ -84,11 +83,6 @@ func (ctrl *PaymentController) GetPaymentById(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
-
-	if response.Appointment.CustomerID != user.ID && response.Appointment.PhotographerID != user.ID {
-		c.JSON(403, gin.H{"error": "You are not authorized to view this payment"})
-		return
-	}
 	c.JSON(200, response)
 }
 
@@ -126,12 +120,125 @@ func (ctrl *PaymentController) CreatePayment(c *gin.Context) {
 	c.JSON(200, response)
 }
 
+// CreateTip godoc
+// @Tags Payment
+// @Summary Tip the photographer of a completed appointment
+// @Description Create a checkout session for a tip, the whole amount goes to the verified photographer without platform fee
+// @Param appointmentId path string true "Appointment ID"
+// @Param successURL query string false "success URL"
+// @Param cancelURL query string false "cancel URL"
+// @Param request body dto.TipRequest true "Tip Request"
+// @Success 200 {object} dto.PaymentURL
+// @Failure 400 {object} string "Bad Request"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
+// @Router /payment/tip/{appointmentId} [post]
+func (ctrl *PaymentController) CreateTip(c *gin.Context) {
+	cancelURLParam, _ := c.GetQuery("cancelURL")
+	successURLParam, _ := c.GetQuery("successURL")
+
+	appointmentId, err := primitive.ObjectIDFromHex(c.Param("appointmentId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid appointment ID format"})
+		return
+	}
+	var req dto.TipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	_, checkoutSession, err := ctrl.Service.CreateTip(c.Request.Context(), user, appointmentId, req.Amount, successURLParam, cancelURLParam)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to create tip")
+		return
+	}
+	c.JSON(200, dto.PaymentURL{URL: checkoutSession.URL})
+}
+
+// ExportPayments godoc
+// @Tags Payment
+// @Summary Export the payments of the user for accounting
+// @Description Download payments, fees, refunds and payout dates created in the date range as CSV or XLSX, admin gets the whole platform
+// @Param from query string false "Start date (YYYY-MM-DD), default is the start of this year"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), default is today"
+// @Param format query string false "csv or xlsx, default is csv"
+// @Success 200 {file} file
+// @Failure 400 {object} string "Bad Request"
+// @Router /payment/export [get]
+func (ctrl *PaymentController) ExportPayments(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+
+	// The dates are days in the time zone the payments are printed in
+	now := time.Now().In(services.PaymentExportLocation)
+	from, err := parseExportDate(c.Query("from"), time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, " + err.Error()})
+		return
+	}
+	to, err := parseExportDate(c.Query("to"), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, " + err.Error()})
+		return
+	}
+	// The end date is inclusive
+	to = to.AddDate(0, 0, 1)
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	var writer services.PaymentExportWriter
+	if format == "xlsx" {
+		writer, err = services.NewPaymentExportXLSXWriter(c.Writer)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the export, " + err.Error()})
+			return
+		}
+	} else {
+		writer = services.NewPaymentExportCSVWriter(c.Writer)
+	}
+	defer writer.Close()
+
+	filename := fmt.Sprintf("payments_%s_%s.%s", from.Format("20060102"), to.AddDate(0, 0, -1).Format("20060102"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	if format == "xlsx" {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	} else {
+		c.Header("Content-Type", "text/csv")
+	}
+	// The rows are written as the payments are read
+	if err := ctrl.Service.ExportPayments(c.Request.Context(), *user, from, to, writer); err != nil {
+		if !c.Writer.Written() {
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payments, " + err.Error()})
+			return
+		}
+		// The body is partially written, only log the error
+		fmt.Println("Error writing payment export: ", err)
+	}
+}
+
+func parseExportDate(value string, defaultValue time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Date(defaultValue.Year(), defaultValue.Month(), defaultValue.Day(), 0, 0, 0, 0, defaultValue.Location()), nil
+	}
+	return time.ParseInLocation("2006-01-02", value, services.PaymentExportLocation)
+}
+
 // GetOnBoardAccountURL godoc
 // @Tags Payment
 // @Summary Create stripe onboarding account URL for photographer
-// @Description Create stripe onboarding account URL for photographer
+// @Description Create stripe onboarding account URL for photographer, the photographer must be verified
 // @Success 200 {object} dto.PaymentURL
 // @Failure 400 {object} string "Bad Request"
+// @Failure 403 {object} string "Forbidden"
 // @Router /payment/onboardingURL [get]
 func (ctrl *PaymentController) GetOnBoardAccountURL(c *gin.Context) {
 	user := middleware.GetUserFromContext(c)
@@ -140,12 +247,12 @@ func (ctrl *PaymentController) GetOnBoardAccountURL(c *gin.Context) {
 	if user.StripeAccountID == nil {
 		account, err := ctrl.Service.RegisterConnectedAccount(c.Request.Context(), *user)
 		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
+			apperrors.HandleError(c, err, "Failed to register account")
 			return
 		}
 		accountId = account.ID
 	} else {
-		accountId = *user.StripeAccountID
+		accountId = string(*user.StripeAccountID)
 	}
 
 	accountLink, err := ctrl.Service.CreateAccountLink(c.Request.Context(), accountId)
@@ -173,7 +280,7 @@ func (ctrl *PaymentController) GetOnBoardAccountURL(c *gin.Context) {
 // 		return
 // 	}
 
-// 	loginLink, err := ctrl.Service.CreateLoginLink(c.Request.Context(), *user.StripeAccountID)
+// 	loginLink, err := ctrl.Service.CreateLoginLink(c.Request.Context(), string(*user.StripeAccountID))
 // 	if err != nil {
 // 		c.JSON(500, gin.H{"error": err.Error()})
 // 		return
@@ -224,6 +331,18 @@ func (ctrl *PaymentController) WebhookListener(c *gin.Context) {
 		fmt.Printf("Checkout session %s completed\n", session.ID)
 		// Handle the successful session completed
 		ctrl.Service.UpdateCheckoutCompleted(c.Request.Context(), session)
+	case "checkout.session.expired":
+		var session stripe.CheckoutSession
+		if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Error parsing checkout session JSON"})
+			return
+		}
+		fmt.Printf("Checkout session %s expired\n", session.ID)
+		// Regenerate the checkout session so the customer can still pay
+		err := ctrl.Service.UpdateCheckoutExpired(c.Request.Context(), session)
+		if err != nil {
+			fmt.Println("Error regenerating checkout session: ", err)
+		}
 	case "charge.updated":
 		var charge stripe.Charge
 		if err := json.Unmarshal(event.Data.Raw, &charge); err != nil {
@@ -236,9 +355,20 @@ func (ctrl *PaymentController) WebhookListener(c *gin.Context) {
 		if err != nil {
 			fmt.Println("Error updating photographer payment status: ", err)
 		}
+	case "charge.refunded":
+		var charge stripe.Charge
+		if err := json.Unmarshal(event.Data.Raw, &charge); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Error parsing charge JSON"})
+			return
+		}
+		fmt.Printf("Charge %s refunded\n", charge.ID)
+		err := ctrl.Service.UpdateRefunded(c.Request.Context(), charge)
+		if err != nil {
+			fmt.Println("Error updating refunded amount: ", err)
+		}
 	case "payout.paid":
 		var payout stripe.Payout
-		if err := json.Unmarshal(event.Data.Raw, &payout); err == nil {
+		if err := json.Unmarshal(event.Data.Raw, &payout); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Error parsing payout JSON"})
 			return
 		}
@@ -262,8 +392,15 @@ func (ctrl *PaymentController) mapToPaymentResponse(ctx context.Context, payment
 	if err != nil {
 		return nil, err
 	}
-	return &dto.PaymentResponse{
+	response := &dto.PaymentResponse{
 		Payment:     payment,
 		Appointment: *appointmentDetail,
-	}, nil
+	}
+	if payment.IsAppointmentPayment() {
+		response.TipTotal, err = ctrl.Service.GetTipTotal(ctx, payment.AppointmentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
 }
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PaymentJobController struct {
+	Service *services.PaymentJobService
+}
+
+func NewPaymentJobController(service *services.PaymentJobService) *PaymentJobController {
+	return &PaymentJobController{Service: service}
+}
+
+// GetPaymentJobs godoc
+// @Tags Payment
+// @Summary Get a list of payment creation jobs
+// @Description Retrieve the payment creation jobs of completed appointments, optionally filtered by status
+// @Param status query string false "Job status (Pending, Succeeded, Failed)"
+// @Success 200 {object} []models.PaymentJob
+// @Failure 400 {object} string "Bad Request"
+// @Router /payment/jobs [get]
+func (ctrl *PaymentJobController) GetPaymentJobs(c *gin.Context) {
+	items, err := ctrl.Service.GetAll(c.Request.Context(), models.PaymentJobStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetUnpaidCompletedAppointments godoc
+// @Tags Payment
+// @Summary Get completed appointments without a valid payment
+// @Description Retrieve completed appointments whose payment is missing or whose checkout session has expired
+// @Success 200 {object} []dto.PaymentRecoveryResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /payment/recovery [get]
+func (ctrl *PaymentJobController) GetUnpaidCompletedAppointments(c *gin.Context) {
+	items, err := ctrl.Service.ListUnpaidCompleted(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// RedrivePayment godoc
+// @Tags Payment
+// @Summary Re-drive the payment creation of a completed appointment
+// @Description Reset the payment job of the appointment and run it immediately
+// @Param appointmentId path string true "Appointment ID"
+// @Success 200 {object} models.PaymentJob
+// @Failure 400 {object} string "Bad Request"
+// @Router /payment/recovery/{appointmentId} [post]
+func (ctrl *PaymentJobController) RedrivePayment(c *gin.Context) {
+	appointmentId, err := primitive.ObjectIDFromHex(c.Param("appointmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID format"})
+		return
+	}
+
+	job, err := ctrl.Service.Redrive(c.Request.Context(), appointmentId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-drive payment, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PhoneController struct {
+	Service *services.PhoneService
+}
+
+func NewPhoneController(service *services.PhoneService) *PhoneController {
+	return &PhoneController{Service: service}
+}
+
+// SendPhoneOTP godoc
+// @Tags User
+// @Summary Send a one-time code to the phone number to verify it
+// @Description The code expires after 5 minutes, another code can be asked for after a minute and at most 5 times an hour.
+// @Description Asking again replaces the code sent before.
+// @Param request body dto.PhoneOTPRequest true "Phone OTP Request"
+// @Success 200 {object} dto.PhoneOTPResponse
+// @Failure 400 {object} string "Bad Request"
+// @Failure 429 {object} string "Too Many Requests"
+// @Router /user/phone/otp [post]
+func (ctrl *PhoneController) SendPhoneOTP(c *gin.Context) {
+	var req dto.PhoneOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.SendOTP(c.Request.Context(), user, req.Phone)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to send code")
+		return
+	}
+	c.JSON(http.StatusOK, dto.PhoneOTPResponse{
+		Phone:      string(item.Phone),
+		ExpireTime: item.ExpireTime,
+		ResendTime: item.CreatedTime.Add(services.PhoneOTPResendInterval),
+	})
+}
+
+// VerifyPhoneOTP godoc
+// @Tags User
+// @Summary Verify the phone number with the code sent to it
+// @Description The phone number the code was sent to becomes the phone of the user. A code can be guessed 5 times.
+// @Param request body dto.PhoneVerifyRequest true "Phone Verify Request"
+// @Success 200 {object} dto.PhoneVerifyResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /user/phone/verify [post]
+func (ctrl *PhoneController) VerifyPhoneOTP(c *gin.Context) {
+	var req dto.PhoneVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	user, err := ctrl.Service.VerifyOTP(c.Request.Context(), user, req.Code)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to verify phone")
+		return
+	}
+	c.JSON(http.StatusOK, dto.PhoneVerifyResponse{Phone: string(user.Phone), PhoneVerified: user.IsPhoneVerified()})
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PhotoModerationController struct {
+	Service *services.PhotoModerationService
+}
+
+func NewPhotoModerationController(service *services.PhotoModerationService) *PhotoModerationController {
+	return &PhotoModerationController{Service: service}
+}
+
+// GetPhotoModerationQueue godoc
+// @Tags Moderation
+// @Summary Get the package photos held because they look like photos of another photographer
+// @Description The oldest photos first, every photo lists the published photos it matches
+// @Param status query string false "Pending, Approved or Rejected, every status when empty"
+// @Param packageId query string false "Package ID"
+// @Success 200 {object} []models.PhotoModeration
+// @Failure 400 {object} string "Bad Request"
+// @Router /moderation/photos [get]
+func (ctrl *PhotoModerationController) GetPhotoModerationQueue(c *gin.Context) {
+	status := models.PhotoModerationStatus(c.Query("status"))
+	if status != "" && !isValidPhotoModerationStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	var packageId *primitive.ObjectID
+	if c.Query("packageId") != "" {
+		id, err := primitive.ObjectIDFromHex(c.Query("packageId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID"})
+			return
+		}
+		packageId = &id
+	}
+
+	items, err := ctrl.Service.GetQueue(c.Request.Context(), status, packageId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch held photos, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// ApprovePhoto godoc
+// @Tags Moderation
+// @Summary Publish a held photo at the end of its package
+// @Param id path string true "Held photo ID"
+// @Success 200 {object} models.PhotoModeration
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /moderation/photos/{id}/approve [post]
+func (ctrl *PhotoModerationController) ApprovePhoto(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid held photo ID"})
+		return
+	}
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.Approve(c.Request.Context(), id, user)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to approve photo")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// RejectPhoto godoc
+// @Tags Moderation
+// @Summary Delete a held photo
+// @Description The photographer gets a notification with the reason
+// @Param id path string true "Held photo ID"
+// @Param request body dto.PhotoModerationRejectRequest true "Reject Request"
+// @Success 200 {object} models.PhotoModeration
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /moderation/photos/{id}/reject [post]
+func (ctrl *PhotoModerationController) RejectPhoto(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid held photo ID"})
+		return
+	}
+	var req dto.PhotoModerationRejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.Reject(c.Request.Context(), id, user, req.Reason)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to reject photo")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func isValidPhotoModerationStatus(status models.PhotoModerationStatus) bool {
+	for _, valid := range models.ValidPhotoModerationStatus {
+		if valid.Value == status {
+			return true
+		}
+	}
+	return false
+}
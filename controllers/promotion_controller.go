@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PromotionController struct {
+	Service           *services.PromotionService
+	PackageService    *services.PackageService
+	SubpackageService *services.SubpackageService
+}
+
+func NewPromotionController(service *services.PromotionService, packageService *services.PackageService, subpackageService *services.SubpackageService) *PromotionController {
+	return &PromotionController{Service: service, PackageService: packageService, SubpackageService: subpackageService}
+}
+
+// GetAllPromotions godoc
+// @Tags Promotion
+// @Summary Get a list of promotions
+// @Description Retrieve the promotions owned by the photographer, or every promotion for admin
+// @Success 200 {object} []models.Promotion
+// @Failure 400 {object} string "Bad Request"
+// @Router /promotion [get]
+func (ctrl *PromotionController) GetAllPromotions(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+
+	items, err := ctrl.Service.GetAllOwned(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetPromotionById godoc
+// @Tags Promotion
+// @Summary Get a promotion by id
+// @Description Retrieve a promotion which is owned by the user
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} models.Promotion
+// @Failure 400 {object} string "Bad Request"
+// @Router /promotion/{id} [get]
+func (ctrl *PromotionController) GetPromotionById(c *gin.Context) {
+	promotion, ok := ctrl.getOwnedPromotion(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, promotion)
+}
+
+// PreviewPromotion godoc
+// @Tags Promotion
+// @Summary Preview a promotion code
+// @Description Check the promotion code against a subpackage and return the discounted price
+// @Param code query string true "Promotion code"
+// @Param subpackageId query string true "Subpackage ID"
+// @Success 200 {object} dto.PromotionPreviewResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /promotion/preview [get]
+func (ctrl *PromotionController) PreviewPromotion(c *gin.Context) {
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	}
+	subpackage, err := ctrl.SubpackageService.GetById(c.Request.Context(), c.Query("subpackageId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to fetch subpackage, " + err.Error()})
+		return
+	}
+	pkg, err := ctrl.PackageService.GetById(c.Request.Context(), subpackage.PackageID.Hex())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to fetch package, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	res, err := ctrl.Service.Preview(c.Request.Context(), user, code, pkg, subpackage)
+	if err != nil {
+		apperrors.HandleError(c, err, "Cannot apply this promotion code")
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// CreatePromotion godoc
+// @Tags Promotion
+// @Summary Create a promotion
+// @Description Photographer creates a promotion for their own packages, admin creates a platform-wide promotion that takes at most the platform fee off
+// @Param request body dto.PromotionRequest true "Create Promotion Request"
+// @Success 201 {object} models.Promotion
+// @Failure 400 {object} string "Bad Request"
+// @Router /promotion [post]
+func (ctrl *PromotionController) CreatePromotion(c *gin.Context) {
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	if err := ctrl.Service.VerifyStrictRequest(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.CreateOne(c.Request.Context(), user, &req)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to create promotion")
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdatePromotion godoc
+// @Tags Promotion
+// @Summary Patch a promotion
+// @Param id path string true "Promotion ID"
+// @Param request body dto.PromotionRequest true "Update Promotion Request"
+// @Success 200 {object} models.Promotion
+// @Failure 400 {object} string "Bad Request"
+// @Router /promotion/{id} [patch]
+func (ctrl *PromotionController) UpdatePromotion(c *gin.Context) {
+	promotion, ok := ctrl.getOwnedPromotion(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.UpdateOne(c.Request.Context(), user, promotion, &req)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to update promotion")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// DeletePromotion godoc
+// @Tags Promotion
+// @Summary Delete a promotion
+// @Description Delete a promotion, appointments keep the discount they were booked with
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} string "OK"
+// @Failure 400 {object} string "Bad Request"
+// @Router /promotion/{id} [delete]
+func (ctrl *PromotionController) DeletePromotion(c *gin.Context) {
+	promotion, ok := ctrl.getOwnedPromotion(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.Service.DeleteOne(c.Request.Context(), promotion.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete item, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion deleted successfully"})
+}
+
+func (ctrl *PromotionController) getOwnedPromotion(c *gin.Context) (*models.Promotion, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		return nil, false
+	}
+	promotion, err := ctrl.Service.GetById(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to fetch item, " + err.Error()})
+		return nil, false
+	}
+
+	user := middleware.GetUserFromContext(c)
+	if !ctrl.Service.CheckOwner(user, promotion) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not own the promotion"})
+		return nil, false
+	}
+	return promotion, true
+}
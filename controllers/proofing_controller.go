@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProofingController struct {
+	Service           *services.ProofingService
+	GalleryController *GalleryController
+}
+
+func NewProofingController(service *services.ProofingService, galleryController *GalleryController) *ProofingController {
+	return &ProofingController{Service: service, GalleryController: galleryController}
+}
+
+// GetProofs godoc
+// @Tags Proofing
+// @Summary Get the proofs and the selection of an appointment
+// @Description The customer sees the watermarked previews once proofing is open, the URLs expire after an hour
+// @Param appointmentId path string true "Appointment ID"
+// @Success 200 {object} dto.ProofResponse
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /gallery/{appointmentId}/proofs [get]
+func (ctrl *ProofingController) GetProofs(c *gin.Context) {
+	appointment, ok := ctrl.GalleryController.getAppointment(c)
+	if !ok {
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.GetProofs(c.Request.Context(), user, appointment)
+	ctrl.respondProofs(c, gallery, "", err)
+}
+
+// AddProofs godoc
+// @Tags Proofing
+// @Summary Add proofs to a gallery
+// @Description Keep confirmed GalleryPhoto uploads as private originals and generate their low resolution watermarked previews
+// @Param appointmentId path string true "Appointment ID"
+// @Param request body dto.GalleryPhotoRequest true "Gallery Photo Request"
+// @Success 200 {object} dto.ProofResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /gallery/{appointmentId}/proofs [post]
+func (ctrl *ProofingController) AddProofs(c *gin.Context) {
+	gallery, ok := ctrl.GalleryController.getOwnedGallery(c)
+	if !ok {
+		return
+	}
+
+	var req dto.GalleryPhotoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.AddProofs(c.Request.Context(), user, gallery, req.UploadIds)
+	ctrl.respondProofs(c, gallery, "", err)
+}
+
+// RemoveProof godoc
+// @Tags Proofing
+// @Summary Remove a proof from a gallery
+// @Param appointmentId path string true "Appointment ID"
+// @Param proofId path string true "Proof ID"
+// @Success 200 {object} dto.ProofResponse
+// @Failure 404 {object} string "Not Found"
+// @Router /gallery/{appointmentId}/proofs/{proofId} [delete]
+func (ctrl *ProofingController) RemoveProof(c *gin.Context) {
+	gallery, ok := ctrl.GalleryController.getOwnedGallery(c)
+	if !ok {
+		return
+	}
+	proofId, err := primitive.ObjectIDFromHex(c.Param("proofId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid proof ID"})
+		return
+	}
+
+	gallery, err = ctrl.Service.RemoveProof(c.Request.Context(), gallery, proofId)
+	ctrl.respondProofs(c, gallery, "", err)
+}
+
+// OpenProofing godoc
+// @Tags Proofing
+// @Summary Open or reopen the proof selection to the customer
+// @Description The selection limit and the extra photo price are taken from the subpackage, reopening unlocks a submitted selection
+// @Param appointmentId path string true "Appointment ID"
+// @Success 200 {object} dto.ProofResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /gallery/{appointmentId}/proofs/open [post]
+func (ctrl *ProofingController) OpenProofing(c *gin.Context) {
+	appointment, ok := ctrl.GalleryController.getAppointment(c)
+	if !ok {
+		return
+	}
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.GalleryController.Service.GetOwnedGallery(c.Request.Context(), user, appointment)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch gallery")
+		return
+	}
+
+	gallery, err = ctrl.Service.OpenProofing(c.Request.Context(), gallery, appointment)
+	ctrl.respondProofs(c, gallery, "", err)
+}
+
+// UpdateProofSelection godoc
+// @Tags Proofing
+// @Summary Select the proofs to edit
+// @Description Replace the selection of the customer while it is open, photos beyond the limit are paid on submit
+// @Param appointmentId path string true "Appointment ID"
+// @Param request body dto.ProofSelectionRequest true "Proof Selection Request"
+// @Success 200 {object} dto.ProofResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /gallery/{appointmentId}/proofs/selection [put]
+func (ctrl *ProofingController) UpdateProofSelection(c *gin.Context) {
+	gallery, ok := ctrl.getCustomerGallery(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ProofSelectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.UpdateSelection(c.Request.Context(), user, gallery, req.PhotoIDs)
+	ctrl.respondProofs(c, gallery, "", err)
+}
+
+// SubmitProofSelection godoc
+// @Tags Proofing
+// @Summary Submit and lock the proof selection
+// @Description The photographer is notified, when photos are selected beyond the limit the response has the checkout URL
+// @Description of the extra photos and the selection is submitted once it is paid
+// @Param appointmentId path string true "Appointment ID"
+// @Param successURL query string false "success URL"
+// @Param cancelURL query string false "cancel URL"
+// @Success 200 {object} dto.ProofResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /gallery/{appointmentId}/proofs/submit [post]
+func (ctrl *ProofingController) SubmitProofSelection(c *gin.Context) {
+	cancelURLParam, _ := c.GetQuery("cancelURL")
+	successURLParam, _ := c.GetQuery("successURL")
+
+	appointment, ok := ctrl.GalleryController.getAppointment(c)
+	if !ok {
+		return
+	}
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.GetProofs(c.Request.Context(), user, appointment)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch gallery")
+		return
+	}
+
+	gallery, checkoutURL, err := ctrl.Service.SubmitSelection(c.Request.Context(), user, gallery, appointment, successURLParam, cancelURLParam)
+	ctrl.respondProofs(c, gallery, checkoutURL, err)
+}
+
+func (ctrl *ProofingController) getCustomerGallery(c *gin.Context) (*models.Gallery, bool) {
+	appointment, ok := ctrl.GalleryController.getAppointment(c)
+	if !ok {
+		return nil, false
+	}
+	user := middleware.GetUserFromContext(c)
+	gallery, err := ctrl.Service.GetProofs(c.Request.Context(), user, appointment)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch gallery")
+		return nil, false
+	}
+	return gallery, true
+}
+
+func (ctrl *ProofingController) respondProofs(c *gin.Context, gallery *models.Gallery, checkoutURL string, err error) {
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to process proofs")
+		return
+	}
+	res, err := ctrl.Service.MapToProofResponse(gallery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign proof URLs, " + err.Error()})
+		return
+	}
+	res.CheckoutURL = checkoutURL
+	c.JSON(http.StatusOK, res)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/Bualoi-s-Dev/backend/middleware"
@@ -112,6 +113,7 @@ func (ctrl *RatingController) CreateRating(c *gin.Context) {
 // @Param ratingId path string true "Rating ID"
 // @Success 200 {object} models.Rating
 // @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
 // @Router /user/{photographerId}/rating/{ratingId} [GET]
 func (ctrl *RatingController) GetRatingById(c *gin.Context) {
 	ratingId := c.Param("ratingId")
@@ -139,9 +141,10 @@ func (ctrl *RatingController) GetRatingById(c *gin.Context) {
 		return
 	}
 
-	item, err := ctrl.RatingService.GetById(c.Request.Context(), photographerObjectID, ratingObjectID)
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.RatingService.GetById(c.Request.Context(), user, photographerObjectID, ratingObjectID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to get rating, " + err.Error()})
+		apperrors.HandleError(c, err, "Failed to get rating")
 		return
 	}
 
@@ -158,6 +161,7 @@ func (ctrl *RatingController) GetRatingById(c *gin.Context) {
 // @Success 200 {object} models.Rating
 // @Failure 403 {object} string "Forbidden"
 // @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
 // @Router /user/{photographerId}/rating/{ratingId} [PUT]
 func (ctrl *RatingController) UpdateRating(c *gin.Context) {
 	user := middleware.GetUserFromContext(c)
@@ -194,9 +198,9 @@ func (ctrl *RatingController) UpdateRating(c *gin.Context) {
 		return
 	}
 	
-	err = ctrl.RatingService.UpdateOne(c.Request.Context(), user.ID, photographerObjectID, ratingObjectID, &ratingRequest)
+	err = ctrl.RatingService.UpdateOne(c.Request.Context(), user, photographerObjectID, ratingObjectID, &ratingRequest)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update rating, " + err.Error()})
+		apperrors.HandleError(c, err, "Failed to update rating")
 		return
 	}
 
@@ -212,6 +216,7 @@ func (ctrl *RatingController) UpdateRating(c *gin.Context) {
 // @Success 200 {object} string "Rating id {ratingId} deleted successfully"
 // @Failure 403 {object} string "Forbidden"
 // @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
 // @Router /user/{photographerId}/rating/{ratingId} [DELETE]
 func (ctrl *RatingController) DeleteRating(c *gin.Context) {
 	user := middleware.GetUserFromContext(c)
@@ -241,9 +246,9 @@ func (ctrl *RatingController) DeleteRating(c *gin.Context) {
 	}
 
 	// Call service to delete the rating
-	err = ctrl.RatingService.DeleteOne(c.Request.Context(), user.ID, photographerObjectID, ratingObjectID)
+	err = ctrl.RatingService.DeleteOne(c.Request.Context(), user, photographerObjectID, ratingObjectID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rating, " + err.Error()})
+		apperrors.HandleError(c, err, "Failed to delete rating")
 		return
 	}
 
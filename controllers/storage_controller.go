@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"os"
+
+	storage "github.com/Bualoi-s-Dev/backend/repositories/storage"
+	"github.com/gin-gonic/gin"
+)
+
+type StorageController struct {
+	Repo *storage.LocalRepository
+}
+
+func NewStorageController(repo *storage.LocalRepository) *StorageController {
+	return &StorageController{Repo: repo}
+}
+
+// GetObject serves an object of the local storage, private objects need a presigned URL like on S3
+func (ctrl *StorageController) GetObject(c *gin.Context) {
+	key, err := storage.CleanKey(c.Param("key"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Object not found"})
+		return
+	}
+	if storage.IsPrivateKey(key) {
+		if err := ctrl.Repo.VerifyPresignedGet(key, c.Request.URL.Query()); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid download URL, " + err.Error()})
+			return
+		}
+	}
+	path, err := ctrl.Repo.Path(key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Object not found"})
+		return
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Object not found"})
+		return
+	}
+
+	if download := c.Query("download"); download != "" {
+		c.FileAttachment(path, download)
+		return
+	}
+	c.File(path)
+}
+
+// PutObject receives a presigned upload for the local storage, it plays the role of the S3 presigned PUT
+func (ctrl *StorageController) PutObject(c *gin.Context) {
+	key, err := storage.CleanKey(c.Param("key"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid object key"})
+		return
+	}
+	contentType := c.GetHeader("Content-Type")
+	size := c.Request.ContentLength
+	if err := ctrl.Repo.VerifyPresignedPut(key, c.Request.URL.Query(), contentType, size); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid upload URL, " + err.Error()})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, size+1))
+	if err != nil || int64(len(body)) != size {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Body does not match the content length"})
+		return
+	}
+	if err := ctrl.Repo.PutObject(key, body, contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store object, " + err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
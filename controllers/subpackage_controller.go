@@ -7,10 +7,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type SubpackageController struct {
@@ -118,13 +120,13 @@ func (ctrl *SubpackageController) GetAllSubpackages(c *gin.Context) {
 // @Param id path string true "Subpackage ID"
 // @Tags Subpackage
 // @Success 200 {object} dto.SubpackageResponse
-// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
 // @Router /subpackage/{id} [GET]
 func (ctrl *SubpackageController) GetByIdSubpackages(c *gin.Context) {
-	id := c.Param("id")
-	item, err := ctrl.Service.GetById(c.Request.Context(), id)
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.GetAuthorized(c.Request.Context(), user, c.Param("id"), policies.ActionRead)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to fetch items, " + err.Error()})
+		apperrors.HandleError(c, err, "Failed to fetch items")
 		return
 	}
 
@@ -144,6 +146,8 @@ func (ctrl *SubpackageController) GetByIdSubpackages(c *gin.Context) {
 // @Param request body dto.SubpackageRequest true "Create Subpackage Request"
 // @Success 200 {object} dto.SubpackageResponse
 // @Failure 400 {object} string "Bad Request"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
 // @Router /subpackage/{packageId} [POST]
 func (ctrl *SubpackageController) CreateSubpackage(c *gin.Context) {
 	var itemRequest dto.SubpackageRequest
@@ -166,18 +170,15 @@ func (ctrl *SubpackageController) CreateSubpackage(c *gin.Context) {
 		return
 	}
 
-	packageIdParam := c.Param("packageId")
-	packageId, err := primitive.ObjectIDFromHex(packageIdParam)
+	// Only the owner of the package can add its subpackages
+	user := middleware.GetUserFromContext(c)
+	pkg, err := ctrl.Service.AuthorizeCreate(c.Request.Context(), user, c.Param("packageId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID, " + err.Error()})
-		return
-	}
-	if _, err := ctrl.PackageService.GetById(c.Request.Context(), packageIdParam); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID, " + err.Error()})
+		apperrors.HandleError(c, err, "Failed to fetch package")
 		return
 	}
 	item := itemRequest.ToModel()
-	item.PackageID = packageId
+	item.PackageID = pkg.ID
 	if err := ctrl.Service.Create(c.Request.Context(), item); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item, " + err.Error()})
 		return
@@ -199,6 +200,8 @@ func (ctrl *SubpackageController) CreateSubpackage(c *gin.Context) {
 // @Param request body dto.SubpackageRequest true "Update Subpackage Request"
 // @Success 200 {object} dto.SubpackageResponse
 // @Failure 400 {object} string "Bad Request"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
 // @Router /subpackage/{id} [PATCH]
 func (ctrl *SubpackageController) UpdateSubpackage(c *gin.Context) {
 	id := c.Param("id")
@@ -208,9 +211,10 @@ func (ctrl *SubpackageController) UpdateSubpackage(c *gin.Context) {
 		return
 	}
 
-	oldSubpackage, err := ctrl.Service.GetById(c.Request.Context(), id)
+	user := middleware.GetUserFromContext(c)
+	oldSubpackage, err := ctrl.Service.GetAuthorized(c.Request.Context(), user, id, policies.ActionUpdate)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to fetch item, " + err.Error()})
+		apperrors.HandleError(c, err, "Failed to fetch item")
 		return
 	}
 
@@ -258,15 +262,18 @@ func (ctrl *SubpackageController) UpdateSubpackage(c *gin.Context) {
 // @Param id path string true "Subpackage ID"
 // @Success 200 {object} string "Subpackage id {id} deleted successfully"
 // @Failure 400 {object} string "Bad Request"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Not Found"
 // @Router /subpackage/{id} [DELETE]
 func (ctrl *SubpackageController) DeleteSubpackage(c *gin.Context) {
 	id := c.Param("id")
-	if _, err := ctrl.Service.GetById(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to fetch item, " + err.Error()})
+	user := middleware.GetUserFromContext(c)
+	subpackage, err := ctrl.Service.GetAuthorized(c.Request.Context(), user, id, policies.ActionDelete)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch item")
 		return
 	}
-	hexId, _ := primitive.ObjectIDFromHex(id)
-	deletable, err := ctrl.Service.IsSubpackageDeletable(c.Request.Context(), hexId)
+	deletable, err := ctrl.Service.IsSubpackageDeletable(c.Request.Context(), subpackage.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check if item is deletable, " + err.Error()})
 		return
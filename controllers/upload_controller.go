@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+type UploadController struct {
+	Service *services.UploadService
+}
+
+func NewUploadController(service *services.UploadService) *UploadController {
+	return &UploadController{Service: service}
+}
+
+// CreateUpload godoc
+// @Tags Upload
+// @Summary Request a presigned upload URL
+// @Description Returns a URL to PUT the file to directly, with the same Content-Type and size as requested.
+// @Description The returned id is then sent as photoUploadIds of a package or a gallery, or profileUploadId of the profile before it expires.
+// @Param request body dto.UploadRequest true "Upload Request"
+// @Success 201 {object} dto.UploadResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /upload [post]
+func (ctrl *UploadController) CreateUpload(c *gin.Context) {
+	var req dto.UploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	if err := services.VerifyUploadRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	if req.Purpose != models.UploadProfile && user.Role != models.Photographer {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only photographers can upload package and gallery photos"})
+		return
+	}
+
+	res, err := ctrl.Service.CreateUpload(c.Request.Context(), user, &req)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to create upload")
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
@@ -4,10 +4,11 @@ import (
 	"net/http"
 	"strconv"
 
-	"firebase.google.com/go/auth"
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/middleware"
 	"github.com/Bualoi-s-Dev/backend/models"
+	authRepo "github.com/Bualoi-s-Dev/backend/repositories/auth"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,17 +18,17 @@ type UserController struct {
 	Service         *services.UserService
 	S3Service       *services.S3Service
 	BusyTimeService *services.BusyTimeService
-	AuthClient      *auth.Client
+	Auth            authRepo.TokenVerifier
 }
 
-func NewUserController(service *services.UserService, s3Service *services.S3Service, busyTimeService *services.BusyTimeService, authClient *auth.Client) *UserController {
-	return &UserController{Service: service, S3Service: s3Service, BusyTimeService: busyTimeService, AuthClient: authClient}
+func NewUserController(service *services.UserService, s3Service *services.S3Service, busyTimeService *services.BusyTimeService, auth authRepo.TokenVerifier) *UserController {
+	return &UserController{Service: service, S3Service: s3Service, BusyTimeService: busyTimeService, Auth: auth}
 }
 
 // GetUserJWT godoc
 // @Tags User
 // @Summary Get a user from jwt
-// @Description Retrieve a user from firebase jwt
+// @Description Retrieve a user from firebase jwt, a suspended user can still read it to see the reason and the expiry of the suspension
 // @Success 200 {object} models.User
 // @Failure 400 {object} string "Bad Request"
 // @Router /user/me [get]
@@ -46,7 +47,7 @@ func (uc *UserController) GetUserJWT(c *gin.Context) {
 // GetUserProfile godoc
 // @Tags User
 // @Summary Get a user from database
-// @Description Retrieve a user from database
+// @Description Retrieve a user from database, with the storage usage of the user
 // @Success 200 {object} dto.UserResponse
 // @Failure 400 {object} string "Bad Request"
 // @Router /user/profile [get]
@@ -55,7 +56,7 @@ func (uc *UserController) GetUserProfile(c *gin.Context) {
 	user := middleware.GetUserFromContext(c)
 
 	// Call the service to get the user's profile picture URL
-	userDb, err := uc.Service.GetUserByEmail(c.Request.Context(), user.Email)
+	userDb, err := uc.Service.GetOwnProfile(c.Request.Context(), user.Email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user data, " + err.Error()})
 		return
@@ -187,7 +188,7 @@ func (uc *UserController) UpdateUserProfile(c *gin.Context) {
 	// Call the service to update the user's profile, include picture
 	newUser, err := uc.Service.UpdateUser(c.Request.Context(), user.ID, user.Email, &userBody)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile, " + err.Error()})
+		apperrors.HandleError(c, err, "Failed to update profile")
 		return
 	}
 
@@ -275,7 +276,7 @@ func (uc *UserController) CheckProviderByEmail(c *gin.Context) {
 		return
 	}
 
-	providers, err := middleware.CheckProviderByEmail(uc.AuthClient, email)
+	providers, err := middleware.CheckProviderByEmail(uc.Auth, email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
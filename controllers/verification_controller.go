@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type VerificationController struct {
+	Service *services.VerificationService
+}
+
+func NewVerificationController(service *services.VerificationService) *VerificationController {
+	return &VerificationController{Service: service}
+}
+
+// SubmitVerification godoc
+// @Tags Verification
+// @Summary Submit the identity document and bank details of the photographer for review
+// @Description The document photos are uploaded with the IdentityDocument purpose. The packages of the photographer are
+// @Description listed and the payout account can be registered once an admin approves it.
+// @Param request body dto.VerificationRequest true "Verification Request"
+// @Success 201 {object} dto.VerificationResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /user/verification [post]
+func (ctrl *VerificationController) SubmitVerification(c *gin.Context) {
+	var req dto.VerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.Submit(c.Request.Context(), user, &req)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to submit verification")
+		return
+	}
+	ctrl.respondVerification(c, http.StatusCreated, item)
+}
+
+// GetOwnVerification godoc
+// @Tags Verification
+// @Summary Get the latest verification submitted by the photographer
+// @Success 200 {object} dto.VerificationResponse
+// @Failure 404 {object} string "Not Found"
+// @Router /user/verification [get]
+func (ctrl *VerificationController) GetOwnVerification(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.GetLatest(c.Request.Context(), user.ID)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch verification")
+		return
+	}
+	ctrl.respondVerification(c, http.StatusOK, item)
+}
+
+// GetVerificationQueue godoc
+// @Tags Verification
+// @Summary Get the verifications submitted by the photographers
+// @Description The oldest verifications first
+// @Param status query string false "Pending, Approved or Rejected, every status when empty"
+// @Success 200 {object} []models.PhotographerVerification
+// @Failure 400 {object} string "Bad Request"
+// @Router /admin/verifications [get]
+func (ctrl *VerificationController) GetVerificationQueue(c *gin.Context) {
+	status := models.VerificationStatus(c.Query("status"))
+	if status != "" && !isValidVerificationStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	items, err := ctrl.Service.GetQueue(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch verifications, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetVerification godoc
+// @Tags Verification
+// @Summary Get a verification with the signed URLs of its document photos
+// @Param id path string true "Verification ID"
+// @Success 200 {object} dto.VerificationResponse
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/verifications/{id} [get]
+func (ctrl *VerificationController) GetVerification(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verification ID"})
+		return
+	}
+	item, err := ctrl.Service.GetById(c.Request.Context(), id)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to fetch verification")
+		return
+	}
+	ctrl.respondVerification(c, http.StatusOK, item)
+}
+
+// ApproveVerification godoc
+// @Tags Verification
+// @Summary Verify the photographer
+// @Description The submitted bank details become the ones of the photographer, who gets a notification
+// @Param id path string true "Verification ID"
+// @Success 200 {object} dto.VerificationResponse
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/verifications/{id}/approve [post]
+func (ctrl *VerificationController) ApproveVerification(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verification ID"})
+		return
+	}
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.Approve(c.Request.Context(), id, user)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to approve verification")
+		return
+	}
+	ctrl.respondVerification(c, http.StatusOK, item)
+}
+
+// RejectVerification godoc
+// @Tags Verification
+// @Summary Reject the verification of the photographer
+// @Description The photographer gets a notification with the reason and can submit again
+// @Param id path string true "Verification ID"
+// @Param request body dto.AdminReasonRequest true "Reject Request"
+// @Success 200 {object} dto.VerificationResponse
+// @Failure 400 {object} string "Bad Request"
+// @Failure 404 {object} string "Not Found"
+// @Router /admin/verifications/{id}/reject [post]
+func (ctrl *VerificationController) RejectVerification(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verification ID"})
+		return
+	}
+	var req dto.AdminReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+	user := middleware.GetUserFromContext(c)
+	item, err := ctrl.Service.Reject(c.Request.Context(), id, user, req.Reason)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to reject verification")
+		return
+	}
+	ctrl.respondVerification(c, http.StatusOK, item)
+}
+
+func (ctrl *VerificationController) respondVerification(c *gin.Context, status int, item *models.PhotographerVerification) {
+	res, err := ctrl.Service.MappedToVerificationResponse(item, middleware.GetUserFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign document URLs, " + err.Error()})
+		return
+	}
+	c.JSON(status, res)
+}
+
+func isValidVerificationStatus(status models.VerificationStatus) bool {
+	for _, valid := range models.ValidVerificationStatus {
+		if valid.Value == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+type WatermarkController struct {
+	Service *services.WatermarkService
+}
+
+func NewWatermarkController(service *services.WatermarkService) *WatermarkController {
+	return &WatermarkController{Service: service}
+}
+
+// GetWatermark godoc
+// @Tags Watermark
+// @Summary Get the watermark of the photographer
+// @Success 200 {object} dto.WatermarkResponse
+// @Router /user/watermark [get]
+func (ctrl *WatermarkController) GetWatermark(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	ctrl.respondWatermark(c, user.Watermark)
+}
+
+// UpdateWatermark godoc
+// @Tags Watermark
+// @Summary Set the watermark of the photographer
+// @Description The watermark is a text or a logo uploaded with the WatermarkLogo purpose, it is drawn over the public package
+// @Description photos and the proofs, the originals stay private. The package photos are rendered again in the background.
+// @Param request body dto.WatermarkRequest true "Watermark Request"
+// @Success 200 {object} dto.WatermarkResponse
+// @Failure 400 {object} string "Bad Request"
+// @Router /user/watermark [put]
+func (ctrl *WatermarkController) UpdateWatermark(c *gin.Context) {
+	var req dto.WatermarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request, " + err.Error()})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	watermark, err := ctrl.Service.UpdateWatermark(c.Request.Context(), user, &req)
+	if err != nil {
+		apperrors.HandleError(c, err, "Failed to update watermark")
+		return
+	}
+	ctrl.respondWatermark(c, watermark)
+}
+
+// DeleteWatermark godoc
+// @Tags Watermark
+// @Summary Remove the watermark of the photographer
+// @Description The package photos are rendered again without the watermark in the background
+// @Success 200 {object} string "OK"
+// @Router /user/watermark [delete]
+func (ctrl *WatermarkController) DeleteWatermark(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	if err := ctrl.Service.DeleteWatermark(c.Request.Context(), user); err != nil {
+		apperrors.HandleError(c, err, "Failed to delete watermark")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Watermark deleted successfully"})
+}
+
+func (ctrl *WatermarkController) respondWatermark(c *gin.Context, watermark *models.Watermark) {
+	res, err := ctrl.Service.MapToWatermarkResponse(watermark)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign logo URL, " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+)
+
+type AdminUserRoleRequest struct {
+	Role models.UserRole `json:"role" binding:"required,any_user_role" example:"Photographer"`
+}
+
+// AdminReasonRequest is told to the users affected by the action of the admin
+type AdminReasonRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"Violates the terms of service"`
+}
+
+// AdminSuspendRequest suspends the user until ExpiresTime, or until they are unsuspended without it. A ban never expires.
+type AdminSuspendRequest struct {
+	Reason      string                `json:"reason" binding:"required,max=500" example:"Violates the terms of service"`
+	Type        models.SuspensionType `json:"type" binding:"omitempty,suspension_type" example:"Suspended"`
+	ExpiresTime *time.Time            `json:"expiresTime" ts_type:"string" example:"2025-03-23T10:00:00Z"`
+}
+
+type AdminAppointmentStatusRequest struct {
+	Status models.AppointmentStatus `json:"status" binding:"required,appointment_status" example:"Canceled"`
+	Reason string                   `json:"reason" binding:"required,max=500" example:"The photographer is sick"`
+}
+
+type AdminUserListResponse struct {
+	Users      []models.User `json:"users" ts_type:"User[]"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+type AdminAppointmentListResponse struct {
+	Appointments []models.Appointment `json:"appointments" ts_type:"Appointment[]"`
+	Pagination   Pagination           `json:"pagination"`
+}
+
+type AdminPaymentListResponse struct {
+	Payments   []models.Payment `json:"payments" ts_type:"Payment[]"`
+	Pagination Pagination       `json:"pagination"`
+}
+
+type AdminAuditLogListResponse struct {
+	Logs       []models.AuditLog `json:"logs" ts_type:"AuditLog[]"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+type AdminUserStats struct {
+	Total         int64 `json:"total" example:"1200"`
+	Photographers int64 `json:"photographers" example:"200"`
+	Customers     int64 `json:"customers" example:"950"`
+	Guests        int64 `json:"guests" example:"45"`
+	Admins        int64 `json:"admins" example:"5"`
+	Suspended     int64 `json:"suspended" example:"3"`
+}
+
+type AdminAppointmentStats struct {
+	Total     int64 `json:"total" example:"500"`
+	Pending   int64 `json:"pending" example:"40"`
+	Accepted  int64 `json:"accepted" example:"60"`
+	Rejected  int64 `json:"rejected" example:"30"`
+	Canceled  int64 `json:"canceled" example:"20"`
+	Completed int64 `json:"completed" example:"350"`
+}
+
+type AdminStatsResponse struct {
+	Users        AdminUserStats        `json:"users"`
+	Packages     int64                 `json:"packages" example:"420"`
+	Appointments AdminAppointmentStats `json:"appointments"`
+	// Payments sums the payments the customers paid, in baht
+	Payments models.PaymentTotals `json:"payments"`
+}
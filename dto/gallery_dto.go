@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GalleryPhotoRequest struct {
+	UploadIds []primitive.ObjectID `json:"uploadIds" binding:"required,min=1" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+}
+
+type GalleryExpireRequest struct {
+	ExpireTime time.Time `json:"expireTime" binding:"required" ts_type:"string" example:"2025-06-23T10:00:00Z"`
+}
+
+type GalleryResponse struct {
+	ID            primitive.ObjectID     `json:"id" ts_type:"string" example:"12345678abcd"`
+	AppointmentID primitive.ObjectID     `json:"appointmentId" ts_type:"string" example:"12345678abcd"`
+	Status        models.GalleryStatus   `json:"status" example:"Delivered"`
+	Photos        []GalleryPhotoResponse `json:"photos"`
+	ExpireTime    time.Time              `json:"expireTime" ts_type:"string" example:"2025-05-23T10:00:00Z"`
+	DeliveredTime *time.Time             `json:"deliveredTime,omitempty" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	ArchivedTime  *time.Time             `json:"archivedTime,omitempty" ts_type:"string" example:"2025-05-23T10:00:00Z"`
+}
+
+// GalleryPhotoResponse carries a signed URL which expires at URLExpireTime, the URL is empty once the gallery is archived
+type GalleryPhotoResponse struct {
+	ID            primitive.ObjectID `json:"id" ts_type:"string" example:"12345678abcd"`
+	FileName      string             `json:"fileName" example:"IMG_0001.jpg"`
+	ContentType   string             `json:"contentType" example:"image/jpeg"`
+	Size          int64              `json:"size" example:"1048576"`
+	URL           string             `json:"url" example:"https://bucket.s3.amazonaws.com/gallery/12345678abcd/12345678abcd.jpg?X-Amz-Signature=abc"`
+	URLExpireTime *time.Time         `json:"urlExpireTime,omitempty" ts_type:"string" example:"2025-02-23T11:00:00Z"`
+}
+
+type ProofSelectionRequest struct {
+	PhotoIDs []primitive.ObjectID `json:"photoIds" binding:"required" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+}
+
+// ProofResponse carries the checkout URL of the extra photos when the submitted selection waits for the payment
+type ProofResponse struct {
+	GalleryID     primitive.ObjectID     `json:"galleryId" ts_type:"string" example:"12345678abcd"`
+	AppointmentID primitive.ObjectID     `json:"appointmentId" ts_type:"string" example:"12345678abcd"`
+	Proofs        []ProofPhotoResponse   `json:"proofs"`
+	Selection     *models.ProofSelection `json:"selection,omitempty" ts_type:"ProofSelection"`
+	CheckoutURL   string                 `json:"checkoutUrl,omitempty" example:"https://checkout.stripe.com/c/pay/cs_test_a1"`
+}
+
+// ProofPhotoResponse carries the signed URL of the watermarked preview, the original is never shared
+type ProofPhotoResponse struct {
+	ID            primitive.ObjectID `json:"id" ts_type:"string" example:"12345678abcd"`
+	FileName      string             `json:"fileName" example:"IMG_0001.jpg"`
+	URL           string             `json:"url" example:"https://bucket.s3.amazonaws.com/gallery/12345678abcd/proof/12345678abcd_preview.jpg?X-Amz-Signature=abc"`
+	URLExpireTime time.Time          `json:"urlExpireTime" ts_type:"string" example:"2025-02-23T11:00:00Z"`
+}
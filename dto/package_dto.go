@@ -9,6 +9,22 @@ type PackageRequest struct {
 	Title  *string             `bson:"title" json:"title" binding:"omitempty" example:"Wedding Bliss Package"`
 	Type   *models.PackageType `bson:"type" json:"type" binding:"omitempty,package_type" example:"WEDDING_BLISS"`
 	Photos *[]string           `bson:"photos" json:"photos" binding:"omitempty" example:"thisisbase64image1,thisisbase64image2"`
+
+	// Confirmed presigned uploads, they are added after the base64 photos
+	PhotoUploadIds *[]primitive.ObjectID `bson:"photo_upload_ids" json:"photoUploadIds" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+}
+
+type PackagePhotoAddRequest struct {
+	Photos         *[]string             `json:"photos" binding:"omitempty" example:"thisisbase64image1,thisisbase64image2"`
+	PhotoUploadIds *[]primitive.ObjectID `json:"photoUploadIds" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+}
+
+type PackagePhotoOrderRequest struct {
+	PhotoIds []primitive.ObjectID `json:"photoIds" binding:"required" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+}
+
+type PackagePhotoCaptionRequest struct {
+	Caption string `json:"caption" binding:"max=200" example:"First dance"`
 }
 
 type PackageResponse struct {
@@ -17,6 +33,7 @@ type PackageResponse struct {
 	Title       string              `form:"title" bson:"title" json:"title" binding:"required" example:"Wedding Bliss Package"`
 	Type        models.PackageType  `form:"type" bson:"type" json:"type" binding:"required,package_type" example:"WEDDING_BLISS"`
 	PhotoUrls   []string            `bson:"photo_urls" json:"photoUrls" example:"/package/12345678abcd_1,/package/12345678abcd_2"`
+	Images      []models.Image      `bson:"images" json:"images" ts_type:"Image[]"`
 	SubPackages []models.Subpackage `bson:"sub_packages,omitempty" json:"subPackages"`
 }
 
@@ -35,5 +52,6 @@ func (item *PackageResponse) ToModel() *models.Package {
 		Title:     item.Title,
 		Type:      item.Type,
 		PhotoUrls: item.PhotoUrls,
+		Images:    item.Images,
 	}
 }
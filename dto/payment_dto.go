@@ -1,12 +1,58 @@
 package dto
 
-import "github.com/Bualoi-s-Dev/backend/models"
+import (
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+)
 
 type PaymentResponse struct {
 	Payment     models.Payment    `json:"payment"`
 	Appointment AppointmentDetail `json:"appointment"`
+	TipTotal    int               `json:"tipTotal" example:"100"`
+}
+
+type TipRequest struct {
+	Amount int `json:"amount" binding:"required,min=20" example:"100"`
 }
 
 type PaymentURL struct {
 	URL string `json:"url" example:"https://stripe.com"`
 }
+
+type PaymentRecoveryReason string
+
+const (
+	RecoveryMissingPayment  PaymentRecoveryReason = "MissingPayment"
+	RecoveryExpiredCheckout PaymentRecoveryReason = "ExpiredCheckout"
+)
+
+type PaymentRecoveryResponse struct {
+	Appointment models.Appointment    `json:"appointment"`
+	Payment     *models.Payment       `json:"payment,omitempty"`
+	Job         *models.PaymentJob    `json:"job,omitempty"`
+	Reason      PaymentRecoveryReason `json:"reason" example:"MissingPayment"`
+}
+
+// PaymentExportRow is one line of the accounting export, amounts are in THB
+type PaymentExportRow struct {
+	PaymentID          string
+	PaymentTime        time.Time
+	Type               models.PaymentType
+	AppointmentID      string
+	AppointmentStatus  models.AppointmentStatus
+	PackageTitle       string
+	SubpackageTitle    string
+	CustomerID         string
+	PhotographerID     string
+	OriginalPrice      int
+	PromoCode          string
+	Discount           int
+	Amount             int
+	Fee                int
+	Refunded           int
+	Net                int
+	CustomerStatus     models.PaymentStatus
+	PhotographerStatus models.PaymentStatus
+	PayoutTime         *time.Time
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// PhoneOTPRequest asks for a code sent to the phone, a Thai number may be written with its leading 0
+type PhoneOTPRequest struct {
+	Phone string `json:"phone" binding:"required,max=32" example:"0812345678"`
+}
+
+// PhoneOTPResponse tells when the code expires and when another one can be asked for
+type PhoneOTPResponse struct {
+	Phone      string    `json:"phone" example:"+66812345678"`
+	ExpireTime time.Time `json:"expireTime" ts_type:"string" example:"2025-02-23T10:05:00Z"`
+	ResendTime time.Time `json:"resendTime" ts_type:"string" example:"2025-02-23T10:01:00Z"`
+}
+
+type PhoneVerifyRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456"`
+}
+
+type PhoneVerifyResponse struct {
+	Phone         string `json:"phone" example:"+66812345678"`
+	PhoneVerified bool   `json:"phoneVerified" example:"true"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+// PhotoModerationRejectRequest is told to the photographer, the held photo is deleted
+type PhotoModerationRejectRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"Copied from another photographer"`
+}
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PromotionRequest struct {
+	Code             *string               `bson:"code" json:"code" binding:"omitempty,alphanum,min=3,max=32" example:"SUMMER10"`
+	PackageIDs       *[]primitive.ObjectID `bson:"package_ids" json:"packageIds" binding:"omitempty" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+	DiscountType     *models.DiscountType  `bson:"discount_type" json:"discountType" binding:"omitempty,discount_type" example:"Percentage"`
+	DiscountValue    *int                  `bson:"discount_value" json:"discountValue" binding:"omitempty,min=1" example:"10"`
+	MaxDiscount      *int                  `bson:"max_discount" json:"maxDiscount" binding:"omitempty,min=0" example:"500"`
+	MinPrice         *int                  `bson:"min_price" json:"minPrice" binding:"omitempty,min=0" example:"1000"`
+	StartTime        *time.Time            `bson:"start_time" json:"startTime" binding:"omitempty" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	EndTime          *time.Time            `bson:"end_time" json:"endTime" binding:"omitempty" ts_type:"string" example:"2025-03-23T10:00:00Z"`
+	UsageLimit       *int                  `bson:"usage_limit" json:"usageLimit" binding:"omitempty,min=0" example:"100"`
+	PerCustomerLimit *int                  `bson:"per_customer_limit" json:"perCustomerLimit" binding:"omitempty,min=0" example:"1"`
+	IsActive         *bool                 `bson:"is_active" json:"isActive" binding:"omitempty" example:"true"`
+}
+
+type PromotionPreviewResponse struct {
+	Code          string              `json:"code" example:"SUMMER10"`
+	DiscountType  models.DiscountType `json:"discountType" example:"Percentage"`
+	DiscountValue int                 `json:"discountValue" example:"10"`
+	OriginalPrice int                 `json:"originalPrice" example:"1500"`
+	Discount      int                 `json:"discount" example:"150"`
+	Price         int                 `json:"price" example:"1350"`
+}
+
+func (item *PromotionRequest) ToModel(owner *models.User, scope models.PromotionScope) *models.Promotion {
+	promotion := &models.Promotion{
+		ID:            primitive.NewObjectID(),
+		Code:          strings.ToUpper(*item.Code),
+		OwnerID:       owner.ID,
+		Scope:         scope,
+		PackageIDs:    []primitive.ObjectID{},
+		DiscountType:  *item.DiscountType,
+		DiscountValue: *item.DiscountValue,
+		StartTime:     *item.StartTime,
+		EndTime:       *item.EndTime,
+		IsActive:      true,
+		CreatedTime:   time.Now(),
+	}
+	if item.PackageIDs != nil {
+		promotion.PackageIDs = *item.PackageIDs
+	}
+	if item.MaxDiscount != nil {
+		promotion.MaxDiscount = *item.MaxDiscount
+	}
+	if item.MinPrice != nil {
+		promotion.MinPrice = *item.MinPrice
+	}
+	if item.UsageLimit != nil {
+		promotion.UsageLimit = *item.UsageLimit
+	}
+	if item.PerCustomerLimit != nil {
+		promotion.PerCustomerLimit = *item.PerCustomerLimit
+	}
+	if item.IsActive != nil {
+		promotion.IsActive = *item.IsActive
+	}
+	return promotion
+}
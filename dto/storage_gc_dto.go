@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+)
+
+type StorageGCReport struct {
+	Mode        string                 `json:"mode" example:"quarantine"`
+	DryRun      bool                   `json:"dryRun" example:"true"`
+	StartedTime time.Time              `json:"startedTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	Scanned     int                    `json:"scanned" example:"120"`
+	Referenced  int                    `json:"referenced" example:"110"`
+	Recent      int                    `json:"recent" example:"2"`
+	Orphans     []models.StorageObject `json:"orphans"`
+	OrphanBytes int64                  `json:"orphanBytes" example:"1048576"`
+	Collected   int                    `json:"collected" example:"8"`
+	Purged      int                    `json:"purged" example:"3"`
+	Errors      []string               `json:"errors"`
+}
@@ -18,6 +18,9 @@ type SubpackageRequest struct {
 	AvailableEndTime   *string           `bson:"available_end_time" json:"availableEndTime" binding:"omitempty,time_format" example:"16:27"`
 	AvailableStartDay  *string           `bson:"available_start_day" json:"availableStartDay" binding:"omitempty,date_format" example:"2021-01-01"`
 	AvailableEndDay    *string           `bson:"available_end_day" json:"availableEndDay" binding:"omitempty,date_format" example:"2021-12-31"`
+
+	SelectionLimit  *int `bson:"selection_limit" json:"selectionLimit" binding:"omitempty,min=0" example:"20"`
+	ExtraPhotoPrice *int `bson:"extra_photo_price" json:"extraPhotoPrice" binding:"omitempty,min=0" example:"100"`
 }
 
 type SubpackageResponse struct {
@@ -35,6 +38,9 @@ type SubpackageResponse struct {
 	AvailableStartDay  string           `bson:"available_start_day" json:"availableStartDay" binding:"omitempty,date_format" example:"2021-01-01"`
 	AvailableEndDay    string           `bson:"available_end_day" json:"availableEndDay" binding:"omitempty,date_format" example:"2021-12-31"`
 
+	SelectionLimit  int `bson:"selection_limit" json:"selectionLimit" example:"20"`
+	ExtraPhotoPrice int `bson:"extra_photo_price" json:"extraPhotoPrice" example:"100"`
+
 	BusyTimes []models.BusyTime `bson:"busy_times" json:"busyTimes" binding:"omitempty"`
 }
 
@@ -59,6 +65,8 @@ func (item *SubpackageRequest) ToModel() *models.Subpackage {
 		AvailableEndTime:   *item.AvailableEndTime,
 		AvailableStartDay:  utils.SafeString(availableStartDay),
 		AvailableEndDay:    utils.SafeString(availableEndDay),
+		SelectionLimit:     utils.SafeInt(item.SelectionLimit),
+		ExtraPhotoPrice:    utils.SafeInt(item.ExtraPhotoPrice),
 	}
 }
 func (item *SubpackageResponse) ToModel() *models.Subpackage {
@@ -77,6 +85,9 @@ func (item *SubpackageResponse) ToModel() *models.Subpackage {
 
 		AvailableStartDay: utils.SafeString(&item.AvailableStartDay),
 		AvailableEndDay:   utils.SafeString(&item.AvailableEndDay),
+
+		SelectionLimit:  item.SelectionLimit,
+		ExtraPhotoPrice: item.ExtraPhotoPrice,
 	}
 }
 
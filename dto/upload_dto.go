@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UploadRequest struct {
+	Purpose     models.UploadPurpose `json:"purpose" binding:"required" example:"PackagePhoto"`
+	ContentType string               `json:"contentType" binding:"required" example:"image/jpeg"`
+	Size        int64                `json:"size" binding:"required" example:"1048576"`
+	FileName    string               `json:"fileName" binding:"max=255" example:"IMG_0001.jpg"`
+}
+
+type UploadResponse struct {
+	ID          primitive.ObjectID `json:"id" ts_type:"string" example:"12345678abcd"`
+	URL         string             `json:"url" example:"https://bucket.s3.amazonaws.com/upload/12345678abcd?X-Amz-Signature=abc"`
+	Method      string             `json:"method" example:"PUT"`
+	ContentType string             `json:"contentType" example:"image/jpeg"`
+	ExpireTime  time.Time          `json:"expireTime" ts_type:"string" example:"2025-02-23T10:15:00Z"`
+}
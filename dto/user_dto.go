@@ -15,11 +15,14 @@ type UserRequest struct {
 	Role             *models.UserRole      `bson:"role,omitempty" json:"role" binding:"omitempty,user_role" example:"Photographer"`
 	Description      *string               `bson:"description,omitempty" json:"description" example:"I'm a photographer"`
 	BankName         *models.BankName      `bson:"bank_name,omitempty" json:"bankName" example:"KRUNG_THAI_BANK"`
-	BankAccount      *string               `bson:"bank_account,omitempty" json:"bankAccount" example:"1234567890"`
+	BankAccount      *string               `bson:"bank_account,omitempty" json:"bankAccount" binding:"omitempty,bank_account" example:"1234567890"`
 	LineID           *string               `bson:"line_id,omitempty" json:"lineID" example:"@meen"`
 	Facebook         *string               `bson:"facebook,omitempty" json:"facebook" example:"Meen"`
 	Instagram        *string               `bson:"instagram,omitempty" json:"instagram" example:"Meen"`
 	ShowcasePackages *[]primitive.ObjectID `bson:"showcase_packages,omitempty" json:"showcasePackages" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+
+	// Confirmed presigned upload used instead of the base64 profile
+	ProfileUploadId *primitive.ObjectID `bson:"profile_upload_id,omitempty" json:"profileUploadId" ts_type:"string" example:"12345678abcd"`
 }
 
 type UserResponse struct {
@@ -31,16 +34,34 @@ type UserResponse struct {
 	Phone    string             `bson:"phone,omitempty" json:"phone" example:"0812345678"`
 	Location string             `bson:"location,omitempty" json:"location" example:"Bangkok, Thailand"`
 
+	// Profile picture renditions, Profile keeps the medium rendition
+	ProfileImage *models.Image `bson:"profile_image,omitempty" json:"profileImage,omitempty" ts_type:"Image"`
+
 	Role             models.UserRole   `bson:"role,omitempty" json:"role" binding:"omitempty,user_role" example:"Photographer"`
 	Description      string            `bson:"description,omitempty" json:"description" example:"I'm a photographer"`
 	BankName         models.BankName   `bson:"bank_name,omitempty" json:"bankName" example:"KRUNG_THAI_BANK"`
-	BankAccount      string            `bson:"bank_account,omitempty" json:"bankAccount" example:"1234567890"`
+	BankAccount      string            `bson:"bank_account,omitempty" json:"bankAccount" example:"xxxxxx7890"` // Masked but in the profile of the user themselves
 	LineID           string            `bson:"line_id,omitempty" json:"lineID" example:"@meen"`
 	Facebook         string            `bson:"facebook,omitempty" json:"facebook" example:"Meen"`
 	Instagram        string            `bson:"instagram,omitempty" json:"instagram" example:"Meen"`
+	Verified         bool              `bson:"-" json:"verified" example:"true"`
+	PhoneVerified    bool              `bson:"-" json:"phoneVerified" example:"true"`
 	ShowcasePackages []PackageResponse `bson:"showcase_packages,omitempty" json:"showcasePackages" ts_type:"PackageResponse[]"`
 	Packages         []PackageResponse `bson:"photographer_packages,omitempty" json:"photographerPackages" ts_type:"PackageResponse[]"`
 	Ratings          []RatingResponse  `bson:"ratings,omitempty" json:"photographerRatings" ts_type:"RatingResponse[]"`
+
+	// StorageUsage is only in the profile of the user themselves
+	StorageUsage *StorageUsageResponse `bson:"-" json:"storageUsage,omitempty" ts_type:"StorageUsageResponse"`
+	// VerificationStatus is only in the profile of the user themselves, empty until they submit a verification
+	VerificationStatus models.VerificationStatus `bson:"-" json:"verificationStatus,omitempty" example:"Pending"`
+}
+
+// StorageUsageResponse is the stored photos and bytes of the user against the quota, a zero quota is unlimited
+type StorageUsageResponse struct {
+	UsedBytes   int64 `json:"usedBytes" example:"1073741824"`
+	UsedPhotos  int   `json:"usedPhotos" example:"250"`
+	QuotaBytes  int64 `json:"quotaBytes" example:"10737418240"`
+	QuotaPhotos int   `json:"quotaPhotos" example:"5000"`
 }
 
 type AuthUserCredentials struct {
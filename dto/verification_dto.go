@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VerificationRequest submits the identity and bank details of the photographer, the document photos are uploaded
+// with the IdentityDocument purpose, the front and the back of an ID card or the photo page of a passport
+type VerificationRequest struct {
+	DocumentType      models.IdentityDocumentType `json:"documentType" binding:"required,identity_document_type" example:"NationalID"`
+	DocumentUploadIds []primitive.ObjectID        `json:"documentUploadIds" binding:"required,min=1,max=2" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+	BankName          models.BankName             `json:"bankName" binding:"required,bank_name" example:"KRUNG_THAI_BANK"`
+	BankAccount       string                      `json:"bankAccount" binding:"required,bank_account" example:"1234567890"`
+	BankAccountName   string                      `json:"bankAccountName" binding:"required,max=200" example:"Meen Meen"`
+}
+
+// VerificationResponse carries the signed URLs of the document photos, which expire at URLExpireTime. BankAccount is
+// masked but for the photographer themselves.
+type VerificationResponse struct {
+	ID              primitive.ObjectID          `json:"id" ts_type:"string" example:"12345678abcd"`
+	UserID          primitive.ObjectID          `json:"userId" ts_type:"string" example:"12345678abcd"`
+	DocumentType    models.IdentityDocumentType `json:"documentType" example:"NationalID"`
+	DocumentURLs    []string                    `json:"documentUrls" example:"https://bucket.s3.amazonaws.com/verification/12345678abcd/12345678abcd?X-Amz-Signature=abc"`
+	URLExpireTime   time.Time                   `json:"urlExpireTime" ts_type:"string" example:"2025-02-23T10:15:00Z"`
+	BankName        models.BankName             `json:"bankName" example:"KRUNG_THAI_BANK"`
+	BankAccount     string                      `json:"bankAccount" example:"1234567890"`
+	BankAccountName string                      `json:"bankAccountName" example:"Meen Meen"`
+	Status          models.VerificationStatus   `json:"status" example:"Pending"`
+	Reason          string                      `json:"reason,omitempty" example:"The document is not readable"`
+	CreatedTime     time.Time                   `json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	ReviewedTime    *time.Time                  `json:"reviewedTime,omitempty" ts_type:"string" example:"2025-02-24T10:00:00Z"`
+}
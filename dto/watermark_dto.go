@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WatermarkRequest replaces the watermark setting, the current logo is kept unless a new one is uploaded or it is removed
+type WatermarkRequest struct {
+	Text         string                   `json:"text" binding:"max=64" example:"© Meen Studio"`
+	LogoUploadId *primitive.ObjectID      `json:"logoUploadId" ts_type:"string" example:"12345678abcd"`
+	RemoveLogo   bool                     `json:"removeLogo" example:"false"`
+	Position     models.WatermarkPosition `json:"position" binding:"required,watermark_position" example:"BottomRight"`
+	Opacity      float64                  `json:"opacity" binding:"required,gt=0,lte=1" example:"0.5"`
+}
+
+// WatermarkResponse is disabled when the photographer has no watermark, the logo URL is signed
+type WatermarkResponse struct {
+	Enabled  bool                     `json:"enabled" example:"true"`
+	Text     string                   `json:"text" example:"© Meen Studio"`
+	LogoURL  string                   `json:"logoUrl,omitempty" example:"https://bucket.s3.amazonaws.com/watermark/12345678abcd/12345678abcd?X-Amz-Signature=abc"`
+	Position models.WatermarkPosition `json:"position,omitempty" example:"BottomRight"`
+	Opacity  float64                  `json:"opacity,omitempty" example:"0.5"`
+}
@@ -0,0 +1,57 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Cipher encrypts with one AES-256-GCM key. A randomized encryption uses a random nonce so the same value encrypts
+// differently every time, a deterministic one derives the nonce from the value so equal values can be looked up.
+type Cipher struct {
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+// NewCipher takes a 32 byte key
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("deterministic nonce"))
+	return &Cipher{aead: aead, nonceKey: mac.Sum(nil)}, nil
+}
+
+// Seal returns the nonce followed by the ciphertext
+func (c *Cipher) Seal(plaintext []byte, deterministic bool) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if deterministic {
+		mac := hmac.New(sha256.New, c.nonceKey)
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *Cipher) Open(sealed []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errors.New("encrypted field is too short")
+	}
+	return c.aead.Open(nil, sealed[:size], sealed[size:], nil)
+}
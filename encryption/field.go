@@ -0,0 +1,39 @@
+package encryption
+
+// fieldKeyring encrypts the fields of the models, it is set once at startup and nil keeps the fields in plain text
+var fieldKeyring *Keyring
+
+func SetFieldKeyring(k *Keyring) {
+	fieldKeyring = k
+}
+
+func FieldKeyring() *Keyring {
+	return fieldKeyring
+}
+
+// EncryptField encrypts the value with the field keyring, an empty value stays empty
+func EncryptField(value string, deterministic bool) (string, error) {
+	if fieldKeyring == nil || value == "" {
+		return value, nil
+	}
+	return fieldKeyring.Encrypt(value, deterministic)
+}
+
+// DecryptField decrypts a value stored by EncryptField
+func DecryptField(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if fieldKeyring == nil {
+		return "", ErrNoKey
+	}
+	return fieldKeyring.Decrypt(value)
+}
+
+// LookupField lists the values a deterministic field equal to the value may be stored as, to query it with $in
+func LookupField(value string) ([]string, error) {
+	if fieldKeyring == nil {
+		return []string{value}, nil
+	}
+	return fieldKeyring.Lookup(value)
+}
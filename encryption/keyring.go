@@ -0,0 +1,146 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks an encrypted value, which is stored as enc:<key id>:<base64 of the nonce and the ciphertext>. A value
+// without it was stored before the field was encrypted.
+const prefix = "enc:"
+
+// DefaultKeyID is the id of a key given without one
+const DefaultKeyID = "v1"
+
+var (
+	ErrNoKey      = errors.New("field is encrypted but no encryption key is configured")
+	ErrUnknownKey = errors.New("field is encrypted with a key that is not configured")
+)
+
+type Key struct {
+	ID       string
+	Material []byte
+}
+
+// Keyring encrypts with its primary key and decrypts with any of its keys, the id of the key is stored with the value so
+// the keys can be rotated
+type Keyring struct {
+	primary string
+	ids     []string
+	ciphers map[string]*Cipher
+}
+
+// NewKeyring takes the primary key first, followed by the older keys still used to decrypt
+func NewKeyring(keys []Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption key")
+	}
+	keyring := &Keyring{primary: keys[0].ID, ciphers: map[string]*Cipher{}}
+	for _, key := range keys {
+		if key.ID == "" || strings.ContainsAny(key.ID, ":, \n") {
+			return nil, fmt.Errorf("invalid encryption key id %q", key.ID)
+		}
+		if _, ok := keyring.ciphers[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate encryption key id %q", key.ID)
+		}
+		cipher, err := NewCipher(key.Material)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q: %v", key.ID, err)
+		}
+		keyring.ids = append(keyring.ids, key.ID)
+		keyring.ciphers[key.ID] = cipher
+	}
+	return keyring, nil
+}
+
+// ParseKeys reads the keys as <id>:<base64 of 32 bytes> separated by commas or new lines, the primary key first. A single
+// key may be given without an id, it gets DefaultKeyID.
+func ParseKeys(data string) ([]Key, error) {
+	entries := strings.FieldsFunc(data, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	keys := []Key{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, found := strings.Cut(entry, ":")
+		if !found {
+			if len(entries) > 1 {
+				return nil, errors.New("every encryption key needs an id when there are several")
+			}
+			id, encoded = DefaultKeyID, entry
+		}
+		material, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q is not base64: %v", id, err)
+		}
+		keys = append(keys, Key{ID: strings.TrimSpace(id), Material: material})
+	}
+	return keys, nil
+}
+
+func (k *Keyring) PrimaryID() string {
+	return k.primary
+}
+
+func (k *Keyring) Encrypt(plaintext string, deterministic bool) (string, error) {
+	return k.encryptWith(k.primary, plaintext, deterministic)
+}
+
+func (k *Keyring) encryptWith(id string, plaintext string, deterministic bool) (string, error) {
+	sealed, err := k.ciphers[id].Seal([]byte(plaintext), deterministic)
+	if err != nil {
+		return "", err
+	}
+	return prefix + id + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns a value stored in plain text as it is
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, encoded, found := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !found {
+		return "", errors.New("encrypted field has no key id")
+	}
+	cipher, ok := k.ciphers[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted field: %v", err)
+	}
+	plaintext, err := cipher.Open(sealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt field: %v", err)
+	}
+	return string(plaintext), nil
+}
+
+// Lookup lists the values a deterministic field equal to the plaintext may be stored as, encrypted with each key or in
+// plain text before it was encrypted
+func (k *Keyring) Lookup(plaintext string) ([]string, error) {
+	values := []string{plaintext}
+	for _, id := range k.ids {
+		value, err := k.encryptWith(id, plaintext, true)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// IsCurrent reports whether the value is encrypted with the primary key
+func (k *Keyring) IsCurrent(value string) bool {
+	return strings.HasPrefix(value, prefix+k.primary+":")
+}
+
+// IsEncrypted reports whether the stored value was encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
@@ -0,0 +1,69 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+)
+
+// KeyProvider loads the keys of the field encryption in the format of ParseKeys, the primary key first
+type KeyProvider interface {
+	Keys(ctx context.Context) ([]Key, error)
+}
+
+// EnvKeyProvider reads the keys from an environment variable, no keys when it is empty
+type EnvKeyProvider struct {
+	Name string
+}
+
+func NewEnvKeyProvider(name string) *EnvKeyProvider {
+	return &EnvKeyProvider{Name: name}
+}
+
+func (p *EnvKeyProvider) Keys(ctx context.Context) ([]Key, error) {
+	return ParseKeys(os.Getenv(p.Name))
+}
+
+// FileKeyProvider reads the keys from a file, such as a mounted secret
+type FileKeyProvider struct {
+	Path string
+}
+
+func NewFileKeyProvider(path string) *FileKeyProvider {
+	return &FileKeyProvider{Path: path}
+}
+
+func (p *FileKeyProvider) Keys(ctx context.Context) ([]Key, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption keys: %v", err)
+	}
+	return ParseKeys(string(data))
+}
+
+// SecretManagerKeyProvider reads the keys from a version of a Google Secret Manager secret, its name is
+// projects/<project>/secrets/<secret>/versions/<version>
+type SecretManagerKeyProvider struct {
+	SecretName string
+}
+
+func NewSecretManagerKeyProvider(secretName string) *SecretManagerKeyProvider {
+	return &SecretManagerKeyProvider{SecretName: secretName}
+}
+
+func (p *SecretManagerKeyProvider) Keys(ctx context.Context) ([]Key, error) {
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret manager client: %v", err)
+	}
+	defer client.Close()
+
+	result, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: p.SecretName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to access encryption keys secret: %v", err)
+	}
+	return ParseKeys(string(result.Payload.Data))
+}
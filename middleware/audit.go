@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RequestIDHeader carries the id of the request, one is generated when the client does not send it
+const RequestIDHeader = "X-Request-ID"
+
+// AuditMiddleware attaches the request and its user to the request context so the changes made by it are recorded in
+// the audit log as theirs, it runs after AuthMiddleware
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIDHeader)
+		if requestId == "" || len(requestId) > 128 {
+			requestId = primitive.NewObjectID().Hex()
+		}
+		c.Header(RequestIDHeader, requestId)
+
+		request := models.AuditRequest{
+			Method:    c.Request.Method,
+			Endpoint:  c.FullPath(),
+			RequestID: requestId,
+			IP:        c.ClientIP(),
+		}
+		if value, exists := c.Get("user"); exists {
+			if user, ok := value.(*models.User); ok {
+				request.ActorID = &user.ID
+				request.ActorRole = user.Role
+			}
+		}
+		c.Request = c.Request.WithContext(services.WithAuditRequest(c.Request.Context(), request))
+		c.Next()
+	}
+}
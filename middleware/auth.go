@@ -6,18 +6,20 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
+	authRepo "github.com/Bualoi-s-Dev/backend/repositories/auth"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
-
-	"firebase.google.com/go/auth"
-	//firebase "firebase.google.com/go/v4"
-	//"google.golang.org/api/option"
 )
 
-func FirebaseAuthMiddleware(authClient *auth.Client, userCollection *mongo.Collection, userService *services.UserService) gin.HandlerFunc {
+// AuthMiddleware verifies the bearer ID token with the verifier, Firebase or the local JWT verifier, and puts the
+// user of its email in the context
+func AuthMiddleware(verifier authRepo.TokenVerifier, userCollection *mongo.Collection, userService *services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip authentication for Stripe webhooks
 		if c.Request.URL.Path == "/payment/webhook" {
@@ -45,7 +47,7 @@ func FirebaseAuthMiddleware(authClient *auth.Client, userCollection *mongo.Colle
 			return
 		}
 
-		token, err := authClient.VerifyIDToken(context.Background(), tokenString)
+		claims, err := verifier.VerifyIDToken(context.Background(), tokenString)
 		if err != nil {
 			log.Printf("[ERROR] Invalid JWT token: %v\n", err)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
@@ -53,7 +55,7 @@ func FirebaseAuthMiddleware(authClient *auth.Client, userCollection *mongo.Colle
 		}
 
 		// Extract email from the verified token
-		email, ok := token.Claims["email"].(string)
+		email, ok := claims["email"].(string)
 		if !ok || email == "" {
 			log.Println("[ERROR] Email not found in token")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Email not found in token"})
@@ -77,11 +79,38 @@ func FirebaseAuthMiddleware(authClient *auth.Client, userCollection *mongo.Colle
 
 			user = newUser
 		}
+		if err := userService.LiftExpiredSuspension(ctx, user); err != nil {
+			log.Printf("[ERROR] Failed to lift the expired suspension of %s: %v", email, err)
+		}
+		authTime, _ := authRepo.ClaimTime(claims, "auth_time")
+		if !CheckSession(c, user, authTime) {
+			return
+		}
 		c.Set("user", user)
 		c.Next()
 	}
 }
 
+// CheckSession aborts the request of a revoked session with 401 and of a suspended or banned user with 403, it returns
+// whether the request can go on
+func CheckSession(c *gin.Context, user *models.User, authTime time.Time) bool {
+	switch err := services.SessionError(user, authTime, time.Now()); err {
+	case nil:
+	case apperrors.ErrSessionRevoked:
+		log.Printf("[INFO] Revoked session: %s", user.Email)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return false
+	default:
+		// A suspended user can still read their own account to see why and until when, and download their data
+		if c.Request.Method != http.MethodGet || (c.Request.URL.Path != "/user/me" && c.Request.URL.Path != "/user/me/export") {
+			log.Printf("[INFO] Suspended user: %s", user.Email)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error() + ", " + user.Suspension.Reason, "suspension": user.Suspension})
+			return false
+		}
+	}
+	return true
+}
+
 func GetUserFromContext(c *gin.Context) *models.User {
 	user, exists := c.Get("user")
 	if !exists {
@@ -100,6 +129,7 @@ func GetUserRoleFromContext(c *gin.Context) models.UserRole {
 	return user.Role
 }
 
+// AllowRoles lets through the given roles, the other signed in users get 403 like with Allow
 func AllowRoles(userService *services.UserService, allowRoles ...models.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role := GetUserRoleFromContext(c)
@@ -109,26 +139,25 @@ func AllowRoles(userService *services.UserService, allowRoles ...models.UserRole
 				return
 			}
 		}
-		c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("%s cannot access this endpoint", role)})
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("%s cannot access this endpoint", role)})
 		c.Abort()
 	}
 }
 
-func CheckProviderByEmail(authClient *auth.Client, email string) ([]string, error) {
-	ctx := context.Background()
-
-	user, err := authClient.GetUserByEmail(ctx, email)
-	if err != nil {
-		if auth.IsUserNotFound(err) || strings.Contains(err.Error(), "no user") {
-			return []string{}, nil
+// Allow lets through the roles that the default policy allows to take one of the actions on the resource, the object
+// itself is authorized once it is fetched
+func Allow(resource policies.Resource, actions ...policies.Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := GetUserRoleFromContext(c)
+		if policies.Default.AllowsRole(role, resource, actions...) {
+			c.Next()
+			return
 		}
-		return nil, fmt.Errorf("failed to get user: %v", err)
-	}
-
-	var providers []string
-	for _, info := range user.ProviderUserInfo {
-		providers = append(providers, info.ProviderID)
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("%s cannot access this endpoint", role)})
+		c.Abort()
 	}
+}
 
-	return providers, nil
+func CheckProviderByEmail(verifier authRepo.TokenVerifier, email string) ([]string, error) {
+	return verifier.GetProviders(context.Background(), email)
 }
@@ -1,23 +1,37 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // TODO: Implement Appointment and BusyTime pair struct
 type Appointment struct {
-	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id" ts_type:"string" example:"656e2b5e3f1a3c4d8b9e1234"`
-	CustomerID     primitive.ObjectID `bson:"customer_id" json:"customerId" ts_type:"string" example:"656e2b5e3f1a3c4d8b9e1238"`
-	PhotographerID primitive.ObjectID `bson:"photographer_id" json:"photographerId" ts_type:"string" example:"656e2b5e3f1a324d8b9e1236"`
-	Package        Package            `bson:"package" json:"package" ts_type:"Package"`
-	Subpackage     Subpackage         `bson:"sub_package" json:"subpackage" ts_type:"Subpackage"`
-	BusyTimeID     primitive.ObjectID `bson:"busy_time_id" json:"busyTimeId" ts_type:"string" example:"656e2b5e3f1a3c4d8b9e7877"`
-	Status         AppointmentStatus  `bson:"status" json:"status" binding:"appointment_status" ts_type:"string" example:"pending"`
-	Location       string             `bson:"location,omitempty" json:"location" ts_type:"string" example:"Bangkok, Thailand"`
-	Price          int                `bson:"price" json:"price" ts_type:"number" example:"1500"`
+	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id" ts_type:"string" example:"656e2b5e3f1a3c4d8b9e1234"`
+	CustomerID     primitive.ObjectID   `bson:"customer_id" json:"customerId" ts_type:"string" example:"656e2b5e3f1a3c4d8b9e1238"`
+	PhotographerID primitive.ObjectID   `bson:"photographer_id" json:"photographerId" ts_type:"string" example:"656e2b5e3f1a324d8b9e1236"`
+	Package        Package              `bson:"package" json:"package" ts_type:"Package"`
+	Subpackage     Subpackage           `bson:"sub_package" json:"subpackage" ts_type:"Subpackage"`
+	BusyTimeID     primitive.ObjectID   `bson:"busy_time_id" json:"busyTimeId" ts_type:"string" example:"656e2b5e3f1a3c4d8b9e7877"`
+	Status         AppointmentStatus    `bson:"status" json:"status" binding:"appointment_status" ts_type:"string" example:"pending"`
+	Location       string               `bson:"location,omitempty" json:"location" ts_type:"string" example:"Bangkok, Thailand"`
+	Price          int                  `bson:"price" json:"price" ts_type:"number" example:"1500"`
+	Discount       *AppointmentDiscount `bson:"discount,omitempty" json:"discount,omitempty" ts_type:"AppointmentDiscount"`
+
+	// Status changes forced by an admin, with their reason
+	StatusOverrides []AppointmentStatusOverride `bson:"status_overrides,omitempty" json:"statusOverrides,omitempty" ts_type:"AppointmentStatusOverride[]"`
 	// Payment       Payment            `bson:"payment,omitempty" json:"payment,omitempty" example:"{...}"`
 }
 
+type AppointmentStatusOverride struct {
+	From        AppointmentStatus  `bson:"from" json:"from" ts_type:"string" example:"Accepted"`
+	To          AppointmentStatus  `bson:"to" json:"to" ts_type:"string" example:"Canceled"`
+	Reason      string             `bson:"reason" json:"reason" example:"The photographer is sick"`
+	ChangedBy   primitive.ObjectID `bson:"changed_by" json:"changedBy" ts_type:"string" example:"656e2b5e3f1a3c4d8b9e1234"`
+	ChangedTime time.Time          `bson:"changed_time" json:"changedTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
 type AppointmentStatus string
 
 const (
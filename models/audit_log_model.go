@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog records a change of an entity, who made it and through which request
+type AuditLog struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	EntityType  AuditEntityType     `bson:"entity_type" json:"entityType" example:"Appointment"`
+	EntityID    primitive.ObjectID  `bson:"entity_id" json:"entityId" ts_type:"string" example:"12345678abcd"`
+	Action      AuditAction         `bson:"action" json:"action" example:"Update"`
+	Changes     []AuditChange       `bson:"changes" json:"changes"`
+	ActorID     *primitive.ObjectID `bson:"actor_id,omitempty" json:"actorId,omitempty" ts_type:"string" example:"12345678abcd"`
+	ActorRole   UserRole            `bson:"actor_role,omitempty" json:"actorRole,omitempty" example:"Customer"`
+	Method      string              `bson:"method,omitempty" json:"method,omitempty" example:"PATCH"`
+	Endpoint    string              `bson:"endpoint,omitempty" json:"endpoint,omitempty" example:"/appointment/status/:id"`
+	RequestID   string              `bson:"request_id,omitempty" json:"requestId,omitempty" example:"0b7c4c5e-3f8e-4d43-9a55-2f1f0e8a9c11"`
+	IP          string              `bson:"ip,omitempty" json:"ip,omitempty" example:"203.0.113.7"`
+	CreatedTime time.Time           `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
+// AuditChange is a field of the entity before and after the change, a field that is added or removed has no value on
+// the other side
+type AuditChange struct {
+	Field  string      `bson:"field" json:"field" example:"status"`
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty" ts_type:"any" example:"Pending"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty" ts_type:"any" example:"Canceled"`
+}
+
+// AuditRequest is who made the request and from where, the changes made without a request (scheduled jobs, webhooks)
+// are recorded without an actor
+type AuditRequest struct {
+	ActorID   *primitive.ObjectID
+	ActorRole UserRole
+	Method    string
+	Endpoint  string
+	RequestID string
+	IP        string
+}
+
+type AuditEntityType string
+
+const (
+	AuditPackage      AuditEntityType = "Package"
+	AuditSubpackage   AuditEntityType = "Subpackage"
+	AuditBusyTime     AuditEntityType = "BusyTime"
+	AuditAppointment  AuditEntityType = "Appointment"
+	AuditPayment      AuditEntityType = "Payment"
+	AuditRating       AuditEntityType = "Rating"
+	AuditUser         AuditEntityType = "User"
+	AuditVerification AuditEntityType = "Verification"
+)
+
+var ValidAuditEntityTypes = []struct {
+	Value  AuditEntityType
+	TSName string
+}{
+	{AuditPackage, string(AuditPackage)},
+	{AuditSubpackage, string(AuditSubpackage)},
+	{AuditBusyTime, string(AuditBusyTime)},
+	{AuditAppointment, string(AuditAppointment)},
+	{AuditPayment, string(AuditPayment)},
+	{AuditRating, string(AuditRating)},
+	{AuditUser, string(AuditUser)},
+	{AuditVerification, string(AuditVerification)},
+}
+
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "Create"
+	AuditUpdate AuditAction = "Update"
+	AuditDelete AuditAction = "Delete"
+)
+
+var ValidAuditActions = []struct {
+	Value  AuditAction
+	TSName string
+}{
+	{AuditCreate, string(AuditCreate)},
+	{AuditUpdate, string(AuditUpdate)},
+	{AuditDelete, string(AuditDelete)},
+}
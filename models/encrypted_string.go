@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/Bualoi-s-Dev/backend/encryption"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// EncryptedString is encrypted with the field keyring when it is stored, it is plain text in the code and the JSON.
+// A value stored before the field was encrypted is read as it is and encrypted on its next write.
+type EncryptedString string
+
+func (s EncryptedString) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalEncrypted(string(s), false)
+}
+
+func (s *EncryptedString) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value, err := unmarshalEncrypted(t, data)
+	*s = EncryptedString(value)
+	return err
+}
+
+// DeterministicString is an EncryptedString that encrypts equal values the same way, so it can be queried for equality
+// with encryption.LookupField. It tells which values are equal to whoever reads the database, only the fields that are
+// looked up use it.
+type DeterministicString string
+
+func (s DeterministicString) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalEncrypted(string(s), true)
+}
+
+func (s *DeterministicString) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value, err := unmarshalEncrypted(t, data)
+	*s = DeterministicString(value)
+	return err
+}
+
+func marshalEncrypted(value string, deterministic bool) (bsontype.Type, []byte, error) {
+	value, err := encryption.EncryptField(value, deterministic)
+	if err != nil {
+		return 0, nil, err
+	}
+	return bson.MarshalValue(value)
+}
+
+func unmarshalEncrypted(t bsontype.Type, data []byte) (string, error) {
+	if t == bson.TypeNull || t == bson.TypeUndefined {
+		return "", nil
+	}
+	var value string
+	if err := (bson.RawValue{Type: t, Value: data}).Unmarshal(&value); err != nil {
+		return "", err
+	}
+	return encryption.DecryptField(value)
+}
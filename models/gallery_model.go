@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Gallery is the private set of final photos a photographer delivers for a completed appointment
+type Gallery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	AppointmentID  primitive.ObjectID `bson:"appointment_id" json:"appointmentId" ts_type:"string" example:"12345678abcd"`
+	PhotographerID primitive.ObjectID `bson:"photographer_id" json:"photographerId" ts_type:"string" example:"12345678abcd"`
+	CustomerID     primitive.ObjectID `bson:"customer_id" json:"customerId" ts_type:"string" example:"12345678abcd"`
+	Status         GalleryStatus      `bson:"status" json:"status" example:"Draft"`
+	Photos         []GalleryPhoto     `bson:"photos" json:"photos" ts_type:"GalleryPhoto[]"`
+	ExpireTime     time.Time          `bson:"expire_time" json:"expireTime" ts_type:"string" example:"2025-05-23T10:00:00Z"`
+	DeliveredTime  *time.Time         `bson:"delivered_time,omitempty" json:"deliveredTime,omitempty" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	ArchivedTime   *time.Time         `bson:"archived_time,omitempty" json:"archivedTime,omitempty" ts_type:"string" example:"2025-05-23T10:00:00Z"`
+	CreatedTime    time.Time          `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+
+	// Proofing, the customer selects the photos to edit from low resolution watermarked proofs
+	Proofs    []GalleryProof  `bson:"proofs,omitempty" json:"proofs" ts_type:"GalleryProof[]"`
+	Selection *ProofSelection `bson:"selection,omitempty" json:"selection,omitempty" ts_type:"ProofSelection"`
+
+	// DownloadArchive is the pre-built ZIP of a large gallery, it is only served while it matches the photos
+	DownloadArchive *GalleryDownloadArchive `bson:"download_archive,omitempty" json:"-"`
+}
+
+// GalleryPhoto is an original file of a gallery, the key is private and only shared through signed URLs
+type GalleryPhoto struct {
+	ID          primitive.ObjectID `bson:"id" json:"id" ts_type:"string" example:"12345678abcd"`
+	Key         string             `bson:"key" json:"-"`
+	FileName    string             `bson:"file_name" json:"fileName" example:"IMG_0001.jpg"`
+	ContentType string             `bson:"content_type" json:"contentType" example:"image/jpeg"`
+	Size        int64              `bson:"size" json:"size" example:"1048576"`
+	CreatedTime time.Time          `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
+// GalleryProof is an unedited photo offered for selection, only its watermarked preview is shared with the customer
+type GalleryProof struct {
+	ID          primitive.ObjectID `bson:"id" json:"id" ts_type:"string" example:"12345678abcd"`
+	OriginalKey string             `bson:"original_key" json:"-"`
+	PreviewKey  string             `bson:"preview_key" json:"-"`
+	FileName    string             `bson:"file_name" json:"fileName" example:"IMG_0001.jpg"`
+	ContentType string             `bson:"content_type" json:"contentType" example:"image/jpeg"`
+	Size        int64              `bson:"size,omitempty" json:"-"`
+	CreatedTime time.Time          `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
+// ProofSelection is the choice of the customer, the limit and the extra photo price are copied from the subpackage
+// when proofing is opened, a limit of 0 means the customer can select every proof.
+// ExtraCount is the number of photos beyond the limit, PaidExtraCount of them are already paid
+type ProofSelection struct {
+	PhotoIDs        []primitive.ObjectID `bson:"photo_ids" json:"photoIds" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+	Limit           int                  `bson:"limit" json:"limit" example:"20"`
+	ExtraPhotoPrice int                  `bson:"extra_photo_price" json:"extraPhotoPrice" example:"100"`
+	ExtraCount      int                  `bson:"extra_count" json:"extraCount" example:"2"`
+	PaidExtraCount  int                  `bson:"paid_extra_count" json:"paidExtraCount" example:"2"`
+	Status          ProofSelectionStatus `bson:"status" json:"status" example:"Open"`
+	ExtraPaymentID  *primitive.ObjectID  `bson:"extra_payment_id,omitempty" json:"extraPaymentId,omitempty" ts_type:"string" example:"12345678abcd"`
+	SubmittedTime   *time.Time           `bson:"submitted_time,omitempty" json:"submittedTime,omitempty" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
+// GalleryDownloadArchive is a ZIP of the photos kept in storage, Fingerprint identifies the photos it contains
+type GalleryDownloadArchive struct {
+	Key         string    `bson:"key"`
+	Fingerprint string    `bson:"fingerprint"`
+	Size        int64     `bson:"size"`
+	CreatedTime time.Time `bson:"created_time"`
+}
+
+type GalleryStatus string
+
+const (
+	GalleryDraft     GalleryStatus = "Draft"
+	GalleryDelivered GalleryStatus = "Delivered"
+	GalleryArchived  GalleryStatus = "Archived"
+)
+
+var ValidGalleryStatus = []struct {
+	Value  GalleryStatus
+	TSName string
+}{
+	{GalleryDraft, string(GalleryDraft)},
+	{GalleryDelivered, string(GalleryDelivered)},
+	{GalleryArchived, string(GalleryArchived)},
+}
+
+type ProofSelectionStatus string
+
+const (
+	ProofSelectionOpen            ProofSelectionStatus = "Open"
+	ProofSelectionAwaitingPayment ProofSelectionStatus = "AwaitingPayment"
+	ProofSelectionSubmitted       ProofSelectionStatus = "Submitted"
+)
+
+var ValidProofSelectionStatus = []struct {
+	Value  ProofSelectionStatus
+	TSName string
+}{
+	{ProofSelectionOpen, string(ProofSelectionOpen)},
+	{ProofSelectionAwaitingPayment, string(ProofSelectionAwaitingPayment)},
+	{ProofSelectionSubmitted, string(ProofSelectionSubmitted)},
+}
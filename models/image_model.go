@@ -0,0 +1,37 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Image holds the storage keys of the renditions generated from an uploaded photo
+type Image struct {
+	Thumbnail string `bson:"thumbnail" json:"thumbnail" example:"/package/12345678abcd_12345678abcd_thumbnail.jpg"`
+	Medium    string `bson:"medium" json:"medium" example:"/package/12345678abcd_12345678abcd_medium.jpg"`
+	Large     string `bson:"large" json:"large" example:"/package/12345678abcd_12345678abcd_large.jpg"`
+	WebP      string `bson:"webp" json:"webp" example:"/package/12345678abcd_12345678abcd_medium.webp"`
+	Width     int    `bson:"width" json:"width" example:"2048"`
+	Height    int    `bson:"height" json:"height" example:"1365"`
+
+	// ID identifies the photo inside its package, the caption is shown with package photos
+	ID      primitive.ObjectID `bson:"id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	Caption string             `bson:"caption,omitempty" json:"caption,omitempty" example:"First dance"`
+
+	// OriginalKey is the private unwatermarked upload the renditions are generated from
+	OriginalKey string `bson:"original_key,omitempty" json:"-"`
+
+	// PerceptualHash finds copies of the photo uploaded by other photographers
+	PerceptualHash string `bson:"perceptual_hash,omitempty" json:"-"`
+
+	// Size is the stored bytes of the renditions and the original, counted in the storage usage of the owner
+	Size int64 `bson:"size,omitempty" json:"-"`
+}
+
+// Keys returns every rendition key of the image
+func (image *Image) Keys() []string {
+	keys := []string{}
+	for _, key := range []string{image.Thumbnail, image.Medium, image.Large, image.WebP} {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification is a message shown in the inbox of a user
+type Notification struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	UserID        primitive.ObjectID  `bson:"user_id" json:"userId" ts_type:"string" example:"12345678abcd"`
+	Type          NotificationType    `bson:"type" json:"type" example:"ProofSelectionSubmitted"`
+	Title         string              `bson:"title" json:"title" example:"Proof selection submitted"`
+	Message       string              `bson:"message" json:"message" example:"Meen selected 20 photos to edit"`
+	AppointmentID *primitive.ObjectID `bson:"appointment_id,omitempty" json:"appointmentId,omitempty" ts_type:"string" example:"12345678abcd"`
+	IsRead        bool                `bson:"is_read" json:"isRead" example:"false"`
+	CreatedTime   time.Time           `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
+type NotificationType string
+
+const (
+	NotificationProofSelectionSubmitted NotificationType = "ProofSelectionSubmitted"
+	NotificationPackagePhotoHeld        NotificationType = "PackagePhotoHeld"
+	NotificationPackagePhotoApproved    NotificationType = "PackagePhotoApproved"
+	NotificationPackagePhotoRejected    NotificationType = "PackagePhotoRejected"
+	NotificationAppointmentStatusForced NotificationType = "AppointmentStatusForced"
+	NotificationPackageRemoved          NotificationType = "PackageRemoved"
+	NotificationRatingRemoved           NotificationType = "RatingRemoved"
+	NotificationVerificationApproved    NotificationType = "VerificationApproved"
+	NotificationVerificationRejected    NotificationType = "VerificationRejected"
+)
+
+var ValidNotificationTypes = []struct {
+	Value  NotificationType
+	TSName string
+}{
+	{NotificationProofSelectionSubmitted, string(NotificationProofSelectionSubmitted)},
+	{NotificationPackagePhotoHeld, string(NotificationPackagePhotoHeld)},
+	{NotificationPackagePhotoApproved, string(NotificationPackagePhotoApproved)},
+	{NotificationPackagePhotoRejected, string(NotificationPackagePhotoRejected)},
+	{NotificationAppointmentStatusForced, string(NotificationAppointmentStatusForced)},
+	{NotificationPackageRemoved, string(NotificationPackageRemoved)},
+	{NotificationRatingRemoved, string(NotificationRatingRemoved)},
+	{NotificationVerificationApproved, string(NotificationVerificationApproved)},
+	{NotificationVerificationRejected, string(NotificationVerificationRejected)},
+}
@@ -10,6 +10,7 @@ type Package struct {
 	Title     string             `form:"title" bson:"title" json:"title" binding:"required" example:"Wedding Bliss Package"`
 	Type      PackageType        `form:"type" bson:"type" json:"type" binding:"required,package_type" example:"WEDDING_BLISS"`
 	PhotoUrls []string           `bson:"photo_urls" json:"photoUrls" example:"/package/12345678abcd_1,/package/12345678abcd_2"`
+	Images    []Image            `bson:"images" json:"images" ts_type:"Image[]"`
 }
 
 type PackageType string
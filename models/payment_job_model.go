@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PaymentJob records the creation of the payment of a completed appointment so that failures can be retried
+type PaymentJob struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	AppointmentID primitive.ObjectID `bson:"appointment_id" json:"appointmentId" ts_type:"string" example:"12345678abcd"`
+	Status        PaymentJobStatus   `bson:"status" json:"status" example:"Pending"`
+	Attempts      int                `bson:"attempts" json:"attempts" example:"1"`
+	LastError     string             `bson:"last_error,omitempty" json:"lastError,omitempty" example:"stripe: connection refused"`
+	NextRunTime   time.Time          `bson:"next_run_time" json:"nextRunTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	CreatedTime   time.Time          `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	UpdatedTime   time.Time          `bson:"updated_time" json:"updatedTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
+type PaymentJobStatus string
+
+const (
+	PaymentJobPending   PaymentJobStatus = "Pending"
+	PaymentJobSucceeded PaymentJobStatus = "Succeeded"
+	PaymentJobFailed    PaymentJobStatus = "Failed"
+)
+
+var ValidPaymentJobStatus = []struct {
+	Value  PaymentJobStatus
+	TSName string
+}{
+	{PaymentJobPending, string(PaymentJobPending)},
+	{PaymentJobSucceeded, string(PaymentJobSucceeded)},
+	{PaymentJobFailed, string(PaymentJobFailed)},
+}
@@ -1,10 +1,17 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type Payment struct {
 	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
 	AppointmentID primitive.ObjectID  `bson:"appointment_id" json:"appointmentId" ts_type:"string" example:"12345678abcd"`
+	Type          PaymentType         `bson:"type,omitempty" json:"type" example:"Appointment"`
+	Amount        int                 `bson:"amount" json:"amount" example:"1500"`
+	Fee           int                 `bson:"fee" json:"fee" example:"5"`
 	Customer      CustomerPayment     `bson:"customer" json:"customer"`
 	Photographer  PhotographerPayment `bson:"photographer" json:"photographer"`
 }
@@ -13,11 +20,27 @@ type CustomerPayment struct {
 	Status          PaymentStatus `bson:"status" json:"status"  binding:"omitempty,payment_status" example:"Paid"`
 	CheckoutID      *string       `bson:"checkout_id" json:"checkoutId" ts_type:"string" example:"12345678abcd"`
 	PaymentIntentID *string       `bson:"payment_intent_id" json:"paymentIntentId" ts_type:"string" example:"12345678abcd"`
+	RefundedAmount  int           `bson:"refunded_amount" json:"refundedAmount" example:"0"`
 }
 
 type PhotographerPayment struct {
 	Status               PaymentStatus `bson:"status" json:"status" binding:"omitempty,payment_status" example:"Paid"`
 	BalanceTransactionID *string       `bson:"balance_transaction_id" json:"balanceTransactionId" ts_type:"string" example:"12345678abcd"`
+	PayoutTime           *time.Time    `bson:"payout_time,omitempty" json:"payoutTime,omitempty" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
+// PaymentWithAppointment is a payment joined with its appointment, used by reports
+type PaymentWithAppointment struct {
+	Payment     `bson:",inline"`
+	Appointment Appointment `bson:"appointment"`
+}
+
+// PaymentTotals sums the payments the customers paid
+type PaymentTotals struct {
+	Count          int64 `bson:"count" json:"count" example:"120"`
+	Amount         int64 `bson:"amount" json:"amount" example:"180000"`
+	Fee            int64 `bson:"fee" json:"fee" example:"9000"`
+	RefundedAmount int64 `bson:"refunded_amount" json:"refundedAmount" example:"1500"`
 }
 
 type PaymentStatus string
@@ -40,3 +63,26 @@ var ValidPaymentStatus = []struct {
 	{Paid, string(Paid)},
 	{Completed, string(Completed)},
 }
+
+type PaymentType string
+
+const (
+	PaymentAppointment PaymentType = "Appointment"
+	PaymentTip         PaymentType = "Tip"
+	PaymentExtraPhotos PaymentType = "ExtraPhotos"
+)
+
+var ValidPaymentTypes = []struct {
+	Value  PaymentType
+	TSName string
+}{
+	{PaymentAppointment, string(PaymentAppointment)},
+	{PaymentTip, string(PaymentTip)},
+	{PaymentExtraPhotos, string(PaymentExtraPhotos)},
+}
+
+// IsAppointmentPayment tells the payment of the appointment itself from tips and extra purchases,
+// payments created before the type existed are appointment payments
+func (payment *Payment) IsAppointmentPayment() bool {
+	return payment.Type == "" || payment.Type == PaymentAppointment
+}
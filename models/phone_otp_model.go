@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PhoneOTP is a one-time code sent to a phone number of the user, only the hash of the code is stored
+type PhoneOTP struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `bson:"user_id"`
+	Phone       EncryptedString    `bson:"phone"`
+	CodeHash    string             `bson:"code_hash"`
+	Attempts    int                `bson:"attempts"`
+	ExpireTime  time.Time          `bson:"expire_time"`
+	CreatedTime time.Time          `bson:"created_time"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PhotoHash indexes the perceptual hash of a published package photo, the bands are the indexed bytes of the hash
+type PhotoHash struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Hash        string             `bson:"hash"`
+	Bands       []int              `bson:"bands"`
+	OwnerID     primitive.ObjectID `bson:"owner_id"`
+	PackageID   primitive.ObjectID `bson:"package_id"`
+	ImageID     primitive.ObjectID `bson:"image_id"`
+	CreatedTime time.Time          `bson:"created_time"`
+}
+
+// PhotoMatch is a published photo of another photographer that looks the same as the uploaded photo
+type PhotoMatch struct {
+	OwnerID   primitive.ObjectID `bson:"owner_id" json:"ownerId" ts_type:"string" example:"12345678abcd"`
+	PackageID primitive.ObjectID `bson:"package_id" json:"packageId" ts_type:"string" example:"12345678abcd"`
+	ImageID   primitive.ObjectID `bson:"image_id" json:"imageId" ts_type:"string" example:"12345678abcd"`
+	Distance  int                `bson:"distance" json:"distance" example:"2"`
+}
+
+// PhotoModeration is an uploaded package photo held back from the package until an admin reviews it
+type PhotoModeration struct {
+	ID           primitive.ObjectID    `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	OwnerID      primitive.ObjectID    `bson:"owner_id" json:"ownerId" ts_type:"string" example:"12345678abcd"`
+	PackageID    primitive.ObjectID    `bson:"package_id" json:"packageId" ts_type:"string" example:"12345678abcd"`
+	Image        Image                 `bson:"image" json:"image"`
+	Matches      []PhotoMatch          `bson:"matches" json:"matches"`
+	Status       PhotoModerationStatus `bson:"status" json:"status" example:"Pending"`
+	Reason       string                `bson:"reason,omitempty" json:"reason,omitempty" example:"Copied from another photographer"`
+	ReviewerID   *primitive.ObjectID   `bson:"reviewer_id,omitempty" json:"reviewerId,omitempty" ts_type:"string" example:"12345678abcd"`
+	CreatedTime  time.Time             `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	ReviewedTime *time.Time            `bson:"reviewed_time,omitempty" json:"reviewedTime,omitempty" ts_type:"string" example:"2025-02-24T10:00:00Z"`
+}
+
+type PhotoModerationStatus string
+
+const (
+	PhotoModerationPending  PhotoModerationStatus = "Pending"
+	PhotoModerationApproved PhotoModerationStatus = "Approved"
+	PhotoModerationRejected PhotoModerationStatus = "Rejected"
+)
+
+var ValidPhotoModerationStatus = []struct {
+	Value  PhotoModerationStatus
+	TSName string
+}{
+	{PhotoModerationPending, string(PhotoModerationPending)},
+	{PhotoModerationApproved, string(PhotoModerationApproved)},
+	{PhotoModerationRejected, string(PhotoModerationRejected)},
+}
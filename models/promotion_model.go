@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Promotion struct {
+	ID               primitive.ObjectID   `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	Code             string               `bson:"code" json:"code" example:"SUMMER10"`
+	OwnerID          primitive.ObjectID   `bson:"owner_id" json:"ownerId" ts_type:"string" example:"12345678abcd"`
+	Scope            PromotionScope       `bson:"scope" json:"scope" binding:"promotion_scope" example:"Photographer"`
+	PackageIDs       []primitive.ObjectID `bson:"package_ids" json:"packageIds" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
+	DiscountType     DiscountType         `bson:"discount_type" json:"discountType" binding:"discount_type" example:"Percentage"`
+	DiscountValue    int                  `bson:"discount_value" json:"discountValue" example:"10"`
+	MaxDiscount      int                  `bson:"max_discount,omitempty" json:"maxDiscount" example:"500" description:"Upper bound of a percentage discount, 0 means no bound"`
+	MinPrice         int                  `bson:"min_price,omitempty" json:"minPrice" example:"1000"`
+	StartTime        time.Time            `bson:"start_time" json:"startTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	EndTime          time.Time            `bson:"end_time" json:"endTime" ts_type:"string" example:"2025-03-23T10:00:00Z"`
+	UsageLimit       int                  `bson:"usage_limit" json:"usageLimit" example:"100" description:"0 means unlimited"`
+	PerCustomerLimit int                  `bson:"per_customer_limit" json:"perCustomerLimit" example:"1" description:"0 means unlimited"`
+	UsedCount        int                  `bson:"used_count" json:"usedCount" example:"12"`
+	IsActive         bool                 `bson:"is_active" json:"isActive" example:"true"`
+	CreatedTime      time.Time            `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
+// PromotionRedemption records a single use of a promotion by a customer
+type PromotionRedemption struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	PromotionID   primitive.ObjectID `bson:"promotion_id" json:"promotionId" ts_type:"string" example:"12345678abcd"`
+	CustomerID    primitive.ObjectID `bson:"customer_id" json:"customerId" ts_type:"string" example:"12345678abcd"`
+	AppointmentID primitive.ObjectID `bson:"appointment_id" json:"appointmentId" ts_type:"string" example:"12345678abcd"`
+	// Slot is which of the PerCustomerLimit uses of the customer this is, 0 when the promotion has no such limit
+	Slot        int       `bson:"slot,omitempty" json:"-"`
+	CreatedTime time.Time `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
+
+// AppointmentDiscount is a snapshot of the promotion applied when the appointment was booked
+type AppointmentDiscount struct {
+	PromotionID   primitive.ObjectID `bson:"promotion_id" json:"promotionId" ts_type:"string" example:"12345678abcd"`
+	Code          string             `bson:"code" json:"code" example:"SUMMER10"`
+	Scope         PromotionScope     `bson:"scope" json:"scope" example:"Photographer"`
+	DiscountType  DiscountType       `bson:"discount_type" json:"discountType" example:"Percentage"`
+	DiscountValue int                `bson:"discount_value" json:"discountValue" example:"10"`
+	OriginalPrice int                `bson:"original_price" json:"originalPrice" example:"1500"`
+	Amount        int                `bson:"amount" json:"amount" example:"150"`
+}
+
+type PromotionScope string
+
+const (
+	PromotionPhotographer PromotionScope = "Photographer"
+	PromotionPlatform     PromotionScope = "Platform"
+)
+
+var ValidPromotionScopes = []struct {
+	Value  PromotionScope
+	TSName string
+}{
+	{PromotionPhotographer, string(PromotionPhotographer)},
+	{PromotionPlatform, string(PromotionPlatform)},
+}
+
+type DiscountType string
+
+const (
+	DiscountPercentage DiscountType = "Percentage"
+	DiscountFixed      DiscountType = "Fixed"
+)
+
+var ValidDiscountTypes = []struct {
+	Value  DiscountType
+	TSName string
+}{
+	{DiscountPercentage, string(DiscountPercentage)},
+	{DiscountFixed, string(DiscountFixed)},
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// StorageObject describes an object listed from the blob storage
+type StorageObject struct {
+	Key          string    `json:"key" example:"package/12345678abcd_12345678abcd_large.jpg"`
+	Size         int64     `json:"size" example:"1048576"`
+	LastModified time.Time `json:"lastModified" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+}
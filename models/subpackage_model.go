@@ -17,6 +17,10 @@ type Subpackage struct {
 
 	AvailableStartDay string `bson:"available_start_day,omitempty" json:"availableStartDay" binding:"date_format" example:"2021-01-01"`
 	AvailableEndDay   string `bson:"available_end_day,omitempty" json:"availableEndDay" binding:"date_format" example:"2021-12-31"`
+
+	// Proofing, the customer selects SelectionLimit photos to edit and pays ExtraPhotoPrice for each extra one
+	SelectionLimit  int `bson:"selection_limit,omitempty" json:"selectionLimit" example:"20"`
+	ExtraPhotoPrice int `bson:"extra_photo_price,omitempty" json:"extraPhotoPrice" example:"100"`
 }
 
 type DayName string
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Upload is a file the client uploads directly to the storage through a presigned URL,
+// it must be confirmed by a package or profile update before it expires
+type Upload struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	OwnerID     primitive.ObjectID `bson:"owner_id" json:"ownerId" ts_type:"string" example:"12345678abcd"`
+	Purpose     UploadPurpose      `bson:"purpose" json:"purpose" example:"PackagePhoto"`
+	Key         string             `bson:"key" json:"key" example:"upload/12345678abcd"`
+	ContentType string             `bson:"content_type" json:"contentType" example:"image/jpeg"`
+	Size        int64              `bson:"size" json:"size" example:"1048576"`
+	Status      UploadStatus       `bson:"status" json:"status" example:"Pending"`
+	ExpireTime  time.Time          `bson:"expire_time" json:"expireTime" ts_type:"string" example:"2025-02-23T10:15:00Z"`
+	CreatedTime time.Time          `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+
+	// FileName is the name of the file on the client, kept for delivered photos
+	FileName string `bson:"file_name,omitempty" json:"fileName,omitempty" example:"IMG_0001.jpg"`
+}
+
+type UploadPurpose string
+
+const (
+	UploadPackagePhoto  UploadPurpose = "PackagePhoto"
+	UploadProfile       UploadPurpose = "Profile"
+	UploadGalleryPhoto  UploadPurpose = "GalleryPhoto"
+	UploadWatermarkLogo UploadPurpose = "WatermarkLogo"
+	// UploadIdentityDocument is a photo of the ID card or passport of a photographer, it stays private
+	UploadIdentityDocument UploadPurpose = "IdentityDocument"
+)
+
+var ValidUploadPurposes = []struct {
+	Value  UploadPurpose
+	TSName string
+}{
+	{UploadPackagePhoto, string(UploadPackagePhoto)},
+	{UploadProfile, string(UploadProfile)},
+	{UploadGalleryPhoto, string(UploadGalleryPhoto)},
+	{UploadWatermarkLogo, string(UploadWatermarkLogo)},
+	{UploadIdentityDocument, string(UploadIdentityDocument)},
+}
+
+type UploadStatus string
+
+const (
+	UploadPending   UploadStatus = "Pending"
+	UploadConfirmed UploadStatus = "Confirmed"
+)
@@ -1,6 +1,11 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type User struct {
 	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
@@ -8,22 +13,95 @@ type User struct {
 	Name     string             `bson:"name,omitempty" json:"name" example:"Meen"`
 	Gender   string             `bson:"gender,omitempty" json:"gender" example:"LGTV"`
 	Profile  string             `bson:"profile,omitempty" json:"profile" example:"/profile/12345678abcd"`
-	Phone    string             `bson:"phone,omitempty" json:"phone" example:"0812345678"`
+	Phone    EncryptedString    `bson:"phone,omitempty" json:"phone" ts_type:"string" example:"0812345678"`
 	Location string             `bson:"location,omitempty" json:"location" example:"Bangkok, Thailand"`
+	// The phone number confirmed with a code sent to it, changing Phone unverifies it
+	VerifiedPhone DeterministicString `bson:"verified_phone,omitempty" json:"-"`
+
+	// Profile picture renditions, Profile keeps the medium rendition
+	ProfileImage *Image `bson:"profile_image,omitempty" json:"profileImage,omitempty" ts_type:"Image"`
 
 	//Photographer Info
 	Role             UserRole             `bson:"role,omitempty" json:"role" binding:"omitempty,user_role" example:"Photographer"`
 	Description      string               `bson:"description,omitempty" json:"description" example:"I'm a photographer"`
 	BankName         BankName             `bson:"bank_name,omitempty" json:"bankName" binding:"omitempty,bank_name" example:"KRUNG_THAI_BANK"`
-	BankAccount      string               `bson:"bank_account,omitempty" json:"bankAccount" example:"1234567890"`
+	BankAccount      EncryptedString      `bson:"bank_account,omitempty" json:"bankAccount" ts_type:"string" example:"1234567890"`
 	LineID           string               `bson:"line_id,omitempty" json:"lineID" example:"@meen"`
 	Facebook         string               `bson:"facebook,omitempty" json:"facebook" example:"Meen"`
 	Instagram        string               `bson:"instagram,omitempty" json:"instagram" example:"Meen"`
 	ShowcasePackages []primitive.ObjectID `bson:"showcase_packages,omitempty" json:"showcasePackages" ts_type:"string[]" example:"12345678abcd,12345678abcd"`
 
 	// Payment Info
-	StripeCustomerID *string `bson:"stripe_customer_id,omitempty" json:"stripeCustomerId" example:"12345678abcd"`
-	StripeAccountID  *string `bson:"stripe_account_id,omitempty" json:"stripeAccountId" example:"12345678abcd"`
+	StripeCustomerID *EncryptedString `bson:"stripe_customer_id,omitempty" json:"stripeCustomerId" ts_type:"string" example:"12345678abcd"`
+	StripeAccountID  *EncryptedString `bson:"stripe_account_id,omitempty" json:"stripeAccountId" ts_type:"string" example:"12345678abcd"`
+
+	// Status of the latest review of the identity and bank details, only a verified photographer is listed and paid out
+	VerificationStatus VerificationStatus `bson:"verification_status,omitempty" json:"verificationStatus,omitempty" example:"Approved"`
+	VerifiedTime       *time.Time         `bson:"verified_time,omitempty" json:"verifiedTime,omitempty" ts_type:"string" example:"2025-02-24T10:00:00Z"`
+
+	// Watermark drawn over the public package photos and the proofs of the photographer
+	Watermark *Watermark `bson:"watermark,omitempty" json:"-"`
+
+	// Set by an admin, a suspended or banned user cannot use the API
+	Suspension *UserSuspension `bson:"suspension,omitempty" json:"suspension,omitempty" ts_type:"UserSuspension"`
+	// The ID tokens of a sign in before it are refused, it is set when the sessions of the user are revoked
+	TokensValidAfter *time.Time `bson:"tokens_valid_after,omitempty" json:"-"`
+
+	// Set when the user asks to delete their account, it is deleted at ScheduledTime unless they cancel it
+	Deletion *UserDeletion `bson:"deletion,omitempty" json:"deletion,omitempty" ts_type:"UserDeletion"`
+}
+
+// IsVerified reports whether the identity and bank details of the user were approved
+func (u *User) IsVerified() bool {
+	return u.VerificationStatus == VerificationApproved
+}
+
+// IsListed reports whether the packages of the user are listed and booked, the photographers from before the
+// verification are listed without being verified
+func (u *User) IsListed() bool {
+	return u.IsVerified() || u.VerificationStatus == VerificationLegacy
+}
+
+// IsPhoneVerified reports whether the current phone of the user was confirmed with a code
+func (u *User) IsPhoneVerified() bool {
+	return u.Phone != "" && string(u.VerifiedPhone) == string(u.Phone)
+}
+
+type UserDeletion struct {
+	RequestedTime time.Time `bson:"requested_time" json:"requestedTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	ScheduledTime time.Time `bson:"scheduled_time" json:"scheduledTime" ts_type:"string" example:"2025-03-25T10:00:00Z"`
+}
+
+type UserSuspension struct {
+	Type          SuspensionType     `bson:"type,omitempty" json:"type" example:"Suspended"`
+	Reason        string             `bson:"reason" json:"reason" example:"Spam"`
+	SuspendedBy   primitive.ObjectID `bson:"suspended_by" json:"suspendedBy" ts_type:"string" example:"12345678abcd"`
+	SuspendedTime time.Time          `bson:"suspended_time" json:"suspendedTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	// A suspension is lifted by itself once it expires, a ban never expires
+	ExpiresTime *time.Time `bson:"expires_time,omitempty" json:"expiresTime,omitempty" ts_type:"string" example:"2025-03-23T10:00:00Z"`
+}
+
+// IsActive reports whether the suspension still applies at the time, a nil suspension never does
+func (s *UserSuspension) IsActive(now time.Time) bool {
+	if s == nil {
+		return false
+	}
+	return s.Type == SuspensionBanned || s.ExpiresTime == nil || now.Before(*s.ExpiresTime)
+}
+
+type SuspensionType string
+
+const (
+	SuspensionSuspended SuspensionType = "Suspended"
+	SuspensionBanned    SuspensionType = "Banned"
+)
+
+var ValidSuspensionTypes = []struct {
+	Value  SuspensionType
+	TSName string
+}{
+	{SuspensionSuspended, string(SuspensionSuspended)},
+	{SuspensionBanned, string(SuspensionBanned)},
 }
 
 func NewUser(email string) *User {
@@ -46,6 +124,7 @@ const (
 	Photographer UserRole = "Photographer"
 	Customer     UserRole = "Customer"
 	Guest        UserRole = "Guest"
+	Admin        UserRole = "Admin"
 )
 
 var ValidUserRoles = []struct {
@@ -55,6 +134,7 @@ var ValidUserRoles = []struct {
 	{Photographer, string(Photographer)},
 	{Customer, string(Customer)},
 	{Guest, string(Guest)},
+	{Admin, string(Admin)},
 }
 
 type BankName string
@@ -91,3 +171,47 @@ var ValidBankNames = []struct {
 	{StandardChartered, string(StandardChartered)},
 	{ICBCThailand, string(ICBCThailand)},
 }
+
+// BankAccountLengths is the number of digits of an account number at each bank
+var BankAccountLengths = map[BankName]int{
+	KrungThaiBank:         10,
+	BangkokBank:           10,
+	SiamCommercialBank:    10,
+	KasikornBank:          10,
+	TMBThanachartBank:     10,
+	KrungsriBank:          10,
+	GovernmentSavingsBank: 12,
+	ThaiMilitaryBank:      10,
+	UOBThailand:           10,
+	CIMBThailand:          10,
+	StandardChartered:     11,
+	ICBCThailand:          10,
+}
+
+// NormalizeBankAccount drops the dashes and spaces the account numbers are written with, such as 123-4-56789-0
+func NormalizeBankAccount(number string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(number)
+}
+
+// IsValidBankAccount reports whether the account number has the digits of the bank, an unknown bank takes the 10 to 12
+// digits of any Thai bank
+func IsValidBankAccount(bank BankName, number string) bool {
+	number = NormalizeBankAccount(number)
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	if length, ok := BankAccountLengths[bank]; ok {
+		return len(number) == length
+	}
+	return len(number) >= 10 && len(number) <= 12
+}
+
+// MaskBankAccount keeps the last 4 digits of the account number
+func MaskBankAccount(number string) string {
+	if len(number) <= 4 {
+		return strings.Repeat("x", len(number))
+	}
+	return strings.Repeat("x", len(number)-4) + number[len(number)-4:]
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PhotographerVerification is the identity document and the bank details a photographer submits, an admin reviews
+// them before the photographer is listed and paid out
+type PhotographerVerification struct {
+	ID              primitive.ObjectID   `bson:"_id,omitempty" json:"id" ts_type:"string" example:"12345678abcd"`
+	UserID          primitive.ObjectID   `bson:"user_id" json:"userId" ts_type:"string" example:"12345678abcd"`
+	DocumentType    IdentityDocumentType `bson:"document_type" json:"documentType" example:"NationalID"`
+	DocumentKeys    []string             `bson:"document_keys" json:"-"`
+	BankName        BankName             `bson:"bank_name" json:"bankName" example:"KRUNG_THAI_BANK"`
+	BankAccount     EncryptedString      `bson:"bank_account" json:"bankAccount" ts_type:"string" example:"1234567890"`
+	BankAccountName string               `bson:"bank_account_name" json:"bankAccountName" example:"Meen Meen"`
+	Status          VerificationStatus   `bson:"status" json:"status" example:"Pending"`
+	Reason          string               `bson:"reason,omitempty" json:"reason,omitempty" example:"The document is not readable"`
+	ReviewerID      *primitive.ObjectID  `bson:"reviewer_id,omitempty" json:"reviewerId,omitempty" ts_type:"string" example:"12345678abcd"`
+	CreatedTime     time.Time            `bson:"created_time" json:"createdTime" ts_type:"string" example:"2025-02-23T10:00:00Z"`
+	ReviewedTime    *time.Time           `bson:"reviewed_time,omitempty" json:"reviewedTime,omitempty" ts_type:"string" example:"2025-02-24T10:00:00Z"`
+}
+
+type VerificationStatus string
+
+const (
+	VerificationPending  VerificationStatus = "Pending"
+	VerificationApproved VerificationStatus = "Approved"
+	VerificationRejected VerificationStatus = "Rejected"
+	// VerificationLegacy is a photographer from before the verification, listed but not verified until they submit one
+	VerificationLegacy VerificationStatus = "Legacy"
+)
+
+var ValidVerificationStatus = []struct {
+	Value  VerificationStatus
+	TSName string
+}{
+	{VerificationPending, string(VerificationPending)},
+	{VerificationApproved, string(VerificationApproved)},
+	{VerificationRejected, string(VerificationRejected)},
+	{VerificationLegacy, string(VerificationLegacy)},
+}
+
+type IdentityDocumentType string
+
+const (
+	IdentityNationalID IdentityDocumentType = "NationalID"
+	IdentityPassport   IdentityDocumentType = "Passport"
+)
+
+var ValidIdentityDocumentTypes = []struct {
+	Value  IdentityDocumentType
+	TSName string
+}{
+	{IdentityNationalID, string(IdentityNationalID)},
+	{IdentityPassport, string(IdentityPassport)},
+}
@@ -0,0 +1,34 @@
+package models
+
+// Watermark is drawn over images shared outside of a private gallery
+type Watermark struct {
+	Text     string            `bson:"text,omitempty" json:"text" example:"© Meen Studio"`
+	Position WatermarkPosition `bson:"position" json:"position" example:"BottomRight"`
+	Opacity  float64           `bson:"opacity" json:"opacity" example:"0.5"`
+
+	// LogoKey is the private storage key of the uploaded logo, the logo replaces the text when it is set
+	LogoKey string `bson:"logo_key,omitempty" json:"-"`
+}
+
+type WatermarkPosition string
+
+const (
+	WatermarkCenter      WatermarkPosition = "Center"
+	WatermarkTopLeft     WatermarkPosition = "TopLeft"
+	WatermarkTopRight    WatermarkPosition = "TopRight"
+	WatermarkBottomLeft  WatermarkPosition = "BottomLeft"
+	WatermarkBottomRight WatermarkPosition = "BottomRight"
+	WatermarkTiled       WatermarkPosition = "Tiled"
+)
+
+var ValidWatermarkPositions = []struct {
+	Value  WatermarkPosition
+	TSName string
+}{
+	{WatermarkCenter, string(WatermarkCenter)},
+	{WatermarkTopLeft, string(WatermarkTopLeft)},
+	{WatermarkTopRight, string(WatermarkTopRight)},
+	{WatermarkBottomLeft, string(WatermarkBottomLeft)},
+	{WatermarkBottomRight, string(WatermarkBottomRight)},
+	{WatermarkTiled, string(WatermarkTiled)},
+}
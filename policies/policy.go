@@ -0,0 +1,163 @@
+package policies
+
+import (
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Resource string
+
+const (
+	ResourcePackage     Resource = "Package"
+	ResourceSubpackage  Resource = "Subpackage"
+	ResourceAppointment Resource = "Appointment"
+	ResourcePayment     Resource = "Payment"
+	ResourceRating      Resource = "Rating"
+)
+
+type Action string
+
+const (
+	ActionRead   Action = "Read"
+	ActionCreate Action = "Create"
+	ActionUpdate Action = "Update"
+	ActionDelete Action = "Delete"
+	// Appointment status changes
+	ActionAccept Action = "Accept"
+	ActionReject Action = "Reject"
+	ActionCancel Action = "Cancel"
+	// Tip the photographer of an appointment
+	ActionTip Action = "Tip"
+)
+
+// Relation is what the subject has to be to the object for a rule to apply
+type Relation string
+
+const (
+	// RelationAny applies to every object of the resource
+	RelationAny Relation = "Any"
+	// RelationCustomer applies to the customer of the appointment or payment and the author of the rating
+	RelationCustomer Relation = "Customer"
+	// RelationPhotographer applies to the owner of the package or subpackage, the photographer of the appointment or
+	// payment and the photographer who is rated
+	RelationPhotographer Relation = "Photographer"
+	// RelationParticipant applies to both the customer and the photographer
+	RelationParticipant Relation = "Participant"
+)
+
+// Rule lets the roles take the action on the objects of the resource they have the relation with
+type Rule struct {
+	Resource Resource
+	Action   Action
+	Roles    []models.UserRole
+	Relation Relation
+}
+
+// Subject is the user taking the action
+type Subject struct {
+	ID   primitive.ObjectID
+	Role models.UserRole
+}
+
+// Object is the resource the action is taken on, with the users it belongs to
+type Object struct {
+	Resource       Resource
+	CustomerID     primitive.ObjectID
+	PhotographerID primitive.ObjectID
+}
+
+// Policy allows an action when at least one of its rules allows it, everything else is denied
+type Policy struct {
+	Rules []Rule
+}
+
+func NewPolicy(rules ...Rule) *Policy {
+	return &Policy{Rules: rules}
+}
+
+func SubjectOf(user *models.User) Subject {
+	if user == nil {
+		return Subject{Role: models.Guest}
+	}
+	return Subject{ID: user.ID, Role: user.Role}
+}
+
+// AllowsRole reports whether the role can take one of the actions on some objects of the resource, it is checked
+// before the object is known
+func (p *Policy) AllowsRole(role models.UserRole, resource Resource, actions ...Action) bool {
+	for _, rule := range p.Rules {
+		if rule.Resource != resource || !hasRole(rule.Roles, role) {
+			continue
+		}
+		for _, action := range actions {
+			if rule.Action == action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Allows reports whether the subject can take the action on the object
+func (p *Policy) Allows(subject Subject, action Action, object Object) bool {
+	for _, rule := range p.Rules {
+		if rule.Resource == object.Resource && rule.Action == action && hasRole(rule.Roles, subject.Role) && related(rule.Relation, subject, object) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize returns nil when the subject can take the action on the object. Otherwise it returns the not found error
+// of the resource when the subject cannot read the object either, so its existence is not disclosed, and
+// apperrors.ErrForbidden when they can.
+func (p *Policy) Authorize(subject Subject, action Action, object Object) error {
+	if p.Allows(subject, action, object) {
+		return nil
+	}
+	if action == ActionRead || !p.Allows(subject, ActionRead, object) {
+		return NotFoundError(object.Resource)
+	}
+	return apperrors.ErrForbidden
+}
+
+// NotFoundError is returned for the objects of the resource that do not exist or are hidden from the subject
+func NotFoundError(resource Resource) error {
+	switch resource {
+	case ResourcePackage:
+		return apperrors.ErrPackageNotFound
+	case ResourceSubpackage:
+		return apperrors.ErrSubpackageNotFound
+	case ResourceAppointment:
+		return apperrors.ErrAppointmentNotFound
+	case ResourcePayment:
+		return apperrors.ErrPaymentNotFound
+	case ResourceRating:
+		return apperrors.ErrRatingNotFound
+	}
+	return apperrors.ErrForbidden
+}
+
+func hasRole(roles []models.UserRole, role models.UserRole) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func related(relation Relation, subject Subject, object Object) bool {
+	switch relation {
+	case RelationAny:
+		return true
+	case RelationCustomer:
+		return !subject.ID.IsZero() && subject.ID == object.CustomerID
+	case RelationPhotographer:
+		return !subject.ID.IsZero() && subject.ID == object.PhotographerID
+	case RelationParticipant:
+		return !subject.ID.IsZero() && (subject.ID == object.CustomerID || subject.ID == object.PhotographerID)
+	}
+	return false
+}
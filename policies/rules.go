@@ -0,0 +1,83 @@
+package policies
+
+import (
+	"github.com/Bualoi-s-Dev/backend/models"
+)
+
+var (
+	everyone     = []models.UserRole{models.Photographer, models.Customer}
+	photographer = []models.UserRole{models.Photographer}
+	customer     = []models.UserRole{models.Customer}
+	admin        = []models.UserRole{models.Admin}
+)
+
+// DefaultRules are the permissions of the API, the admin back office has its own routes
+var DefaultRules = []Rule{
+	{Resource: ResourcePackage, Action: ActionRead, Roles: everyone, Relation: RelationAny},
+	{Resource: ResourcePackage, Action: ActionCreate, Roles: photographer, Relation: RelationAny},
+	{Resource: ResourcePackage, Action: ActionUpdate, Roles: photographer, Relation: RelationPhotographer},
+	{Resource: ResourcePackage, Action: ActionDelete, Roles: photographer, Relation: RelationPhotographer},
+
+	{Resource: ResourceSubpackage, Action: ActionRead, Roles: everyone, Relation: RelationAny},
+	{Resource: ResourceSubpackage, Action: ActionCreate, Roles: photographer, Relation: RelationPhotographer},
+	{Resource: ResourceSubpackage, Action: ActionUpdate, Roles: photographer, Relation: RelationPhotographer},
+	{Resource: ResourceSubpackage, Action: ActionDelete, Roles: photographer, Relation: RelationPhotographer},
+
+	{Resource: ResourceAppointment, Action: ActionRead, Roles: everyone, Relation: RelationParticipant},
+	{Resource: ResourceAppointment, Action: ActionCreate, Roles: customer, Relation: RelationAny},
+	{Resource: ResourceAppointment, Action: ActionAccept, Roles: photographer, Relation: RelationPhotographer},
+	{Resource: ResourceAppointment, Action: ActionReject, Roles: everyone, Relation: RelationParticipant},
+	{Resource: ResourceAppointment, Action: ActionCancel, Roles: everyone, Relation: RelationParticipant},
+	{Resource: ResourceAppointment, Action: ActionTip, Roles: customer, Relation: RelationCustomer},
+	{Resource: ResourceAppointment, Action: ActionDelete, Roles: admin, Relation: RelationAny},
+
+	{Resource: ResourcePayment, Action: ActionRead, Roles: everyone, Relation: RelationParticipant},
+
+	{Resource: ResourceRating, Action: ActionRead, Roles: everyone, Relation: RelationAny},
+	{Resource: ResourceRating, Action: ActionCreate, Roles: customer, Relation: RelationAny},
+	{Resource: ResourceRating, Action: ActionUpdate, Roles: customer, Relation: RelationCustomer},
+	{Resource: ResourceRating, Action: ActionDelete, Roles: customer, Relation: RelationCustomer},
+}
+
+var Default = NewPolicy(DefaultRules...)
+
+// Authorize checks the action of the user on the object against the default policy
+func Authorize(user *models.User, action Action, object Object) error {
+	return Default.Authorize(SubjectOf(user), action, object)
+}
+
+// AppointmentStatusAction is the action of changing the status of an appointment to the status
+func AppointmentStatusAction(status models.AppointmentStatus) Action {
+	switch status {
+	case models.AppointmentAccepted:
+		return ActionAccept
+	case models.AppointmentRejected:
+		return ActionReject
+	case models.AppointmentCanceled:
+		return ActionCancel
+	}
+	return ActionUpdate
+}
+
+// Package is owned by its photographer
+func Package(pkg *models.Package) Object {
+	return Object{Resource: ResourcePackage, PhotographerID: pkg.OwnerID}
+}
+
+// Subpackage belongs to the owner of its package
+func Subpackage(pkg *models.Package) Object {
+	return Object{Resource: ResourceSubpackage, PhotographerID: pkg.OwnerID}
+}
+
+func Appointment(appointment *models.Appointment) Object {
+	return Object{Resource: ResourceAppointment, CustomerID: appointment.CustomerID, PhotographerID: appointment.PhotographerID}
+}
+
+// Payment belongs to the parties of its appointment
+func Payment(appointment *models.Appointment) Object {
+	return Object{Resource: ResourcePayment, CustomerID: appointment.CustomerID, PhotographerID: appointment.PhotographerID}
+}
+
+func Rating(rating *models.Rating) Object {
+	return Object{Resource: ResourceRating, CustomerID: rating.CustomerID, PhotographerID: rating.PhotographerID}
+}
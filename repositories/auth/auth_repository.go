@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token has expired")
+	ErrUserNotFound = errors.New("auth user not found")
+)
+
+// TokenVerifier is the identity provider that signs the ID tokens of the users, the accounts are looked up by email
+type TokenVerifier interface {
+	// VerifyIDToken checks the signature and the expiry of the token and returns its claims
+	VerifyIDToken(ctx context.Context, token string) (map[string]interface{}, error)
+	// SetCustomUserClaims replaces the custom claims of the account, they are in the tokens issued afterwards
+	SetCustomUserClaims(ctx context.Context, email string, claims map[string]interface{}) error
+	// GetProviders returns the sign-in providers linked to the email, it is empty when there is no account
+	GetProviders(ctx context.Context, email string) ([]string, error)
+	// RevokeRefreshTokens signs the account out of every device, the ID tokens already issued stay valid until they
+	// expire so their auth_time has to be checked against the time of the revocation
+	RevokeRefreshTokens(ctx context.Context, email string) error
+	// DeleteUser removes the account of the email, it is not an error when there is no account
+	DeleteUser(ctx context.Context, email string) error
+}
+
+// ClaimTime reads a time claim such as exp or auth_time, in seconds since the epoch
+func ClaimTime(claims map[string]interface{}, key string) (time.Time, bool) {
+	switch value := claims[key].(type) {
+	case float64:
+		return time.Unix(int64(value), 0), true
+	case int64:
+		return time.Unix(value, 0), true
+	case json.Number:
+		seconds, err := value.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(seconds, 0), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"firebase.google.com/go/auth"
+)
+
+// FirebaseVerifier verifies the ID tokens issued by Firebase Authentication
+type FirebaseVerifier struct {
+	Client *auth.Client
+}
+
+func NewFirebaseVerifier(client *auth.Client) *FirebaseVerifier {
+	return &FirebaseVerifier{Client: client}
+}
+
+func (v *FirebaseVerifier) VerifyIDToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	idToken, err := v.Client.VerifyIDToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]interface{}{}
+	for key, value := range idToken.Claims {
+		claims[key] = value
+	}
+	claims["sub"] = idToken.Subject
+	claims["iat"] = idToken.IssuedAt
+	claims["exp"] = idToken.Expires
+	claims["auth_time"] = idToken.AuthTime
+	return claims, nil
+}
+
+func (v *FirebaseVerifier) SetCustomUserClaims(ctx context.Context, email string, claims map[string]interface{}) error {
+	user, err := v.Client.GetUserByEmail(ctx, email)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to fetch Firebase user: %v", err)
+	}
+	return v.Client.SetCustomUserClaims(ctx, user.UID, claims)
+}
+
+func (v *FirebaseVerifier) RevokeRefreshTokens(ctx context.Context, email string) error {
+	user, err := v.Client.GetUserByEmail(ctx, email)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to fetch Firebase user: %v", err)
+	}
+	return v.Client.RevokeRefreshTokens(ctx, user.UID)
+}
+
+func (v *FirebaseVerifier) DeleteUser(ctx context.Context, email string) error {
+	user, err := v.Client.GetUserByEmail(ctx, email)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to fetch Firebase user: %v", err)
+	}
+	return v.Client.DeleteUser(ctx, user.UID)
+}
+
+func (v *FirebaseVerifier) GetProviders(ctx context.Context, email string) ([]string, error) {
+	user, err := v.Client.GetUserByEmail(ctx, email)
+	if err != nil {
+		if auth.IsUserNotFound(err) || strings.Contains(err.Error(), "no user") {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	providers := []string{}
+	for _, info := range user.ProviderUserInfo {
+		providers = append(providers, info.ProviderID)
+	}
+	return providers, nil
+}
@@ -0,0 +1,309 @@
+package repositories
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	LocalTokenIssuer = "local"
+	// LocalProviderID is the provider of the accounts of the local verifier
+	LocalProviderID = "local"
+	// DefaultLocalTokenDuration is how long the minted tokens are valid, as long as the Firebase ID tokens
+	DefaultLocalTokenDuration = time.Hour
+	// localTokenLeeway is the clock skew accepted on the issued time
+	localTokenLeeway = time.Minute
+)
+
+var (
+	ErrEmailExists        = errors.New("email already exists")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+// Reserved claims are set by the verifier and never taken from the custom claims
+var localReservedClaims = map[string]bool{"iss": true, "sub": true, "iat": true, "exp": true, "auth_time": true, "email": true}
+
+// LocalVerifier signs and verifies JWTs with an HMAC secret (HS256) or an RSA key (RS256), it replaces Firebase for
+// tests and local development. Its accounts, their bcrypt password hashes and custom claims are kept in memory.
+type LocalVerifier struct {
+	Issuer     string
+	Secret     []byte
+	PrivateKey *rsa.PrivateKey
+
+	mu       sync.Mutex
+	accounts map[string]*localAccount
+}
+
+type localAccount struct {
+	UID          string
+	PasswordHash []byte
+	Claims       map[string]interface{}
+}
+
+func NewHMACVerifier(secret []byte) *LocalVerifier {
+	return &LocalVerifier{Issuer: LocalTokenIssuer, Secret: secret, accounts: map[string]*localAccount{}}
+}
+
+func NewRSAVerifier(privateKey *rsa.PrivateKey) *LocalVerifier {
+	return &LocalVerifier{Issuer: LocalTokenIssuer, PrivateKey: privateKey, accounts: map[string]*localAccount{}}
+}
+
+// ParseRSAPrivateKey reads a PEM encoded PKCS #1 or PKCS #8 RSA private key
+func ParseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA private key")
+	}
+	return rsaKey, nil
+}
+
+func (v *LocalVerifier) algorithm() string {
+	if v.PrivateKey != nil {
+		return "RS256"
+	}
+	return "HS256"
+}
+
+// Register creates the account of the email with its password and returns its uid, like Firebase an email that
+// already has a password cannot be registered again
+func (v *LocalVerifier) Register(email string, password string) (string, error) {
+	if email == "" || password == "" {
+		return "", errors.New("email and password are required")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	account := v.account(email)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if account.PasswordHash != nil {
+		return "", ErrEmailExists
+	}
+	account.PasswordHash = hash
+	return account.UID, nil
+}
+
+// SignIn mints a token of the email when the password matches the one it was registered with
+func (v *LocalVerifier) SignIn(email string, password string) (string, error) {
+	v.mu.Lock()
+	account, ok := v.accounts[email]
+	var hash []byte
+	if ok {
+		hash = account.PasswordHash
+	}
+	v.mu.Unlock()
+	if hash == nil || bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return "", ErrInvalidCredentials
+	}
+	return v.MintToken(email, 0)
+}
+
+// UID returns the uid of the account of the email, the account is created without a password when it does not exist
+func (v *LocalVerifier) UID(email string) string {
+	return v.account(email).UID
+}
+
+func (v *LocalVerifier) account(email string) *localAccount {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.accounts == nil {
+		v.accounts = map[string]*localAccount{}
+	}
+	account, ok := v.accounts[email]
+	if !ok {
+		account = &localAccount{UID: primitive.NewObjectID().Hex(), Claims: map[string]interface{}{}}
+		v.accounts[email] = account
+	}
+	return account
+}
+
+// MintToken signs an ID token of the email with the custom claims of its account, valid for ttl
+// (DefaultLocalTokenDuration when it is not positive). It does not check a password, SignIn does.
+func (v *LocalVerifier) MintToken(email string, ttl time.Duration) (string, error) {
+	if email == "" {
+		return "", errors.New("email is required")
+	}
+	if ttl <= 0 {
+		ttl = DefaultLocalTokenDuration
+	}
+	account := v.account(email)
+	now := time.Now()
+
+	claims := map[string]interface{}{}
+	v.mu.Lock()
+	for key, value := range account.Claims {
+		claims[key] = value
+	}
+	v.mu.Unlock()
+	claims["iss"] = v.Issuer
+	claims["sub"] = account.UID
+	claims["email"] = email
+	claims["iat"] = now.Unix()
+	claims["auth_time"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	return v.Sign(claims)
+}
+
+// Sign encodes the claims as they are into a signed JWT
+func (v *LocalVerifier) Sign(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": v.algorithm(), "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := v.signature(signingInput)
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (v *LocalVerifier) signature(signingInput string) ([]byte, error) {
+	if v.PrivateKey != nil {
+		digest := sha256.Sum256([]byte(signingInput))
+		return rsa.SignPKCS1v15(rand.Reader, v.PrivateKey, crypto.SHA256, digest[:])
+	}
+	if len(v.Secret) == 0 {
+		return nil, errors.New("local verifier has no key")
+	}
+	mac := hmac.New(sha256.New, v.Secret)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil), nil
+}
+
+func (v *LocalVerifier) VerifyIDToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	// The algorithm is fixed by the key, a token cannot choose it
+	if header.Alg != v.algorithm() {
+		return nil, fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !v.validSignature(parts[0]+"."+parts[1], signature) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeTokenPart(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims["iss"] != v.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+	now := time.Now()
+	expires, ok := ClaimTime(claims, "exp")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing expiry", ErrInvalidToken)
+	}
+	if !now.Before(expires) {
+		return nil, ErrTokenExpired
+	}
+	if issued, ok := ClaimTime(claims, "iat"); !ok || issued.After(now.Add(localTokenLeeway)) {
+		return nil, fmt.Errorf("%w: invalid issued time", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+func (v *LocalVerifier) validSignature(signingInput string, signature []byte) bool {
+	if v.PrivateKey != nil {
+		digest := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(&v.PrivateKey.PublicKey, crypto.SHA256, digest[:], signature) == nil
+	}
+	expected, err := v.signature(signingInput)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, signature)
+}
+
+func decodeTokenPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SetCustomUserClaims replaces the custom claims of the account, the account is created when the email got its token
+// from another process sharing the key
+func (v *LocalVerifier) SetCustomUserClaims(ctx context.Context, email string, claims map[string]interface{}) error {
+	account := v.account(email)
+	custom := map[string]interface{}{}
+	for key, value := range claims {
+		if !localReservedClaims[key] {
+			custom[key] = value
+		}
+	}
+	v.mu.Lock()
+	account.Claims = custom
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *LocalVerifier) GetProviders(ctx context.Context, email string) ([]string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.accounts[email]; !ok {
+		return []string{}, nil
+	}
+	return []string{LocalProviderID}, nil
+}
+
+// RevokeRefreshTokens does nothing, the local tokens cannot be refreshed and a new one is minted for every sign in
+func (v *LocalVerifier) RevokeRefreshTokens(ctx context.Context, email string) error {
+	return nil
+}
+
+func (v *LocalVerifier) DeleteUser(ctx context.Context, email string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.accounts, email)
+	return nil
+}
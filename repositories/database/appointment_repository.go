@@ -10,6 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type AppointmentRepository struct {
@@ -232,3 +233,70 @@ func (repo *AppointmentRepository) DeleteAppointment(ctx context.Context, id pri
 	_, err := repo.AppointmentCollection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
+
+// GetByUserId returns the appointments the user is the customer or the photographer of, whatever their current role
+func (repo *AppointmentRepository) GetByUserId(ctx context.Context, userId primitive.ObjectID) ([]models.Appointment, error) {
+	var items []models.Appointment
+	filter := bson.M{"$or": bson.A{bson.M{"customer_id": userId}, bson.M{"photographer_id": userId}}}
+	cursor, err := repo.AppointmentCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Appointment{}
+	}
+	return items, nil
+}
+
+// AnonymizeUser unlinks the appointments from the deleted user, their side is set to the nil ID and the location
+// given by the customer is removed. The appointments are kept for the other party and the accounting.
+func (repo *AppointmentRepository) AnonymizeUser(ctx context.Context, userId primitive.ObjectID) error {
+	if _, err := repo.AppointmentCollection.UpdateMany(ctx, bson.M{"customer_id": userId},
+		bson.M{"$set": bson.M{"customer_id": primitive.NilObjectID, "location": ""}}); err != nil {
+		return err
+	}
+	_, err := repo.AppointmentCollection.UpdateMany(ctx, bson.M{"photographer_id": userId},
+		bson.M{"$set": bson.M{"photographer_id": primitive.NilObjectID}})
+	return err
+}
+
+// Search returns a page of every appointment, the newest first, filtered by status and by the customer or the
+// photographer when they are set, with the number of matching appointments
+func (repo *AppointmentRepository) Search(ctx context.Context, status models.AppointmentStatus, userId *primitive.ObjectID, page, limit int) ([]models.Appointment, int64, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if userId != nil {
+		filter["$or"] = bson.A{bson.M{"customer_id": *userId}, bson.M{"photographer_id": *userId}}
+	}
+	total, err := repo.AppointmentCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []models.Appointment
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+	cursor, err := repo.AppointmentCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, 0, err
+	}
+	if items == nil {
+		items = []models.Appointment{}
+	}
+	return items, total, nil
+}
+
+func (repo *AppointmentRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	return countByField(ctx, repo.AppointmentCollection, "status")
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditLogRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewAuditLogRepository(collection *mongo.Collection) *AuditLogRepository {
+	return &AuditLogRepository{Collection: collection}
+}
+
+func (repo *AuditLogRepository) Create(ctx context.Context, item *models.AuditLog) error {
+	_, err := repo.Collection.InsertOne(ctx, item)
+	return err
+}
+
+// GetByEntity returns the history of the entity, the latest change first
+func (repo *AuditLogRepository) GetByEntity(ctx context.Context, entityType models.AuditEntityType, entityId primitive.ObjectID, page, limit int) ([]models.AuditLog, int64, error) {
+	filter := bson.M{"entity_type": entityType, "entity_id": entityId}
+	total, err := repo.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []models.AuditLog
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_time", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := repo.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, 0, err
+	}
+	if items == nil {
+		items = []models.AuditLog{}
+	}
+	return items, total, nil
+}
+
+// DeleteBefore removes the entries older than the time and returns how many were removed
+func (repo *AuditLogRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := repo.Collection.DeleteMany(ctx, bson.M{"created_time": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// RedactFields replaces the values of the fields in the changes of the entity type with the redacted value, a side
+// without a value is left without one. It returns how many entries had a value to replace, only counting them on a dry run.
+func (repo *AuditLogRepository) RedactFields(ctx context.Context, entityType models.AuditEntityType, fields []string, redacted string, dryRun bool) (int64, error) {
+	return repo.redactFields(ctx, bson.M{"entity_type": entityType}, fields, redacted, dryRun)
+}
+
+// RedactEntityFields is RedactFields for the entries of the entities only
+func (repo *AuditLogRepository) RedactEntityFields(ctx context.Context, entityType models.AuditEntityType, entityIds []primitive.ObjectID, fields []string, redacted string) (int64, error) {
+	if len(entityIds) == 0 {
+		return 0, nil
+	}
+	return repo.redactFields(ctx, bson.M{"entity_type": entityType, "entity_id": bson.M{"$in": entityIds}}, fields, redacted, false)
+}
+
+func (repo *AuditLogRepository) redactFields(ctx context.Context, filter bson.M, fields []string, redacted string, dryRun bool) (int64, error) {
+	filter = bson.M{
+		"$and": bson.A{filter},
+		"changes": bson.M{"$elemMatch": bson.M{
+			"field": bson.M{"$in": fields},
+			"$or": bson.A{
+				bson.M{"before": bson.M{"$exists": true, "$ne": redacted}},
+				bson.M{"after": bson.M{"$exists": true, "$ne": redacted}},
+			},
+		}},
+	}
+	if dryRun {
+		return repo.Collection.CountDocuments(ctx, filter)
+	}
+	update := bson.M{"$set": bson.M{
+		"changes.$[before].before": redacted,
+		"changes.$[after].after":   redacted,
+	}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{
+		bson.M{"before.field": bson.M{"$in": fields}, "before.before": bson.M{"$exists": true}},
+		bson.M{"after.field": bson.M{"$in": fields}, "after.after": bson.M{"$exists": true}},
+	}})
+	res, err := repo.Collection.UpdateMany(ctx, filter, update, opts)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// DeleteByEntities removes the history of the entities and returns how many entries were removed
+func (repo *AuditLogRepository) DeleteByEntities(ctx context.Context, entityType models.AuditEntityType, entityIds []primitive.ObjectID) (int64, error) {
+	if len(entityIds) == 0 {
+		return 0, nil
+	}
+	res, err := repo.Collection.DeleteMany(ctx, bson.M{"entity_type": entityType, "entity_id": bson.M{"$in": entityIds}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// RemoveActorIP removes the client IP of the requests the actor made, the changes stay recorded as made by their id
+func (repo *AuditLogRepository) RemoveActorIP(ctx context.Context, actorId primitive.ObjectID) error {
+	_, err := repo.Collection.UpdateMany(ctx, bson.M{"actor_id": actorId, "ip": bson.M{"$exists": true}}, bson.M{"$unset": bson.M{"ip": ""}})
+	return err
+}
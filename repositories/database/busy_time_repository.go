@@ -86,6 +86,13 @@ func (r *BusyTimeRepository) GetByPhotographerId(ctx context.Context, photograph
 	return items, nil
 }
 
+// AnonymizePhotographer unlinks the busy times of the appointments from the deleted photographer
+func (r *BusyTimeRepository) AnonymizePhotographer(ctx context.Context, photographerId primitive.ObjectID) error {
+	_, err := r.Collection.UpdateMany(ctx, bson.M{"photographer_id": photographerId, "type": models.TypeAppointment},
+		bson.M{"$set": bson.M{"photographer_id": primitive.NilObjectID}})
+	return err
+}
+
 func (r *BusyTimeRepository) GetByPhotographerIdValid(ctx context.Context, photographerId primitive.ObjectID) ([]models.BusyTime, error) {
 	var items []models.BusyTime
 	cursor, err := r.Collection.Find(ctx, bson.M{
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EncryptedFieldRepository reads and writes the encrypted fields of a collection as they are stored, without decrypting
+// them, to re-encrypt them with another key
+type EncryptedFieldRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewEncryptedFieldRepository(collection *mongo.Collection) *EncryptedFieldRepository {
+	return &EncryptedFieldRepository{Collection: collection}
+}
+
+// Each calls fn with every document that has one of the fields, with only the fields and the id
+func (repo *EncryptedFieldRepository) Each(ctx context.Context, fields []string, fn func(doc bson.M) error) error {
+	or := bson.A{}
+	projection := bson.M{"_id": 1}
+	for _, field := range fields {
+		or = append(or, bson.M{field: bson.M{"$type": "string"}})
+		projection[field] = 1
+	}
+	cursor, err := repo.Collection.Find(ctx, bson.M{"$or": or}, options.Find().SetProjection(projection))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// SetFields writes the fields when the document still has the expected values, it returns false when it was changed
+// meanwhile
+func (repo *EncryptedFieldRepository) SetFields(ctx context.Context, id primitive.ObjectID, expected bson.M, updates bson.M) (bool, error) {
+	filter := bson.M{"_id": id}
+	for field, value := range expected {
+		filter[field] = value
+	}
+	res, err := repo.Collection.UpdateOne(ctx, filter, bson.M{"$set": updates})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type GalleryRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewGalleryRepository(collection *mongo.Collection) *GalleryRepository {
+	return &GalleryRepository{Collection: collection}
+}
+
+func (repo *GalleryRepository) GetByAppointmentId(ctx context.Context, appointmentId primitive.ObjectID) (*models.Gallery, error) {
+	var item models.Gallery
+	err := repo.Collection.FindOne(ctx, bson.M{"appointment_id": appointmentId}).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *GalleryRepository) Create(ctx context.Context, item *models.Gallery) error {
+	if item.Photos == nil {
+		item.Photos = []models.GalleryPhoto{}
+	}
+	_, err := repo.Collection.InsertOne(ctx, item)
+	return err
+}
+
+func (repo *GalleryRepository) Replace(ctx context.Context, item *models.Gallery) error {
+	_, err := repo.Collection.ReplaceOne(ctx, bson.M{"_id": item.ID}, item)
+	return err
+}
+
+// SetDownloadArchive saves the pre-built archive without overwriting the rest of the gallery
+func (repo *GalleryRepository) SetDownloadArchive(ctx context.Context, id primitive.ObjectID, archive *models.GalleryDownloadArchive) error {
+	_, err := repo.Collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"download_archive": archive}})
+	return err
+}
+
+func (repo *GalleryRepository) GetByPhotographerId(ctx context.Context, photographerId primitive.ObjectID) ([]models.Gallery, error) {
+	var items []models.Gallery
+	cursor, err := repo.Collection.Find(ctx, bson.M{"photographer_id": photographerId})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Gallery{}
+	}
+	return items, nil
+}
+
+func (repo *GalleryRepository) GetByCustomerId(ctx context.Context, customerId primitive.ObjectID) ([]models.Gallery, error) {
+	var items []models.Gallery
+	cursor, err := repo.Collection.Find(ctx, bson.M{"customer_id": customerId})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Gallery{}
+	}
+	return items, nil
+}
+
+func (repo *GalleryRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// AnonymizePhotographer unlinks the deleted photographer from their galleries, the customers keep them
+func (repo *GalleryRepository) AnonymizePhotographer(ctx context.Context, photographerId primitive.ObjectID) error {
+	_, err := repo.Collection.UpdateMany(ctx, bson.M{"photographer_id": photographerId}, bson.M{"$set": bson.M{"photographer_id": primitive.NilObjectID}})
+	return err
+}
+
+// GetExpired returns the galleries that are not archived yet and have passed their expire time
+func (repo *GalleryRepository) GetExpired(ctx context.Context, now time.Time) ([]models.Gallery, error) {
+	var items []models.Gallery
+	filter := bson.M{"status": bson.M{"$ne": models.GalleryArchived}, "expire_time": bson.M{"$lt": now}}
+	cursor, err := repo.Collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Gallery{}
+	}
+	return items, nil
+}
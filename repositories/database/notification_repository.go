@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewNotificationRepository(collection *mongo.Collection) *NotificationRepository {
+	return &NotificationRepository{Collection: collection}
+}
+
+func (repo *NotificationRepository) Create(ctx context.Context, item *models.Notification) error {
+	_, err := repo.Collection.InsertOne(ctx, item)
+	return err
+}
+
+// GetByUserId returns the latest notifications of the user first
+func (repo *NotificationRepository) GetByUserId(ctx context.Context, userId primitive.ObjectID, limit int64) ([]models.Notification, error) {
+	var items []models.Notification
+	opts := options.Find().SetSort(bson.D{{Key: "created_time", Value: -1}}).SetLimit(limit)
+	cursor, err := repo.Collection.Find(ctx, bson.M{"user_id": userId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Notification{}
+	}
+	return items, nil
+}
+
+func (repo *NotificationRepository) DeleteByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	_, err := repo.Collection.DeleteMany(ctx, bson.M{"user_id": userId})
+	return err
+}
+
+// MarkRead marks the notification of the user as read, it returns false when the user has no such notification
+func (repo *NotificationRepository) MarkRead(ctx context.Context, id primitive.ObjectID, userId primitive.ObjectID) (bool, error) {
+	res, err := repo.Collection.UpdateOne(ctx, bson.M{"_id": id, "user_id": userId}, bson.M{"$set": bson.M{"is_read": true}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
@@ -100,6 +100,9 @@ func (repo *PackageRepository) CreateOne(ctx context.Context, item *models.Packa
 	if item.PhotoUrls == nil {
 		item.PhotoUrls = []string{}
 	}
+	if item.Images == nil {
+		item.Images = []models.Image{}
+	}
 	return repo.Collection.InsertOne(ctx, item)
 }
 func (repo *PackageRepository) ReplaceOne(ctx context.Context, id string, updates *models.Package) (*mongo.UpdateResult, error) {
@@ -130,3 +133,7 @@ func (repo *PackageRepository) DeleteOne(ctx context.Context, id string) (*mongo
 
 	return repo.Collection.DeleteOne(ctx, bson.M{"_id": objectId})
 }
+
+func (repo *PackageRepository) Count(ctx context.Context) (int64, error) {
+	return repo.Collection.CountDocuments(ctx, bson.M{})
+}
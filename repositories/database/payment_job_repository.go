@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PaymentJobRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewPaymentJobRepository(collection *mongo.Collection) *PaymentJobRepository {
+	return &PaymentJobRepository{Collection: collection}
+}
+
+// GetAll returns the jobs sorted by the latest update, filtered by status when it is not empty
+func (repo *PaymentJobRepository) GetAll(ctx context.Context, status models.PaymentJobStatus) ([]models.PaymentJob, error) {
+	var items []models.PaymentJob
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.M{"updated_time": -1})
+	cursor, err := repo.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.PaymentJob{}
+	}
+	return items, nil
+}
+
+func (repo *PaymentJobRepository) GetByAppointmentId(ctx context.Context, appointmentId primitive.ObjectID) (*models.PaymentJob, error) {
+	var item models.PaymentJob
+	err := repo.Collection.FindOne(ctx, bson.M{"appointment_id": appointmentId}).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *PaymentJobRepository) Create(ctx context.Context, item *models.PaymentJob) error {
+	_, err := repo.Collection.InsertOne(ctx, item)
+	return err
+}
+
+func (repo *PaymentJobRepository) Replace(ctx context.Context, item *models.PaymentJob) error {
+	_, err := repo.Collection.ReplaceOne(ctx, bson.M{"_id": item.ID}, item)
+	return err
+}
+
+// ClaimDue takes one pending job whose run time has passed and pushes its run time forward by the lease,
+// so a concurrent runner does not pick the same job. It returns nil when there is nothing to run.
+func (repo *PaymentJobRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*models.PaymentJob, error) {
+	filter := bson.M{"status": models.PaymentJobPending, "next_run_time": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_run_time": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"next_run_time": 1})
+
+	var item models.PaymentJob
+	err := repo.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
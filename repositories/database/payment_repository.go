@@ -4,13 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Bualoi-s-Dev/backend/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// appointmentPaymentType matches the payment of the appointment itself, payments created before the type existed have none
+var appointmentPaymentType = bson.M{"$in": bson.A{models.PaymentAppointment, nil}}
+
 type PaymentRepository struct {
 	Collection            *mongo.Collection
 	AppointmentCollection *mongo.Collection
@@ -51,15 +56,33 @@ func (repo *PaymentRepository) GetById(ctx context.Context, id primitive.ObjectI
 	return &item, nil
 }
 
+// GetByAppointmentID returns the payment of the appointment itself, tips are excluded
 func (repo *PaymentRepository) GetByAppointmentID(ctx context.Context, appointmentID primitive.ObjectID) (*models.Payment, error) {
 	var item models.Payment
-	err := repo.Collection.FindOne(ctx, bson.M{"appointment_id": appointmentID}).Decode(&item)
+	err := repo.Collection.FindOne(ctx, bson.M{"appointment_id": appointmentID, "type": appointmentPaymentType}).Decode(&item)
 	if err != nil {
 		return nil, err
 	}
 	return &item, nil
 }
 
+func (repo *PaymentRepository) GetTipsByAppointmentID(ctx context.Context, appointmentID primitive.ObjectID) ([]models.Payment, error) {
+	var items []models.Payment
+	cursor, err := repo.Collection.Find(ctx, bson.M{"appointment_id": appointmentID, "type": models.PaymentTip})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Payment{}
+	}
+	return items, nil
+}
+
 func (repo *PaymentRepository) GetByUserIDAndRole(ctx context.Context, role models.UserRole, userId primitive.ObjectID) ([]models.Payment, error) {
 	var items []models.Payment
 	var fieldToFind string
@@ -107,6 +130,110 @@ func (repo *PaymentRepository) GetByUserIDAndRole(ctx context.Context, role mode
 	return items, nil
 }
 
+// EachWithAppointmentByUserIDAndRole calls fn with the payments created in [from, to) joined with their appointment, oldest
+// first and one at a time, scoped to the user like GetByUserIDAndRole, admin gets the payments of the whole platform
+func (repo *PaymentRepository) EachWithAppointmentByUserIDAndRole(ctx context.Context, role models.UserRole, userId primitive.ObjectID, from, to time.Time, fn func(item *models.PaymentWithAppointment) error) error {
+	// The payment creation time is taken from its object id, sorting before the lookup walks the _id index instead of
+	// sorting every payment of the range in memory
+	pipeline := mongo.Pipeline{
+		bson.D{
+			{Key: "$match", Value: bson.D{
+				{Key: "_id", Value: bson.D{
+					{Key: "$gte", Value: primitive.NewObjectIDFromTimestamp(from)},
+					{Key: "$lt", Value: primitive.NewObjectIDFromTimestamp(to)},
+				}},
+			}},
+		},
+		bson.D{
+			{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}},
+		},
+		bson.D{
+			{Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: repo.AppointmentCollection.Name()},
+				{Key: "localField", Value: "appointment_id"},
+				{Key: "foreignField", Value: "_id"},
+				{Key: "as", Value: "appointment"},
+			}},
+		},
+		bson.D{
+			{Key: "$unwind", Value: "$appointment"},
+		},
+	}
+	switch role {
+	case models.Photographer:
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.D{{Key: "appointment.photographer_id", Value: userId}}}})
+	case models.Customer:
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.D{{Key: "appointment.customer_id", Value: userId}}}})
+	case models.Admin:
+	default:
+		return errors.New("guest cannot have payments")
+	}
+
+	cursor, err := repo.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var item models.PaymentWithAppointment
+		if err := cursor.Decode(&item); err != nil {
+			return err
+		}
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// GetCompletedAppointmentsWithoutPaidPayment returns completed appointments whose payment is missing or not paid yet
+func (repo *PaymentRepository) GetCompletedAppointmentsWithoutPaidPayment(ctx context.Context) ([]models.Appointment, error) {
+	var items []models.Appointment
+	pipeline := mongo.Pipeline{
+		bson.D{
+			{Key: "$match", Value: bson.D{
+				{Key: "status", Value: models.AppointmentCompleted},
+			}},
+		},
+		bson.D{
+			{Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: repo.Collection.Name()},
+				{Key: "let", Value: bson.D{{Key: "appointmentId", Value: "$_id"}}},
+				{Key: "pipeline", Value: mongo.Pipeline{
+					bson.D{{Key: "$match", Value: bson.D{
+						{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$appointment_id", "$$appointmentId"}}}},
+						{Key: "type", Value: appointmentPaymentType},
+					}}},
+				}},
+				{Key: "as", Value: "payment"},
+			}},
+		},
+		bson.D{
+			{Key: "$match", Value: bson.D{
+				{Key: "$or", Value: bson.A{
+					bson.D{{Key: "payment", Value: bson.D{{Key: "$size", Value: 0}}}},
+					bson.D{{Key: "payment.customer.status", Value: models.Unpaid}},
+				}},
+			}},
+		},
+	}
+
+	cursor, err := repo.AppointmentCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Appointment{}
+	}
+	return items, nil
+}
+
 func (repo *PaymentRepository) GetByCheckoutID(ctx context.Context, checkoutID string) (*models.Payment, error) {
 	var item models.Payment
 	err := repo.Collection.FindOne(ctx, bson.M{"customer.checkout_id": checkoutID}).Decode(&item)
@@ -153,3 +280,62 @@ func (repo *PaymentRepository) UpdatePhotographerPayment(ctx context.Context, id
 	_, err := repo.Collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"photographer": photographerPayment}})
 	return err
 }
+
+// Search returns a page of every payment, the newest first, filtered by the status of the customer and the
+// appointment when they are set, with the number of matching payments
+func (repo *PaymentRepository) Search(ctx context.Context, status models.PaymentStatus, appointmentId *primitive.ObjectID, page, limit int) ([]models.Payment, int64, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["customer.status"] = status
+	}
+	if appointmentId != nil {
+		filter["appointment_id"] = *appointmentId
+	}
+	total, err := repo.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []models.Payment
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+	cursor, err := repo.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, 0, err
+	}
+	if items == nil {
+		items = []models.Payment{}
+	}
+	return items, total, nil
+}
+
+// GetPaidTotals sums the payments the customers paid, including the ones already paid out to the photographers
+func (repo *PaymentRepository) GetPaidTotals(ctx context.Context) (*models.PaymentTotals, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"customer.status": bson.M{"$in": bson.A{models.Paid, models.Completed}}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "amount", Value: bson.D{{Key: "$sum", Value: "$amount"}}},
+			{Key: "fee", Value: bson.D{{Key: "$sum", Value: "$fee"}}},
+			{Key: "refunded_amount", Value: bson.D{{Key: "$sum", Value: "$customer.refunded_amount"}}},
+		}}},
+	}
+	cursor, err := repo.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	totals := &models.PaymentTotals{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(totals); err != nil {
+			return nil, err
+		}
+	}
+	return totals, cursor.Err()
+}
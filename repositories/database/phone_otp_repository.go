@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PhoneOTPRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewPhoneOTPRepository(collection *mongo.Collection) *PhoneOTPRepository {
+	return &PhoneOTPRepository{Collection: collection}
+}
+
+func (repo *PhoneOTPRepository) Create(ctx context.Context, item *models.PhoneOTP) error {
+	_, err := repo.Collection.InsertOne(ctx, item)
+	return err
+}
+
+// GetLatestByUserId returns the last code sent to the user, nil when there is none
+func (repo *PhoneOTPRepository) GetLatestByUserId(ctx context.Context, userId primitive.ObjectID) (*models.PhoneOTP, error) {
+	var item models.PhoneOTP
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_time", Value: -1}})
+	err := repo.Collection.FindOne(ctx, bson.M{"user_id": userId}, opts).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *PhoneOTPRepository) CountSince(ctx context.Context, userId primitive.ObjectID, since time.Time) (int64, error) {
+	return repo.Collection.CountDocuments(ctx, bson.M{"user_id": userId, "created_time": bson.M{"$gte": since}})
+}
+
+// IncrementAttempts counts a guess of the code, it returns false when the code already had max guesses
+func (repo *PhoneOTPRepository) IncrementAttempts(ctx context.Context, id primitive.ObjectID, max int) (bool, error) {
+	res, err := repo.Collection.UpdateOne(ctx, bson.M{"_id": id, "attempts": bson.M{"$lt": max}}, bson.M{"$inc": bson.M{"attempts": 1}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (repo *PhoneOTPRepository) DeleteByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	_, err := repo.Collection.DeleteMany(ctx, bson.M{"user_id": userId})
+	return err
+}
+
+func (repo *PhoneOTPRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := repo.Collection.DeleteMany(ctx, bson.M{"created_time": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
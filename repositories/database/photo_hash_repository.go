@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PhotoHashRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewPhotoHashRepository(collection *mongo.Collection) *PhotoHashRepository {
+	return &PhotoHashRepository{Collection: collection}
+}
+
+func (repo *PhotoHashRepository) CreateMany(ctx context.Context, items []models.PhotoHash) error {
+	if len(items) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(items))
+	for i := range items {
+		docs[i] = items[i]
+	}
+	_, err := repo.Collection.InsertMany(ctx, docs)
+	return err
+}
+
+// EnsureIndexes indexes the bands, every upload looks up the hashes sharing one of its bands
+func (repo *PhotoHashRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := repo.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "bands", Value: 1}}})
+	return err
+}
+
+// EachCandidate calls fn with every hash of the other photographers sharing a band, the caller compares them. The
+// candidates are streamed without a limit, a single shared band is enough for a hash to be a match.
+func (repo *PhotoHashRepository) EachCandidate(ctx context.Context, bands []int, excludeOwnerId primitive.ObjectID, fn func(models.PhotoHash) error) error {
+	filter := bson.M{"bands": bson.M{"$in": bands}, "owner_id": bson.M{"$ne": excludeOwnerId}}
+	opts := options.Find().SetProjection(bson.M{"bands": 0})
+	cursor, err := repo.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var item models.PhotoHash
+		if err := cursor.Decode(&item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (repo *PhotoHashRepository) DeleteByImageIds(ctx context.Context, imageIds []primitive.ObjectID) error {
+	if len(imageIds) == 0 {
+		return nil
+	}
+	_, err := repo.Collection.DeleteMany(ctx, bson.M{"image_id": bson.M{"$in": imageIds}})
+	return err
+}
+
+func (repo *PhotoHashRepository) DeleteByPackageId(ctx context.Context, packageId primitive.ObjectID) error {
+	_, err := repo.Collection.DeleteMany(ctx, bson.M{"package_id": packageId})
+	return err
+}
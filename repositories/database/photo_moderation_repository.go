@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PhotoModerationRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewPhotoModerationRepository(collection *mongo.Collection) *PhotoModerationRepository {
+	return &PhotoModerationRepository{Collection: collection}
+}
+
+func (repo *PhotoModerationRepository) CreateMany(ctx context.Context, items []models.PhotoModeration) error {
+	if len(items) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(items))
+	for i := range items {
+		docs[i] = items[i]
+	}
+	_, err := repo.Collection.InsertMany(ctx, docs)
+	return err
+}
+
+func (repo *PhotoModerationRepository) GetById(ctx context.Context, id primitive.ObjectID) (*models.PhotoModeration, error) {
+	var item models.PhotoModeration
+	err := repo.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetMany returns the oldest items first, filtered by status and package when they are set
+func (repo *PhotoModerationRepository) GetMany(ctx context.Context, status models.PhotoModerationStatus, packageId *primitive.ObjectID) ([]models.PhotoModeration, error) {
+	var items []models.PhotoModeration
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if packageId != nil {
+		filter["package_id"] = *packageId
+	}
+	cursor, err := repo.Collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_time", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.PhotoModeration{}
+	}
+	return items, nil
+}
+
+func (repo *PhotoModerationRepository) GetPendingByOwnerId(ctx context.Context, ownerId primitive.ObjectID) ([]models.PhotoModeration, error) {
+	var items []models.PhotoModeration
+	cursor, err := repo.Collection.Find(ctx, bson.M{"owner_id": ownerId, "status": models.PhotoModerationPending})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.PhotoModeration{}
+	}
+	return items, nil
+}
+
+// UpdateStatus moves the item out of the given status, it returns false when the item is not in that status anymore
+func (repo *PhotoModerationRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, from models.PhotoModerationStatus, updates bson.M) (bool, error) {
+	res, err := repo.Collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, bson.M{"$set": updates})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (repo *PhotoModerationRepository) DeleteByIds(ctx context.Context, ids []primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := repo.Collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
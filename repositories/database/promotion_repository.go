@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PromotionRepository struct {
+	Collection           *mongo.Collection
+	RedemptionCollection *mongo.Collection
+}
+
+func NewPromotionRepository(collection *mongo.Collection, redemptionCollection *mongo.Collection) *PromotionRepository {
+	return &PromotionRepository{Collection: collection, RedemptionCollection: redemptionCollection}
+}
+
+// EnsureIndexes makes the codes and the redemption slots of a customer unique, the service checks them first for a
+// friendly error but two requests can still race between the check and the insert
+func (repo *PromotionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := repo.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = repo.RedemptionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "promotion_id", Value: 1}, {Key: "customer_id", Value: 1}, {Key: "slot", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"slot": bson.M{"$gt": 0}}),
+	})
+	return err
+}
+
+func (repo *PromotionRepository) GetAll(ctx context.Context) ([]models.Promotion, error) {
+	return repo.find(ctx, bson.M{})
+}
+
+func (repo *PromotionRepository) GetByOwnerId(ctx context.Context, ownerId primitive.ObjectID) ([]models.Promotion, error) {
+	return repo.find(ctx, bson.M{"owner_id": ownerId})
+}
+
+func (repo *PromotionRepository) GetById(ctx context.Context, id primitive.ObjectID) (*models.Promotion, error) {
+	var item models.Promotion
+	if err := repo.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *PromotionRepository) GetByCode(ctx context.Context, code string) (*models.Promotion, error) {
+	var item models.Promotion
+	if err := repo.Collection.FindOne(ctx, bson.M{"code": code}).Decode(&item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *PromotionRepository) Create(ctx context.Context, item *models.Promotion) error {
+	if item.PackageIDs == nil {
+		item.PackageIDs = []primitive.ObjectID{}
+	}
+	_, err := repo.Collection.InsertOne(ctx, item)
+	if mongo.IsDuplicateKeyError(err) {
+		return apperrors.ErrPromotionCodeDuplicate
+	}
+	return err
+}
+
+func (repo *PromotionRepository) Replace(ctx context.Context, item *models.Promotion) error {
+	_, err := repo.Collection.ReplaceOne(ctx, bson.M{"_id": item.ID}, item)
+	if mongo.IsDuplicateKeyError(err) {
+		return apperrors.ErrPromotionCodeDuplicate
+	}
+	return err
+}
+
+func (repo *PromotionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// IncrementUsage atomically reserves one use of the promotion, it returns false when the usage cap is reached
+func (repo *PromotionRepository) IncrementUsage(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"usage_limit": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$used_count", "$usage_limit"}}},
+		},
+	}
+	res, err := repo.Collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"used_count": 1}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (repo *PromotionRepository) DecrementUsage(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "used_count": bson.M{"$gt": 0}}
+	_, err := repo.Collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"used_count": -1}})
+	return err
+}
+
+// CreateRedemption records the use of the promotion by the customer. With a per customer limit each use takes one of
+// the limit slots of the customer, so two bookings racing cannot both take the last one. It returns false when every
+// slot is taken
+func (repo *PromotionRepository) CreateRedemption(ctx context.Context, item *models.PromotionRedemption, perCustomerLimit int) (bool, error) {
+	if perCustomerLimit == 0 {
+		_, err := repo.RedemptionCollection.InsertOne(ctx, item)
+		return err == nil, err
+	}
+	for slot := 1; slot <= perCustomerLimit; slot++ {
+		item.Slot = slot
+		_, err := repo.RedemptionCollection.InsertOne(ctx, item)
+		if err == nil {
+			return true, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return false, err
+		}
+	}
+	item.Slot = 0
+	return false, nil
+}
+
+func (repo *PromotionRepository) CountRedemptionsByCustomer(ctx context.Context, promotionId, customerId primitive.ObjectID) (int64, error) {
+	return repo.RedemptionCollection.CountDocuments(ctx, bson.M{"promotion_id": promotionId, "customer_id": customerId})
+}
+
+// DeleteRedemptionByAppointmentId removes the redemption of the appointment and returns it, nil if there is none
+func (repo *PromotionRepository) DeleteRedemptionByAppointmentId(ctx context.Context, appointmentId primitive.ObjectID) (*models.PromotionRedemption, error) {
+	var item models.PromotionRedemption
+	err := repo.RedemptionCollection.FindOneAndDelete(ctx, bson.M{"appointment_id": appointmentId}).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *PromotionRepository) find(ctx context.Context, filter bson.M) ([]models.Promotion, error) {
+	var items []models.Promotion
+	opts := options.Find().SetSort(bson.M{"created_time": -1})
+	cursor, err := repo.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	if items == nil {
+		items = []models.Promotion{}
+	}
+	return items, nil
+}
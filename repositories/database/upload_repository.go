@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type UploadRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewUploadRepository(collection *mongo.Collection) *UploadRepository {
+	return &UploadRepository{Collection: collection}
+}
+
+func (repo *UploadRepository) Create(ctx context.Context, item *models.Upload) error {
+	_, err := repo.Collection.InsertOne(ctx, item)
+	return err
+}
+
+func (repo *UploadRepository) GetById(ctx context.Context, id primitive.ObjectID) (*models.Upload, error) {
+	var item models.Upload
+	err := repo.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// MarkConfirmed moves a pending upload to confirmed, it returns false when the upload was not pending anymore
+func (repo *UploadRepository) MarkConfirmed(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	filter := bson.M{"_id": id, "status": models.UploadPending}
+	res, err := repo.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"status": models.UploadConfirmed}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (repo *UploadRepository) GetExpiredPending(ctx context.Context, now time.Time) ([]models.Upload, error) {
+	var items []models.Upload
+	cursor, err := repo.Collection.Find(ctx, bson.M{"status": models.UploadPending, "expire_time": bson.M{"$lt": now}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Upload{}
+	}
+	return items, nil
+}
+
+// GetPendingByOwnerId returns the uploads of the owner that can still be confirmed
+func (repo *UploadRepository) GetPendingByOwnerId(ctx context.Context, ownerId primitive.ObjectID, now time.Time) ([]models.Upload, error) {
+	var items []models.Upload
+	cursor, err := repo.Collection.Find(ctx, bson.M{"owner_id": ownerId, "status": models.UploadPending, "expire_time": bson.M{"$gte": now}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Upload{}
+	}
+	return items, nil
+}
+
+func (repo *UploadRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
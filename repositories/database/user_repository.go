@@ -2,11 +2,15 @@ package repositories
 
 import (
 	"context"
+	"regexp"
+	"time"
 
+	"github.com/Bualoi-s-Dev/backend/encryption"
 	"github.com/Bualoi-s-Dev/backend/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepository struct {
@@ -74,6 +78,20 @@ func (repo *UserRepository) ReplaceUser(ctx context.Context, userId primitive.Ob
 	return res, nil
 }
 
+// IsVerifiedPhoneTaken reports whether another user verified the phone, the verified phones are encrypted
+// deterministically to be looked up
+func (repo *UserRepository) IsVerifiedPhoneTaken(ctx context.Context, phone string, userId primitive.ObjectID) (bool, error) {
+	values, err := encryption.LookupField(phone)
+	if err != nil {
+		return false, err
+	}
+	count, err := repo.Collection.CountDocuments(ctx, bson.M{"verified_phone": bson.M{"$in": values}, "_id": bson.M{"$ne": userId}})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (repo *UserRepository) FindPhotographers(ctx context.Context) ([]models.User, error) {
 	var users []models.User
 	cursor, err := repo.Collection.Find(ctx, bson.M{"role": models.UserRole("Photographer")})
@@ -85,3 +103,156 @@ func (repo *UserRepository) FindPhotographers(ctx context.Context) ([]models.Use
 	}
 	return users, nil
 }
+
+// FindListedPhotographerIds returns the ids of the photographers whose packages are listed, see models.User.IsListed
+func (repo *UserRepository) FindListedPhotographerIds(ctx context.Context) ([]primitive.ObjectID, error) {
+	filter := bson.M{"role": models.Photographer, "verification_status": bson.M{"$in": bson.A{models.VerificationApproved, models.VerificationLegacy}}}
+	cursor, err := repo.Collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids, nil
+}
+
+// FindNeverVerifiedPhotographers returns the photographers created before the time who never submitted a verification
+func (repo *UserRepository) FindNeverVerifiedPhotographers(ctx context.Context, before time.Time) ([]models.User, error) {
+	filter := bson.M{
+		"_id":                 bson.M{"$lt": primitive.NewObjectIDFromTimestamp(before)},
+		"role":                models.Photographer,
+		"verification_status": bson.M{"$exists": false},
+	}
+	cursor, err := repo.Collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindAllWithProfile returns the stored image fields of every user that has a profile picture or a watermark logo
+func (repo *UserRepository) FindAllWithProfile(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	filter := bson.M{"$or": bson.A{
+		bson.M{"profile": bson.M{"$nin": bson.A{nil, ""}}},
+		bson.M{"profile_image": bson.M{"$ne": nil}},
+		bson.M{"watermark.logo_key": bson.M{"$nin": bson.A{nil, ""}}},
+	}}
+	projection := options.Find().SetProjection(bson.M{"profile": 1, "profile_image": 1, "watermark.logo_key": 1})
+	cursor, err := repo.Collection.Find(ctx, filter, projection)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []models.User{}
+	}
+	return users, nil
+}
+
+// Search returns a page of the users whose email or name contains the query, filtered by role when it is set,
+// with the number of matching users
+func (repo *UserRepository) Search(ctx context.Context, query string, role models.UserRole, page, limit int) ([]models.User, int64, error) {
+	filter := bson.M{}
+	if query != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+		filter["$or"] = bson.A{bson.M{"email": pattern}, bson.M{"name": pattern}}
+	}
+	if role != "" {
+		filter["role"] = role
+	}
+	total, err := repo.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+	cursor, err := repo.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+	if users == nil {
+		users = []models.User{}
+	}
+	return users, total, nil
+}
+
+func (repo *UserRepository) CountByRole(ctx context.Context) (map[string]int64, error) {
+	return countByField(ctx, repo.Collection, "role")
+}
+
+func (repo *UserRepository) CountSuspended(ctx context.Context) (int64, error) {
+	return repo.Collection.CountDocuments(ctx, bson.M{"suspension": bson.M{"$ne": nil}})
+}
+
+// FindDeletionDue returns the users whose account deletion is scheduled at or before the time
+func (repo *UserRepository) FindDeletionDue(ctx context.Context, now time.Time) ([]models.User, error) {
+	var users []models.User
+	cursor, err := repo.Collection.Find(ctx, bson.M{"deletion.scheduled_time": bson.M{"$lte": now}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []models.User{}
+	}
+	return users, nil
+}
+
+func (repo *UserRepository) DeleteUser(ctx context.Context, userId primitive.ObjectID) error {
+	_, err := repo.Collection.DeleteOne(ctx, bson.M{"_id": userId})
+	return err
+}
+
+// countByField counts the documents of the collection grouped by the value of the string field
+func countByField(ctx context.Context, collection *mongo.Collection, field string) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$" + field}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID    string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{}
+	for _, group := range groups {
+		counts[group.ID] += group.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type VerificationRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewVerificationRepository(collection *mongo.Collection) *VerificationRepository {
+	return &VerificationRepository{Collection: collection}
+}
+
+func (repo *VerificationRepository) Create(ctx context.Context, item *models.PhotographerVerification) error {
+	_, err := repo.Collection.InsertOne(ctx, item)
+	return err
+}
+
+func (repo *VerificationRepository) GetById(ctx context.Context, id primitive.ObjectID) (*models.PhotographerVerification, error) {
+	var item models.PhotographerVerification
+	err := repo.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetMany returns the oldest items first, filtered by status when it is set
+func (repo *VerificationRepository) GetMany(ctx context.Context, status models.VerificationStatus) ([]models.PhotographerVerification, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	return repo.find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_time", Value: 1}}))
+}
+
+// GetByUserId returns the submissions of the user, the latest first
+func (repo *VerificationRepository) GetByUserId(ctx context.Context, userId primitive.ObjectID) ([]models.PhotographerVerification, error) {
+	return repo.find(ctx, bson.M{"user_id": userId}, options.Find().SetSort(bson.D{{Key: "created_time", Value: -1}}))
+}
+
+func (repo *VerificationRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.PhotographerVerification, error) {
+	var items []models.PhotographerVerification
+	cursor, err := repo.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.PhotographerVerification{}
+	}
+	return items, nil
+}
+
+func (repo *VerificationRepository) HasPending(ctx context.Context, userId primitive.ObjectID) (bool, error) {
+	count, err := repo.Collection.CountDocuments(ctx, bson.M{"user_id": userId, "status": models.VerificationPending})
+	return count > 0, err
+}
+
+// UpdateStatus moves the item out of the given status, it returns false when the item is not in that status anymore
+func (repo *VerificationRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, from models.VerificationStatus, updates bson.M) (bool, error) {
+	res, err := repo.Collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, bson.M{"$set": updates})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (repo *VerificationRepository) DeleteByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	_, err := repo.Collection.DeleteMany(ctx, bson.M{"user_id": userId})
+	return err
+}
@@ -18,6 +18,11 @@ func (m *MockUserRepository) FindUserByID(ctx context.Context, id primitive.Obje
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindListedPhotographerIds(ctx context.Context) ([]primitive.ObjectID, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]primitive.ObjectID), args.Error(1)
+}
+
 // func (m *MockUserRepository) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
 // 	args := m.Called(ctx, email)
 // 	return nil, args.Error(1)
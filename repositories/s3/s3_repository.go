@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"mime"
 	"mime/multipart"
 	"os"
 	"time"
 
 	"context"
 
+	"github.com/Bualoi-s-Dev/backend/models"
+	storage "github.com/Bualoi-s-Dev/backend/repositories/storage"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
@@ -22,9 +26,10 @@ import (
 )
 
 type S3Repository struct {
-	BucketName string
-	Client     *s3.Client
-	Uploaders  *S3Uploaders
+	BucketName    string
+	Client        *s3.Client
+	PresignClient *s3.PresignClient
+	Uploaders     *S3Uploaders
 }
 type S3Uploaders struct {
 	DefaultUploader    *manager.Uploader
@@ -42,13 +47,13 @@ func NewUploaders(client *s3.Client) *S3Uploaders {
 	}
 }
 
-func NewS3Repository() *S3Repository {
+func NewS3Repository() (*S3Repository, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), "")),
 		config.WithRegion("auto"),
 	)
 	if err != nil {
-		log.Fatalln("error:", err)
+		return nil, err
 	}
 	bucketName := os.Getenv("S3_BUCKET_NAME")
 	// client := s3.NewFromConfig(cfg)
@@ -58,10 +63,11 @@ func NewS3Repository() *S3Repository {
 	uploaders := NewUploaders(client)
 
 	return &S3Repository{
-		BucketName: bucketName,
-		Client:     client,
-		Uploaders:  uploaders,
-	}
+		BucketName:    bucketName,
+		Client:        client,
+		PresignClient: s3.NewPresignClient(client),
+		Uploaders:     uploaders,
+	}, nil
 }
 
 func (s *S3Repository) UploadFile(file *multipart.FileHeader, key string) (string, error) {
@@ -110,6 +116,120 @@ func (s *S3Repository) UploadBase64(fileBytes []byte, key string, contentType st
 	return "/" + genKey, nil
 }
 
+// PutObject uploads the body under the exact key, the object is publicly readable unless the key is private
+func (s *S3Repository) PutObject(key string, body []byte, contentType string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := s.Uploaders.LimitedImgUploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:             aws.String(s.BucketName),
+		Key:                aws.String(key),
+		Body:               bytes.NewReader(body),
+		ACL:                objectACL(key),
+		ContentDisposition: aws.String("inline"),
+		ContentType:        aws.String(contentType),
+	})
+	return err
+}
+
+// PutObjectStream uploads the body in parts as it is read, so its size does not have to be known
+func (s *S3Repository) PutObjectStream(key string, body io.Reader, contentType string) error {
+	_, err := s.Uploaders.DefaultUploader.Upload(context.Background(), &s3.PutObjectInput{
+		Bucket:      aws.String(s.BucketName),
+		Key:         aws.String(key),
+		Body:        body,
+		ACL:         objectACL(key),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+// OpenObject streams the object, there is no timeout as a large object can take long to read
+func (s *S3Repository) OpenObject(key string) (io.ReadCloser, error) {
+	output, err := s.Client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+// objectACL keeps the objects under the private prefixes readable only through presigned URLs
+func objectACL(key string) types.ObjectCannedACL {
+	if storage.IsPrivateKey(key) {
+		return types.ObjectCannedACLPrivate
+	}
+	return types.ObjectCannedACLPublicRead
+}
+
+func (s *S3Repository) GetObject(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	output, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
+
+func (s *S3Repository) ListObjects(prefix string) ([]models.StorageObject, error) {
+	items := []models.StorageObject{}
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.BucketName),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			items = append(items, models.StorageObject{
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return items, nil
+}
+
+// PresignPut signs the content type and length, so the client cannot upload anything else with the URL
+func (s *S3Repository) PresignPut(key string, contentType string, size int64, expires time.Duration) (string, error) {
+	req, err := s.PresignClient.PresignPutObject(context.TODO(), &s3.PutObjectInput{
+		Bucket:        aws.String(s.BucketName),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+func (s *S3Repository) PresignGet(key string, expires time.Duration, downloadName string) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(key),
+	}
+	if downloadName != "" {
+		input.ResponseContentDisposition = aws.String(mime.FormatMediaType("attachment", map[string]string{"filename": downloadName}))
+	}
+	req, err := s.PresignClient.PresignGetObject(context.TODO(), input, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
 func (s *S3Repository) DeleteObject(key string) error {
 	bucket := s.BucketName
 	input := &s3.DeleteObjectInput{
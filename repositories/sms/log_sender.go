@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"log"
+)
+
+// LogSender writes the messages to the log instead of sending them, for development
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, phone string, message string) error {
+	log.Printf("[SMS] to %s: %s", phone, message)
+	return nil
+}
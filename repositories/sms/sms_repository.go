@@ -0,0 +1,8 @@
+package repositories
+
+import "context"
+
+// SMSSender delivers text messages, the phone numbers are in the E.164 format such as +66812345678
+type SMSSender interface {
+	Send(ctx context.Context, phone string, message string) error
+}
@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LocalRepository keeps the objects on the local disk, it is meant for development and offline testing.
+// Presigned uploads are PUT to the server itself under URLPrefix and checked with an HMAC signature.
+type LocalRepository struct {
+	BaseDir   string
+	URLPrefix string
+	Secret    []byte
+}
+
+func NewLocalRepository(baseDir string, urlPrefix string, secret []byte) (*LocalRepository, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalRepository{BaseDir: baseDir, URLPrefix: urlPrefix, Secret: secret}, nil
+}
+
+func (s *LocalRepository) UploadFile(file *multipart.FileHeader, key string) (string, error) {
+	uploadFile, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer uploadFile.Close()
+
+	if err := s.write(key, uploadFile); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (s *LocalRepository) UploadBase64(fileBytes []byte, key string, contentType string) (string, error) {
+	genKey := key + "_" + primitive.NewObjectID().Hex()
+	if err := s.write(genKey, bytes.NewReader(fileBytes)); err != nil {
+		return "", err
+	}
+	return "/" + genKey, nil
+}
+
+func (s *LocalRepository) PutObject(key string, body []byte, contentType string) error {
+	return s.write(key, bytes.NewReader(body))
+}
+
+func (s *LocalRepository) GetObject(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (s *LocalRepository) OpenObject(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *LocalRepository) PutObjectStream(key string, body io.Reader, contentType string) error {
+	return s.write(key, body)
+}
+
+func (s *LocalRepository) ListObjects(prefix string) ([]models.StorageObject, error) {
+	items := []models.StorageObject{}
+	err := filepath.WalkDir(s.BaseDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.BaseDir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		items = append(items, models.StorageObject{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *LocalRepository) PresignPut(key string, contentType string, size int64, expires time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expireTime := time.Now().Add(expires).Unix()
+	query := url.Values{}
+	query.Set("contentType", contentType)
+	query.Set("size", strconv.FormatInt(size, 10))
+	query.Set("expires", strconv.FormatInt(expireTime, 10))
+	query.Set("signature", s.sign(http.MethodPut, key, contentType, size, expireTime))
+	return s.URLPrefix + "/" + strings.TrimPrefix(key, "/") + "?" + query.Encode(), nil
+}
+
+// VerifyPresignedPut checks the query of a URL returned by PresignPut against the upload request
+func (s *LocalRepository) VerifyPresignedPut(key string, query url.Values, contentType string, size int64) error {
+	signedSize, err := strconv.ParseInt(query.Get("size"), 10, 64)
+	if err != nil {
+		return errors.New("invalid size")
+	}
+	expireTime, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return errors.New("invalid expires")
+	}
+	expected := s.sign(http.MethodPut, key, query.Get("contentType"), signedSize, expireTime)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return errors.New("invalid signature")
+	}
+	if time.Now().Unix() > expireTime {
+		return errors.New("upload URL has expired")
+	}
+	if contentType != query.Get("contentType") || size != signedSize {
+		return errors.New("content type or size does not match the upload URL")
+	}
+	return nil
+}
+
+func (s *LocalRepository) PresignGet(key string, expires time.Duration, downloadName string) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expireTime := time.Now().Add(expires).Unix()
+	query := url.Values{}
+	if downloadName != "" {
+		query.Set("download", downloadName)
+	}
+	query.Set("expires", strconv.FormatInt(expireTime, 10))
+	query.Set("signature", s.sign(http.MethodGet, key, downloadName, 0, expireTime))
+	return s.URLPrefix + "/" + strings.TrimPrefix(key, "/") + "?" + query.Encode(), nil
+}
+
+// VerifyPresignedGet checks the query of a URL returned by PresignGet
+func (s *LocalRepository) VerifyPresignedGet(key string, query url.Values) error {
+	expireTime, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return errors.New("invalid expires")
+	}
+	expected := s.sign(http.MethodGet, key, query.Get("download"), 0, expireTime)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return errors.New("invalid signature")
+	}
+	if time.Now().Unix() > expireTime {
+		return errors.New("download URL has expired")
+	}
+	return nil
+}
+
+// Path returns the file of the object on the disk
+func (s *LocalRepository) Path(key string) (string, error) {
+	return s.path(key)
+}
+
+func (s *LocalRepository) sign(method string, key string, value string, size int64, expireTime int64) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%d\n%d", method, strings.TrimPrefix(key, "/"), value, size, expireTime)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalRepository) DeleteObject(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete object %s: %v", key, err)
+	}
+	return nil
+}
+
+func (s *LocalRepository) write(key string, body io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, body)
+	return err
+}
+
+// path resolves the key inside the base directory, keys escaping it are rejected
+func (s *LocalRepository) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + strings.TrimPrefix(key, "/"))
+	if cleaned == "/" {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.BaseDir, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+)
+
+// StorageRepository is the blob storage that keeps uploaded images, keys are returned with a leading slash
+// when generated by the storage and are served under the public storage URL
+type StorageRepository interface {
+	UploadFile(file *multipart.FileHeader, key string) (string, error)
+	UploadBase64(fileBytes []byte, key string, contentType string) (string, error)
+	PutObject(key string, body []byte, contentType string) error
+	GetObject(key string) ([]byte, error)
+	// OpenObject streams the object without reading it into memory, the caller closes it
+	OpenObject(key string) (io.ReadCloser, error)
+	// PutObjectStream uploads a body of unknown size, such as an archive being written
+	PutObjectStream(key string, body io.Reader, contentType string) error
+	DeleteObject(key string) error
+	// ListObjects returns every object whose key starts with the prefix, the keys have no leading slash
+	ListObjects(prefix string) ([]models.StorageObject, error)
+	// PresignPut returns a URL the client can PUT exactly size bytes of contentType to, until it expires
+	PresignPut(key string, contentType string, size int64, expires time.Duration) (string, error)
+	// PresignGet returns a URL to read a private object until it expires, it is downloaded as downloadName when set
+	PresignGet(key string, expires time.Duration, downloadName string) (string, error)
+}
+
+// PrivatePrefixes are the key prefixes that are only readable through presigned URLs
+var PrivatePrefixes = []string{"gallery/", "archive/", "upload/", "quarantine/", "original/", "watermark/"}
+
+var ErrInvalidKey = errors.New("invalid object key")
+
+// CleanKey trims the leading slash of a requested key and rejects keys that are not already clean, such as
+// "//gallery/x", "./gallery/x" or "a/../gallery/x", so a private prefix cannot be hidden from IsPrivateKey
+func CleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
+
+func IsPrivateKey(key string) bool {
+	key = strings.TrimPrefix(key, "/")
+	for _, prefix := range PrivatePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
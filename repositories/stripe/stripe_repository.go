@@ -167,7 +167,7 @@ func (s *StripeRepository) AttachAccountSetting(accountID string) error {
 	return err
 }
 
-func (s *StripeRepository) CreateCheckoutSession(customerId string, sellerAccountId string, productName string, amount int64, quantity int64, currency string, successURL string, cancelURL string) (*stripe.CheckoutSession, error) {
+func (s *StripeRepository) CreateCheckoutSession(customerId string, sellerAccountId string, productName string, amount int64, quantity int64, currency string, applicationFee int64, successURL string, cancelURL string) (*stripe.CheckoutSession, error) {
 	frontendURL := utils.GetFrontendURL()
 	successURLRedirect := utils.SafeStringWithDefault(successURL, frontendURL+"/payment/success")
 	cancelURLRedirect := utils.SafeStringWithDefault(cancelURL, frontendURL+"/payment/topay")
@@ -192,17 +192,28 @@ func (s *StripeRepository) CreateCheckoutSession(customerId string, sellerAccoun
 		},
 		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
 			OnBehalfOf: stripe.String(sellerAccountId), // Seller's connected account ID
-			// TODO: Change the application fee amount
-			ApplicationFeeAmount: stripe.Int64(500), // Platform fee, currently 5 THB
 			TransferData: &stripe.CheckoutSessionPaymentIntentDataTransferDataParams{
 				Destination: stripe.String(sellerAccountId), // Seller's connected account ID
 			},
 		},
 	}
+	// Platform fee in the smallest currency unit, omitted when the whole amount goes to the seller
+	if applicationFee > 0 {
+		params.PaymentIntentData.ApplicationFeeAmount = stripe.Int64(applicationFee)
+	}
 
 	return session.New(params)
 }
 
+func (s *StripeRepository) GetCheckoutSession(checkoutId string) (*stripe.CheckoutSession, error) {
+	return session.Get(checkoutId, nil)
+}
+
+// ExpireCheckoutSession closes an open checkout session so it cannot be paid anymore
+func (s *StripeRepository) ExpireCheckoutSession(checkoutId string) (*stripe.CheckoutSession, error) {
+	return session.Expire(checkoutId, nil)
+}
+
 func (s *StripeRepository) CreatePayout(accountID string, amount int64, currency string) (*stripe.Payout, error) {
 	params := &stripe.PayoutParams{
 		Amount:   stripe.Int64(amount),
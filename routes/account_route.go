@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func AccountRoutes(router *gin.Engine, ctrl *controllers.AccountController) {
+	accountRoutes := router.Group("/user/me")
+	{
+		accountRoutes.GET("/export", ctrl.ExportAccount)
+		accountRoutes.DELETE("", ctrl.DeleteAccount)
+		accountRoutes.POST("/restore", ctrl.RestoreAccount)
+	}
+}
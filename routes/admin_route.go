@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AdminRoutes(router *gin.Engine, ctrl *controllers.AdminController, userService *services.UserService) {
+	adminRoutes := router.Group("/admin", middleware.AllowRoles(userService, models.Admin))
+	{
+		adminRoutes.GET("/users", ctrl.GetUsers)
+		adminRoutes.GET("/users/:id", ctrl.GetUser)
+		adminRoutes.PATCH("/users/:id/role", ctrl.UpdateUserRole)
+		adminRoutes.POST("/users/:id/suspend", ctrl.SuspendUser)
+		adminRoutes.POST("/users/:id/unsuspend", ctrl.UnsuspendUser)
+		adminRoutes.POST("/users/:id/revoke-sessions", ctrl.RevokeUserSessions)
+		adminRoutes.GET("/appointments", ctrl.GetAppointments)
+		adminRoutes.GET("/appointments/:id", ctrl.GetAppointment)
+		adminRoutes.PATCH("/appointments/:id/status", ctrl.ForceAppointmentStatus)
+		adminRoutes.GET("/payments", ctrl.GetPayments)
+		adminRoutes.GET("/payments/:id", ctrl.GetPayment)
+		adminRoutes.DELETE("/packages/:id", ctrl.RemovePackage)
+		adminRoutes.DELETE("/ratings/:id", ctrl.RemoveRating)
+		adminRoutes.GET("/stats", ctrl.GetStats)
+		adminRoutes.GET("/audit/:entityType/:id", ctrl.GetAuditLog)
+	}
+}
@@ -3,26 +3,21 @@ package routes
 import (
 	"github.com/Bualoi-s-Dev/backend/controllers"
 	"github.com/Bualoi-s-Dev/backend/middleware"
-	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 )
 
 func AppointmentRoutes(router *gin.Engine, ctrl *controllers.AppointmentController, userService *services.UserService) {
 	appointmentGroup := router.Group("/appointment")
-	commonRoutes := appointmentGroup.Group("", middleware.AllowRoles(userService, models.Photographer, models.Customer))
+	readRoutes := appointmentGroup.Group("", middleware.Allow(policies.ResourceAppointment, policies.ActionRead))
 	{
-		commonRoutes.GET("", ctrl.GetAllAppointment)
-		commonRoutes.GET("/:id", ctrl.GetAppointmentById)
-		commonRoutes.GET("/detail", ctrl.GetAllAppointmentDetail)
-		commonRoutes.GET("/detail/:id", ctrl.GetAppointmentDetailById)
-		commonRoutes.PATCH("/status/:id", ctrl.UpdateAppointmentStatus)
+		readRoutes.GET("", ctrl.GetAllAppointment)
+		readRoutes.GET("/:id", ctrl.GetAppointmentById)
+		readRoutes.GET("/detail", ctrl.GetAllAppointmentDetail)
+		readRoutes.GET("/detail/:id", ctrl.GetAppointmentDetailById)
 	}
-	customerRoutes := appointmentGroup.Group("", middleware.AllowRoles(userService, models.Customer))
-	{
-		customerRoutes.POST("/:subpackageId", ctrl.CreateAppointment)
-		// TODO: refactor common route later
-	}
-
-	appointmentGroup.DELETE("/:id", ctrl.DeleteAppointment)
+	appointmentGroup.PATCH("/status/:id", middleware.Allow(policies.ResourceAppointment, policies.ActionAccept, policies.ActionReject, policies.ActionCancel), ctrl.UpdateAppointmentStatus)
+	appointmentGroup.POST("/:subpackageId", middleware.Allow(policies.ResourceAppointment, policies.ActionCreate), ctrl.CreateAppointment)
+	appointmentGroup.DELETE("/:id", middleware.Allow(policies.ResourceAppointment, policies.ActionDelete), ctrl.DeleteAppointment)
 }
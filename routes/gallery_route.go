@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+func GalleryRoutes(router *gin.Engine, ctrl *controllers.GalleryController, userService *services.UserService) {
+	galleryRoutes := router.Group("/gallery")
+	commonRoutes := galleryRoutes.Group("", middleware.AllowRoles(userService, models.Photographer, models.Customer))
+	{
+		commonRoutes.GET("/:appointmentId", ctrl.GetGallery)
+		commonRoutes.GET("/:appointmentId/download", ctrl.DownloadGallery)
+	}
+	photographerRoutes := galleryRoutes.Group("", middleware.AllowRoles(userService, models.Photographer))
+	{
+		photographerRoutes.POST("/:appointmentId", ctrl.CreateGallery)
+		photographerRoutes.POST("/:appointmentId/photos", ctrl.AddGalleryPhotos)
+		photographerRoutes.DELETE("/:appointmentId/photos/:photoId", ctrl.RemoveGalleryPhoto)
+		photographerRoutes.POST("/:appointmentId/deliver", ctrl.DeliverGallery)
+		photographerRoutes.PATCH("/:appointmentId/expire", ctrl.UpdateGalleryExpireTime)
+	}
+}
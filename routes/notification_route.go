@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+func NotificationRoutes(router *gin.Engine, ctrl *controllers.NotificationController, userService *services.UserService) {
+	notificationRoutes := router.Group("/notification", middleware.AllowRoles(userService, models.Photographer, models.Customer))
+	{
+		notificationRoutes.GET("", ctrl.GetNotifications)
+		notificationRoutes.PATCH("/:id/read", ctrl.ReadNotification)
+	}
+}
@@ -3,25 +3,30 @@ package routes
 import (
 	"github.com/Bualoi-s-Dev/backend/controllers"
 	"github.com/Bualoi-s-Dev/backend/middleware"
-	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 )
 
 func PackageRoutes(router *gin.Engine, ctrl *controllers.PackageController, userService *services.UserService) {
 	packageRoutes := router.Group("/package")
-	commonRoutes := packageRoutes.Group("", middleware.AllowRoles(userService, models.Photographer, models.Customer))
+	readRoutes := packageRoutes.Group("", middleware.Allow(policies.ResourcePackage, policies.ActionRead))
 	{
-		commonRoutes.GET("", ctrl.GetAllPackages)
-		commonRoutes.GET("/recommend", ctrl.GetRecommendedPackages)
-		commonRoutes.GET("/:id", ctrl.GetOnePackage)
+		readRoutes.GET("", ctrl.GetAllPackages)
+		readRoutes.GET("/recommend", ctrl.GetRecommendedPackages)
+		readRoutes.GET("/:id", ctrl.GetOnePackage)
 	}
 
-	photographerRoutes := packageRoutes.Group("", middleware.AllowRoles(userService, models.Photographer))
+	packageRoutes.POST("", middleware.Allow(policies.ResourcePackage, policies.ActionCreate), ctrl.CreateOnePackage)
+	packageRoutes.DELETE("/:id", middleware.Allow(policies.ResourcePackage, policies.ActionDelete), ctrl.DeleteOnePackage)
+	updateRoutes := packageRoutes.Group("", middleware.Allow(policies.ResourcePackage, policies.ActionUpdate))
 	{
+		updateRoutes.PATCH("/:id", ctrl.UpdateOnePackage)
 
-		photographerRoutes.POST("", ctrl.CreateOnePackage)
-		photographerRoutes.PATCH("/:id", ctrl.UpdateOnePackage)
-		photographerRoutes.DELETE("/:id", ctrl.DeleteOnePackage)
+		updateRoutes.POST("/:id/photos", ctrl.AddPackagePhotos)
+		updateRoutes.PUT("/:id/photos/order", ctrl.ReorderPackagePhotos)
+		updateRoutes.PATCH("/:id/photos/:photoId", ctrl.UpdatePackagePhotoCaption)
+		updateRoutes.DELETE("/:id/photos/:photoId", ctrl.RemovePackagePhoto)
+		updateRoutes.PUT("/:id/photos/:photoId/cover", ctrl.SetPackageCoverPhoto)
 	}
 }
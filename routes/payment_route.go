@@ -4,22 +4,31 @@ import (
 	"github.com/Bualoi-s-Dev/backend/controllers"
 	"github.com/Bualoi-s-Dev/backend/middleware"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 )
 
-func PaymentRoutes(router *gin.Engine, ctrl *controllers.PaymentController, userService *services.UserService) {
+func PaymentRoutes(router *gin.Engine, ctrl *controllers.PaymentController, jobCtrl *controllers.PaymentJobController, userService *services.UserService) {
 	paymentRoutes := router.Group("/payment")
-	commonRoutes := paymentRoutes.Group("", middleware.AllowRoles(userService, models.Photographer, models.Customer))
+	readRoutes := paymentRoutes.Group("", middleware.Allow(policies.ResourcePayment, policies.ActionRead))
 	{
-		commonRoutes.GET("", ctrl.GetAllOwnedPayments)
-		commonRoutes.GET("/:id", ctrl.GetPaymentById)
+		readRoutes.GET("", ctrl.GetAllOwnedPayments)
+		readRoutes.GET("/export", ctrl.ExportPayments)
+		readRoutes.GET("/:id", ctrl.GetPaymentById)
 	}
 	photographerRoutes := paymentRoutes.Group("", middleware.AllowRoles(userService, models.Photographer))
 	{
 		photographerRoutes.GET("/onboardingURL", ctrl.GetOnBoardAccountURL)
 		// photographerRoutes.GET("/loginDashboardURL", ctrl.GetLoginLinkAccountURL)
 	}
+	paymentRoutes.POST("/tip/:appointmentId", middleware.Allow(policies.ResourceAppointment, policies.ActionTip), ctrl.CreateTip)
+	adminRoutes := paymentRoutes.Group("", middleware.AllowRoles(userService, models.Admin))
+	{
+		adminRoutes.GET("/jobs", jobCtrl.GetPaymentJobs)
+		adminRoutes.GET("/recovery", jobCtrl.GetUnpaidCompletedAppointments)
+		adminRoutes.POST("/recovery/:appointmentId", jobCtrl.RedrivePayment)
+	}
 	paymentRoutes.POST("/charge/:appointmentId", ctrl.CreatePayment)
 	paymentRoutes.POST("/webhook", ctrl.WebhookListener)
 }
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func PhoneRoutes(router *gin.Engine, ctrl *controllers.PhoneController) {
+	phoneRoutes := router.Group("/user/phone")
+	{
+		phoneRoutes.POST("/otp", ctrl.SendPhoneOTP)
+		phoneRoutes.POST("/verify", ctrl.VerifyPhoneOTP)
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+func PhotoModerationRoutes(router *gin.Engine, ctrl *controllers.PhotoModerationController, userService *services.UserService) {
+	moderationRoutes := router.Group("/moderation/photos", middleware.AllowRoles(userService, models.Admin))
+	{
+		moderationRoutes.GET("", ctrl.GetPhotoModerationQueue)
+		moderationRoutes.POST("/:id/approve", ctrl.ApprovePhoto)
+		moderationRoutes.POST("/:id/reject", ctrl.RejectPhoto)
+	}
+}
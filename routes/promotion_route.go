@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+func PromotionRoutes(router *gin.Engine, ctrl *controllers.PromotionController, userService *services.UserService) {
+	promotionRoutes := router.Group("/promotion")
+	customerRoutes := promotionRoutes.Group("", middleware.AllowRoles(userService, models.Customer))
+	{
+		customerRoutes.GET("/preview", ctrl.PreviewPromotion)
+	}
+	managerRoutes := promotionRoutes.Group("", middleware.AllowRoles(userService, models.Photographer, models.Admin))
+	{
+		managerRoutes.GET("", ctrl.GetAllPromotions)
+		managerRoutes.GET("/:id", ctrl.GetPromotionById)
+		managerRoutes.POST("", ctrl.CreatePromotion)
+		managerRoutes.PATCH("/:id", ctrl.UpdatePromotion)
+		managerRoutes.DELETE("/:id", ctrl.DeletePromotion)
+	}
+}
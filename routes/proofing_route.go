@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+func ProofingRoutes(router *gin.Engine, ctrl *controllers.ProofingController, userService *services.UserService) {
+	proofRoutes := router.Group("/gallery/:appointmentId/proofs")
+	commonRoutes := proofRoutes.Group("", middleware.AllowRoles(userService, models.Photographer, models.Customer))
+	{
+		commonRoutes.GET("", ctrl.GetProofs)
+	}
+	photographerRoutes := proofRoutes.Group("", middleware.AllowRoles(userService, models.Photographer))
+	{
+		photographerRoutes.POST("", ctrl.AddProofs)
+		photographerRoutes.DELETE("/:proofId", ctrl.RemoveProof)
+		photographerRoutes.POST("/open", ctrl.OpenProofing)
+	}
+	customerRoutes := proofRoutes.Group("", middleware.AllowRoles(userService, models.Customer))
+	{
+		customerRoutes.PUT("/selection", ctrl.UpdateProofSelection)
+		customerRoutes.POST("/submit", ctrl.SubmitProofSelection)
+	}
+}
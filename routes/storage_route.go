@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// StorageRoutes serves the objects of the local storage, the public storage URL should point to /storage
+func StorageRoutes(router *gin.Engine, ctrl *controllers.StorageController) {
+	router.GET("/storage/*key", ctrl.GetObject)
+	router.HEAD("/storage/*key", ctrl.GetObject)
+	router.PUT("/storage/*key", ctrl.PutObject)
+}
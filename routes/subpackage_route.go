@@ -3,23 +3,19 @@ package routes
 import (
 	"github.com/Bualoi-s-Dev/backend/controllers"
 	"github.com/Bualoi-s-Dev/backend/middleware"
-	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 )
 
 func SubpackageRoutes(router *gin.Engine, ctrl *controllers.SubpackageController, userService *services.UserService) {
 	subpackageRoutes := router.Group("/subpackage")
-	commonRoutes := subpackageRoutes.Group("", middleware.AllowRoles(userService, models.Photographer, models.Customer))
+	readRoutes := subpackageRoutes.Group("", middleware.Allow(policies.ResourceSubpackage, policies.ActionRead))
 	{
-		commonRoutes.GET("", ctrl.GetAllSubpackages)
-		commonRoutes.GET("/:id", ctrl.GetByIdSubpackages)
-	}
-	photographerRoutes := subpackageRoutes.Group("", middleware.AllowRoles(userService, models.Photographer))
-	{
-
-		photographerRoutes.POST("/:packageId", ctrl.CreateSubpackage)
-		photographerRoutes.PATCH("/:id", ctrl.UpdateSubpackage)
-		photographerRoutes.DELETE("/:id", ctrl.DeleteSubpackage)
+		readRoutes.GET("", ctrl.GetAllSubpackages)
+		readRoutes.GET("/:id", ctrl.GetByIdSubpackages)
 	}
+	subpackageRoutes.POST("/:packageId", middleware.Allow(policies.ResourceSubpackage, policies.ActionCreate), ctrl.CreateSubpackage)
+	subpackageRoutes.PATCH("/:id", middleware.Allow(policies.ResourceSubpackage, policies.ActionUpdate), ctrl.UpdateSubpackage)
+	subpackageRoutes.DELETE("/:id", middleware.Allow(policies.ResourceSubpackage, policies.ActionDelete), ctrl.DeleteSubpackage)
 }
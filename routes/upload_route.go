@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+func UploadRoutes(router *gin.Engine, ctrl *controllers.UploadController, userService *services.UserService) {
+	uploadRoutes := router.Group("/upload")
+	{
+		uploadRoutes.POST("", ctrl.CreateUpload)
+	}
+}
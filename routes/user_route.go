@@ -4,6 +4,7 @@ import (
 	"github.com/Bualoi-s-Dev/backend/controllers"
 	"github.com/Bualoi-s-Dev/backend/middleware"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	"github.com/Bualoi-s-Dev/backend/services"
 	"github.com/gin-gonic/gin"
 )
@@ -13,15 +14,14 @@ func UserRoutes(router *gin.Engine, userController *controllers.UserController,
 	commonRoutes := userGroup.Group("", middleware.AllowRoles(userService, models.Photographer, models.Customer))
 	{
 		commonRoutes.GET("/photographers", userController.GetPhotographers)
-		commonRoutes.GET("/:photographerId/rating", RatingController.GetAllRatingsFromPhotographer)
-		commonRoutes.GET("/:photographerId/rating/:ratingId", RatingController.GetRatingById)
-
 	}
-	customerRoutes := userGroup.Group("", middleware.AllowRoles(userService, models.Customer))
+	ratingRoutes := userGroup.Group("/:photographerId/rating")
 	{
-		customerRoutes.POST("/:photographerId/rating", RatingController.CreateRating)
-		customerRoutes.PUT("/:photographerId/rating/:ratingId", RatingController.UpdateRating)
-		customerRoutes.DELETE("/:photographerId/rating/:ratingId", RatingController.DeleteRating)
+		ratingRoutes.GET("", middleware.Allow(policies.ResourceRating, policies.ActionRead), RatingController.GetAllRatingsFromPhotographer)
+		ratingRoutes.GET("/:ratingId", middleware.Allow(policies.ResourceRating, policies.ActionRead), RatingController.GetRatingById)
+		ratingRoutes.POST("", middleware.Allow(policies.ResourceRating, policies.ActionCreate), RatingController.CreateRating)
+		ratingRoutes.PUT("/:ratingId", middleware.Allow(policies.ResourceRating, policies.ActionUpdate), RatingController.UpdateRating)
+		ratingRoutes.DELETE("/:ratingId", middleware.Allow(policies.ResourceRating, policies.ActionDelete), RatingController.DeleteRating)
 	}
 	photographerRoutes := userGroup.Group("", middleware.AllowRoles(userService, models.Photographer))
 	{
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+func VerificationRoutes(router *gin.Engine, ctrl *controllers.VerificationController, userService *services.UserService) {
+	photographerRoutes := router.Group("/user/verification", middleware.AllowRoles(userService, models.Photographer))
+	{
+		photographerRoutes.GET("", ctrl.GetOwnVerification)
+		photographerRoutes.POST("", ctrl.SubmitVerification)
+	}
+	adminRoutes := router.Group("/admin/verifications", middleware.AllowRoles(userService, models.Admin))
+	{
+		adminRoutes.GET("", ctrl.GetVerificationQueue)
+		adminRoutes.GET("/:id", ctrl.GetVerification)
+		adminRoutes.POST("/:id/approve", ctrl.ApproveVerification)
+		adminRoutes.POST("/:id/reject", ctrl.RejectVerification)
+	}
+}
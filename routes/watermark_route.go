@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+func WatermarkRoutes(router *gin.Engine, ctrl *controllers.WatermarkController, userService *services.UserService) {
+	watermarkRoutes := router.Group("/user/watermark", middleware.AllowRoles(userService, models.Photographer))
+	{
+		watermarkRoutes.GET("", ctrl.GetWatermark)
+		watermarkRoutes.PUT("", ctrl.UpdateWatermark)
+		watermarkRoutes.DELETE("", ctrl.DeleteWatermark)
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+)
+
+// AccountExport is the personal data of a user, written into the export archive as one JSON file per field
+type AccountExport struct {
+	Profile       *models.User
+	Packages      []models.Package
+	Subpackages   []models.Subpackage
+	BusyTimes     []models.BusyTime
+	Appointments  []models.Appointment
+	Payments      []models.Payment
+	Ratings       []models.Rating
+	Verifications []models.PhotographerVerification
+	CreatedTime   time.Time
+}
+
+// AccountExportImage is an uploaded image of the user, Name is its path inside the archive
+type AccountExportImage struct {
+	Name string
+	Key  string
+}
+
+// AccountExportName is the file name of the ZIP of the personal data of the user
+func AccountExportName(user *models.User) string {
+	return fmt.Sprintf("account_%s.zip", user.ID.Hex())
+}
+
+// Export gathers the personal data of the user, the payments are the ones made and received by them and the ratings
+// are the ones written by them and about them
+func (s *AccountService) Export(ctx context.Context, user *models.User) (*AccountExport, error) {
+	export := &AccountExport{Profile: user, Subpackages: []models.Subpackage{}, Payments: []models.Payment{}, CreatedTime: time.Now()}
+
+	var err error
+	if export.Packages, err = s.PackageRepo.GetByOwnerId(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	for _, pkg := range export.Packages {
+		subpackages, err := s.SubpackageRepo.GetByPackageId(ctx, pkg.ID)
+		if err != nil {
+			return nil, err
+		}
+		export.Subpackages = append(export.Subpackages, subpackages...)
+	}
+	if export.BusyTimes, err = s.BusyTimeRepo.GetByPhotographerId(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	if export.Appointments, err = s.AppointmentRepo.GetByUserId(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	for _, role := range []models.UserRole{models.Customer, models.Photographer} {
+		payments, err := s.PaymentRepo.GetByUserIDAndRole(ctx, role, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		export.Payments = append(export.Payments, payments...)
+	}
+	if export.Ratings, err = s.ratingsOf(ctx, user); err != nil {
+		return nil, err
+	}
+	if export.Verifications, err = s.VerificationService.Repo.GetByUserId(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// AccountExportImages lists the uploaded images of the export with the identity documents, the original upload is used
+// when it is kept and the largest rendition otherwise
+func AccountExportImages(export *AccountExport) []AccountExportImage {
+	images := []AccountExportImage{}
+	add := func(dir string, key string) {
+		key = strings.TrimPrefix(key, "/")
+		if key == "" {
+			return
+		}
+		images = append(images, AccountExportImage{Name: path.Join("images", dir, path.Base(key)), Key: key})
+	}
+
+	user := export.Profile
+	if user.ProfileImage != nil {
+		add("profile", exportImageKey(user.ProfileImage))
+	} else {
+		add("profile", user.Profile)
+	}
+	if user.Watermark != nil {
+		add("watermark", user.Watermark.LogoKey)
+	}
+	for _, verification := range export.Verifications {
+		for _, key := range verification.DocumentKeys {
+			add(path.Join("verification", verification.ID.Hex()), key)
+		}
+	}
+	for _, pkg := range export.Packages {
+		dir := path.Join("packages", pkg.ID.Hex())
+		if len(pkg.Images) == 0 {
+			for _, key := range pkg.PhotoUrls {
+				add(dir, key)
+			}
+			continue
+		}
+		for i := range pkg.Images {
+			add(dir, exportImageKey(&pkg.Images[i]))
+		}
+	}
+	return images
+}
+
+func exportImageKey(image *models.Image) string {
+	if image.OriginalKey != "" {
+		return image.OriginalKey
+	}
+	return image.Large
+}
+
+// WriteExport streams the archive of the export, the images are stored without compression as they are compressed
+// already
+func (s *AccountService) WriteExport(w io.Writer, export *AccountExport) error {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"packages.json", export.Packages},
+		{"subpackages.json", export.Subpackages},
+		{"busy_times.json", export.BusyTimes},
+		{"appointments.json", export.Appointments},
+		{"payments.json", export.Payments},
+		{"ratings.json", export.Ratings},
+		{"verifications.json", export.Verifications},
+	}
+	for _, file := range files {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.CreatedTime})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return fmt.Errorf("failed to add %s to the archive, %v", file.name, err)
+		}
+	}
+
+	for _, image := range AccountExportImages(export) {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: image.Name, Method: zip.Store, Modified: export.CreatedTime})
+		if err != nil {
+			return err
+		}
+		if err := s.copyObject(entry, image.Key); err != nil {
+			return fmt.Errorf("failed to add %s to the archive, %v", image.Key, err)
+		}
+	}
+	return archive.Close()
+}
+
+func (s *AccountService) copyObject(w io.Writer, key string) error {
+	body, err := s.S3Service.OpenObject(key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(w, body)
+	return err
+}
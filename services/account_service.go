@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	authRepo "github.com/Bualoi-s-Dev/backend/repositories/auth"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultAccountDeletionGracePeriod is how long a user can cancel the deletion of their account
+const DefaultAccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+// AccountService exports the personal data of the users and deletes their accounts, as the PDPA requires. A deletion
+// is scheduled after a grace period, until then the user can still sign in and cancel it.
+type AccountService struct {
+	UserRepo            *repositories.UserRepository
+	PackageRepo         *repositories.PackageRepository
+	SubpackageRepo      *repositories.SubpackageRepository
+	BusyTimeRepo        *repositories.BusyTimeRepository
+	AppointmentRepo     *repositories.AppointmentRepository
+	PaymentRepo         *repositories.PaymentRepository
+	RatingRepo          *repositories.RatingRepository
+	NotificationRepo    *repositories.NotificationRepository
+	PhoneOTPRepo        *repositories.PhoneOTPRepository
+	GalleryRepo         *repositories.GalleryRepository
+	PackageService      *PackageService
+	SubpackageService   *SubpackageService
+	BusyTimeService     *BusyTimeService
+	VerificationService *VerificationService
+	GalleryService      *GalleryService
+	S3Service           *S3Service
+	Auth                authRepo.TokenVerifier
+	Audit               *AuditService
+	GracePeriod         time.Duration
+}
+
+func NewAccountService(userRepo *repositories.UserRepository, packageRepo *repositories.PackageRepository, subpackageRepo *repositories.SubpackageRepository, busyTimeRepo *repositories.BusyTimeRepository,
+	appointmentRepo *repositories.AppointmentRepository, paymentRepo *repositories.PaymentRepository, ratingRepo *repositories.RatingRepository, notificationRepo *repositories.NotificationRepository, phoneOTPRepo *repositories.PhoneOTPRepository,
+	galleryRepo *repositories.GalleryRepository, packageService *PackageService, subpackageService *SubpackageService, busyTimeService *BusyTimeService, verificationService *VerificationService, galleryService *GalleryService, s3Service *S3Service, auth authRepo.TokenVerifier, audit *AuditService, gracePeriod time.Duration) *AccountService {
+	return &AccountService{
+		UserRepo:            userRepo,
+		PackageRepo:         packageRepo,
+		SubpackageRepo:      subpackageRepo,
+		BusyTimeRepo:        busyTimeRepo,
+		AppointmentRepo:     appointmentRepo,
+		PaymentRepo:         paymentRepo,
+		RatingRepo:          ratingRepo,
+		NotificationRepo:    notificationRepo,
+		PhoneOTPRepo:        phoneOTPRepo,
+		GalleryRepo:         galleryRepo,
+		PackageService:      packageService,
+		SubpackageService:   subpackageService,
+		BusyTimeService:     busyTimeService,
+		VerificationService: verificationService,
+		GalleryService:      galleryService,
+		S3Service:           s3Service,
+		Auth:                auth,
+		Audit:               audit,
+		GracePeriod:         gracePeriod,
+	}
+}
+
+// RequestDeletion schedules the deletion of the account after the grace period, it is refused while the user has an
+// appointment that may still go ahead. Asking again keeps the first schedule.
+func (s *AccountService) RequestDeletion(ctx context.Context, user *models.User) (*models.User, error) {
+	if user.Deletion != nil {
+		return user, nil
+	}
+	appointments, err := s.AppointmentRepo.GetByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if HasActiveAppointment(appointments) {
+		return nil, apperrors.ErrAccountActiveAppointments
+	}
+
+	now := time.Now()
+	deletion := &models.UserDeletion{RequestedTime: now, ScheduledTime: now.Add(s.GracePeriod)}
+	if _, err := s.UserRepo.UpdateUser(ctx, user.ID, bson.M{"deletion": deletion}); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditUser, user.ID, bson.M{"deletion": nil}, bson.M{"deletion": deletion})
+	user.Deletion = deletion
+	return user, nil
+}
+
+// CancelDeletion keeps the account whose deletion is scheduled
+func (s *AccountService) CancelDeletion(ctx context.Context, user *models.User) (*models.User, error) {
+	if user.Deletion == nil {
+		return nil, apperrors.ErrAccountDeletionNotScheduled
+	}
+	if _, err := s.UserRepo.UpdateUser(ctx, user.ID, bson.M{"deletion": nil}); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditUser, user.ID, bson.M{"deletion": user.Deletion}, bson.M{"deletion": nil})
+	user.Deletion = nil
+	return user, nil
+}
+
+// DeleteDue deletes the accounts whose grace period is over, an account with an appointment that may still go ahead is
+// retried on the next run
+func (s *AccountService) DeleteDue(ctx context.Context) {
+	users, err := s.UserRepo.FindDeletionDue(ctx, time.Now())
+	if err != nil {
+		log.Println("Failed to find the accounts to delete:", err)
+		return
+	}
+	for i := range users {
+		if err := s.DeleteAccount(ctx, &users[i]); err != nil {
+			log.Println("Failed to delete account", users[i].ID.Hex(), err)
+		}
+	}
+}
+
+// DeleteAccount removes the personal data of the user: their packages with their photos, busy times, ratings,
+// notifications, phone codes, verifications with their documents, the galleries of their bookings with their photos,
+// profile picture, watermark logo, auth account, the user itself and its history in the audit log. The appointments and
+// the galleries they delivered are anonymized and the payments are kept for the accounting, they only refer to the
+// anonymized appointments.
+func (s *AccountService) DeleteAccount(ctx context.Context, user *models.User) error {
+	appointments, err := s.AppointmentRepo.GetByUserId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if HasActiveAppointment(appointments) {
+		return apperrors.ErrAccountActiveAppointments
+	}
+
+	packages, err := s.PackageRepo.GetByOwnerId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	for _, pkg := range packages {
+		subpackages, err := s.SubpackageRepo.GetByPackageId(ctx, pkg.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.PackageService.DeleteOne(ctx, pkg.ID.Hex()); err != nil {
+			return err
+		}
+		for _, subpackage := range subpackages {
+			if err := s.SubpackageService.Delete(ctx, subpackage.ID.Hex()); err != nil {
+				return err
+			}
+		}
+	}
+
+	busyTimes, err := s.BusyTimeRepo.GetByPhotographerId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	for _, busyTime := range busyTimes {
+		if busyTime.Type == models.TypeAppointment {
+			continue
+		}
+		if err := s.BusyTimeService.Delete(ctx, busyTime.ID.Hex()); err != nil {
+			return err
+		}
+	}
+	if err := s.BusyTimeRepo.AnonymizePhotographer(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// The photos of the customer's galleries are theirs, the customers keep the galleries of a deleted photographer
+	galleries, err := s.GalleryRepo.GetByCustomerId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	for i := range galleries {
+		if err := s.GalleryService.DeleteGallery(ctx, &galleries[i]); err != nil {
+			return err
+		}
+	}
+	if err := s.GalleryRepo.AnonymizePhotographer(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := s.AppointmentRepo.AnonymizeUser(ctx, user.ID); err != nil {
+		return err
+	}
+	bookedAppointmentIds := []primitive.ObjectID{}
+	for _, appointment := range appointments {
+		before, after := bson.M{}, bson.M{}
+		if appointment.CustomerID == user.ID {
+			bookedAppointmentIds = append(bookedAppointmentIds, appointment.ID)
+			before["customer_id"], after["customer_id"] = appointment.CustomerID, primitive.NilObjectID
+			before["location"], after["location"] = AuditRedacted, ""
+		}
+		if appointment.PhotographerID == user.ID {
+			before["photographer_id"], after["photographer_id"] = appointment.PhotographerID, primitive.NilObjectID
+		}
+		s.Audit.Record(ctx, models.AuditAppointment, appointment.ID, before, after)
+	}
+
+	ratings, err := s.ratingsOf(ctx, user)
+	if err != nil {
+		return err
+	}
+	for _, rating := range ratings {
+		if err := s.RatingRepo.DeleteOne(ctx, rating.ID); err != nil {
+			return err
+		}
+		s.Audit.Record(ctx, models.AuditRating, rating.ID, bson.M{"_id": rating.ID}, nil)
+	}
+	if err := s.NotificationRepo.DeleteByUserId(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.PhoneOTPRepo.DeleteByUserId(ctx, user.ID); err != nil {
+		return err
+	}
+	verifications, err := s.VerificationService.Repo.GetByUserId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	verificationIds := make([]primitive.ObjectID, 0, len(verifications))
+	for _, verification := range verifications {
+		verificationIds = append(verificationIds, verification.ID)
+	}
+	if err := s.VerificationService.DeleteByUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// The objects left behind are removed by the storage GC, as nothing refers to them anymore
+	if user.ProfileImage != nil {
+		if err := s.S3Service.DeleteImage(user.ProfileImage); err != nil {
+			log.Println("Failed to delete the profile picture of", user.ID.Hex(), err)
+		}
+	} else if user.Profile != "" {
+		if err := s.S3Service.DeleteObject(strings.TrimPrefix(user.Profile, "/")); err != nil {
+			log.Println("Failed to delete the profile picture of", user.ID.Hex(), err)
+		}
+	}
+	if user.Watermark != nil && user.Watermark.LogoKey != "" {
+		if err := s.S3Service.DeleteObject(user.Watermark.LogoKey); err != nil {
+			log.Println("Failed to delete the watermark logo of", user.ID.Hex(), err)
+		}
+	}
+
+	if err := s.Auth.DeleteUser(ctx, user.Email); err != nil && !errors.Is(err, authRepo.ErrUserNotFound) {
+		return fmt.Errorf("failed to delete auth account: %v", err)
+	}
+	if err := s.UserRepo.DeleteUser(ctx, user.ID); err != nil {
+		return err
+	}
+	// The earlier history held the personal data that was just deleted, only the id of the deletion is kept
+	if err := s.Audit.EraseUser(ctx, user.ID, verificationIds, bookedAppointmentIds); err != nil {
+		return fmt.Errorf("failed to erase the audit log: %v", err)
+	}
+	s.Audit.Record(ctx, models.AuditUser, user.ID, bson.M{"_id": user.ID}, nil)
+	return nil
+}
+
+// ratingsOf returns the ratings written by the user and the ones about them
+func (s *AccountService) ratingsOf(ctx context.Context, user *models.User) ([]models.Rating, error) {
+	written, err := s.RatingRepo.GetByCustomerId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	received, err := s.RatingRepo.GetByPhotographerId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return append(written, received...), nil
+}
+
+// HasActiveAppointment reports whether one of the appointments is pending or accepted
+func HasActiveAppointment(appointments []models.Appointment) bool {
+	for _, appointment := range appointments {
+		if appointment.Status == models.AppointmentPending || appointment.Status == models.AppointmentAccepted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	authRepo "github.com/Bualoi-s-Dev/backend/repositories/auth"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AdminService is the back office of the platform, every action is taken on behalf of an admin and the
+// affected users are told the reason
+type AdminService struct {
+	UserRepo            *repositories.UserRepository
+	AppointmentRepo     *repositories.AppointmentRepository
+	PaymentRepo         *repositories.PaymentRepository
+	RatingRepo          *repositories.RatingRepository
+	PackageService      *PackageService
+	BusyTimeService     *BusyTimeService
+	PromotionService    *PromotionService
+	PaymentJobService   *PaymentJobService
+	NotificationService *NotificationService
+	Auth                authRepo.TokenVerifier
+	Audit               *AuditService
+}
+
+func NewAdminService(userRepo *repositories.UserRepository, appointmentRepo *repositories.AppointmentRepository, paymentRepo *repositories.PaymentRepository, ratingRepo *repositories.RatingRepository,
+	packageService *PackageService, busyTimeService *BusyTimeService, promotionService *PromotionService, paymentJobService *PaymentJobService, notificationService *NotificationService, auth authRepo.TokenVerifier) *AdminService {
+	return &AdminService{
+		UserRepo:            userRepo,
+		AppointmentRepo:     appointmentRepo,
+		PaymentRepo:         paymentRepo,
+		RatingRepo:          ratingRepo,
+		PackageService:      packageService,
+		BusyTimeService:     busyTimeService,
+		PromotionService:    promotionService,
+		PaymentJobService:   paymentJobService,
+		NotificationService: notificationService,
+		Auth:                auth,
+	}
+}
+
+func (s *AdminService) SearchUsers(ctx context.Context, query string, role models.UserRole, page, limit int) ([]models.User, int, error) {
+	users, total, err := s.UserRepo.Search(ctx, query, role, page, limit)
+	return users, int(total), err
+}
+
+func (s *AdminService) GetUser(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	user, err := s.UserRepo.FindUserByID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrUserNotFound
+	}
+	return user, err
+}
+
+// SetUserRole changes the role of the user in the database and in the claims of their next tokens
+func (s *AdminService) SetUserRole(ctx context.Context, admin *models.User, id primitive.ObjectID, role models.UserRole) (*models.User, error) {
+	if admin.ID == id {
+		return nil, apperrors.ErrAdminSelf
+	}
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.setRole(ctx, user, role)
+}
+
+// GrantAdmin makes the user of the email an admin without another admin, for the first admin of the platform. The
+// user must have signed in once.
+func (s *AdminService) GrantAdmin(ctx context.Context, email string) (*models.User, error) {
+	user, err := s.UserRepo.FindUserByEmail(ctx, email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.setRole(ctx, user, models.Admin)
+}
+
+func (s *AdminService) setRole(ctx context.Context, user *models.User, role models.UserRole) (*models.User, error) {
+	if user.Role == role {
+		return user, nil
+	}
+	if err := s.Auth.SetCustomUserClaims(ctx, user.Email, map[string]interface{}{"role": string(role)}); err != nil {
+		return nil, fmt.Errorf("failed to update auth role: %v", err)
+	}
+	if _, err := s.UserRepo.UpdateUser(ctx, user.ID, bson.M{"role": role}); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditUser, user.ID, bson.M{"role": user.Role}, bson.M{"role": role})
+	user.Role = role
+	return user, nil
+}
+
+// SuspendUser stops the user from using the API until the suspension expires or they are unsuspended, a banned user
+// is only unbanned by an admin. The user is signed out of every device.
+func (s *AdminService) SuspendUser(ctx context.Context, admin *models.User, id primitive.ObjectID, req *dto.AdminSuspendRequest) (*models.User, error) {
+	if admin.ID == id {
+		return nil, apperrors.ErrAdminSelf
+	}
+	now := time.Now()
+	suspension := &models.UserSuspension{Type: req.Type, Reason: req.Reason, SuspendedBy: admin.ID, SuspendedTime: now, ExpiresTime: req.ExpiresTime}
+	if suspension.Type == "" {
+		suspension.Type = models.SuspensionSuspended
+	}
+	if err := ValidateSuspension(suspension, now); err != nil {
+		return nil, err
+	}
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.UserRepo.UpdateUser(ctx, id, bson.M{"suspension": suspension}); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditUser, id, bson.M{"suspension": user.Suspension}, bson.M{"suspension": suspension})
+	user.Suspension = suspension
+
+	if err := s.RevokeSessions(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ValidateSuspension checks that a suspension expires after now and that a ban does not expire
+func ValidateSuspension(suspension *models.UserSuspension, now time.Time) error {
+	if suspension.ExpiresTime == nil {
+		return nil
+	}
+	if suspension.Type == models.SuspensionBanned || !suspension.ExpiresTime.After(now) {
+		return apperrors.ErrSuspensionExpiry
+	}
+	return nil
+}
+
+// RevokeSessions signs the user out of every device, the ID tokens of a sign in before now are refused
+func (s *AdminService) RevokeSessions(ctx context.Context, user *models.User) error {
+	if err := s.Auth.RevokeRefreshTokens(ctx, user.Email); err != nil && !errors.Is(err, authRepo.ErrUserNotFound) {
+		return fmt.Errorf("failed to revoke auth sessions: %v", err)
+	}
+	now := time.Now()
+	if _, err := s.UserRepo.UpdateUser(ctx, user.ID, bson.M{"tokens_valid_after": now}); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditUser, user.ID, bson.M{"tokens_valid_after": user.TokensValidAfter}, bson.M{"tokens_valid_after": now})
+	user.TokensValidAfter = &now
+	return nil
+}
+
+// RevokeUserSessions signs the user out of every device without suspending them
+func (s *AdminService) RevokeUserSessions(ctx context.Context, admin *models.User, id primitive.ObjectID) (*models.User, error) {
+	if admin.ID == id {
+		return nil, apperrors.ErrAdminSelf
+	}
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.RevokeSessions(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *AdminService) UnsuspendUser(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.UserRepo.UpdateUser(ctx, id, bson.M{"suspension": nil}); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditUser, id, bson.M{"suspension": user.Suspension}, bson.M{"suspension": nil})
+	user.Suspension = nil
+	return user, nil
+}
+
+func (s *AdminService) SearchAppointments(ctx context.Context, status models.AppointmentStatus, userId *primitive.ObjectID, page, limit int) ([]models.Appointment, int, error) {
+	items, total, err := s.AppointmentRepo.Search(ctx, status, userId, page, limit)
+	return items, int(total), err
+}
+
+func (s *AdminService) GetAppointment(ctx context.Context, id primitive.ObjectID) (*models.Appointment, error) {
+	appointment, err := s.AppointmentRepo.GetById(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrAppointmentNotFound
+	}
+	return appointment, err
+}
+
+// ForceAppointmentStatus moves the appointment to any status with the side effects of the usual transitions: only an
+// accepted appointment reserves the time of the photographer, a canceled or rejected one releases its promotion and a
+// completed one is paid out. The change and its reason are kept on the appointment.
+func (s *AdminService) ForceAppointmentStatus(ctx context.Context, admin *models.User, id primitive.ObjectID, req *dto.AdminAppointmentStatusRequest) (*models.Appointment, error) {
+	appointment, err := s.GetAppointment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if appointment.Status == req.Status {
+		return nil, apperrors.ErrAppointmentStatusUnchanged
+	}
+	before := AuditSnapshot(appointment)
+
+	// A completed appointment keeps the time it was accepted for
+	if req.Status != models.AppointmentCompleted {
+		busyTime, err := s.BusyTimeService.GetById(ctx, appointment.BusyTimeID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		busyTime.IsValid = req.Status == models.AppointmentAccepted
+		if err := s.BusyTimeService.UpdateValidStatus(ctx, busyTime); err != nil {
+			return nil, err
+		}
+	}
+	if appointment.Discount != nil && (req.Status == models.AppointmentCanceled || req.Status == models.AppointmentRejected) {
+		if err := s.PromotionService.Release(ctx, appointment.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	from := appointment.Status
+	appointment.StatusOverrides = append(appointment.StatusOverrides, models.AppointmentStatusOverride{
+		From:        from,
+		To:          req.Status,
+		Reason:      req.Reason,
+		ChangedBy:   admin.ID,
+		ChangedTime: time.Now(),
+	})
+	appointment.Status = req.Status
+	if _, err := s.AppointmentRepo.ReplaceAppointment(ctx, appointment); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditAppointment, appointment.ID, before, appointment)
+
+	if req.Status == models.AppointmentCompleted {
+		if _, err := s.PaymentJobService.Enqueue(ctx, appointment.ID); err != nil {
+			log.Println("Failed to enqueue payment job of appointment", appointment.ID.Hex(), err)
+		}
+	}
+	message := fmt.Sprintf("An admin changed the appointment of %s from %s to %s: %s", appointment.Package.Title, from, req.Status, req.Reason)
+	for _, userId := range []primitive.ObjectID{appointment.CustomerID, appointment.PhotographerID} {
+		s.NotificationService.Notify(ctx, userId, models.NotificationAppointmentStatusForced, "Appointment status changed", message, &appointment.ID)
+	}
+	return appointment, nil
+}
+
+func (s *AdminService) SearchPayments(ctx context.Context, status models.PaymentStatus, appointmentId *primitive.ObjectID, page, limit int) ([]models.Payment, int, error) {
+	items, total, err := s.PaymentRepo.Search(ctx, status, appointmentId, page, limit)
+	return items, int(total), err
+}
+
+func (s *AdminService) GetPayment(ctx context.Context, id primitive.ObjectID) (*models.Payment, error) {
+	payment, err := s.PaymentRepo.GetById(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrPaymentNotFound
+	}
+	return payment, err
+}
+
+// RemovePackage deletes the package with its photos, the photographer is told the reason
+func (s *AdminService) RemovePackage(ctx context.Context, id primitive.ObjectID, reason string) error {
+	pkg, err := s.PackageService.GetById(ctx, id.Hex())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return apperrors.ErrPackageNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.PackageService.DeleteOne(ctx, id.Hex()); err != nil {
+		return err
+	}
+	s.NotificationService.Notify(ctx, pkg.OwnerID, models.NotificationPackageRemoved, "Package removed",
+		fmt.Sprintf("Your package %s was removed by an admin: %s", pkg.Title, reason), nil)
+	return nil
+}
+
+// RemoveRating deletes the review, its author is told the reason
+func (s *AdminService) RemoveRating(ctx context.Context, id primitive.ObjectID, reason string) error {
+	rating, err := s.RatingRepo.GetById(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return apperrors.ErrRatingNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.RatingRepo.DeleteOne(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditRating, rating.ID, rating, nil)
+	s.NotificationService.Notify(ctx, rating.CustomerID, models.NotificationRatingRemoved, "Review removed",
+		"Your review was removed by an admin: "+reason, nil)
+	return nil
+}
+
+// GetAuditLog returns the history of the entity, the latest change first
+func (s *AdminService) GetAuditLog(ctx context.Context, entityType models.AuditEntityType, entityId primitive.ObjectID, page, limit int) ([]models.AuditLog, int, error) {
+	return s.Audit.GetByEntity(ctx, entityType, entityId, page, limit)
+}
+
+func (s *AdminService) GetStats(ctx context.Context) (*dto.AdminStatsResponse, error) {
+	roles, err := s.UserRepo.CountByRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	suspended, err := s.UserRepo.CountSuspended(ctx)
+	if err != nil {
+		return nil, err
+	}
+	packages, err := s.PackageService.Repo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := s.AppointmentRepo.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.PaymentRepo.GetPaidTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return AdminStatsOf(roles, suspended, packages, statuses, payments), nil
+}
+
+// AdminStatsOf puts the counts grouped by user role and appointment status into the statistics
+func AdminStatsOf(roles map[string]int64, suspended int64, packages int64, statuses map[string]int64, payments *models.PaymentTotals) *dto.AdminStatsResponse {
+	stats := &dto.AdminStatsResponse{
+		Users: dto.AdminUserStats{
+			Photographers: roles[string(models.Photographer)],
+			Customers:     roles[string(models.Customer)],
+			Guests:        roles[string(models.Guest)],
+			Admins:        roles[string(models.Admin)],
+			Suspended:     suspended,
+		},
+		Packages: packages,
+		Appointments: dto.AdminAppointmentStats{
+			Pending:   statuses[string(models.AppointmentPending)],
+			Accepted:  statuses[string(models.AppointmentAccepted)],
+			Rejected:  statuses[string(models.AppointmentRejected)],
+			Canceled:  statuses[string(models.AppointmentCanceled)],
+			Completed: statuses[string(models.AppointmentCompleted)],
+		},
+		Payments: *payments,
+	}
+	for _, count := range roles {
+		stats.Users.Total += count
+	}
+	for _, count := range statuses {
+		stats.Appointments.Total += count
+	}
+	return stats
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -12,33 +13,82 @@ import (
 
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type AppointmentService struct {
-	AppointmentRepo *repositories.AppointmentRepository
-	PackageRepo     *repositories.PackageRepository
-	SubpackageRepo  *repositories.SubpackageRepository
-	BusyTimeRepo    *repositories.BusyTimeRepository
-	UserRepo        *repositories.UserRepository
-	PaymentService  *PaymentService
+	AppointmentRepo   *repositories.AppointmentRepository
+	PackageRepo       *repositories.PackageRepository
+	SubpackageRepo    *repositories.SubpackageRepository
+	BusyTimeRepo      *repositories.BusyTimeRepository
+	UserRepo          *repositories.UserRepository
+	BusyTimeService   *BusyTimeService
+	PaymentService    *PaymentService
+	PaymentJobService *PaymentJobService
+	PromotionService  *PromotionService
+	Audit             *AuditService
+	// A customer must verify their phone before booking when it is set
+	RequireVerifiedPhone bool
 }
 
 // literally just getbyID and check if the user is authorized
 
 func NewAppointmentService(appointmentRepo *repositories.AppointmentRepository, packageRepo *repositories.PackageRepository, subpackageRepo *repositories.SubpackageRepository,
-	busyTimeRepo *repositories.BusyTimeRepository, userRepo *repositories.UserRepository, paymentService *PaymentService) *AppointmentService {
+	busyTimeRepo *repositories.BusyTimeRepository, userRepo *repositories.UserRepository, busyTimeService *BusyTimeService, paymentService *PaymentService, paymentJobService *PaymentJobService,
+	promotionService *PromotionService) *AppointmentService {
 	return &AppointmentService{
-		AppointmentRepo: appointmentRepo,
-		PackageRepo:     packageRepo,
-		SubpackageRepo:  subpackageRepo,
-		BusyTimeRepo:    busyTimeRepo,
-		UserRepo:        userRepo,
-		PaymentService:  paymentService,
+		AppointmentRepo:   appointmentRepo,
+		PackageRepo:       packageRepo,
+		SubpackageRepo:    subpackageRepo,
+		BusyTimeRepo:      busyTimeRepo,
+		UserRepo:          userRepo,
+		BusyTimeService:   busyTimeService,
+		PaymentService:    paymentService,
+		PaymentJobService: paymentJobService,
+		PromotionService:  promotionService,
 	}
 }
 
+// CheckCanBook refuses a customer without a verified phone when the phone is required for booking
+func (s *AppointmentService) CheckCanBook(user *models.User) error {
+	if s.RequireVerifiedPhone && !user.IsPhoneVerified() {
+		return apperrors.ErrPhoneNotVerified
+	}
+	return nil
+}
+
+// CheckBookable refuses to book a subpackage of a photographer whose packages are not listed
+func (s *AppointmentService) CheckBookable(ctx context.Context, subpackageId primitive.ObjectID) error {
+	subpackage, err := s.SubpackageRepo.GetById(ctx, subpackageId.Hex())
+	if isNotFound(err) {
+		return apperrors.ErrSubpackageNotFound
+	}
+	if err != nil {
+		return err
+	}
+	pkg, err := s.PackageRepo.GetById(ctx, subpackage.PackageID.Hex())
+	if isNotFound(err) {
+		return apperrors.ErrSubpackageNotFound
+	}
+	if err != nil {
+		return err
+	}
+	photographer, err := s.UserRepo.FindUserByID(ctx, pkg.OwnerID)
+	if isNotFound(err) {
+		return apperrors.ErrSubpackageNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !photographer.IsListed() {
+		return apperrors.ErrPhotographerNotVerified
+	}
+	return nil
+}
+
 func (s *AppointmentService) GetAllAppointment(ctx context.Context, user *models.User) ([]models.Appointment, error) {
 	return s.AppointmentRepo.GetAll(ctx, user.ID, user.Role)
 }
@@ -96,6 +146,7 @@ func (s *AppointmentService) GetAppointmentDetailById(ctx context.Context, user
 		EndTime:          busyTime.EndTime,
 		Status:           appointment.Status,
 		Location:         appointment.Location,
+		Discount:         appointment.Discount,
 	}
 	return detail, nil
 }
@@ -181,6 +232,7 @@ func (s *AppointmentService) GetFilteredAppointments(ctx context.Context, user *
 			Status:         item.Status,
 			Location:       item.Location,
 			Price:          item.Price,
+			Discount:       item.Discount,
 		}
 
 		appointments = append(appointments, appointmentResponse)
@@ -324,13 +376,20 @@ func matchesFilters(detail *dto.AppointmentDetail, filters map[string]string) bo
 }
 
 func (s *AppointmentService) GetAppointmentById(ctx context.Context, user *models.User, appointmentId primitive.ObjectID) (*models.Appointment, error) {
+	return s.GetAuthorized(ctx, user, appointmentId, policies.ActionRead)
+}
+
+// GetAuthorized returns the appointment when the user can take the action on it
+func (s *AppointmentService) GetAuthorized(ctx context.Context, user *models.User, appointmentId primitive.ObjectID, action policies.Action) (*models.Appointment, error) {
 	appointment, err := s.AppointmentRepo.GetById(ctx, appointmentId)
+	if isNotFound(err) {
+		return nil, apperrors.ErrAppointmentNotFound
+	}
 	if err != nil {
-		return nil, apperrors.ErrBadRequest
+		return nil, err
 	}
-
-	if appointment.CustomerID != user.ID && appointment.PhotographerID != user.ID {
-		return nil, apperrors.ErrUnauthorized
+	if err := policies.Authorize(user, action, policies.Appointment(appointment)); err != nil {
+		return nil, err
 	}
 	return appointment, nil
 }
@@ -347,19 +406,101 @@ func (s *AppointmentService) CreateOneAppointment(ctx context.Context, user *mod
 	}
 	appointment := req.ToModel(user, pkg, subpackage, busyTime)
 
-	return s.AppointmentRepo.CreateAppointment(ctx, appointment)
+	// Apply the promotion code, the discount is snapshotted on the appointment
+	if req.PromoCode != nil && strings.TrimSpace(*req.PromoCode) != "" {
+		if err := s.PromotionService.Redeem(ctx, user, *req.PromoCode, appointment); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.AppointmentRepo.CreateAppointment(ctx, appointment)
+	if err != nil {
+		if appointment.Discount != nil {
+			_ = s.PromotionService.Release(ctx, appointment.ID)
+		}
+		return created, err
+	}
+	s.Audit.Record(ctx, models.AuditAppointment, created.ID, nil, created)
+	return created, nil
 }
 
 func (s *AppointmentService) UpdateAppointmentStatus(ctx context.Context, user *models.User, appointment *models.Appointment, req *dto.AppointmentUpdateStatusRequest) (*models.Appointment, error) {
+	before := AuditSnapshot(appointment)
 	appointment.Status = req.Status
-	return s.AppointmentRepo.ReplaceAppointment(ctx, appointment)
+
+	// The promotion can be used again when the appointment does not go ahead
+	if appointment.Discount != nil && (req.Status == models.AppointmentCanceled || req.Status == models.AppointmentRejected) {
+		if err := s.PromotionService.Release(ctx, appointment.ID); err != nil {
+			return nil, err
+		}
+	}
+	updated, err := s.AppointmentRepo.ReplaceAppointment(ctx, appointment)
+	if err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditAppointment, appointment.ID, before, updated)
+	return updated, nil
 }
 
-func (s *AppointmentService) DeleteAppointment(ctx context.Context, appointmentId primitive.ObjectID, user *models.User) error {
-	if _, err := s.GetAppointmentById(ctx, user, appointmentId); err != nil {
+// DeleteAppointment cancels the appointment on behalf of the admin instead of removing it, so that nothing refers to a
+// missing appointment: the time of the photographer is freed, the promotion can be used again and the open checkout
+// of its payment is expired. A paid or completed appointment, which may have a payout, a payment job or a gallery,
+// is refused. An appointment that already did not go ahead is left as it is.
+func (s *AppointmentService) DeleteAppointment(ctx context.Context, user *models.User, appointmentId primitive.ObjectID) error {
+	appointment, err := s.GetAuthorized(ctx, user, appointmentId, policies.ActionDelete)
+	if err != nil {
+		return err
+	}
+	switch appointment.Status {
+	case models.AppointmentCompleted:
+		return apperrors.ErrAppointmentDeleteCompleted
+	case models.AppointmentCanceled, models.AppointmentRejected:
+		return nil
+	}
+
+	payment, err := s.PaymentService.GetPaymentByAppointmentId(ctx, appointment.ID)
+	if err != nil && !isNotFound(err) {
 		return err
 	}
-	return s.AppointmentRepo.DeleteAppointment(ctx, appointmentId)
+	if payment != nil {
+		if err := s.PaymentService.ExpireCheckout(ctx, payment); err != nil {
+			if errors.Is(err, apperrors.ErrCheckoutCompleted) {
+				return apperrors.ErrAppointmentDeletePaid
+			}
+			return err
+		}
+	}
+
+	busyTime, err := s.BusyTimeService.GetById(ctx, appointment.BusyTimeID.Hex())
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if busyTime != nil && busyTime.IsValid {
+		busyTime.IsValid = false
+		if err := s.BusyTimeService.UpdateValidStatus(ctx, busyTime); err != nil {
+			return err
+		}
+	}
+	if appointment.Discount != nil {
+		if err := s.PromotionService.Release(ctx, appointment.ID); err != nil {
+			return err
+		}
+	}
+
+	before := AuditSnapshot(appointment)
+	appointment.StatusOverrides = append(appointment.StatusOverrides, models.AppointmentStatusOverride{
+		From:        appointment.Status,
+		To:          models.AppointmentCanceled,
+		Reason:      "Deleted by an admin",
+		ChangedBy:   user.ID,
+		ChangedTime: time.Now(),
+	})
+	appointment.Status = models.AppointmentCanceled
+	if _, err := s.AppointmentRepo.ReplaceAppointment(ctx, appointment); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditAppointment, appointment.ID, before, appointment)
+	return nil
 }
 
 func (s *AppointmentService) AutoUpdateAppointmentStatus(ctx context.Context) error {
@@ -377,7 +518,11 @@ func (s *AppointmentService) AutoUpdateAppointmentStatus(ctx context.Context) er
 	)
 
 	go func() {
-		s.AppointmentRepo.UpdateCanceledAppointment(ctx, currentTime)
+		canceledIds, _ := s.AppointmentRepo.UpdateCanceledAppointment(ctx, currentTime)
+		for _, id := range canceledIds {
+			s.PromotionService.Release(ctx, id)
+			s.Audit.Record(ctx, models.AuditAppointment, id, bson.M{"status": models.AppointmentPending}, bson.M{"status": models.AppointmentCanceled})
+		}
 	}()
 
 	// filter only end_time is less than current time and status is "Accepted"
@@ -385,7 +530,14 @@ func (s *AppointmentService) AutoUpdateAppointmentStatus(ctx context.Context) er
 	go func() {
 		updatedIds, _ := s.AppointmentRepo.UpdateCompletedAppointment(ctx, currentTime)
 		for _, id := range updatedIds {
-			s.PaymentService.CreatePayment(ctx, id, "", "")
+			s.Audit.Record(ctx, models.AuditAppointment, id, bson.M{"status": models.AppointmentAccepted}, bson.M{"status": models.AppointmentCompleted})
+			if _, err := s.PaymentJobService.Enqueue(ctx, id); err != nil {
+				fmt.Println("Error enqueueing payment job: ", err)
+			}
+		}
+		// Also retries the jobs that failed in previous runs
+		if err := s.PaymentJobService.RunDue(ctx); err != nil {
+			fmt.Println("Error running payment jobs: ", err)
 		}
 	}()
 
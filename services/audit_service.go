@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/encryption"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditRedacted replaces the values of the encrypted fields in the audit log
+const AuditRedacted = "[redacted]"
+
+// DefaultAuditLogRetention is how long the entries of the audit log are kept
+const DefaultAuditLogRetention = 365 * 24 * time.Hour
+
+type auditRequestKey struct{}
+
+// AuditService records the changes of the entities with the request that made them, the services that change an
+// entity hold it in their Audit field
+type AuditService struct {
+	Repo *repositories.AuditLogRepository
+	// Retention is how long the entries are kept, 0 keeps them forever
+	Retention time.Duration
+}
+
+func NewAuditService(repo *repositories.AuditLogRepository, retention time.Duration) *AuditService {
+	return &AuditService{Repo: repo, Retention: retention}
+}
+
+// WithAuditRequest attaches the request to the context, the changes made with the context are recorded as made by it
+func WithAuditRequest(ctx context.Context, request models.AuditRequest) context.Context {
+	return context.WithValue(ctx, auditRequestKey{}, request)
+}
+
+// AuditRequestFrom returns the request attached to the context, the request context is used for a gin context
+func AuditRequestFrom(ctx context.Context) (models.AuditRequest, bool) {
+	if c, ok := ctx.(*gin.Context); ok && c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	request, ok := ctx.Value(auditRequestKey{}).(models.AuditRequest)
+	return request, ok
+}
+
+// Record adds the change of the entity to the audit log. before is nil for a creation and after is nil for a
+// deletion, an entity that is changed in place has to be snapshotted with AuditSnapshot before the change. An update
+// that changes no field is not recorded. A failure is only logged so it never fails the change itself.
+func (s *AuditService) Record(ctx context.Context, entityType models.AuditEntityType, entityId primitive.ObjectID, before, after interface{}) {
+	if s == nil || s.Repo == nil {
+		return
+	}
+	beforeDoc, afterDoc := AuditSnapshot(before), AuditSnapshot(after)
+	entry := &models.AuditLog{
+		EntityType:  entityType,
+		EntityID:    entityId,
+		Action:      models.AuditUpdate,
+		Changes:     AuditDiff(beforeDoc, afterDoc),
+		CreatedTime: time.Now(),
+	}
+	switch {
+	case beforeDoc == nil && afterDoc == nil:
+		return
+	case beforeDoc == nil:
+		entry.Action = models.AuditCreate
+	case afterDoc == nil:
+		entry.Action = models.AuditDelete
+	case len(entry.Changes) == 0:
+		return
+	}
+	if request, ok := AuditRequestFrom(ctx); ok {
+		entry.ActorID = request.ActorID
+		entry.ActorRole = request.ActorRole
+		entry.Method = request.Method
+		entry.Endpoint = request.Endpoint
+		entry.RequestID = request.RequestID
+		entry.IP = request.IP
+	}
+	if err := s.Repo.Create(context.WithoutCancel(ctx), entry); err != nil {
+		log.Println("Failed to record audit log of", entityType, entityId.Hex(), err)
+	}
+}
+
+// GetByEntity returns the history of the entity, the latest change first
+func (s *AuditService) GetByEntity(ctx context.Context, entityType models.AuditEntityType, entityId primitive.ObjectID, page, limit int) ([]models.AuditLog, int, error) {
+	items, total, err := s.Repo.GetByEntity(ctx, entityType, entityId, page, limit)
+	return items, int(total), err
+}
+
+// EraseUser removes the personal data of a deleted user from the audit log: the history of the user and of their
+// verifications, the locations in the history of the appointments they booked and the IP of the requests they made
+func (s *AuditService) EraseUser(ctx context.Context, userId primitive.ObjectID, verificationIds []primitive.ObjectID, bookedAppointmentIds []primitive.ObjectID) error {
+	if s == nil || s.Repo == nil {
+		return nil
+	}
+	if _, err := s.Repo.DeleteByEntities(ctx, models.AuditUser, []primitive.ObjectID{userId}); err != nil {
+		return err
+	}
+	if _, err := s.Repo.DeleteByEntities(ctx, models.AuditVerification, verificationIds); err != nil {
+		return err
+	}
+	if _, err := s.Repo.RedactEntityFields(ctx, models.AuditAppointment, bookedAppointmentIds, []string{"location"}, AuditRedacted); err != nil {
+		return err
+	}
+	return s.Repo.RemoveActorIP(ctx, userId)
+}
+
+// PurgeExpired removes the entries older than the retention
+func (s *AuditService) PurgeExpired(ctx context.Context) {
+	if s.Retention <= 0 {
+		return
+	}
+	count, err := s.Repo.DeleteBefore(ctx, time.Now().Add(-s.Retention))
+	if err != nil {
+		log.Println("Failed to purge the audit log:", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Purged %d audit log entries", count)
+	}
+}
+
+// AuditSnapshot is the document of the entity as it is stored, it is nil for a nil entity
+func AuditSnapshot(v interface{}) bson.M {
+	if v == nil {
+		return nil
+	}
+	if doc, ok := v.(bson.M); ok {
+		return redactEncryptedValues(doc)
+	}
+	if value := reflect.ValueOf(v); value.Kind() == reflect.Ptr && value.IsNil() {
+		return nil
+	}
+	data, err := bson.Marshal(v)
+	if err != nil {
+		log.Println("Failed to snapshot", reflect.TypeOf(v), "for the audit log:", err)
+		return nil
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		log.Println("Failed to snapshot", reflect.TypeOf(v), "for the audit log:", err)
+		return nil
+	}
+	redactEncryptedFields(reflect.TypeOf(v), doc)
+	return doc
+}
+
+// auditRedactionKey only lives as long as the process, the digests compare the snapshots of a single change
+var auditRedactionKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalln("Failed to generate the audit redaction key:", err)
+	}
+	return key
+}()
+
+// auditRedactedValue stands for the value of an encrypted field in a snapshot. Equal plain texts have equal digests,
+// so a field is only listed as changed when its value changed although every encryption of it differs, and it is
+// stored as AuditRedacted.
+type auditRedactedValue struct {
+	digest string
+}
+
+func (v auditRedactedValue) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(AuditRedacted)
+}
+
+func (v auditRedactedValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(AuditRedacted)
+}
+
+// redactEncryptedFields replaces the encrypted fields of the model in its snapshot, the audit log never holds their
+// plain text nor a ciphertext of a key that may be rotated away
+func redactEncryptedFields(t reflect.Type, doc bson.M) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for field := range EncryptedFields(reflect.Zero(t).Interface()) {
+		value, ok := doc[field].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if plaintext, err := encryption.DecryptField(value); err == nil {
+			value = plaintext
+		}
+		doc[field] = redactedValue(value)
+	}
+}
+
+// redactEncryptedValues copies the partial document of the changed fields and redacts its values of an encrypted type
+func redactEncryptedValues(doc bson.M) bson.M {
+	redacted := make(bson.M, len(doc))
+	for field, value := range doc {
+		switch v := value.(type) {
+		case models.EncryptedString:
+			if v != "" {
+				value = redactedValue(string(v))
+			}
+		case models.DeterministicString:
+			if v != "" {
+				value = redactedValue(string(v))
+			}
+		}
+		redacted[field] = value
+	}
+	return redacted
+}
+
+func redactedValue(plaintext string) auditRedactedValue {
+	mac := hmac.New(sha256.New, auditRedactionKey)
+	mac.Write([]byte(plaintext))
+	return auditRedactedValue{digest: string(mac.Sum(nil))}
+}
+
+// AuditDiff lists the top level fields that differ between the documents, sorted by name
+func AuditDiff(before, after bson.M) []models.AuditChange {
+	fields := make(map[string]struct{}, len(before)+len(after))
+	for field := range before {
+		fields[field] = struct{}{}
+	}
+	for field := range after {
+		fields[field] = struct{}{}
+	}
+
+	changes := []models.AuditChange{}
+	for field := range fields {
+		oldValue, newValue := before[field], after[field]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, models.AuditChange{Field: field, Before: oldValue, After: newValue})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// isNotFound reports whether the lookup failed because no document has the id, an id that cannot be parsed does not
+// name any document either
+func isNotFound(err error) bool {
+	var invalidByte hex.InvalidByteError
+	return errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, primitive.ErrInvalidHex) || errors.As(err, &invalidByte)
+}
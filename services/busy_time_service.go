@@ -15,6 +15,7 @@ type BusyTimeService struct {
 	Repository     *repositories.BusyTimeRepository
 	SubpackageRepo *repositories.SubpackageRepository
 	PackageRepo    *repositories.PackageRepository
+	Audit          *AuditService
 }
 
 func NewBusyTimeService(repository *repositories.BusyTimeRepository, subpackageRepo *repositories.SubpackageRepository, packageRepo *repositories.PackageRepository) *BusyTimeService {
@@ -45,7 +46,11 @@ func (s *BusyTimeService) CreateFromUser(ctx context.Context, request *dto.BusyT
 	if !isAvailable {
 		return nil, apperrors.ErrTimeOverlapped
 	}
-	return nil, s.Repository.Create(ctx, model)
+	if err := s.Repository.Create(ctx, model); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditBusyTime, model.ID, nil, model)
+	return nil, nil
 }
 
 // func (s *BusyTimeService) CreateForUpdate(ctx context.Context, request *dto.BusyTimeStrictRequest, oldID, photographerId primitive.ObjectID) error {
@@ -82,7 +87,11 @@ func (s *BusyTimeService) CreateFromSubpackage(ctx context.Context, request *dto
 	if !isAvailable {
 		return nil, apperrors.ErrTimeOverlapped
 	}
-	return model, s.Repository.Create(ctx, model)
+	if err := s.Repository.Create(ctx, model); err != nil {
+		return model, err
+	}
+	s.Audit.Record(ctx, models.AuditBusyTime, model.ID, nil, model)
+	return model, nil
 }
 
 func (s *BusyTimeService) UpdateValidStatus(ctx context.Context, busyTime *models.BusyTime) error {
@@ -95,11 +104,27 @@ func (s *BusyTimeService) UpdateValidStatus(ctx context.Context, busyTime *model
 			return apperrors.ErrTimeOverlapped
 		}
 	}
-	return s.Repository.UpdateOne(ctx, busyTime)
+	before, err := s.Repository.GetById(ctx, busyTime.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if err := s.Repository.UpdateOne(ctx, busyTime); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditBusyTime, busyTime.ID, before, busyTime)
+	return nil
 }
 
 func (s *BusyTimeService) Delete(ctx context.Context, id string) error {
-	return s.Repository.DeleteOne(ctx, id)
+	before, err := s.Repository.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.Repository.DeleteOne(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditBusyTime, before.ID, before, nil)
+	return nil
 }
 
 func (s *BusyTimeService) IsPhotographerAvailable(ctx context.Context, photographerId primitive.ObjectID, startTime, endTime time.Time, ignoreBusyTime *primitive.ObjectID) (bool, error) {
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Bualoi-s-Dev/backend/encryption"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	encryptedStringType     = reflect.TypeOf(models.EncryptedString(""))
+	deterministicStringType = reflect.TypeOf(models.DeterministicString(""))
+)
+
+// EncryptedCollection is a collection whose Fields are encrypted, the value tells whether the field is deterministic
+type EncryptedCollection struct {
+	Name   string
+	Repo   *repositories.EncryptedFieldRepository
+	Fields map[string]bool
+}
+
+// FieldRotationReport counts per collection the documents scanned, the ones re-encrypted and the ones changed while
+// they were re-encrypted, which are left for the next run
+type FieldRotationReport struct {
+	PrimaryKeyID string                    `json:"primaryKeyId"`
+	DryRun       bool                      `json:"dryRun"`
+	Collections  []FieldRotationCollection `json:"collections"`
+	// AuditRedacted counts the audit log entries that still held a value of an encrypted field
+	AuditRedacted int64    `json:"auditRedacted"`
+	Errors        []string `json:"errors"`
+}
+
+type FieldRotationCollection struct {
+	Name      string `json:"name"`
+	Scanned   int    `json:"scanned"`
+	Rotated   int    `json:"rotated"`
+	Conflicts int    `json:"conflicts"`
+}
+
+// AuditedEntity is an entity type of the audit log whose Fields are encrypted
+type AuditedEntity struct {
+	EntityType models.AuditEntityType
+	Fields     map[string]bool
+}
+
+// FieldEncryptionService re-encrypts the encrypted fields with the primary key, after a new key is added or to encrypt
+// the values stored before their field was encrypted. The audit log is not re-encrypted, the values its entries recorded
+// before AuditSnapshot redacted them are redacted instead.
+type FieldEncryptionService struct {
+	Collections     []EncryptedCollection
+	AuditLogRepo    *repositories.AuditLogRepository
+	AuditedEntities []AuditedEntity
+}
+
+func NewFieldEncryptionService(collections ...EncryptedCollection) *FieldEncryptionService {
+	return &FieldEncryptionService{Collections: collections}
+}
+
+// EncryptedFields lists the encrypted fields of the model by their BSON name, true for the deterministic ones. Only the
+// top level fields are listed.
+func EncryptedFields(model interface{}) map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(model)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType != encryptedStringType && fieldType != deterministicStringType {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("bson"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = fieldType == deterministicStringType
+	}
+	return fields
+}
+
+// Rotate re-encrypts every value not encrypted as the primary key would, a value it cannot decrypt is reported and left
+// as it is
+func (s *FieldEncryptionService) Rotate(ctx context.Context, dryRun bool) (*FieldRotationReport, error) {
+	keyring := encryption.FieldKeyring()
+	if keyring == nil {
+		return nil, errors.New("no field encryption key is set")
+	}
+
+	report := &FieldRotationReport{PrimaryKeyID: keyring.PrimaryID(), DryRun: dryRun, Collections: []FieldRotationCollection{}, Errors: []string{}}
+	for _, collection := range s.Collections {
+		result := FieldRotationCollection{Name: collection.Name}
+		fields := make([]string, 0, len(collection.Fields))
+		for field := range collection.Fields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		err := collection.Repo.Each(ctx, fields, func(doc bson.M) error {
+			result.Scanned++
+			id, _ := doc["_id"].(primitive.ObjectID)
+			expected, updates, err := RotatedFields(keyring, doc, collection.Fields)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s %s: %v", collection.Name, id.Hex(), err))
+				return nil
+			}
+			if len(updates) == 0 {
+				return nil
+			}
+			if dryRun {
+				result.Rotated++
+				return nil
+			}
+			written, err := collection.Repo.SetFields(ctx, id, expected, updates)
+			if err != nil {
+				return err
+			}
+			if written {
+				result.Rotated++
+			} else {
+				result.Conflicts++
+			}
+			return nil
+		})
+		report.Collections = append(report.Collections, result)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	redacted, err := s.RedactAuditLog(ctx, dryRun)
+	report.AuditRedacted = redacted
+	return report, err
+}
+
+// RedactAuditLog replaces the values of the encrypted fields in the audit log entries recorded before they were
+// redacted, plain text or a ciphertext of a key that may be removed, and returns how many entries had one
+func (s *FieldEncryptionService) RedactAuditLog(ctx context.Context, dryRun bool) (int64, error) {
+	if s.AuditLogRepo == nil {
+		return 0, nil
+	}
+	var total int64
+	for _, entity := range s.AuditedEntities {
+		fields := make([]string, 0, len(entity.Fields))
+		for field := range entity.Fields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		count, err := s.AuditLogRepo.RedactFields(ctx, entity.EntityType, fields, AuditRedacted, dryRun)
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// RotatedFields returns the stored values of the document to replace and their re-encrypted values, the values in plain
+// text are encrypted
+func RotatedFields(keyring *encryption.Keyring, doc bson.M, fields map[string]bool) (bson.M, bson.M, error) {
+	expected, updates := bson.M{}, bson.M{}
+	for field, deterministic := range fields {
+		value, ok := doc[field].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if !deterministic && keyring.IsCurrent(value) {
+			continue
+		}
+		plaintext, err := keyring.Decrypt(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", field, err)
+		}
+		encrypted, err := keyring.Encrypt(plaintext, deterministic)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", field, err)
+		}
+		if encrypted != value {
+			expected[field], updates[field] = value, encrypted
+		}
+	}
+	return expected, updates, nil
+}
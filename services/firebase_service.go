@@ -3,22 +3,35 @@ package services
 import (
 	"firebase.google.com/go/auth"
 	"github.com/Bualoi-s-Dev/backend/dto"
+	authRepo "github.com/Bualoi-s-Dev/backend/repositories/auth"
 	repositories "github.com/Bualoi-s-Dev/backend/repositories/firebase"
 	"github.com/gin-gonic/gin"
 )
 
 type FirebaseService struct {
 	Repo *repositories.FirebaseRepository
+	// Local signs in and registers the accounts instead of Firebase when the local verifier is used
+	Local *authRepo.LocalVerifier
 }
 
-func NewFirebaseService(repo *repositories.FirebaseRepository) *FirebaseService {
-	return &FirebaseService{Repo: repo}
+func NewFirebaseService(repo *repositories.FirebaseRepository, local *authRepo.LocalVerifier) *FirebaseService {
+	return &FirebaseService{Repo: repo, Local: local}
 }
 
 func (s *FirebaseService) Login(c *gin.Context, req dto.AuthUserCredentials) (string, error) {
+	if s.Local != nil {
+		return s.Local.SignIn(req.Email, req.Password)
+	}
 	return s.Repo.LoginUser(c, req)
 }
 
 func (s *FirebaseService) Register(c *gin.Context, req dto.AuthUserCredentials) (*auth.UserRecord, error) {
+	if s.Local != nil {
+		uid, err := s.Local.Register(req.Email, req.Password)
+		if err != nil {
+			return nil, err
+		}
+		return &auth.UserRecord{UserInfo: &auth.UserInfo{UID: uid, Email: req.Email, ProviderID: authRepo.LocalProviderID}}, nil
+	}
 	return s.Repo.RegisterUser(c, req)
 }
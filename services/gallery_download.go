@@ -0,0 +1,179 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// GalleryPrebuildPhotoCount and GalleryPrebuildSize make a gallery large enough to keep a pre-built archive,
+	// which is served from storage with range requests so an interrupted download can be resumed
+	GalleryPrebuildPhotoCount       = 100
+	GalleryPrebuildSize       int64 = 1 << 30
+)
+
+// GalleryArchiveName is the file name of the ZIP of the gallery photos
+func GalleryArchiveName(gallery *models.Gallery) string {
+	return fmt.Sprintf("photos_%s.zip", gallery.AppointmentID.Hex())
+}
+
+// IsLargeGallery tells whether the gallery should be downloaded from a pre-built archive
+func IsLargeGallery(gallery *models.Gallery) bool {
+	if len(gallery.Photos) >= GalleryPrebuildPhotoCount {
+		return true
+	}
+	var size int64
+	for _, photo := range gallery.Photos {
+		size += photo.Size
+	}
+	return size >= GalleryPrebuildSize
+}
+
+// GalleryFingerprint identifies the photos of the gallery, an archive is stale once the photos change
+func GalleryFingerprint(photos []models.GalleryPhoto) string {
+	hash := sha256.New()
+	for _, photo := range photos {
+		fmt.Fprintf(hash, "%s\n%s\n", photo.ID.Hex(), photo.Key)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// GalleryArchiveEntryNames names the photos inside the archive, a repeated file name gets a number like "IMG_0001 (2).jpg"
+func GalleryArchiveEntryNames(photos []models.GalleryPhoto) []string {
+	names := make([]string, len(photos))
+	used := map[string]bool{}
+	for i, photo := range photos {
+		name := GalleryFileName(photo.FileName, i+1, photo.ContentType)
+		ext := path.Ext(name)
+		base := strings.TrimSuffix(name, ext)
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s (%d)%s", base, n, ext)
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
+// WriteArchive streams the photos of the gallery into a ZIP one by one, the photos are stored without compression
+// as they are compressed already, so only one photo is read at a time
+func (s *GalleryService) WriteArchive(w io.Writer, gallery *models.Gallery) error {
+	archive := zip.NewWriter(w)
+	names := GalleryArchiveEntryNames(gallery.Photos)
+	for i, photo := range gallery.Photos {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     names[i],
+			Method:   zip.Store,
+			Modified: photo.CreatedTime,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.copyObject(entry, photo.Key); err != nil {
+			return fmt.Errorf("failed to add %s to the archive, %v", photo.Key, err)
+		}
+	}
+	return archive.Close()
+}
+
+func (s *GalleryService) copyObject(w io.Writer, key string) error {
+	body, err := s.S3Service.OpenObject(key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(w, body)
+	return err
+}
+
+// GetDownloadArchiveURL returns the signed URL of the pre-built archive when it matches the photos, otherwise
+// the archive of a large gallery is built in the background for the next download and false is returned
+func (s *GalleryService) GetDownloadArchiveURL(gallery *models.Gallery) (string, bool, error) {
+	archive := gallery.DownloadArchive
+	if archive != nil && archive.Fingerprint == GalleryFingerprint(gallery.Photos) {
+		url, err := s.S3Service.PresignGet(archive.Key, GalleryURLExpireDuration, GalleryArchiveName(gallery))
+		if err != nil {
+			return "", false, err
+		}
+		return url, true, nil
+	}
+	if IsLargeGallery(gallery) {
+		s.BuildDownloadArchive(gallery)
+	}
+	return "", false, nil
+}
+
+// BuildDownloadArchive writes the archive of the gallery into storage in the background, while it is written
+// the gallery is downloaded on the fly, a gallery is built once at a time
+func (s *GalleryService) BuildDownloadArchive(gallery *models.Gallery) {
+	if _, building := s.building.LoadOrStore(gallery.ID, true); building {
+		return
+	}
+	snapshot := *gallery
+	snapshot.Photos = append([]models.GalleryPhoto{}, gallery.Photos...)
+
+	go func() {
+		defer s.building.Delete(snapshot.ID)
+		if err := s.buildDownloadArchive(context.Background(), &snapshot); err != nil {
+			log.Println("Failed to build the archive of gallery", snapshot.ID.Hex(), err)
+		}
+	}()
+}
+
+func (s *GalleryService) buildDownloadArchive(ctx context.Context, gallery *models.Gallery) error {
+	key := fmt.Sprintf("gallery/%s/archive_%s.zip", gallery.AppointmentID.Hex(), primitive.NewObjectID().Hex())
+	reader, writer := io.Pipe()
+	counter := &countingWriter{w: writer}
+	go func() {
+		writer.CloseWithError(s.WriteArchive(counter, gallery))
+	}()
+	if err := s.S3Service.PutObjectStream(key, reader, "application/zip"); err != nil {
+		reader.CloseWithError(err)
+		_ = s.S3Service.DeleteObject(key)
+		return err
+	}
+
+	archive := &models.GalleryDownloadArchive{
+		Key:         key,
+		Fingerprint: GalleryFingerprint(gallery.Photos),
+		Size:        counter.n,
+		CreatedTime: time.Now(),
+	}
+	if err := s.Repo.SetDownloadArchive(ctx, gallery.ID, archive); err != nil {
+		_ = s.S3Service.DeleteObject(key)
+		return err
+	}
+	s.deleteDownloadArchive(gallery.DownloadArchive)
+	return nil
+}
+
+func (s *GalleryService) deleteDownloadArchive(archive *models.GalleryDownloadArchive) {
+	if archive == nil {
+		return
+	}
+	if err := s.S3Service.DeleteObject(archive.Key); err != nil {
+		log.Println("Failed to delete gallery archive", archive.Key, err)
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// DefaultGalleryExpireDuration is how long a gallery stays downloadable after it is created
+	DefaultGalleryExpireDuration = 90 * 24 * time.Hour
+	// GalleryURLExpireDuration is how long a signed photo URL is valid
+	GalleryURLExpireDuration = time.Hour
+	GalleryArchivePrefix     = "archive/"
+)
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type GalleryService struct {
+	Repo          *repositories.GalleryRepository
+	PaymentRepo   *repositories.PaymentRepository
+	UploadService *UploadService
+	S3Service     *S3Service
+
+	// building holds the ids of the galleries whose archive is being built
+	building sync.Map
+}
+
+func NewGalleryService(repo *repositories.GalleryRepository, paymentRepo *repositories.PaymentRepository, uploadService *UploadService, s3Service *S3Service) *GalleryService {
+	return &GalleryService{Repo: repo, PaymentRepo: paymentRepo, UploadService: uploadService, S3Service: s3Service}
+}
+
+func (s *GalleryService) CreateGallery(ctx context.Context, user *models.User, appointment *models.Appointment) (*models.Gallery, error) {
+	if appointment.PhotographerID != user.ID {
+		return nil, apperrors.ErrForbidden
+	}
+	if appointment.Status != models.AppointmentCompleted {
+		return nil, apperrors.ErrGalleryAppointmentNotCompleted
+	}
+	if _, err := s.Repo.GetByAppointmentId(ctx, appointment.ID); err == nil {
+		return nil, apperrors.ErrGalleryExists
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	now := time.Now()
+	item := &models.Gallery{
+		ID:             primitive.NewObjectID(),
+		AppointmentID:  appointment.ID,
+		PhotographerID: appointment.PhotographerID,
+		CustomerID:     appointment.CustomerID,
+		Status:         models.GalleryDraft,
+		Photos:         []models.GalleryPhoto{},
+		ExpireTime:     now.Add(DefaultGalleryExpireDuration),
+		CreatedTime:    now,
+	}
+	if err := s.Repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetGallery returns the gallery of the appointment if the user can access it,
+// the customer can only see it once it is delivered and the appointment is paid
+func (s *GalleryService) GetGallery(ctx context.Context, user *models.User, appointment *models.Appointment) (*models.Gallery, error) {
+	gallery, err := s.Repo.GetByAppointmentId(ctx, appointment.ID)
+	if err == mongo.ErrNoDocuments {
+		return nil, apperrors.ErrGalleryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch user.ID {
+	case gallery.PhotographerID:
+		return gallery, nil
+	case gallery.CustomerID:
+		if gallery.Status == models.GalleryDraft {
+			return nil, apperrors.ErrGalleryNotAvailable
+		}
+		paid, err := s.IsAppointmentPaid(ctx, appointment.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !paid {
+			return nil, apperrors.ErrGalleryNotAvailable
+		}
+		return gallery, nil
+	default:
+		return nil, apperrors.ErrForbidden
+	}
+}
+
+func (s *GalleryService) IsAppointmentPaid(ctx context.Context, appointmentId primitive.ObjectID) (bool, error) {
+	payment, err := s.PaymentRepo.GetByAppointmentID(ctx, appointmentId)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return payment.Customer.Status == models.Paid, nil
+}
+
+// GetOwnedGallery returns the gallery for the photographer to change, archived galleries cannot be changed
+func (s *GalleryService) GetOwnedGallery(ctx context.Context, user *models.User, appointment *models.Appointment) (*models.Gallery, error) {
+	if appointment.PhotographerID != user.ID {
+		return nil, apperrors.ErrForbidden
+	}
+	gallery, err := s.Repo.GetByAppointmentId(ctx, appointment.ID)
+	if err == mongo.ErrNoDocuments {
+		return nil, apperrors.ErrGalleryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if gallery.Status == models.GalleryArchived {
+		return nil, apperrors.ErrGalleryArchived
+	}
+	return gallery, nil
+}
+
+// AddPhotos moves the confirmed uploads into the gallery as they are, without renditions
+func (s *GalleryService) AddPhotos(ctx context.Context, user *models.User, gallery *models.Gallery, uploadIds []primitive.ObjectID) (*models.Gallery, error) {
+	for _, uploadId := range uploadIds {
+		photoId := primitive.NewObjectID()
+		key := fmt.Sprintf("gallery/%s/%s", gallery.AppointmentID.Hex(), photoId.Hex())
+		upload, err := s.UploadService.ConfirmRaw(ctx, user.ID, uploadId, models.UploadGalleryPhoto, key)
+		if err != nil {
+			return nil, err
+		}
+		gallery.Photos = append(gallery.Photos, models.GalleryPhoto{
+			ID:          photoId,
+			Key:         key,
+			FileName:    GalleryFileName(upload.FileName, len(gallery.Photos)+1, upload.ContentType),
+			ContentType: upload.ContentType,
+			Size:        upload.Size,
+			CreatedTime: time.Now(),
+		})
+	}
+	if err := s.Repo.Replace(ctx, gallery); err != nil {
+		return nil, err
+	}
+	return gallery, nil
+}
+
+func (s *GalleryService) RemovePhoto(ctx context.Context, gallery *models.Gallery, photoId primitive.ObjectID) (*models.Gallery, error) {
+	for i, photo := range gallery.Photos {
+		if photo.ID != photoId {
+			continue
+		}
+		gallery.Photos = append(gallery.Photos[:i:i], gallery.Photos[i+1:]...)
+		if err := s.Repo.Replace(ctx, gallery); err != nil {
+			return nil, err
+		}
+		if err := s.S3Service.DeleteObject(photo.Key); err != nil {
+			log.Println("Failed to delete gallery photo", photo.Key, err)
+		}
+		return gallery, nil
+	}
+	return nil, apperrors.ErrGalleryPhotoNotFound
+}
+
+// Deliver opens the gallery to the customer, the photos are downloadable once the appointment is paid.
+// The archive of a large gallery is built right away, so the first download can be resumed
+func (s *GalleryService) Deliver(ctx context.Context, gallery *models.Gallery) (*models.Gallery, error) {
+	if len(gallery.Photos) == 0 {
+		return nil, apperrors.ErrGalleryEmpty
+	}
+	now := time.Now()
+	gallery.Status = models.GalleryDelivered
+	gallery.DeliveredTime = &now
+	if err := s.Repo.Replace(ctx, gallery); err != nil {
+		return nil, err
+	}
+	if IsLargeGallery(gallery) {
+		s.BuildDownloadArchive(gallery)
+	}
+	return gallery, nil
+}
+
+func (s *GalleryService) UpdateExpireTime(ctx context.Context, gallery *models.Gallery, expireTime time.Time) (*models.Gallery, error) {
+	if !expireTime.After(time.Now()) {
+		return nil, apperrors.ErrGalleryExpireTime
+	}
+	gallery.ExpireTime = expireTime
+	if err := s.Repo.Replace(ctx, gallery); err != nil {
+		return nil, err
+	}
+	return gallery, nil
+}
+
+// MapToGalleryResponse signs a download URL for every photo, archived photos have no URL
+func (s *GalleryService) MapToGalleryResponse(gallery *models.Gallery) (*dto.GalleryResponse, error) {
+	res := &dto.GalleryResponse{
+		ID:            gallery.ID,
+		AppointmentID: gallery.AppointmentID,
+		Status:        gallery.Status,
+		Photos:        []dto.GalleryPhotoResponse{},
+		ExpireTime:    gallery.ExpireTime,
+		DeliveredTime: gallery.DeliveredTime,
+		ArchivedTime:  gallery.ArchivedTime,
+	}
+	urlExpireTime := time.Now().Add(GalleryURLExpireDuration)
+	for _, photo := range gallery.Photos {
+		item := dto.GalleryPhotoResponse{
+			ID:          photo.ID,
+			FileName:    photo.FileName,
+			ContentType: photo.ContentType,
+			Size:        photo.Size,
+		}
+		if gallery.Status != models.GalleryArchived {
+			url, err := s.S3Service.PresignGet(photo.Key, GalleryURLExpireDuration, photo.FileName)
+			if err != nil {
+				return nil, err
+			}
+			item.URL = url
+			item.URLExpireTime = &urlExpireTime
+		}
+		res.Photos = append(res.Photos, item)
+	}
+	return res, nil
+}
+
+// DeleteGallery removes the gallery with its photos, proofs and download archive
+func (s *GalleryService) DeleteGallery(ctx context.Context, gallery *models.Gallery) error {
+	keys := []string{}
+	for _, photo := range gallery.Photos {
+		keys = append(keys, photo.Key)
+	}
+	for _, proof := range gallery.Proofs {
+		keys = append(keys, proof.OriginalKey, proof.PreviewKey)
+	}
+	if gallery.DownloadArchive != nil {
+		keys = append(keys, gallery.DownloadArchive.Key)
+	}
+	// The objects left behind are removed by the storage GC, as nothing refers to them anymore
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.S3Service.DeleteObject(key); err != nil {
+			log.Println("Failed to delete gallery object", key, err)
+		}
+	}
+	return s.Repo.Delete(ctx, gallery.ID)
+}
+
+// ArchiveExpired moves the photos of the expired galleries under the archive prefix, where the bucket lifecycle
+// can move them to a colder storage class, the gallery cannot be downloaded anymore
+func (s *GalleryService) ArchiveExpired(ctx context.Context) {
+	galleries, err := s.Repo.GetExpired(ctx, time.Now())
+	if err != nil {
+		log.Println("Failed to fetch expired galleries", err)
+		return
+	}
+	for _, gallery := range galleries {
+		if err := s.archive(ctx, &gallery); err != nil {
+			log.Println("Failed to archive gallery", gallery.ID.Hex(), err)
+		}
+	}
+}
+
+func (s *GalleryService) archive(ctx context.Context, gallery *models.Gallery) error {
+	for i, photo := range gallery.Photos {
+		if strings.HasPrefix(photo.Key, GalleryArchivePrefix) {
+			continue
+		}
+		archivedKey := GalleryArchivePrefix + photo.Key
+		if err := s.moveObject(photo.Key, archivedKey, photo.ContentType); err != nil {
+			// Keep the photos moved so far, the next run continues from here
+			_ = s.Repo.Replace(ctx, gallery)
+			return err
+		}
+		gallery.Photos[i].Key = archivedKey
+	}
+	// Proofs are not shared anymore, their originals are archived with the photos and the previews are removed
+	for i, proof := range gallery.Proofs {
+		if strings.HasPrefix(proof.OriginalKey, GalleryArchivePrefix) {
+			continue
+		}
+		archivedKey := GalleryArchivePrefix + proof.OriginalKey
+		if err := s.moveObject(proof.OriginalKey, archivedKey, proof.ContentType); err != nil {
+			_ = s.Repo.Replace(ctx, gallery)
+			return err
+		}
+		gallery.Proofs[i].OriginalKey = archivedKey
+		if err := s.S3Service.DeleteObject(proof.PreviewKey); err != nil {
+			log.Println("Failed to delete proof preview", proof.PreviewKey, err)
+		}
+	}
+
+	// The photos cannot be downloaded anymore
+	s.deleteDownloadArchive(gallery.DownloadArchive)
+	gallery.DownloadArchive = nil
+
+	now := time.Now()
+	gallery.Status = models.GalleryArchived
+	gallery.ArchivedTime = &now
+	return s.Repo.Replace(ctx, gallery)
+}
+
+func (s *GalleryService) moveObject(from string, to string, contentType string) error {
+	data, err := s.S3Service.GetObject(from)
+	if err != nil {
+		return err
+	}
+	if err := s.S3Service.Repo.PutObject(to, data, contentType); err != nil {
+		return err
+	}
+	return s.S3Service.DeleteObject(from)
+}
+
+// GalleryFileName keeps the client file name, or names the photo by its position
+func GalleryFileName(fileName string, position int, contentType string) string {
+	if fileName = path.Base(strings.ReplaceAll(fileName, "\\", "/")); fileName != "." && fileName != "/" && fileName != "" {
+		return fileName
+	}
+	return fmt.Sprintf("photo_%03d%s", position, imageExtensions[contentType])
+}
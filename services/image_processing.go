@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxImagePixels rejects images that would take too much memory to decode
+const MaxImagePixels = 50_000_000
+
+// ImageRendition is one generated version of an uploaded image
+type ImageRendition struct {
+	Name        string
+	Ext         string
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+type imageRenditionSpec struct {
+	name    string
+	maxSide int
+	webp    bool
+}
+
+var imageRenditionSpecs = []imageRenditionSpec{
+	{name: "thumbnail", maxSide: 320},
+	{name: "medium", maxSide: 1024},
+	{name: "large", maxSide: 2048},
+	{name: "medium", maxSide: 1024, webp: true},
+}
+
+var allowedImageFormats = map[string]bool{"jpeg": true, "png": true, "gif": true, "webp": true}
+
+// VerifyImage checks that the bytes are really an image in a supported format and of a reasonable size
+func VerifyImage(data []byte) (string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("invalid image content: %v", err)
+	}
+	if !allowedImageFormats[format] {
+		return "", fmt.Errorf("unsupported image type %s", format)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxImagePixels {
+		return "", errors.New("image dimensions are too large")
+	}
+	return format, nil
+}
+
+// ProcessImage decodes the image and generates the renditions, every rendition is re-encoded so EXIF data
+// such as the GPS location is dropped, the EXIF orientation of a JPEG is applied to the pixels first.
+// The watermark, when there is one, is drawn over every rendition
+func ProcessImage(data []byte, watermark *ImageWatermark) ([]ImageRendition, error) {
+	format, err := VerifyImage(data)
+	if err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image content: %v", err)
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	renditions := []ImageRendition{}
+	for _, spec := range imageRenditionSpecs {
+		img := orientImage(fitImage(src, spec.maxSide), orientation)
+		ApplyWatermark(img, watermark)
+
+		var buf bytes.Buffer
+		rendition := ImageRendition{Name: spec.name, Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+		if spec.webp {
+			rendition.Ext, rendition.ContentType = "webp", "image/webp"
+			err = nativewebp.Encode(&buf, img, nil)
+		} else {
+			rendition.Ext, rendition.ContentType = "jpg", "image/jpeg"
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return nil, err
+		}
+		rendition.Data = buf.Bytes()
+		renditions = append(renditions, rendition)
+	}
+	return renditions, nil
+}
+
+// ProofMaxSide is the longest side of a proof, large enough to choose from and too small to print
+const ProofMaxSide = 1024
+
+// ProcessProofImage generates the low resolution proof of an original photo, marked as a proof and
+// with the watermark of the photographer when there is one
+func ProcessProofImage(data []byte, watermark *ImageWatermark) (*ImageRendition, error) {
+	format, err := VerifyImage(data)
+	if err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image content: %v", err)
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	img := orientImage(fitImage(src, ProofMaxSide), orientation)
+	ApplyWatermark(img, &ProofWatermark)
+	ApplyWatermark(img, watermark)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 70}); err != nil {
+		return nil, err
+	}
+	return &ImageRendition{
+		Name:        "proof",
+		Ext:         "jpg",
+		ContentType: "image/jpeg",
+		Data:        buf.Bytes(),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}, nil
+}
+
+// fitImage scales the image down so its longest side is at most maxSide, transparent pixels become white
+func fitImage(src image.Image, maxSide int) *image.RGBA {
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	if longest := max(width, height); longest > maxSide {
+		width = max(width*maxSide/longest, 1)
+		height = max(height*maxSide/longest, 1)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+	return dst
+}
+
+// orientImage turns the pixels upright according to the EXIF orientation (1 to 8)
+func orientImage(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirror horizontal
+				dx, dy = width-1-x, y
+			case 3: // rotate 180
+				dx, dy = width-1-x, height-1-y
+			case 4: // mirror vertical
+				dx, dy = x, height-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90 clockwise
+				dx, dy = height-1-y, x
+			case 7: // transverse
+				dx, dy = height-1-y, width-1-x
+			case 8: // rotate 90 counter-clockwise
+				dx, dy = y, width-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the orientation tag from the EXIF segment of a JPEG, 1 (upright) when there is none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// Metadata segments are all before the start of scan
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
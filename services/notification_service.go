@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationLimit is the number of latest notifications returned to the user
+const NotificationLimit = 50
+
+type NotificationService struct {
+	Repo *repositories.NotificationRepository
+}
+
+func NewNotificationService(repo *repositories.NotificationRepository) *NotificationService {
+	return &NotificationService{Repo: repo}
+}
+
+func (s *NotificationService) GetByUserId(ctx context.Context, userId primitive.ObjectID) ([]models.Notification, error) {
+	return s.Repo.GetByUserId(ctx, userId, NotificationLimit)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, id primitive.ObjectID, userId primitive.ObjectID) (bool, error) {
+	return s.Repo.MarkRead(ctx, id, userId)
+}
+
+// Notify adds a notification to the inbox of the user, a failure is logged and does not fail the caller
+func (s *NotificationService) Notify(ctx context.Context, userId primitive.ObjectID, notificationType models.NotificationType, title string, message string, appointmentId *primitive.ObjectID) {
+	item := &models.Notification{
+		ID:            primitive.NewObjectID(),
+		UserID:        userId,
+		Type:          notificationType,
+		Title:         title,
+		Message:       message,
+		AppointmentID: appointmentId,
+		CreatedTime:   time.Now(),
+	}
+	if err := s.Repo.Create(ctx, item); err != nil {
+		log.Println("Failed to create notification for", userId.Hex(), err)
+	}
+}
@@ -2,13 +2,16 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
 
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
 	"github.com/jinzhu/copier"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,23 +22,33 @@ type PackageService struct {
 	S3Service         *S3Service
 	SubpackageService *SubpackageService
 	UserRepo          UserRepositoryInterface
+	UploadService     *UploadService
+	WatermarkService  *WatermarkService
+	ModerationService *PhotoModerationService
+	Audit             *AuditService
 }
 
 // UserRepositoryInterface defines the methods needed for testing
 type UserRepositoryInterface interface {
 	FindUserByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	FindListedPhotographerIds(ctx context.Context) ([]primitive.ObjectID, error)
 }
 
-func NewPackageService(repo *repositories.PackageRepository, s3Service *S3Service, subpackageService *SubpackageService, userRepo UserRepositoryInterface) *PackageService {
-	return &PackageService{Repo: repo, S3Service: s3Service, SubpackageService: subpackageService, UserRepo: userRepo}
+func NewPackageService(repo *repositories.PackageRepository, s3Service *S3Service, subpackageService *SubpackageService, userRepo UserRepositoryInterface, uploadService *UploadService, watermarkService *WatermarkService, moderationService *PhotoModerationService) *PackageService {
+	return &PackageService{Repo: repo, S3Service: s3Service, SubpackageService: subpackageService, UserRepo: userRepo, UploadService: uploadService, WatermarkService: watermarkService, ModerationService: moderationService}
 }
 
+// GetAll returns the listed packages, the ones of the verified photographers
 func (s *PackageService) GetAll(ctx context.Context) ([]models.Package, error) {
-	return s.Repo.GetAll(ctx)
+	pkgs, err := s.Repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.listed(ctx, pkgs)
 }
 
 func (s *PackageService) GetAllRecommended(ctx context.Context, size int) ([]models.Package, error) {
-	pkgs, err := s.Repo.GetAll(ctx)
+	pkgs, err := s.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +61,54 @@ func (s *PackageService) GetAllRecommended(ctx context.Context, size int) ([]mod
 	return pkgs[:size], nil
 }
 
+// listed keeps the packages whose owner is verified
+func (s *PackageService) listed(ctx context.Context, pkgs []models.Package) ([]models.Package, error) {
+	ownerIds, err := s.UserRepo.FindListedPhotographerIds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ListedPackages(pkgs, ownerIds), nil
+}
+
+// ListedPackages keeps the packages owned by one of the verified owners, in their order
+func ListedPackages(pkgs []models.Package, verifiedOwnerIds []primitive.ObjectID) []models.Package {
+	verified := make(map[primitive.ObjectID]bool, len(verifiedOwnerIds))
+	for _, id := range verifiedOwnerIds {
+		verified[id] = true
+	}
+	listed := []models.Package{}
+	for _, pkg := range pkgs {
+		if verified[pkg.OwnerID] {
+			listed = append(listed, pkg)
+		}
+	}
+	return listed
+}
+
+// IsListedFor reports whether the viewer sees the packages of the owner, the packages of a photographer who is not
+// listed are only seen by them and the admins
+func IsListedFor(viewer *models.User, owner *models.User) bool {
+	if owner.IsListed() {
+		return true
+	}
+	return viewer != nil && (viewer.ID == owner.ID || viewer.Role == models.Admin)
+}
+
+// checkListed returns notFound when the packages of the owner are not listed for the viewer
+func checkListed(ctx context.Context, userRepo UserRepositoryInterface, viewer *models.User, ownerId primitive.ObjectID, notFound error) error {
+	owner, err := userRepo.FindUserByID(ctx, ownerId)
+	if isNotFound(err) {
+		return notFound
+	}
+	if err != nil {
+		return err
+	}
+	if !IsListedFor(viewer, owner) {
+		return notFound
+	}
+	return nil
+}
+
 func (s *PackageService) GetById(ctx context.Context, packageId string) (*models.Package, error) {
 	return s.Repo.GetById(ctx, packageId)
 }
@@ -67,14 +128,21 @@ func (s *PackageService) CreateOne(ctx context.Context, itemInput *dto.PackageRe
 	item := itemInput.ToModel(ownerId)
 	item.ID = primitive.NewObjectID()
 
-	photoUrls, upErr := s.UploadPackagePhotos(*itemInput.Photos, item.ID.Hex())
+	screening, upErr := s.screenRequestPhotos(ctx, itemInput, ownerId, item.ID)
 	if upErr != nil {
 		return nil, upErr
 	}
-	item.PhotoUrls = photoUrls
+	setPackageImages(item, screening.Published)
 
-	_, err := s.Repo.CreateOne(ctx, item)
-	return item, err
+	if _, err := s.Repo.CreateOne(ctx, item); err != nil {
+		// Do not leave the uploaded photos behind
+		_ = s.DeletePackagePhotos(item)
+		s.ModerationService.Discard(ctx, screening)
+		return nil, err
+	}
+	s.ModerationService.Commit(ctx, screening)
+	s.Audit.Record(ctx, models.AuditPackage, item.ID, nil, item)
+	return item, nil
 }
 
 func (s *PackageService) UpdateOne(ctx context.Context, packageId string, updates *dto.PackageRequest) (*models.Package, error) {
@@ -83,29 +151,43 @@ func (s *PackageService) UpdateOne(ctx context.Context, packageId string, update
 	if err != nil {
 		return nil, err
 	}
+	before := AuditSnapshot(pkg)
 	// Replace with new values
 	if err := copier.Copy(pkg, updates); err != nil {
 		return nil, err
 	}
 
 	// Upload new photos if any
-	if updates.Photos != nil {
-		// Delete old photos
-		delErr := s.DeletePackagePhotos(pkg.PhotoUrls)
-		if delErr != nil {
-			return nil, delErr
-		}
-		pkg.PhotoUrls = []string{}
-
+	var screening *PhotoScreening
+	if updates.Photos != nil || updates.PhotoUploadIds != nil {
 		// Upload new photos
-		photoUrls, upErr := s.UploadPackagePhotos(*updates.Photos, packageId)
+		var upErr error
+		screening, upErr = s.screenRequestPhotos(ctx, updates, pkg.OwnerID, pkg.ID)
 		if upErr != nil {
 			return nil, upErr
 		}
-		pkg.PhotoUrls = photoUrls
+
+		// Delete old photos
+		delErr := s.DeletePackagePhotos(pkg)
+		if delErr != nil {
+			s.ModerationService.Discard(ctx, screening)
+			return nil, delErr
+		}
+		s.ModerationService.Unindex(ctx, pkg.Images)
+		setPackageImages(pkg, screening.Published)
 	}
 
 	_, err = s.Repo.ReplaceOne(ctx, packageId, pkg)
+	if screening != nil {
+		if err != nil {
+			s.ModerationService.Discard(ctx, screening)
+		} else {
+			s.ModerationService.Commit(ctx, screening)
+		}
+	}
+	if err == nil {
+		s.Audit.Record(ctx, models.AuditPackage, pkg.ID, before, pkg)
+	}
 	return pkg, err
 }
 
@@ -115,32 +197,227 @@ func (s *PackageService) DeleteOne(ctx context.Context, packageId string) error
 	if findErr != nil {
 		return findErr
 	}
-	delErr := s.DeletePackagePhotos(curPackage.PhotoUrls)
+	delErr := s.DeletePackagePhotos(curPackage)
 	if delErr != nil {
 		return delErr
 	}
 
-	_, err := s.Repo.DeleteOne(ctx, packageId)
-	return err
+	if _, err := s.Repo.DeleteOne(ctx, packageId); err != nil {
+		return err
+	}
+	s.ModerationService.RemovePackage(ctx, curPackage.ID)
+	s.Audit.Record(ctx, models.AuditPackage, curPackage.ID, curPackage, nil)
+	return nil
+}
+
+// AddPhotos appends the base64 photos and confirmed uploads after the current photos
+func (s *PackageService) AddPhotos(ctx context.Context, pkg *models.Package, req *dto.PackagePhotoAddRequest) (*models.Package, error) {
+	before := AuditSnapshot(pkg)
+	NormalizePackageImages(pkg)
+	screening, err := s.screenRequestPhotos(ctx, &dto.PackageRequest{Photos: req.Photos, PhotoUploadIds: req.PhotoUploadIds}, pkg.OwnerID, pkg.ID)
+	if err != nil {
+		return nil, err
+	}
+	setPackageImages(pkg, append(pkg.Images, screening.Published...))
+
+	if _, err := s.Repo.ReplaceOne(ctx, pkg.ID.Hex(), pkg); err != nil {
+		for _, image := range screening.Published {
+			_ = s.S3Service.DeleteImage(&image)
+		}
+		s.ModerationService.Discard(ctx, screening)
+		return nil, err
+	}
+	s.ModerationService.Commit(ctx, screening)
+	s.Audit.Record(ctx, models.AuditPackage, pkg.ID, before, pkg)
+	return pkg, nil
+}
+
+// RemovePhoto deletes one photo and its renditions, the other photos keep their URLs
+func (s *PackageService) RemovePhoto(ctx context.Context, pkg *models.Package, photoId primitive.ObjectID) (*models.Package, error) {
+	before := AuditSnapshot(pkg)
+	NormalizePackageImages(pkg)
+	index := findPackageImage(pkg.Images, photoId)
+	if index < 0 {
+		return nil, apperrors.ErrPackagePhotoNotFound
+	}
+	removed := pkg.Images[index]
+	setPackageImages(pkg, append(pkg.Images[:index:index], pkg.Images[index+1:]...))
+
+	if _, err := s.Repo.ReplaceOne(ctx, pkg.ID.Hex(), pkg); err != nil {
+		return nil, err
+	}
+	s.ModerationService.Unindex(ctx, []models.Image{removed})
+	if err := s.S3Service.DeleteImage(&removed); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditPackage, pkg.ID, before, pkg)
+	return pkg, nil
+}
+
+// ReorderPhotos sorts the photos in the given order, the first photo is the cover
+func (s *PackageService) ReorderPhotos(ctx context.Context, pkg *models.Package, photoIds []primitive.ObjectID) (*models.Package, error) {
+	before := AuditSnapshot(pkg)
+	NormalizePackageImages(pkg)
+	images, err := ReorderImages(pkg.Images, photoIds)
+	if err != nil {
+		return nil, err
+	}
+	setPackageImages(pkg, images)
+
+	if _, err := s.Repo.ReplaceOne(ctx, pkg.ID.Hex(), pkg); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditPackage, pkg.ID, before, pkg)
+	return pkg, nil
+}
+
+// SetCoverPhoto moves the photo to the front, the other photos keep their order
+func (s *PackageService) SetCoverPhoto(ctx context.Context, pkg *models.Package, photoId primitive.ObjectID) (*models.Package, error) {
+	before := AuditSnapshot(pkg)
+	NormalizePackageImages(pkg)
+	index := findPackageImage(pkg.Images, photoId)
+	if index < 0 {
+		return nil, apperrors.ErrPackagePhotoNotFound
+	}
+	images := append([]models.Image{pkg.Images[index]}, pkg.Images[:index]...)
+	setPackageImages(pkg, append(images, pkg.Images[index+1:]...))
+
+	if _, err := s.Repo.ReplaceOne(ctx, pkg.ID.Hex(), pkg); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditPackage, pkg.ID, before, pkg)
+	return pkg, nil
+}
+
+func (s *PackageService) UpdatePhotoCaption(ctx context.Context, pkg *models.Package, photoId primitive.ObjectID, caption string) (*models.Package, error) {
+	before := AuditSnapshot(pkg)
+	NormalizePackageImages(pkg)
+	index := findPackageImage(pkg.Images, photoId)
+	if index < 0 {
+		return nil, apperrors.ErrPackagePhotoNotFound
+	}
+	pkg.Images[index].Caption = caption
+
+	if _, err := s.Repo.ReplaceOne(ctx, pkg.ID.Hex(), pkg); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditPackage, pkg.ID, before, pkg)
+	return pkg, nil
+}
+
+// ReorderImages returns the images in the order of the ids, which must list every image exactly once
+func ReorderImages(images []models.Image, photoIds []primitive.ObjectID) ([]models.Image, error) {
+	if len(photoIds) != len(images) {
+		return nil, apperrors.ErrPackagePhotoOrder
+	}
+	ordered := make([]models.Image, 0, len(images))
+	used := make(map[primitive.ObjectID]bool, len(photoIds))
+	for _, photoId := range photoIds {
+		index := findPackageImage(images, photoId)
+		if index < 0 || used[photoId] {
+			return nil, apperrors.ErrPackagePhotoOrder
+		}
+		used[photoId] = true
+		ordered = append(ordered, images[index])
+	}
+	return ordered, nil
+}
+
+func findPackageImage(images []models.Image, photoId primitive.ObjectID) int {
+	for i, image := range images {
+		if image.ID == photoId {
+			return i
+		}
+	}
+	return -1
+}
+
+// NormalizePackageImages gives an id to every photo, including the plain photos uploaded before renditions existed.
+// The id of a photo stored without one is derived from its key, so every request sees the same id until it is saved.
+func NormalizePackageImages(pkg *models.Package) {
+	for _, photo := range pkg.PhotoUrls[min(len(pkg.Images), len(pkg.PhotoUrls)):] {
+		pkg.Images = append(pkg.Images, models.Image{Large: photo})
+	}
+	for i := range pkg.Images {
+		if pkg.Images[i].ID.IsZero() {
+			pkg.Images[i].ID = legacyPackageImageID(pkg.ID, &pkg.Images[i])
+		}
+	}
+}
+
+func legacyPackageImageID(packageId primitive.ObjectID, image *models.Image) primitive.ObjectID {
+	key := image.OriginalKey
+	if key == "" {
+		key = image.Large
+	}
+	sum := sha256.Sum256(append(packageId[:], key...))
+	var id primitive.ObjectID
+	copy(id[:], sum[:])
+	return id
 }
 
 // Helper function
 
-func (s *PackageService) UploadPackagePhotos(photoBase64 []string, id string) ([]string, error) {
-	photoUrls := []string{}
-	for _, photo := range photoBase64 {
-		// Add / to the photo path
-		photoUrl, err := s.S3Service.UploadBase64([]byte(photo), "package/"+id)
+// UploadPackagePhotos keeps the originals privately and uploads the renditions with the watermark of the owner,
+// the quota of the owner must fit every photo before any is uploaded
+func (s *PackageService) UploadPackagePhotos(ctx context.Context, ownerId primitive.ObjectID, photoBase64 []string, id string, watermark *ImageWatermark) ([]models.Image, error) {
+	return s.S3Service.UploadPortfolioBase64(ctx, ownerId, photoBase64, "package/"+id, watermark)
+}
+
+// screenRequestPhotos uploads the photos of the request and holds the ones that look copied from another photographer
+func (s *PackageService) screenRequestPhotos(ctx context.Context, req *dto.PackageRequest, ownerId primitive.ObjectID, packageId primitive.ObjectID) (*PhotoScreening, error) {
+	images, err := s.uploadRequestPhotos(ctx, req, ownerId, packageId.Hex())
+	if err != nil {
+		return nil, err
+	}
+	screening, err := s.ModerationService.Screen(ctx, ownerId, packageId, images)
+	if err != nil {
+		for _, image := range images {
+			_ = s.S3Service.DeleteImage(&image)
+		}
+		return nil, err
+	}
+	return screening, nil
+}
+
+// uploadRequestPhotos uploads the base64 photos then confirms the presigned uploads of the request
+func (s *PackageService) uploadRequestPhotos(ctx context.Context, req *dto.PackageRequest, ownerId primitive.ObjectID, id string) ([]models.Image, error) {
+	images := []models.Image{}
+	if req.Photos == nil && req.PhotoUploadIds == nil {
+		return images, nil
+	}
+	watermark, err := s.WatermarkService.GetImageWatermark(ctx, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Photos != nil {
+		uploaded, err := s.UploadPackagePhotos(ctx, ownerId, *req.Photos, id, watermark)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, uploaded...)
+	}
+	if req.PhotoUploadIds != nil {
+		confirmed, err := s.UploadService.ConfirmMany(ctx, ownerId, *req.PhotoUploadIds, models.UploadPackagePhoto, "package/"+id, watermark)
 		if err != nil {
+			for _, image := range images {
+				_ = s.S3Service.DeleteImage(&image)
+			}
 			return nil, err
 		}
-		photoUrls = append(photoUrls, photoUrl)
+		images = append(images, confirmed...)
 	}
-	return photoUrls, nil
+	return images, nil
 }
 
-func (s *PackageService) DeletePackagePhotos(photoUrls []string) error {
-	for _, photo := range photoUrls {
+// DeletePackagePhotos removes every rendition of the package photos, and the plain photos uploaded before renditions existed
+func (s *PackageService) DeletePackagePhotos(pkg *models.Package) error {
+	for _, image := range pkg.Images {
+		if err := s.S3Service.DeleteImage(&image); err != nil {
+			return err
+		}
+	}
+	for _, photo := range pkg.PhotoUrls[min(len(pkg.Images), len(pkg.PhotoUrls)):] {
 		// Remove / from the photo path
 		cleanedPath := strings.TrimPrefix(photo, "/")
 		err := s.S3Service.DeleteObject(cleanedPath)
@@ -151,17 +428,33 @@ func (s *PackageService) DeletePackagePhotos(photoUrls []string) error {
 	return nil
 }
 
-func (s *PackageService) CheckOwner(ctx context.Context, user *models.User, packageId string) (bool, error) {
-	pkg, err := s.Repo.GetById(ctx, packageId)
-	if err != nil {
-		return false, err
+// setPackageImages keeps PhotoUrls pointing at the large rendition of every image
+func setPackageImages(pkg *models.Package, images []models.Image) {
+	pkg.Images = images
+	pkg.PhotoUrls = make([]string, len(images))
+	for i, image := range images {
+		pkg.PhotoUrls[i] = image.Large
 	}
-	return pkg.OwnerID == user.ID, nil
 }
 
-func (s *PackageService) CheckPackageExist(ctx context.Context, packageId string) error {
-	_, err := s.Repo.GetById(ctx, packageId)
-	return err
+// GetAuthorized returns the package when the user can take the action on it
+func (s *PackageService) GetAuthorized(ctx context.Context, user *models.User, packageId string, action policies.Action) (*models.Package, error) {
+	pkg, err := s.Repo.GetById(ctx, packageId)
+	if isNotFound(err) {
+		return nil, apperrors.ErrPackageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := policies.Authorize(user, action, policies.Package(pkg)); err != nil {
+		return nil, err
+	}
+	if action == policies.ActionRead {
+		if err := checkListed(ctx, s.UserRepo, user, pkg.OwnerID, apperrors.ErrPackageNotFound); err != nil {
+			return nil, err
+		}
+	}
+	return pkg, nil
 }
 
 func (s *PackageService) VerifyStrictRequest(ctx context.Context, req *dto.PackageRequest) error {
@@ -171,8 +464,8 @@ func (s *PackageService) VerifyStrictRequest(ctx context.Context, req *dto.Packa
 	if req.Type == nil {
 		return errors.New("type is required")
 	}
-	if req.Photos == nil {
-		return errors.New("photos is required")
+	if req.Photos == nil && req.PhotoUploadIds == nil {
+		return errors.New("photos or photoUploadIds is required")
 	}
 	return nil
 }
@@ -182,6 +475,7 @@ func (s *PackageService) MappedToPackageResponse(ctx context.Context, item *mode
 	if err != nil {
 		return nil, err
 	}
+	NormalizePackageImages(item)
 
 	return &dto.PackageResponse{
 		ID:          item.ID,
@@ -189,6 +483,7 @@ func (s *PackageService) MappedToPackageResponse(ctx context.Context, item *mode
 		Title:       item.Title,
 		Type:        item.Type,
 		PhotoUrls:   item.PhotoUrls,
+		Images:      item.Images,
 		SubPackages: subpackages,
 	}, nil
 
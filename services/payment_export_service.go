@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// PaymentExportLocation is the time zone of the dates of the export, both the printed ones and the date range
+var PaymentExportLocation = time.FixedZone("Asia/Bangkok", 7*60*60)
+
+var paymentExportHeader = []string{
+	"Payment ID", "Payment Date", "Type", "Appointment ID", "Appointment Status", "Package", "Subpackage",
+	"Customer ID", "Photographer ID", "Original Price", "Promo Code", "Discount", "Amount", "Platform Fee",
+	"Refunded", "Net", "Customer Status", "Photographer Status", "Payout Date",
+}
+
+// ExportPayments writes the payments created in [from, to) that the user can see as they are read, admin sees the whole
+// platform. Nothing is written to the output of the writer before the first payment is read.
+func (service *PaymentService) ExportPayments(ctx context.Context, user models.User, from, to time.Time, writer PaymentExportWriter) error {
+	err := service.DatabaseRepository.EachWithAppointmentByUserIDAndRole(ctx, user.Role, user.ID, from, to, func(item *models.PaymentWithAppointment) error {
+		return writer.WriteRow(ToPaymentExportRow(*item))
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// ToPaymentExportRow flattens the payment, payments created before the amount was stored fall back to the appointment price
+func ToPaymentExportRow(item models.PaymentWithAppointment) dto.PaymentExportRow {
+	paymentType := item.Type
+	if paymentType == "" {
+		paymentType = models.PaymentAppointment
+	}
+	amount := item.Amount
+	if amount == 0 && paymentType == models.PaymentAppointment {
+		amount = item.Appointment.Price
+	}
+
+	row := dto.PaymentExportRow{
+		PaymentID:          item.ID.Hex(),
+		PaymentTime:        item.ID.Timestamp(),
+		Type:               paymentType,
+		AppointmentID:      item.AppointmentID.Hex(),
+		AppointmentStatus:  item.Appointment.Status,
+		PackageTitle:       item.Appointment.Package.Title,
+		SubpackageTitle:    item.Appointment.Subpackage.Title,
+		CustomerID:         item.Appointment.CustomerID.Hex(),
+		PhotographerID:     item.Appointment.PhotographerID.Hex(),
+		OriginalPrice:      amount,
+		Amount:             amount,
+		Fee:                item.Fee,
+		Refunded:           item.Customer.RefundedAmount,
+		CustomerStatus:     item.Customer.Status,
+		PhotographerStatus: item.Photographer.Status,
+		PayoutTime:         item.Photographer.PayoutTime,
+	}
+	if paymentType == models.PaymentAppointment && item.Appointment.Discount != nil {
+		row.OriginalPrice = item.Appointment.Discount.OriginalPrice
+		row.PromoCode = item.Appointment.Discount.Code
+		row.Discount = item.Appointment.Discount.Amount
+	}
+	row.Net = row.Amount - row.Fee - row.Refunded
+	return row
+}
+
+// PaymentExportWriter writes the rows of a payment export one at a time, Flush writes what is left once every row is
+// written and Close releases the writer whether or not the export was flushed
+type PaymentExportWriter interface {
+	WriteRow(row dto.PaymentExportRow) error
+	Flush() error
+	Close() error
+}
+
+type paymentExportCSVWriter struct {
+	writer *csv.Writer
+	header bool
+}
+
+// NewPaymentExportCSVWriter writes the rows to w as they come, buffered by the CSV writer
+func NewPaymentExportCSVWriter(w io.Writer) PaymentExportWriter {
+	return &paymentExportCSVWriter{writer: csv.NewWriter(w)}
+}
+
+func (e *paymentExportCSVWriter) WriteRow(row dto.PaymentExportRow) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	values := paymentExportValues(row)
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = fmt.Sprint(value)
+	}
+	return e.writer.Write(record)
+}
+
+func (e *paymentExportCSVWriter) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *paymentExportCSVWriter) Close() error {
+	return nil
+}
+
+func (e *paymentExportCSVWriter) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.writer.Write(paymentExportHeader)
+}
+
+type paymentExportXLSXWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	rows   int
+}
+
+// NewPaymentExportXLSXWriter streams the rows into the sheet, which excelize keeps in a temporary file once it grows,
+// the workbook is written to w on Flush
+func NewPaymentExportXLSXWriter(w io.Writer) (PaymentExportWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	header := make([]interface{}, len(paymentExportHeader))
+	for i, title := range paymentExportHeader {
+		header[i] = title
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &paymentExportXLSXWriter{w: w, file: file, stream: stream, rows: 1}, nil
+}
+
+func (e *paymentExportXLSXWriter) WriteRow(row dto.PaymentExportRow) error {
+	e.rows++
+	cell, err := excelize.CoordinatesToCellName(1, e.rows)
+	if err != nil {
+		return err
+	}
+	return e.stream.SetRow(cell, paymentExportValues(row))
+}
+
+func (e *paymentExportXLSXWriter) Flush() error {
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	return e.file.Write(e.w)
+}
+
+// Close removes the temporary files of the sheet
+func (e *paymentExportXLSXWriter) Close() error {
+	return e.file.Close()
+}
+
+func paymentExportValues(row dto.PaymentExportRow) []interface{} {
+	payoutTime := ""
+	if row.PayoutTime != nil {
+		payoutTime = formatExportTime(*row.PayoutTime)
+	}
+	return []interface{}{
+		row.PaymentID, formatExportTime(row.PaymentTime), string(row.Type), row.AppointmentID, string(row.AppointmentStatus),
+		row.PackageTitle, row.SubpackageTitle, row.CustomerID, row.PhotographerID, row.OriginalPrice, row.PromoCode,
+		row.Discount, row.Amount, row.Fee, row.Refunded, row.Net, string(row.CustomerStatus), string(row.PhotographerStatus), payoutTime,
+	}
+}
+
+func formatExportTime(t time.Time) string {
+	return t.In(PaymentExportLocation).Format("2006-01-02 15:04:05")
+}
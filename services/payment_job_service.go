@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// MaxPaymentJobAttempts is the number of tries before a job is marked as failed and needs a manual re-drive
+	MaxPaymentJobAttempts = 8
+	paymentJobBaseBackoff = time.Minute
+	paymentJobMaxBackoff  = 6 * time.Hour
+	paymentJobLease       = 5 * time.Minute
+)
+
+type PaymentJobService struct {
+	Repo           *repositories.PaymentJobRepository
+	PaymentRepo    *repositories.PaymentRepository
+	PaymentService *PaymentService
+}
+
+func NewPaymentJobService(repo *repositories.PaymentJobRepository, paymentRepo *repositories.PaymentRepository, paymentService *PaymentService) *PaymentJobService {
+	return &PaymentJobService{Repo: repo, PaymentRepo: paymentRepo, PaymentService: paymentService}
+}
+
+func (s *PaymentJobService) GetAll(ctx context.Context, status models.PaymentJobStatus) ([]models.PaymentJob, error) {
+	return s.Repo.GetAll(ctx, status)
+}
+
+// Enqueue schedules the payment creation of the appointment to run now, an existing job is reset
+func (s *PaymentJobService) Enqueue(ctx context.Context, appointmentId primitive.ObjectID) (*models.PaymentJob, error) {
+	now := time.Now()
+	job, err := s.Repo.GetByAppointmentId(ctx, appointmentId)
+	if err == mongo.ErrNoDocuments {
+		job = &models.PaymentJob{
+			ID:            primitive.NewObjectID(),
+			AppointmentID: appointmentId,
+			Status:        models.PaymentJobPending,
+			NextRunTime:   now,
+			CreatedTime:   now,
+			UpdatedTime:   now,
+		}
+		return job, s.Repo.Create(ctx, job)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job.Status = models.PaymentJobPending
+	job.Attempts = 0
+	job.NextRunTime = now
+	job.UpdatedTime = now
+	return job, s.Repo.Replace(ctx, job)
+}
+
+// Redrive enqueues the appointment and runs its job right away
+func (s *PaymentJobService) Redrive(ctx context.Context, appointmentId primitive.ObjectID) (*models.PaymentJob, error) {
+	job, err := s.Enqueue(ctx, appointmentId)
+	if err != nil {
+		return nil, err
+	}
+	return job, s.run(ctx, job)
+}
+
+// RunDue runs every pending job whose retry time has passed
+func (s *PaymentJobService) RunDue(ctx context.Context) error {
+	for {
+		job, err := s.Repo.ClaimDue(ctx, time.Now(), paymentJobLease)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return nil
+		}
+		if err := s.run(ctx, job); err != nil {
+			return err
+		}
+	}
+}
+
+// ListUnpaidCompleted returns completed appointments that have no payment, or whose checkout session has expired
+func (s *PaymentJobService) ListUnpaidCompleted(ctx context.Context) ([]dto.PaymentRecoveryResponse, error) {
+	appointments, err := s.PaymentRepo.GetCompletedAppointmentsWithoutPaidPayment(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.PaymentRecoveryResponse{}
+	for _, appointment := range appointments {
+		item := dto.PaymentRecoveryResponse{Appointment: appointment, Reason: dto.RecoveryMissingPayment}
+
+		payment, err := s.PaymentRepo.GetByAppointmentID(ctx, appointment.ID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if payment != nil {
+			expired, err := s.PaymentService.IsCheckoutExpired(payment)
+			if err != nil {
+				return nil, err
+			}
+			if !expired {
+				continue
+			}
+			item.Payment = payment
+			item.Reason = dto.RecoveryExpiredCheckout
+		}
+
+		if job, err := s.Repo.GetByAppointmentId(ctx, appointment.ID); err == nil {
+			item.Job = job
+		} else if err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+// run tries the job once and records the outcome, a failure is scheduled again with exponential backoff
+func (s *PaymentJobService) run(ctx context.Context, job *models.PaymentJob) error {
+	_, runErr := s.PaymentService.EnsurePayment(ctx, job.AppointmentID)
+
+	now := time.Now()
+	job.Attempts++
+	job.UpdatedTime = now
+	if runErr == nil {
+		job.Status = models.PaymentJobSucceeded
+		job.LastError = ""
+	} else {
+		fmt.Printf("Payment job of appointment %s failed (attempt %d): %v\n", job.AppointmentID.Hex(), job.Attempts, runErr)
+		job.LastError = runErr.Error()
+		if job.Attempts >= MaxPaymentJobAttempts {
+			job.Status = models.PaymentJobFailed
+		} else {
+			job.NextRunTime = now.Add(PaymentJobBackoff(job.Attempts))
+		}
+	}
+	return s.Repo.Replace(ctx, job)
+}
+
+// PaymentJobBackoff returns the wait before the next try, doubling from one minute up to six hours
+func PaymentJobBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return paymentJobBaseBackoff
+	}
+	backoff := paymentJobBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= paymentJobMaxBackoff {
+			return paymentJobMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -4,20 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	databaseRepo "github.com/Bualoi-s-Dev/backend/repositories/database"
 	stripeRepo "github.com/Bualoi-s-Dev/backend/repositories/stripe"
 	"github.com/stripe/stripe-go/v81"
 	"github.com/stripe/stripe-go/v81/balancetransaction"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// PlatformFee is the fee in THB that the platform keeps from every appointment payment
+const PlatformFee = 5
+
 type PaymentService struct {
 	DatabaseRepository            *databaseRepo.PaymentRepository
 	UserDatabaseRepository        *databaseRepo.UserRepository
 	AppointmentDatabaseRepository *databaseRepo.AppointmentRepository
 	StripeRepository              *stripeRepo.StripeRepository
+	Audit                         *AuditService
+
+	// PaidHandlers are called once the checkout of a payment of their type is completed
+	PaidHandlers map[models.PaymentType]func(ctx context.Context, payment *models.Payment) error
 }
 
 func NewPaymentService(databaseRepository *databaseRepo.PaymentRepository, userRepo *databaseRepo.UserRepository, appointmentRepo *databaseRepo.AppointmentRepository,
@@ -30,6 +41,27 @@ func NewPaymentService(databaseRepository *databaseRepo.PaymentRepository, userR
 	}
 }
 
+func (service *PaymentService) createPayment(ctx context.Context, payment *models.Payment) error {
+	if err := service.DatabaseRepository.Create(ctx, payment); err != nil {
+		return err
+	}
+	service.Audit.Record(ctx, models.AuditPayment, payment.ID, nil, payment)
+	return nil
+}
+
+// replacePayment stores the payment, the change from the stored one is recorded in the audit log
+func (service *PaymentService) replacePayment(ctx context.Context, payment *models.Payment) error {
+	before, err := service.DatabaseRepository.GetById(ctx, payment.ID)
+	if err != nil {
+		return err
+	}
+	if err := service.DatabaseRepository.Replace(ctx, payment.ID, payment); err != nil {
+		return err
+	}
+	service.Audit.Record(ctx, models.AuditPayment, payment.ID, before, payment)
+	return nil
+}
+
 func (service *PaymentService) GetAllOwnedPayments(ctx context.Context, user models.User) ([]models.Payment, error) {
 	return service.DatabaseRepository.GetByUserIDAndRole(ctx, user.Role, user.ID)
 }
@@ -38,6 +70,28 @@ func (service *PaymentService) GetPaymentById(ctx context.Context, id primitive.
 	return service.DatabaseRepository.GetById(ctx, id)
 }
 
+// GetAuthorized returns the payment when the user can take the action on it, as a party of its appointment
+func (service *PaymentService) GetAuthorized(ctx context.Context, user *models.User, id primitive.ObjectID, action policies.Action) (*models.Payment, error) {
+	payment, err := service.DatabaseRepository.GetById(ctx, id)
+	if isNotFound(err) {
+		return nil, apperrors.ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	appointment, err := service.AppointmentDatabaseRepository.GetById(ctx, payment.AppointmentID)
+	if isNotFound(err) {
+		return nil, apperrors.ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := policies.Authorize(user, action, policies.Payment(appointment)); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
 func (service *PaymentService) GetPaymentByAppointmentId(ctx context.Context, appointmentId primitive.ObjectID) (*models.Payment, error) {
 	return service.DatabaseRepository.GetByAppointmentID(ctx, appointmentId)
 }
@@ -50,7 +104,8 @@ func (service *PaymentService) RegisterCustomer(ctx context.Context, user models
 	}
 
 	// Update stripe customer id in user
-	user.StripeCustomerID = &customer.ID
+	customerId := models.EncryptedString(customer.ID)
+	user.StripeCustomerID = &customerId
 	_, err = service.UserDatabaseRepository.ReplaceUser(ctx, user.ID, &user)
 	if err != nil {
 		return nil, err
@@ -58,7 +113,13 @@ func (service *PaymentService) RegisterCustomer(ctx context.Context, user models
 	return customer, nil
 }
 
+// RegisterConnectedAccount creates the Stripe account the payouts of the photographer go to, only a verified
+// photographer has one
 func (service *PaymentService) RegisterConnectedAccount(ctx context.Context, user models.User) (*stripe.Account, error) {
+	if !user.IsVerified() {
+		return nil, apperrors.ErrPhotographerNotVerified
+	}
+
 	// Create stripe connected account
 	account, err := service.StripeRepository.CreateConnectedAccount(user.Email)
 	fmt.Println("Create account", account, err)
@@ -81,7 +142,8 @@ func (service *PaymentService) RegisterConnectedAccount(ctx context.Context, use
 	}
 
 	// Update stripe account id in user
-	user.StripeAccountID = &account.ID
+	accountId := models.EncryptedString(account.ID)
+	user.StripeAccountID = &accountId
 	_, err = service.UserDatabaseRepository.ReplaceUser(ctx, user.ID, &user)
 	if err != nil {
 		return nil, err
@@ -101,6 +163,18 @@ func (service *PaymentService) CreatePayment(ctx context.Context, appointmentId
 	if err != nil {
 		return nil, err
 	}
+	// Fully discounted appointment has nothing to charge
+	if appointment.Price <= 0 {
+		payment := &models.Payment{
+			ID:            primitive.NewObjectID(),
+			AppointmentID: appointmentId,
+			Type:          models.PaymentAppointment,
+			Customer:      models.CustomerPayment{Status: models.Paid},
+			Photographer:  models.PhotographerPayment{Status: models.Completed},
+		}
+		return payment, service.createPayment(ctx, payment)
+	}
+
 	customer, err := service.UserDatabaseRepository.FindUserByID(ctx, appointment.CustomerID)
 	if err != nil {
 		return nil, err
@@ -122,7 +196,7 @@ func (service *PaymentService) CreatePayment(ctx context.Context, appointmentId
 		}
 		stripeCustomerId = stripeCustomer.ID
 	} else {
-		stripeCustomerId = *customer.StripeCustomerID
+		stripeCustomerId = string(*customer.StripeCustomerID)
 	}
 
 	// Create photographer stripe account, if not exist create new stripe account
@@ -137,12 +211,11 @@ func (service *PaymentService) CreatePayment(ctx context.Context, appointmentId
 		}
 		stripeAccountId = stripeAccount.ID
 	} else {
-		stripeAccountId = *photographer.StripeAccountID
+		stripeAccountId = string(*photographer.StripeAccountID)
 	}
 
 	// Create checkout session for customer into photographer account
-	subpackage := appointment.Subpackage
-	checkoutSession, err := service.CreateCheckoutSession(stripeCustomerId, stripeAccountId, subpackage.Title, int64(appointment.Price), successURL, cancelURL)
+	checkoutSession, err := service.CreateCheckoutSession(stripeCustomerId, stripeAccountId, appointment, successURL, cancelURL)
 	if err != nil {
 		return nil, err
 	}
@@ -151,6 +224,9 @@ func (service *PaymentService) CreatePayment(ctx context.Context, appointmentId
 	payment := &models.Payment{
 		ID:            primitive.NewObjectID(),
 		AppointmentID: appointmentId,
+		Type:          models.PaymentAppointment,
+		Amount:        appointment.Price,
+		Fee:           CalculateApplicationFee(appointment),
 		Customer: models.CustomerPayment{
 			Status:     models.Unpaid,
 			CheckoutID: &checkoutSession.ID,
@@ -159,7 +235,242 @@ func (service *PaymentService) CreatePayment(ctx context.Context, appointmentId
 			Status: models.Wait,
 		},
 	}
-	return payment, service.DatabaseRepository.Create(ctx, payment)
+	return payment, service.createPayment(ctx, payment)
+}
+
+// EnsurePayment makes sure the completed appointment has a payment the customer can still pay,
+// the payment is created when missing and its checkout session is regenerated when expired
+func (service *PaymentService) EnsurePayment(ctx context.Context, appointmentId primitive.ObjectID) (*models.Payment, error) {
+	payment, err := service.DatabaseRepository.GetByAppointmentID(ctx, appointmentId)
+	if err == mongo.ErrNoDocuments {
+		return service.CreatePayment(ctx, appointmentId, "", "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	expired, err := service.IsCheckoutExpired(payment)
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return payment, service.RegenerateCheckoutSession(ctx, payment)
+	}
+	return payment, nil
+}
+
+// IsCheckoutExpired reports whether an unpaid payment can no longer be paid through its checkout session
+func (service *PaymentService) IsCheckoutExpired(payment *models.Payment) (bool, error) {
+	if payment.Customer.Status != models.Unpaid {
+		return false, nil
+	}
+	if payment.Customer.CheckoutID == nil {
+		return true, nil
+	}
+	checkoutSession, err := service.StripeRepository.GetCheckoutSession(*payment.Customer.CheckoutID)
+	if err != nil {
+		return false, err
+	}
+	return checkoutSession.Status == stripe.CheckoutSessionStatusExpired, nil
+}
+
+// RegenerateCheckoutSession replaces the checkout session of an unpaid appointment payment with a new one
+func (service *PaymentService) RegenerateCheckoutSession(ctx context.Context, payment *models.Payment) error {
+	appointment, err := service.AppointmentDatabaseRepository.GetById(ctx, payment.AppointmentID)
+	if err != nil {
+		return err
+	}
+	customer, err := service.UserDatabaseRepository.FindUserByID(ctx, appointment.CustomerID)
+	if err != nil {
+		return err
+	}
+	photographer, err := service.UserDatabaseRepository.FindUserByID(ctx, appointment.PhotographerID)
+	if err != nil {
+		return err
+	}
+	if customer.StripeCustomerID == nil || photographer.StripeAccountID == nil {
+		return errors.New("stripe account of the appointment is missing")
+	}
+
+	checkoutSession, err := service.CreateCheckoutSession(string(*customer.StripeCustomerID), string(*photographer.StripeAccountID), appointment, "", "")
+	if err != nil {
+		return err
+	}
+	payment.Customer.CheckoutID = &checkoutSession.ID
+	return service.replacePayment(ctx, payment)
+}
+
+// UpdateCheckoutExpired gives an appointment payment a fresh checkout session, an expired tip or purchase is simply left unpaid
+func (service *PaymentService) UpdateCheckoutExpired(ctx context.Context, checkoutSession stripe.CheckoutSession) error {
+	payment, err := service.DatabaseRepository.GetByCheckoutID(ctx, checkoutSession.ID)
+	if err != nil {
+		return err
+	}
+	if !payment.IsAppointmentPayment() || payment.Customer.Status != models.Unpaid {
+		return nil
+	}
+	return service.RegenerateCheckoutSession(ctx, payment)
+}
+
+// CreateExtraPhotoPayment charges the customer for the photos selected beyond the limit of the subpackage,
+// like a tip the whole amount goes to the photographer
+func (service *PaymentService) CreateExtraPhotoPayment(ctx context.Context, customer *models.User, appointment *models.Appointment, count int, successURL string, cancelURL string) (*models.Payment, *stripe.CheckoutSession, error) {
+	photographer, err := service.UserDatabaseRepository.FindUserByID(ctx, appointment.PhotographerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if photographer.StripeAccountID == nil {
+		return nil, nil, apperrors.ErrExtraPhotoPhotographerNoAccount
+	}
+
+	var stripeCustomerId string
+	if customer.StripeCustomerID == nil {
+		stripeCustomer, err := service.RegisterCustomer(ctx, *customer)
+		if err != nil {
+			return nil, nil, err
+		}
+		stripeCustomerId = stripeCustomer.ID
+	} else {
+		stripeCustomerId = string(*customer.StripeCustomerID)
+	}
+
+	price := appointment.Subpackage.ExtraPhotoPrice
+	productName := "Extra edited photo for " + appointment.Subpackage.Title
+	checkoutSession, err := service.StripeRepository.CreateCheckoutSession(stripeCustomerId, string(*photographer.StripeAccountID), productName, int64(price)*100, int64(count), "thb", 0, successURL, cancelURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payment := &models.Payment{
+		ID:            primitive.NewObjectID(),
+		AppointmentID: appointment.ID,
+		Type:          models.PaymentExtraPhotos,
+		Amount:        price * count,
+		Customer: models.CustomerPayment{
+			Status:     models.Unpaid,
+			CheckoutID: &checkoutSession.ID,
+		},
+		Photographer: models.PhotographerPayment{
+			Status: models.Wait,
+		},
+	}
+	return payment, checkoutSession, service.createPayment(ctx, payment)
+}
+
+// ExpireCheckout closes the checkout session of an unpaid payment before it is replaced, so the customer cannot pay
+// both. It returns ErrCheckoutCompleted when the session was completed meanwhile.
+func (service *PaymentService) ExpireCheckout(ctx context.Context, payment *models.Payment) error {
+	if payment.Customer.Status == models.Paid {
+		return apperrors.ErrCheckoutCompleted
+	}
+	if payment.Customer.CheckoutID == nil {
+		return nil
+	}
+	if _, err := service.StripeRepository.ExpireCheckoutSession(*payment.Customer.CheckoutID); err != nil {
+		// Only an open session can be expired, one that is already expired is fine
+		checkoutSession, getErr := service.StripeRepository.GetCheckoutSession(*payment.Customer.CheckoutID)
+		if getErr != nil {
+			return err
+		}
+		switch checkoutSession.Status {
+		case stripe.CheckoutSessionStatusExpired:
+			return nil
+		case stripe.CheckoutSessionStatusComplete:
+			return apperrors.ErrCheckoutCompleted
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateTip charges the customer a tip for a completed appointment, the whole amount goes to the photographer without platform fee
+func (service *PaymentService) CreateTip(ctx context.Context, customer *models.User, appointmentId primitive.ObjectID, amount int, successURL string, cancelURL string) (*models.Payment, *stripe.CheckoutSession, error) {
+	appointment, err := service.AppointmentDatabaseRepository.GetById(ctx, appointmentId)
+	if isNotFound(err) {
+		return nil, nil, apperrors.ErrAppointmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	photographer, err := service.UserDatabaseRepository.FindUserByID(ctx, appointment.PhotographerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := CheckTippable(customer, appointment, photographer); err != nil {
+		return nil, nil, err
+	}
+
+	var stripeCustomerId string
+	if customer.StripeCustomerID == nil {
+		stripeCustomer, err := service.RegisterCustomer(ctx, *customer)
+		if err != nil {
+			return nil, nil, err
+		}
+		stripeCustomerId = stripeCustomer.ID
+	} else {
+		stripeCustomerId = string(*customer.StripeCustomerID)
+	}
+
+	productName := "Tip for " + appointment.Subpackage.Title
+	price, applicationFee := TipCharge(amount)
+	checkoutSession, err := service.StripeRepository.CreateCheckoutSession(stripeCustomerId, string(*photographer.StripeAccountID), productName, price, 1, "thb", applicationFee, successURL, cancelURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payment := &models.Payment{
+		ID:            primitive.NewObjectID(),
+		AppointmentID: appointmentId,
+		Type:          models.PaymentTip,
+		Amount:        amount,
+		Customer: models.CustomerPayment{
+			Status:     models.Unpaid,
+			CheckoutID: &checkoutSession.ID,
+		},
+		Photographer: models.PhotographerPayment{
+			Status: models.Wait,
+		},
+	}
+	return payment, checkoutSession, service.createPayment(ctx, payment)
+}
+
+// CheckTippable refuses a tip the customer cannot give: only the customer of a completed appointment tips, and only a
+// verified photographer with a payout account receives it
+func CheckTippable(customer *models.User, appointment *models.Appointment, photographer *models.User) error {
+	if err := policies.Authorize(customer, policies.ActionTip, policies.Appointment(appointment)); err != nil {
+		return err
+	}
+	if appointment.Status != models.AppointmentCompleted {
+		return apperrors.ErrTipAppointmentNotCompleted
+	}
+	if !photographer.IsVerified() {
+		return apperrors.ErrPhotographerNotVerified
+	}
+	if photographer.StripeAccountID == nil {
+		return apperrors.ErrTipPhotographerNoAccount
+	}
+	return nil
+}
+
+// TipCharge returns what the checkout of a tip charges and the platform fee kept from it, in satang. The platform
+// keeps nothing of a tip
+func TipCharge(amount int) (int64, int64) {
+	return int64(amount) * 100, 0
+}
+
+// GetTipTotal sums the tips the customer has already paid for the appointment
+func (service *PaymentService) GetTipTotal(ctx context.Context, appointmentId primitive.ObjectID) (int, error) {
+	tips, err := service.DatabaseRepository.GetTipsByAppointmentID(ctx, appointmentId)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, tip := range tips {
+		if tip.Customer.Status == models.Paid {
+			total += tip.Amount
+		}
+	}
+	return total, nil
 }
 
 func (service *PaymentService) CreateAccountLink(ctx context.Context, accountId string) (*stripe.AccountLink, error) {
@@ -170,23 +481,48 @@ func (service *PaymentService) CreateLoginLink(ctx context.Context, accountId st
 	return service.StripeRepository.CreateLoginLink(accountId)
 }
 
-func (service *PaymentService) CreateCheckoutSession(customerId string, sellerAccountId string, productName string, amount int64, successURL string, cancelURL string) (*stripe.CheckoutSession, error) {
-	stripeCheckout, err := service.StripeRepository.CreateCheckoutSession(customerId, sellerAccountId, productName, amount*100, 1, "thb", successURL, cancelURL)
+// CreateCheckoutSession charges the discounted appointment price, a platform-wide promotion is funded from the platform fee
+func (service *PaymentService) CreateCheckoutSession(customerId string, sellerAccountId string, appointment *models.Appointment, successURL string, cancelURL string) (*stripe.CheckoutSession, error) {
+	productName := appointment.Subpackage.Title
+	if appointment.Discount != nil {
+		productName = fmt.Sprintf("%s (%s -%d THB)", productName, appointment.Discount.Code, appointment.Discount.Amount)
+	}
+	if appointment.Price <= 0 {
+		return nil, errors.New("appointment is free, no checkout is required")
+	}
+	applicationFee := int64(CalculateApplicationFee(appointment))
+
+	stripeCheckout, err := service.StripeRepository.CreateCheckoutSession(customerId, sellerAccountId, productName, int64(appointment.Price)*100, 1, "thb", applicationFee*100, successURL, cancelURL)
 	if err != nil {
 		return nil, err
 	}
 	return stripeCheckout, nil
 }
 
+// CalculateApplicationFee returns the platform fee in THB kept from the appointment payment,
+// a platform-wide discount is taken out of the fee and the fee never exceeds the price. CalculateDiscount caps
+// the platform-wide discounts at the fee, so the photographer's transfer is never reduced by them.
+func CalculateApplicationFee(appointment *models.Appointment) int {
+	fee := PlatformFee
+	if appointment.Discount != nil && appointment.Discount.Scope == models.PromotionPlatform {
+		fee = max(fee-appointment.Discount.Amount, 0)
+	}
+	return max(min(fee, appointment.Price), 0)
+}
+
 func (service *PaymentService) UpdateAccount(ctx context.Context, user models.User) error {
+	if !models.IsValidBankAccount(user.BankName, string(user.BankAccount)) {
+		return apperrors.ErrBankAccountInvalid
+	}
+
 	// Re-Attach bank account
-	err := service.StripeRepository.UpdateBankAccount(*user.StripeAccountID, user.BankAccount)
+	err := service.StripeRepository.UpdateBankAccount(string(*user.StripeAccountID), string(user.BankAccount))
 	if err != nil {
 		return err
 	}
 
 	// Re-Attach account setting
-	err = service.StripeRepository.AttachAccountSetting(*user.StripeAccountID)
+	err = service.StripeRepository.AttachAccountSetting(string(*user.StripeAccountID))
 	return err
 }
 
@@ -202,8 +538,23 @@ func (service *PaymentService) UpdateCheckoutCompleted(ctx context.Context, chec
 	payment.Customer.PaymentIntentID = &checkoutSession.PaymentIntent.ID
 
 	// Update payment in database
-	err = service.DatabaseRepository.Replace(ctx, payment.ID, payment)
-	return err
+	err = service.replacePayment(ctx, payment)
+	if err != nil {
+		return err
+	}
+
+	if handler, ok := service.PaidHandlers[payment.Type]; ok {
+		return handler(ctx, payment)
+	}
+	return nil
+}
+
+// OnPaid registers the handler called when a payment of the type is paid
+func (service *PaymentService) OnPaid(paymentType models.PaymentType, handler func(ctx context.Context, payment *models.Payment) error) {
+	if service.PaidHandlers == nil {
+		service.PaidHandlers = map[models.PaymentType]func(ctx context.Context, payment *models.Payment) error{}
+	}
+	service.PaidHandlers[paymentType] = handler
 }
 
 func (service *PaymentService) PaidPhotographer(ctx context.Context, charge stripe.Charge) error {
@@ -221,10 +572,20 @@ func (service *PaymentService) PaidPhotographer(ctx context.Context, charge stri
 	payment.Photographer.Status = models.InProcess
 
 	// Update payment in database
-	err = service.DatabaseRepository.Replace(ctx, payment.ID, payment)
+	err = service.replacePayment(ctx, payment)
 	return err
 }
 
+// UpdateRefunded records how much of the payment has been refunded to the customer
+func (service *PaymentService) UpdateRefunded(ctx context.Context, charge stripe.Charge) error {
+	payment, err := service.DatabaseRepository.GetByPaymentIntentID(ctx, charge.PaymentIntent.ID)
+	if err != nil {
+		return err
+	}
+	payment.Customer.RefundedAmount = int(charge.AmountRefunded / 100)
+	return service.replacePayment(ctx, payment)
+}
+
 func (service *PaymentService) UpdateSuccessPayoutPhotographer(ctx context.Context, payout stripe.Payout) error {
 	// Get transaction list from payout
 	params := &stripe.BalanceTransactionListParams{
@@ -242,8 +603,10 @@ func (service *PaymentService) UpdateSuccessPayoutPhotographer(ctx context.Conte
 		}
 
 		// Update photographer payment status
+		payoutTime := time.Unix(payout.ArrivalDate, 0)
 		payment.Photographer.Status = models.Completed
-		err = service.DatabaseRepository.Replace(ctx, payment.ID, payment)
+		payment.Photographer.PayoutTime = &payoutTime
+		err = service.replacePayment(ctx, payment)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"math/bits"
+	"strconv"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	// PhotoDuplicateDistance is the largest number of differing bits for two photos to be considered the same,
+	// resizing, recompressing or a small watermark changes a few bits of the hash
+	PhotoDuplicateDistance = 6
+	// perceptualHashBands splits the hash into bytes, two hashes within PhotoDuplicateDistance bits share at least one
+	perceptualHashBands = 8
+)
+
+// PerceptualHash computes the difference hash of the image, it stays nearly the same when the image is resized,
+// recompressed or slightly edited. The hash is 64 bits in hexadecimal, empty when the image has no detail to compare
+func PerceptualHash(data []byte) (string, error) {
+	format, err := VerifyImage(data)
+	if err != nil {
+		return "", err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("invalid image content: %v", err)
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	img := orientImage(fitImage(src, 256), orientation)
+	gray := image.NewGray(image.Rect(0, 0, 9, 8))
+	xdraw.CatmullRom.Scale(gray, gray.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if gray.GrayAt(x, y).Y < gray.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	// A plain image would match every other plain image
+	if hash == 0 || hash == ^uint64(0) {
+		return "", nil
+	}
+	return fmt.Sprintf("%016x", hash), nil
+}
+
+// PerceptualHashDistance is the number of differing bits of two hashes
+func PerceptualHashDistance(a string, b string) (int, error) {
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return 0, err
+	}
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return 0, err
+	}
+	return bits.OnesCount64(x ^ y), nil
+}
+
+// PerceptualHashBands returns every byte of the hash tagged with its position, they are indexed to find
+// the hashes that may be close without comparing every stored hash
+func PerceptualHashBands(hash string) ([]int, error) {
+	value, err := strconv.ParseUint(hash, 16, 64)
+	if err != nil {
+		return nil, err
+	}
+	bands := make([]int, perceptualHashBands)
+	for i := range bands {
+		bands[i] = i<<8 | int(value>>(8*i)&0xff)
+	}
+	return bands, nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	sms "github.com/Bualoi-s-Dev/backend/repositories/sms"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PhoneOTPDigits         = 6
+	PhoneOTPExpireDuration = 5 * time.Minute
+	// PhoneOTPMaxAttempts is how many codes can be guessed against one sent code
+	PhoneOTPMaxAttempts = 5
+	// PhoneOTPResendInterval is how long the user waits before asking for another code
+	PhoneOTPResendInterval = time.Minute
+	// PhoneOTPMaxSendsPerHour is how many codes a user can be sent in an hour
+	PhoneOTPMaxSendsPerHour = 5
+	// PhoneOTPRetention is how long the sent codes are kept, they count against the hourly limit until then
+	PhoneOTPRetention = 24 * time.Hour
+)
+
+// PhoneService verifies the phone number of the users with a one-time code sent by SMS, only an HMAC of the code is
+// stored so a leaked database does not leak the codes
+type PhoneService struct {
+	Repo     *repositories.PhoneOTPRepository
+	UserRepo *repositories.UserRepository
+	Sender   sms.SMSSender
+	Secret   []byte
+	Audit    *AuditService
+}
+
+func NewPhoneService(repo *repositories.PhoneOTPRepository, userRepo *repositories.UserRepository, sender sms.SMSSender, secret []byte) *PhoneService {
+	return &PhoneService{Repo: repo, UserRepo: userRepo, Sender: sender, Secret: secret}
+}
+
+// Enabled tells whether the codes can be sent and checked, phone verification is turned off without a sender or a secret
+func (s *PhoneService) Enabled() bool {
+	return s.Sender != nil && len(s.Secret) > 0
+}
+
+// SendOTP sends a code to the phone, asking again replaces the code sent before
+func (s *PhoneService) SendOTP(ctx context.Context, user *models.User, phone string) (*models.PhoneOTP, error) {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsPhoneVerified() && string(user.VerifiedPhone) == phone {
+		return nil, apperrors.ErrPhoneAlreadyVerified
+	}
+	if err := s.checkPhoneAvailable(ctx, user, phone); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	latest, err := s.Repo.GetLatestByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && now.Before(latest.CreatedTime.Add(PhoneOTPResendInterval)) {
+		return nil, apperrors.ErrPhoneOTPRateLimited
+	}
+	sent, err := s.Repo.CountSince(ctx, user.ID, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if sent >= PhoneOTPMaxSendsPerHour {
+		return nil, apperrors.ErrPhoneOTPRateLimited
+	}
+
+	code, err := generatePhoneOTPCode()
+	if err != nil {
+		return nil, err
+	}
+	item := &models.PhoneOTP{
+		ID:          primitive.NewObjectID(),
+		UserID:      user.ID,
+		Phone:       models.EncryptedString(phone),
+		ExpireTime:  now.Add(PhoneOTPExpireDuration),
+		CreatedTime: now,
+	}
+	item.CodeHash = PhoneOTPHash(s.Secret, item.ID, phone, code)
+	// The code is stored before it is sent, a failed send still counts against the limits
+	if err := s.Repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	message := fmt.Sprintf("Your Bualoi verification code is %s, it expires in %d minutes", code, int(PhoneOTPExpireDuration.Minutes()))
+	if err := s.Sender.Send(ctx, phone, message); err != nil {
+		return nil, fmt.Errorf("failed to send the code: %v", err)
+	}
+	return item, nil
+}
+
+// VerifyOTP checks the code against the latest one sent to the user, the phone it was sent to becomes the verified
+// phone of the user
+func (s *PhoneService) VerifyOTP(ctx context.Context, user *models.User, code string) (*models.User, error) {
+	item, err := s.Repo.GetLatestByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || time.Now().After(item.ExpireTime) {
+		return nil, apperrors.ErrPhoneOTPExpired
+	}
+	counted, err := s.Repo.IncrementAttempts(ctx, item.ID, PhoneOTPMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !counted {
+		return nil, apperrors.ErrPhoneOTPAttempts
+	}
+	expected := PhoneOTPHash(s.Secret, item.ID, string(item.Phone), strings.TrimSpace(code))
+	if !hmac.Equal([]byte(expected), []byte(item.CodeHash)) {
+		return nil, apperrors.ErrPhoneOTPInvalid
+	}
+	// Another user may have verified the phone since the code was sent
+	if err := s.checkPhoneAvailable(ctx, user, string(item.Phone)); err != nil {
+		return nil, err
+	}
+
+	verifiedPhone := models.DeterministicString(item.Phone)
+	before := bson.M{"phone": user.Phone, "verified_phone": user.VerifiedPhone}
+	updates := bson.M{"phone": item.Phone, "verified_phone": verifiedPhone}
+	if _, err := s.UserRepo.UpdateUser(ctx, user.ID, updates); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditUser, user.ID, before, updates)
+	if err := s.Repo.DeleteByUserId(ctx, user.ID); err != nil {
+		log.Println("Failed to delete the phone codes of", user.ID.Hex(), err)
+	}
+	user.Phone, user.VerifiedPhone = item.Phone, verifiedPhone
+	return user, nil
+}
+
+// checkPhoneAvailable refuses a phone another user verified, a phone verifies a single account
+func (s *PhoneService) checkPhoneAvailable(ctx context.Context, user *models.User, phone string) error {
+	taken, err := s.UserRepo.IsVerifiedPhoneTaken(ctx, phone, user.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return apperrors.ErrPhoneInUse
+	}
+	return nil
+}
+
+// PurgeExpired removes the codes past the retention
+func (s *PhoneService) PurgeExpired(ctx context.Context) {
+	deleted, err := s.Repo.DeleteBefore(ctx, time.Now().Add(-PhoneOTPRetention))
+	if err != nil {
+		log.Println("Failed to purge the phone codes:", err)
+		return
+	}
+	if deleted > 0 {
+		log.Println("Purged", deleted, "phone codes")
+	}
+}
+
+// NormalizePhone returns the phone number in the E.164 format, a Thai number may be written with its leading 0 and
+// the spaces, dashes and brackets are dropped
+func NormalizePhone(phone string) (string, error) {
+	phone = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			return -1
+		}
+		return r
+	}, phone)
+	if strings.HasPrefix(phone, "0") && !strings.HasPrefix(phone, "00") {
+		phone = "+66" + phone[1:]
+	}
+	digits := strings.TrimPrefix(phone, "+")
+	if digits == phone || len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", apperrors.ErrPhoneInvalid
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", apperrors.ErrPhoneInvalid
+		}
+	}
+	// A Thai number is 9 digits after the country code, or 8 for a landline
+	if strings.HasPrefix(digits, "66") && len(digits) != 10 && len(digits) != 11 {
+		return "", apperrors.ErrPhoneInvalid
+	}
+	return phone, nil
+}
+
+// PhoneOTPHash is the HMAC of the code, bound to the id of the sent code and the phone so a hash cannot be reused
+func PhoneOTPHash(secret []byte, id primitive.ObjectID, phone string, code string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id.Hex() + "|" + phone + "|" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func generatePhoneOTPCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < PhoneOTPDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", PhoneOTPDigits, n), nil
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PhotoScreening is the result of comparing uploaded package photos with the published photos of the other
+// photographers, the photos that look copied are held in the moderation queue instead of the package
+type PhotoScreening struct {
+	OwnerID   primitive.ObjectID
+	PackageID primitive.ObjectID
+	Published []models.Image
+	Held      []models.PhotoModeration
+}
+
+// PhotoModerationService indexes the perceptual hashes of the package photos and keeps the queue of held photos
+type PhotoModerationService struct {
+	Repo                *repositories.PhotoModerationRepository
+	HashRepo            *repositories.PhotoHashRepository
+	PackageRepo         *repositories.PackageRepository
+	S3Service           *S3Service
+	WatermarkService    *WatermarkService
+	NotificationService *NotificationService
+}
+
+func NewPhotoModerationService(repo *repositories.PhotoModerationRepository, hashRepo *repositories.PhotoHashRepository, packageRepo *repositories.PackageRepository, s3Service *S3Service, watermarkService *WatermarkService, notificationService *NotificationService) *PhotoModerationService {
+	return &PhotoModerationService{Repo: repo, HashRepo: hashRepo, PackageRepo: packageRepo, S3Service: s3Service, WatermarkService: watermarkService, NotificationService: notificationService}
+}
+
+// FindMatches returns the published photos of the other photographers that look the same, the closest first
+func (s *PhotoModerationService) FindMatches(ctx context.Context, ownerId primitive.ObjectID, hash string) ([]models.PhotoMatch, error) {
+	matches := []models.PhotoMatch{}
+	if hash == "" {
+		return matches, nil
+	}
+	bands, err := PerceptualHashBands(hash)
+	if err != nil {
+		return nil, err
+	}
+	err = s.HashRepo.EachCandidate(ctx, bands, ownerId, func(candidate models.PhotoHash) error {
+		distance, err := PerceptualHashDistance(hash, candidate.Hash)
+		if err != nil || distance > PhotoDuplicateDistance {
+			return nil
+		}
+		matches = append(matches, models.PhotoMatch{
+			OwnerID:   candidate.OwnerID,
+			PackageID: candidate.PackageID,
+			ImageID:   candidate.ImageID,
+			Distance:  distance,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Distance < matches[j].Distance })
+	return matches, nil
+}
+
+// Screen splits the uploaded photos of the package, the held photos are queued right away and must be
+// discarded when the package fails to save
+func (s *PhotoModerationService) Screen(ctx context.Context, ownerId primitive.ObjectID, packageId primitive.ObjectID, images []models.Image) (*PhotoScreening, error) {
+	screening := &PhotoScreening{OwnerID: ownerId, PackageID: packageId, Published: []models.Image{}, Held: []models.PhotoModeration{}}
+	now := time.Now()
+	for _, image := range images {
+		matches, err := s.FindMatches(ctx, ownerId, image.PerceptualHash)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			screening.Published = append(screening.Published, image)
+			continue
+		}
+		screening.Held = append(screening.Held, models.PhotoModeration{
+			ID:          primitive.NewObjectID(),
+			OwnerID:     ownerId,
+			PackageID:   packageId,
+			Image:       image,
+			Matches:     matches,
+			Status:      models.PhotoModerationPending,
+			CreatedTime: now,
+		})
+	}
+	if err := s.Repo.CreateMany(ctx, screening.Held); err != nil {
+		return nil, err
+	}
+	return screening, nil
+}
+
+// Commit indexes the published photos once the package is saved and tells the photographer about the held ones
+func (s *PhotoModerationService) Commit(ctx context.Context, screening *PhotoScreening) {
+	s.Index(ctx, screening.OwnerID, screening.PackageID, screening.Published)
+	if len(screening.Held) > 0 {
+		message := fmt.Sprintf("%d photos look the same as photos of another photographer and are published after they are reviewed", len(screening.Held))
+		s.NotificationService.Notify(ctx, screening.OwnerID, models.NotificationPackagePhotoHeld, "Photos held for review", message, nil)
+	}
+}
+
+// Discard removes the held photos of a package that was not saved
+func (s *PhotoModerationService) Discard(ctx context.Context, screening *PhotoScreening) {
+	ids := make([]primitive.ObjectID, len(screening.Held))
+	for i, item := range screening.Held {
+		ids[i] = item.ID
+		if err := s.S3Service.DeleteImage(&item.Image); err != nil {
+			log.Println("Failed to delete held photo", item.ID.Hex(), err)
+		}
+	}
+	if err := s.Repo.DeleteByIds(ctx, ids); err != nil {
+		log.Println("Failed to delete held photos", err)
+	}
+}
+
+// Index adds the hashes of the published photos, an indexing failure does not fail the upload
+func (s *PhotoModerationService) Index(ctx context.Context, ownerId primitive.ObjectID, packageId primitive.ObjectID, images []models.Image) {
+	items := []models.PhotoHash{}
+	now := time.Now()
+	for _, image := range images {
+		if image.PerceptualHash == "" {
+			continue
+		}
+		bands, err := PerceptualHashBands(image.PerceptualHash)
+		if err != nil {
+			continue
+		}
+		items = append(items, models.PhotoHash{
+			Hash:        image.PerceptualHash,
+			Bands:       bands,
+			OwnerID:     ownerId,
+			PackageID:   packageId,
+			ImageID:     image.ID,
+			CreatedTime: now,
+		})
+	}
+	if err := s.HashRepo.CreateMany(ctx, items); err != nil {
+		log.Println("Failed to index photos of package", packageId.Hex(), err)
+	}
+}
+
+// Unindex removes the hashes of the photos removed from a package
+func (s *PhotoModerationService) Unindex(ctx context.Context, images []models.Image) {
+	ids := make([]primitive.ObjectID, 0, len(images))
+	for _, image := range images {
+		if !image.ID.IsZero() {
+			ids = append(ids, image.ID)
+		}
+	}
+	if err := s.HashRepo.DeleteByImageIds(ctx, ids); err != nil {
+		log.Println("Failed to remove photo hashes", err)
+	}
+}
+
+// RemovePackage removes the hashes of a deleted package and rejects its held photos
+func (s *PhotoModerationService) RemovePackage(ctx context.Context, packageId primitive.ObjectID) {
+	if err := s.HashRepo.DeleteByPackageId(ctx, packageId); err != nil {
+		log.Println("Failed to remove photo hashes of package", packageId.Hex(), err)
+	}
+	items, err := s.Repo.GetMany(ctx, models.PhotoModerationPending, &packageId)
+	if err != nil {
+		log.Println("Failed to fetch held photos of package", packageId.Hex(), err)
+		return
+	}
+	for _, item := range items {
+		if _, err := s.reject(ctx, &item, nil, "Package was deleted"); err != nil {
+			log.Println("Failed to reject held photo", item.ID.Hex(), err)
+		}
+	}
+}
+
+func (s *PhotoModerationService) GetQueue(ctx context.Context, status models.PhotoModerationStatus, packageId *primitive.ObjectID) ([]models.PhotoModeration, error) {
+	return s.Repo.GetMany(ctx, status, packageId)
+}
+
+// Approve publishes the held photo at the end of its package, its renditions are generated again with the
+// current watermark of the photographer
+func (s *PhotoModerationService) Approve(ctx context.Context, id primitive.ObjectID, reviewer *models.User) (*models.PhotoModeration, error) {
+	item, err := s.getPending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := s.PackageRepo.GetById(ctx, item.PackageID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	watermark, err := s.WatermarkService.GetImageWatermark(ctx, item.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	image, err := s.S3Service.RenderPortfolioImage(&item.Image, watermark)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claimed, err := s.Repo.UpdateStatus(ctx, id, models.PhotoModerationPending, bson.M{
+		"status":        models.PhotoModerationApproved,
+		"image":         image,
+		"reviewer_id":   reviewer.ID,
+		"reviewed_time": now,
+	})
+	if err != nil || !claimed {
+		_ = s.S3Service.DeleteRenditions(image)
+		if err == nil {
+			err = apperrors.ErrPhotoModerationReviewed
+		}
+		return nil, err
+	}
+
+	NormalizePackageImages(pkg)
+	setPackageImages(pkg, append(pkg.Images, *image))
+	if _, err := s.PackageRepo.ReplaceOne(ctx, pkg.ID.Hex(), pkg); err != nil {
+		// Put the photo back in the queue
+		_, _ = s.Repo.UpdateStatus(ctx, id, models.PhotoModerationApproved, bson.M{
+			"status":        models.PhotoModerationPending,
+			"image":         item.Image,
+			"reviewer_id":   nil,
+			"reviewed_time": nil,
+		})
+		_ = s.S3Service.DeleteRenditions(image)
+		return nil, err
+	}
+	if err := s.S3Service.DeleteRenditions(&item.Image); err != nil {
+		log.Println("Failed to delete held photo renditions", item.ID.Hex(), err)
+	}
+	s.Index(ctx, item.OwnerID, item.PackageID, []models.Image{*image})
+	s.NotificationService.Notify(ctx, item.OwnerID, models.NotificationPackagePhotoApproved, "Photo approved",
+		fmt.Sprintf("A held photo is now published in %s", pkg.Title), nil)
+
+	item.Status, item.Image, item.ReviewerID, item.ReviewedTime = models.PhotoModerationApproved, *image, &reviewer.ID, &now
+	return item, nil
+}
+
+// Reject deletes the held photo, the photographer is told the reason
+func (s *PhotoModerationService) Reject(ctx context.Context, id primitive.ObjectID, reviewer *models.User, reason string) (*models.PhotoModeration, error) {
+	item, err := s.getPending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.reject(ctx, item, &reviewer.ID, reason)
+}
+
+func (s *PhotoModerationService) reject(ctx context.Context, item *models.PhotoModeration, reviewerId *primitive.ObjectID, reason string) (*models.PhotoModeration, error) {
+	now := time.Now()
+	claimed, err := s.Repo.UpdateStatus(ctx, item.ID, models.PhotoModerationPending, bson.M{
+		"status":        models.PhotoModerationRejected,
+		"reason":        reason,
+		"reviewer_id":   reviewerId,
+		"reviewed_time": now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, apperrors.ErrPhotoModerationReviewed
+	}
+	if err := s.S3Service.DeleteImage(&item.Image); err != nil {
+		log.Println("Failed to delete rejected photo", item.ID.Hex(), err)
+	}
+	s.NotificationService.Notify(ctx, item.OwnerID, models.NotificationPackagePhotoRejected, "Photo rejected",
+		"A held photo was not published: "+reason, nil)
+
+	item.Status, item.Reason, item.ReviewerID, item.ReviewedTime = models.PhotoModerationRejected, reason, reviewerId, &now
+	return item, nil
+}
+
+func (s *PhotoModerationService) getPending(ctx context.Context, id primitive.ObjectID) (*models.PhotoModeration, error) {
+	item, err := s.Repo.GetById(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrPhotoModerationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != models.PhotoModerationPending {
+		return nil, apperrors.ErrPhotoModerationReviewed
+	}
+	return item, nil
+}
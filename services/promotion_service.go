@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"github.com/jinzhu/copier"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type PromotionService struct {
+	Repo        *repositories.PromotionRepository
+	PackageRepo *repositories.PackageRepository
+}
+
+func NewPromotionService(repo *repositories.PromotionRepository, packageRepo *repositories.PackageRepository) *PromotionService {
+	return &PromotionService{Repo: repo, PackageRepo: packageRepo}
+}
+
+// GetAllOwned returns every promotion for admin, and only the photographer's own promotions otherwise
+func (s *PromotionService) GetAllOwned(ctx context.Context, user *models.User) ([]models.Promotion, error) {
+	if user.Role == models.Admin {
+		return s.Repo.GetAll(ctx)
+	}
+	return s.Repo.GetByOwnerId(ctx, user.ID)
+}
+
+func (s *PromotionService) GetById(ctx context.Context, id primitive.ObjectID) (*models.Promotion, error) {
+	return s.Repo.GetById(ctx, id)
+}
+
+func (s *PromotionService) CreateOne(ctx context.Context, user *models.User, req *dto.PromotionRequest) (*models.Promotion, error) {
+	scope := models.PromotionPhotographer
+	if user.Role == models.Admin {
+		scope = models.PromotionPlatform
+	}
+	item := req.ToModel(user, scope)
+	if err := s.verifyPromotion(ctx, user, item); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Repo.GetByCode(ctx, item.Code); err == nil {
+		return nil, apperrors.ErrPromotionCodeDuplicate
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	return item, s.Repo.Create(ctx, item)
+}
+
+func (s *PromotionService) UpdateOne(ctx context.Context, user *models.User, promotion *models.Promotion, req *dto.PromotionRequest) (*models.Promotion, error) {
+	oldCode := promotion.Code
+	if err := copier.Copy(promotion, req); err != nil {
+		return nil, err
+	}
+	promotion.Code = strings.ToUpper(promotion.Code)
+	if err := s.verifyPromotion(ctx, user, promotion); err != nil {
+		return nil, err
+	}
+
+	if promotion.Code != oldCode {
+		if _, err := s.Repo.GetByCode(ctx, promotion.Code); err == nil {
+			return nil, apperrors.ErrPromotionCodeDuplicate
+		} else if err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+	}
+
+	return promotion, s.Repo.Replace(ctx, promotion)
+}
+
+func (s *PromotionService) DeleteOne(ctx context.Context, id primitive.ObjectID) error {
+	return s.Repo.Delete(ctx, id)
+}
+
+// CheckOwner reports whether the user can manage the promotion, admin can manage every promotion
+func (s *PromotionService) CheckOwner(user *models.User, promotion *models.Promotion) bool {
+	return user.Role == models.Admin || promotion.OwnerID == user.ID
+}
+
+func (s *PromotionService) VerifyStrictRequest(ctx context.Context, req *dto.PromotionRequest) error {
+	if req.Code == nil {
+		return errors.New("code is required")
+	}
+	if req.DiscountType == nil {
+		return errors.New("discountType is required")
+	}
+	if req.DiscountValue == nil {
+		return errors.New("discountValue is required")
+	}
+	if req.StartTime == nil {
+		return errors.New("startTime is required")
+	}
+	if req.EndTime == nil {
+		return errors.New("endTime is required")
+	}
+	return nil
+}
+
+// Preview validates the code against the subpackage and returns the discount it would give
+func (s *PromotionService) Preview(ctx context.Context, customer *models.User, code string, pkg *models.Package, subpackage *models.Subpackage) (*dto.PromotionPreviewResponse, error) {
+	promotion, discount, err := s.FindApplicable(ctx, customer, code, pkg, subpackage.Price)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PromotionPreviewResponse{
+		Code:          promotion.Code,
+		DiscountType:  promotion.DiscountType,
+		DiscountValue: promotion.DiscountValue,
+		OriginalPrice: subpackage.Price,
+		Discount:      discount,
+		Price:         subpackage.Price - discount,
+	}, nil
+}
+
+// FindApplicable looks up the code and checks that the customer can use it on the package at the given price
+func (s *PromotionService) FindApplicable(ctx context.Context, customer *models.User, code string, pkg *models.Package, price int) (*models.Promotion, int, error) {
+	promotion, err := s.Repo.GetByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err == mongo.ErrNoDocuments {
+		return nil, 0, apperrors.ErrPromotionNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := CheckPromotionApplicable(promotion, pkg, price, time.Now()); err != nil {
+		return nil, 0, err
+	}
+	if promotion.UsageLimit > 0 && promotion.UsedCount >= promotion.UsageLimit {
+		return nil, 0, apperrors.ErrPromotionUsageExceeded
+	}
+	// Redeem enforces the per customer limit atomically, this check only answers early
+	if promotion.PerCustomerLimit > 0 {
+		used, err := s.Repo.CountRedemptionsByCustomer(ctx, promotion.ID, customer.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if used >= int64(promotion.PerCustomerLimit) {
+			return nil, 0, apperrors.ErrPromotionUsageExceeded
+		}
+	}
+
+	return promotion, CalculateDiscount(promotion, price), nil
+}
+
+// Redeem applies the code to the appointment, reserving one use of the promotion and snapshotting the discount
+func (s *PromotionService) Redeem(ctx context.Context, customer *models.User, code string, appointment *models.Appointment) error {
+	promotion, discount, err := s.FindApplicable(ctx, customer, code, &appointment.Package, appointment.Price)
+	if err != nil {
+		return err
+	}
+
+	reserved, err := s.Repo.IncrementUsage(ctx, promotion.ID)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return apperrors.ErrPromotionUsageExceeded
+	}
+
+	redemption := &models.PromotionRedemption{
+		ID:            primitive.NewObjectID(),
+		PromotionID:   promotion.ID,
+		CustomerID:    customer.ID,
+		AppointmentID: appointment.ID,
+		CreatedTime:   time.Now(),
+	}
+	created, err := s.Repo.CreateRedemption(ctx, redemption, promotion.PerCustomerLimit)
+	if err != nil || !created {
+		_ = s.Repo.DecrementUsage(ctx, promotion.ID)
+		if err == nil {
+			err = apperrors.ErrPromotionUsageExceeded
+		}
+		return err
+	}
+
+	appointment.Discount = &models.AppointmentDiscount{
+		PromotionID:   promotion.ID,
+		Code:          promotion.Code,
+		Scope:         promotion.Scope,
+		DiscountType:  promotion.DiscountType,
+		DiscountValue: promotion.DiscountValue,
+		OriginalPrice: appointment.Price,
+		Amount:        discount,
+	}
+	appointment.Price -= discount
+	return nil
+}
+
+// Release gives back the use of the promotion redeemed by the appointment, if any
+func (s *PromotionService) Release(ctx context.Context, appointmentId primitive.ObjectID) error {
+	redemption, err := s.Repo.DeleteRedemptionByAppointmentId(ctx, appointmentId)
+	if err != nil || redemption == nil {
+		return err
+	}
+	return s.Repo.DecrementUsage(ctx, redemption.PromotionID)
+}
+
+func (s *PromotionService) verifyPromotion(ctx context.Context, user *models.User, item *models.Promotion) error {
+	if !item.EndTime.After(item.StartTime) {
+		return errors.New("endTime must be after startTime")
+	}
+	if item.DiscountType == models.DiscountPercentage && item.DiscountValue > 100 {
+		return errors.New("percentage discount cannot exceed 100")
+	}
+	if item.Scope == models.PromotionPlatform {
+		return CheckPlatformDiscount(item)
+	}
+
+	// Photographer can only run promotions on their own packages
+	for _, packageId := range item.PackageIDs {
+		pkg, err := s.PackageRepo.GetById(ctx, packageId.Hex())
+		if err != nil {
+			return err
+		}
+		if pkg.OwnerID != item.OwnerID {
+			return apperrors.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// CheckPlatformDiscount refuses a platform-wide promotion that can take more than PlatformFee off, a percentage
+// discount must be bounded by its MaxDiscount
+func CheckPlatformDiscount(promotion *models.Promotion) error {
+	switch promotion.DiscountType {
+	case models.DiscountPercentage:
+		if promotion.MaxDiscount == 0 || promotion.MaxDiscount > PlatformFee {
+			return apperrors.ErrPromotionOverPlatformFee
+		}
+	case models.DiscountFixed:
+		if promotion.DiscountValue > PlatformFee {
+			return apperrors.ErrPromotionOverPlatformFee
+		}
+	}
+	return nil
+}
+
+// CheckPromotionApplicable checks the static rules of the promotion: active flag, validity window, scope and minimum price
+func CheckPromotionApplicable(promotion *models.Promotion, pkg *models.Package, price int, now time.Time) error {
+	if !promotion.IsActive {
+		return apperrors.ErrPromotionInactive
+	}
+	if now.Before(promotion.StartTime) || now.After(promotion.EndTime) {
+		return apperrors.ErrPromotionExpired
+	}
+	if promotion.Scope == models.PromotionPhotographer && promotion.OwnerID != pkg.OwnerID {
+		return apperrors.ErrPromotionNotApplicable
+	}
+	if len(promotion.PackageIDs) > 0 {
+		found := false
+		for _, packageId := range promotion.PackageIDs {
+			if packageId == pkg.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return apperrors.ErrPromotionNotApplicable
+		}
+	}
+	if price < promotion.MinPrice {
+		return apperrors.ErrPromotionNotApplicable
+	}
+	return nil
+}
+
+// CalculateDiscount returns the discount amount in THB, never more than the price itself. A platform-wide
+// promotion is funded from the platform fee, so it never takes more than PlatformFee off and the photographer
+// is paid the same as without it. CheckPlatformDiscount refuses the promotions that would be cut by it.
+func CalculateDiscount(promotion *models.Promotion, price int) int {
+	var discount int
+	switch promotion.DiscountType {
+	case models.DiscountPercentage:
+		discount = price * promotion.DiscountValue / 100
+		if promotion.MaxDiscount > 0 && discount > promotion.MaxDiscount {
+			discount = promotion.MaxDiscount
+		}
+	case models.DiscountFixed:
+		discount = promotion.DiscountValue
+	}
+	if promotion.Scope == models.PromotionPlatform && discount > PlatformFee {
+		discount = PlatformFee
+	}
+	if discount > price {
+		discount = price
+	}
+	if discount < 0 {
+		discount = 0
+	}
+	return discount
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ProofingService lets the customer choose the photos to edit from the proofs of the appointment gallery,
+// photos beyond the limit of the subpackage are bought through an ExtraPhotos payment
+type ProofingService struct {
+	GalleryRepo         *repositories.GalleryRepository
+	UploadService       *UploadService
+	S3Service           *S3Service
+	PaymentService      *PaymentService
+	NotificationService *NotificationService
+	WatermarkService    *WatermarkService
+}
+
+func NewProofingService(galleryRepo *repositories.GalleryRepository, uploadService *UploadService, s3Service *S3Service, paymentService *PaymentService, notificationService *NotificationService, watermarkService *WatermarkService) *ProofingService {
+	return &ProofingService{
+		GalleryRepo:         galleryRepo,
+		UploadService:       uploadService,
+		S3Service:           s3Service,
+		PaymentService:      paymentService,
+		NotificationService: notificationService,
+		WatermarkService:    watermarkService,
+	}
+}
+
+// GetProofs returns the gallery for its proofs, the customer can see them once proofing is open
+func (s *ProofingService) GetProofs(ctx context.Context, user *models.User, appointment *models.Appointment) (*models.Gallery, error) {
+	gallery, err := s.GalleryRepo.GetByAppointmentId(ctx, appointment.ID)
+	if err == mongo.ErrNoDocuments {
+		return nil, apperrors.ErrGalleryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch user.ID {
+	case gallery.PhotographerID:
+		return gallery, nil
+	case gallery.CustomerID:
+		if gallery.Selection == nil || gallery.Status == models.GalleryArchived {
+			return nil, apperrors.ErrProofingNotOpen
+		}
+		return gallery, nil
+	default:
+		return nil, apperrors.ErrForbidden
+	}
+}
+
+// AddProofs keeps the confirmed uploads as private originals and generates their watermarked previews,
+// with the watermark of the photographer over the proof mark
+func (s *ProofingService) AddProofs(ctx context.Context, user *models.User, gallery *models.Gallery, uploadIds []primitive.ObjectID) (*models.Gallery, error) {
+	if err := checkSelectionUnlocked(gallery); err != nil {
+		return nil, err
+	}
+	watermark, err := s.WatermarkService.LoadImageWatermark(user.Watermark)
+	if err != nil {
+		return nil, err
+	}
+	for _, uploadId := range uploadIds {
+		proofId := primitive.NewObjectID()
+		key := fmt.Sprintf("gallery/%s/proof/%s", gallery.AppointmentID.Hex(), proofId.Hex())
+		upload, err := s.UploadService.ConfirmRaw(ctx, user.ID, uploadId, models.UploadGalleryPhoto, key)
+		if err != nil {
+			return nil, err
+		}
+		proof, previewSize, err := s.createPreview(key, watermark)
+		if err != nil {
+			_ = s.S3Service.DeleteObject(key)
+			return nil, err
+		}
+		gallery.Proofs = append(gallery.Proofs, models.GalleryProof{
+			ID:          proofId,
+			OriginalKey: key,
+			PreviewKey:  proof,
+			FileName:    GalleryFileName(upload.FileName, len(gallery.Proofs)+1, upload.ContentType),
+			ContentType: upload.ContentType,
+			Size:        upload.Size + previewSize,
+			CreatedTime: time.Now(),
+		})
+	}
+	if err := s.GalleryRepo.Replace(ctx, gallery); err != nil {
+		return nil, err
+	}
+	return gallery, nil
+}
+
+func (s *ProofingService) createPreview(key string, watermark *ImageWatermark) (string, int64, error) {
+	data, err := s.S3Service.GetObject(key)
+	if err != nil {
+		return "", 0, err
+	}
+	rendition, err := ProcessProofImage(data, watermark)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w, %v", apperrors.ErrUploadInvalid, err)
+	}
+	previewKey := fmt.Sprintf("%s_preview.%s", key, rendition.Ext)
+	if err := s.S3Service.Repo.PutObject(previewKey, rendition.Data, rendition.ContentType); err != nil {
+		return "", 0, err
+	}
+	return previewKey, int64(len(rendition.Data)), nil
+}
+
+func (s *ProofingService) RemoveProof(ctx context.Context, gallery *models.Gallery, proofId primitive.ObjectID) (*models.Gallery, error) {
+	if err := checkSelectionUnlocked(gallery); err != nil {
+		return nil, err
+	}
+	for i, proof := range gallery.Proofs {
+		if proof.ID != proofId {
+			continue
+		}
+		gallery.Proofs = append(gallery.Proofs[:i:i], gallery.Proofs[i+1:]...)
+		if gallery.Selection != nil {
+			gallery.Selection.PhotoIDs = removeObjectID(gallery.Selection.PhotoIDs, proofId)
+		}
+		if err := s.GalleryRepo.Replace(ctx, gallery); err != nil {
+			return nil, err
+		}
+		for _, key := range []string{proof.OriginalKey, proof.PreviewKey} {
+			if err := s.S3Service.DeleteObject(key); err != nil {
+				log.Println("Failed to delete proof", key, err)
+			}
+		}
+		return gallery, nil
+	}
+	return nil, apperrors.ErrProofNotFound
+}
+
+// OpenProofing lets the customer select, the limit and the price are taken from the subpackage of the appointment,
+// opening it again unlocks a submitted selection so the customer can change it without paying the extra photos twice
+func (s *ProofingService) OpenProofing(ctx context.Context, gallery *models.Gallery, appointment *models.Appointment) (*models.Gallery, error) {
+	if len(gallery.Proofs) == 0 {
+		return nil, apperrors.ErrProofingEmpty
+	}
+	photoIds, paidExtraCount := []primitive.ObjectID{}, 0
+	if gallery.Selection != nil {
+		if gallery.Selection.Status == models.ProofSelectionAwaitingPayment {
+			return nil, apperrors.ErrProofSelectionLocked
+		}
+		photoIds, paidExtraCount = gallery.Selection.PhotoIDs, gallery.Selection.PaidExtraCount
+	}
+	gallery.Selection = &models.ProofSelection{
+		PhotoIDs:        photoIds,
+		PaidExtraCount:  paidExtraCount,
+		Limit:           appointment.Subpackage.SelectionLimit,
+		ExtraPhotoPrice: appointment.Subpackage.ExtraPhotoPrice,
+		Status:          models.ProofSelectionOpen,
+	}
+	if err := s.GalleryRepo.Replace(ctx, gallery); err != nil {
+		return nil, err
+	}
+	return gallery, nil
+}
+
+// UpdateSelection replaces the selected proofs of the customer while the selection is open
+func (s *ProofingService) UpdateSelection(ctx context.Context, user *models.User, gallery *models.Gallery, photoIds []primitive.ObjectID) (*models.Gallery, error) {
+	if err := checkCustomerSelection(user, gallery); err != nil {
+		return nil, err
+	}
+	if gallery.Selection.Status != models.ProofSelectionOpen {
+		return nil, apperrors.ErrProofSelectionLocked
+	}
+	if err := validateSelection(gallery.Proofs, photoIds); err != nil {
+		return nil, err
+	}
+
+	gallery.Selection.PhotoIDs = photoIds
+	if err := s.GalleryRepo.Replace(ctx, gallery); err != nil {
+		return nil, err
+	}
+	return gallery, nil
+}
+
+// SubmitSelection locks the selection, when photos are selected beyond the limit the selection waits for
+// the ExtraPhotos payment and the checkout session is returned. Submitting again expires the previous checkout
+// session and gives a new one.
+func (s *ProofingService) SubmitSelection(ctx context.Context, user *models.User, gallery *models.Gallery, appointment *models.Appointment, successURL string, cancelURL string) (*models.Gallery, string, error) {
+	if err := checkCustomerSelection(user, gallery); err != nil {
+		return nil, "", err
+	}
+	selection := gallery.Selection
+	if selection.Status == models.ProofSelectionSubmitted {
+		return nil, "", apperrors.ErrProofSelectionLocked
+	}
+	if len(selection.PhotoIDs) == 0 {
+		return nil, "", apperrors.ErrProofSelectionEmpty
+	}
+
+	selection.ExtraCount = ExtraSelectionCount(len(selection.PhotoIDs), selection.Limit)
+	unpaidCount := selection.ExtraCount - selection.PaidExtraCount
+	if unpaidCount <= 0 {
+		if err := s.submit(ctx, gallery); err != nil {
+			return nil, "", err
+		}
+		return gallery, "", nil
+	}
+	if selection.ExtraPhotoPrice <= 0 {
+		return nil, "", apperrors.ErrProofSelectionLimit
+	}
+
+	if selection.Status == models.ProofSelectionAwaitingPayment && selection.ExtraPaymentID != nil {
+		previous, err := s.PaymentService.GetPaymentById(ctx, *selection.ExtraPaymentID)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := s.PaymentService.ExpireCheckout(ctx, previous); err != nil {
+			if errors.Is(err, apperrors.ErrCheckoutCompleted) {
+				return nil, "", apperrors.ErrExtraPhotoPaymentPaid
+			}
+			return nil, "", err
+		}
+	}
+
+	// The snapshot price is used even if the photographer changed the subpackage after proofing was opened
+	appointment.Subpackage.ExtraPhotoPrice = selection.ExtraPhotoPrice
+	payment, checkoutSession, err := s.PaymentService.CreateExtraPhotoPayment(ctx, user, appointment, unpaidCount, successURL, cancelURL)
+	if err != nil {
+		return nil, "", err
+	}
+	selection.Status = models.ProofSelectionAwaitingPayment
+	selection.ExtraPaymentID = &payment.ID
+	if err := s.GalleryRepo.Replace(ctx, gallery); err != nil {
+		return nil, "", err
+	}
+	return gallery, checkoutSession.URL, nil
+}
+
+// CompleteExtraPhotoPayment submits the selection once its ExtraPhotos payment is paid,
+// it is registered on the payment service as the handler of the payment type
+func (s *ProofingService) CompleteExtraPhotoPayment(ctx context.Context, payment *models.Payment) error {
+	gallery, err := s.GalleryRepo.GetByAppointmentId(ctx, payment.AppointmentID)
+	if err != nil {
+		return err
+	}
+	selection := gallery.Selection
+	if selection == nil || selection.Status != models.ProofSelectionAwaitingPayment {
+		log.Println("Extra photo payment", payment.ID.Hex(), "is not awaited by the selection of gallery", gallery.ID.Hex())
+		return nil
+	}
+	if !CreditExtraPhotoPayment(selection, payment) {
+		log.Println("Extra photo payment", payment.ID.Hex(), "replaced by a later submission of gallery", gallery.ID.Hex())
+		return s.GalleryRepo.Replace(ctx, gallery)
+	}
+	return s.submit(ctx, gallery)
+}
+
+// CreditExtraPhotoPayment counts the photos of the paid payment as paid and reports whether every extra photo is paid.
+// The payment of the current checkout pays them all, a checkout replaced by a later submission that was paid before it
+// expired pays for the photos it was created for.
+func CreditExtraPhotoPayment(selection *models.ProofSelection, payment *models.Payment) bool {
+	if selection.ExtraPaymentID != nil && *selection.ExtraPaymentID == payment.ID {
+		selection.PaidExtraCount = selection.ExtraCount
+		return true
+	}
+	if selection.ExtraPhotoPrice > 0 {
+		selection.PaidExtraCount += payment.Amount / selection.ExtraPhotoPrice
+	}
+	if selection.PaidExtraCount < selection.ExtraCount {
+		return false
+	}
+	selection.PaidExtraCount = selection.ExtraCount
+	return true
+}
+
+func (s *ProofingService) submit(ctx context.Context, gallery *models.Gallery) error {
+	now := time.Now()
+	gallery.Selection.Status = models.ProofSelectionSubmitted
+	gallery.Selection.SubmittedTime = &now
+	if err := s.GalleryRepo.Replace(ctx, gallery); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("The customer selected %d photos to edit", len(gallery.Selection.PhotoIDs))
+	if gallery.Selection.ExtraCount > 0 {
+		message += fmt.Sprintf(", including %d paid extra photos", gallery.Selection.ExtraCount)
+	}
+	s.NotificationService.Notify(ctx, gallery.PhotographerID, models.NotificationProofSelectionSubmitted, "Proof selection submitted", message, &gallery.AppointmentID)
+	return nil
+}
+
+// MapToProofResponse signs the preview URL of every proof, the originals are never shared
+func (s *ProofingService) MapToProofResponse(gallery *models.Gallery) (*dto.ProofResponse, error) {
+	res := &dto.ProofResponse{
+		GalleryID:     gallery.ID,
+		AppointmentID: gallery.AppointmentID,
+		Proofs:        []dto.ProofPhotoResponse{},
+		Selection:     gallery.Selection,
+	}
+	urlExpireTime := time.Now().Add(GalleryURLExpireDuration)
+	for _, proof := range gallery.Proofs {
+		url, err := s.S3Service.PresignGet(proof.PreviewKey, GalleryURLExpireDuration, "")
+		if err != nil {
+			return nil, err
+		}
+		res.Proofs = append(res.Proofs, dto.ProofPhotoResponse{
+			ID:            proof.ID,
+			FileName:      proof.FileName,
+			URL:           url,
+			URLExpireTime: urlExpireTime,
+		})
+	}
+	return res, nil
+}
+
+// ExtraSelectionCount is the number of selected photos beyond the limit, there is no limit when it is 0
+func ExtraSelectionCount(selected int, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return max(selected-limit, 0)
+}
+
+func checkSelectionUnlocked(gallery *models.Gallery) error {
+	if gallery.Selection != nil && gallery.Selection.Status != models.ProofSelectionOpen {
+		return apperrors.ErrProofSelectionLocked
+	}
+	return nil
+}
+
+func checkCustomerSelection(user *models.User, gallery *models.Gallery) error {
+	if gallery.CustomerID != user.ID {
+		return apperrors.ErrForbidden
+	}
+	if gallery.Selection == nil || gallery.Status == models.GalleryArchived {
+		return apperrors.ErrProofingNotOpen
+	}
+	return nil
+}
+
+func validateSelection(proofs []models.GalleryProof, photoIds []primitive.ObjectID) error {
+	exists := map[primitive.ObjectID]bool{}
+	for _, proof := range proofs {
+		exists[proof.ID] = true
+	}
+	for _, id := range photoIds {
+		if !exists[id] {
+			return apperrors.ErrProofSelectionInvalid
+		}
+		// Each proof can be selected once
+		exists[id] = false
+	}
+	return nil
+}
+
+func removeObjectID(ids []primitive.ObjectID, id primitive.ObjectID) []primitive.ObjectID {
+	result := []primitive.ObjectID{}
+	for _, item := range ids {
+		if item != id {
+			result = append(result, item)
+		}
+	}
+	return result
+}
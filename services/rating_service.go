@@ -6,12 +6,14 @@ import (
 	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type RatingService struct {
 	Repository *repositories.RatingRepository
+	Audit      *AuditService
 }
 
 func NewRatingService(repository *repositories.RatingRepository) *RatingService {
@@ -28,17 +30,27 @@ func (s *RatingService) GetByPhotographerId(ctx context.Context, photographerId
 	return s.Repository.GetByPhotographerId(ctx, photographerId)
 }
 
-func (s *RatingService) GetById(ctx context.Context, photographerId, ratingId primitive.ObjectID) (*models.Rating, error) {
-	var item *models.Rating
-	isPhotographerOwner, err := s.Repository.IsPhotographerRatingOwner(ctx, photographerId, ratingId)
+func (s *RatingService) GetById(ctx context.Context, user *models.User, photographerId, ratingId primitive.ObjectID) (*models.Rating, error) {
+	return s.GetAuthorized(ctx, user, photographerId, ratingId, policies.ActionRead)
+}
+
+// GetAuthorized returns the rating of the photographer when the user can take the action on it
+func (s *RatingService) GetAuthorized(ctx context.Context, user *models.User, photographerId, ratingId primitive.ObjectID, action policies.Action) (*models.Rating, error) {
+	rating, err := s.Repository.GetById(ctx, ratingId)
+	if isNotFound(err) {
+		return nil, apperrors.ErrRatingNotFound
+	}
 	if err != nil {
-		return item, err
+		return nil, err
 	}
-	if !isPhotographerOwner {
-		return item, apperrors.ErrPhotographerRatingMismatched
+	// A rating is only found under the photographer it is about
+	if rating.PhotographerID != photographerId {
+		return nil, apperrors.ErrRatingNotFound
 	}
-
-	return s.Repository.GetById(ctx, ratingId)
+	if err := policies.Authorize(user, action, policies.Rating(rating)); err != nil {
+		return nil, err
+	}
+	return rating, nil
 }
 
 func (s *RatingService) CreateOneFromCustomer(ctx context.Context, request *dto.RatingRequest, customerId primitive.ObjectID, photographerId primitive.ObjectID) error {
@@ -50,19 +62,20 @@ func (s *RatingService) CreateOneFromCustomer(ctx context.Context, request *dto.
 	if hasReviewed {
 		return apperrors.ErrAlreadyReviewed
 	}
-	return s.Repository.CreateOne(ctx, model)
-}
-
-func (s *RatingService) UpdateOne(ctx context.Context, customerId, photographerId, ratingId primitive.ObjectID, request *dto.RatingRequest) error {
-	if err := s.IsOwner(ctx, customerId, photographerId, ratingId); err != nil {
+	model.ID = primitive.NewObjectID()
+	if err := s.Repository.CreateOne(ctx, model); err != nil {
 		return err
 	}
+	s.Audit.Record(ctx, models.AuditRating, model.ID, nil, model)
+	return nil
+}
 
-	// Fetch the existing rating
-	existingRating, err := s.Repository.GetById(ctx, ratingId)
+func (s *RatingService) UpdateOne(ctx context.Context, user *models.User, photographerId, ratingId primitive.ObjectID, request *dto.RatingRequest) error {
+	existingRating, err := s.GetAuthorized(ctx, user, photographerId, ratingId, policies.ActionUpdate)
 	if err != nil {
 		return err
 	}
+	before := AuditSnapshot(existingRating)
 
 	// Update fields
 	existingRating.Rating = request.Rating
@@ -70,16 +83,25 @@ func (s *RatingService) UpdateOne(ctx context.Context, customerId, photographerI
 		existingRating.Review = *request.Review
 	}
 
-	return s.Repository.UpdateOne(ctx, existingRating)
+	if err := s.Repository.UpdateOne(ctx, existingRating); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditRating, existingRating.ID, before, existingRating)
+	return nil
 }
 
-func (s *RatingService) DeleteOne(ctx context.Context, customerId, photographerId, ratingId primitive.ObjectID) error {
-	if err := s.IsOwner(ctx, customerId, photographerId, ratingId); err != nil {
+func (s *RatingService) DeleteOne(ctx context.Context, user *models.User, photographerId, ratingId primitive.ObjectID) error {
+	rating, err := s.GetAuthorized(ctx, user, photographerId, ratingId, policies.ActionDelete)
+	if err != nil {
 		return err
 	}
 
 	// Proceed with deletion
-	return s.Repository.DeleteOne(ctx, ratingId)
+	if err := s.Repository.DeleteOne(ctx, ratingId); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditRating, rating.ID, rating, nil)
+	return nil
 }
 
 func (s *RatingService) MappedToRatingResponse(ctx context.Context, item *models.Rating) (*dto.RatingResponse, error) {
@@ -93,26 +115,3 @@ func (s *RatingService) MappedToRatingResponse(ctx context.Context, item *models
 	}, nil
 
 }
-
-func (s *RatingService) IsOwner(ctx context.Context, customerId, photographerId, ratingId primitive.ObjectID) error {
-	// Check if the rating exists and was written by this user
-	isCustomerOwner, err := s.Repository.IsCustomerRatingOwner(ctx, customerId, ratingId)
-	if err != nil {
-		return err
-	}
-	if !isCustomerOwner {
-		return apperrors.ErrCustomerRatingMismatched
-	}
-
-	// Check if the rating exists and was written by this user
-	isPhotographerOwner, err := s.Repository.IsPhotographerRatingOwner(ctx, photographerId, ratingId)
-	if err != nil {
-		return err
-	}
-	if !isPhotographerOwner {
-		return apperrors.ErrPhotographerRatingMismatched
-	}
-
-	return nil
-
-}
@@ -1,19 +1,36 @@
 package services
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/http"
 	"strings"
+	"time"
 
-	repositories "github.com/Bualoi-s-Dev/backend/repositories/s3"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// OriginalPrefix keeps the private originals of the public images, the renditions are generated from them
+const OriginalPrefix = "original/"
+
 type S3Service struct {
-	Repo *repositories.S3Repository
+	Repo repositories.StorageRepository
+
+	// Quota is checked before an upload of an owner is stored, nothing is checked when it is nil
+	Quota StorageQuotaChecker
+}
+
+// StorageQuotaChecker refuses an upload that would take its owner over the storage quota
+type StorageQuotaChecker interface {
+	CheckQuota(ctx context.Context, ownerId primitive.ObjectID, size int64, photos int) error
 }
 
-func NewS3Service(repo *repositories.S3Repository) *S3Service {
+func NewS3Service(repo repositories.StorageRepository) *S3Service {
 	return &S3Service{Repo: repo}
 }
 
@@ -35,15 +52,233 @@ func (s *S3Service) UploadBase64(fileBytes []byte, key string) (string, error) {
 	return s.Repo.UploadBase64(imageData, key, ext)
 }
 
+// UploadImage processes the base64 image of the owner into renditions and uploads them under the key prefix
+func (s *S3Service) UploadImage(ctx context.Context, ownerId primitive.ObjectID, base64Str string, key string) (*models.Image, error) {
+	imageData, err := s.decodeBase64Image(base64Str)
+	if err != nil {
+		return nil, err
+	}
+	return s.UploadImageBytes(ctx, ownerId, imageData, key)
+}
+
+// UploadImageBytes processes the raw image of the owner into renditions and uploads them under the key prefix,
+// it is not counted as a photo in the quota
+func (s *S3Service) UploadImageBytes(ctx context.Context, ownerId primitive.ObjectID, imageData []byte, key string) (*models.Image, error) {
+	renditions, err := ProcessImage(imageData, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckQuota(ctx, ownerId, renditionsSize(renditions), 0); err != nil {
+		return nil, err
+	}
+	id := primitive.NewObjectID()
+	return s.putRenditions(renditions, key+"_"+id.Hex(), &models.Image{ID: id})
+}
+
+// UploadPortfolioBase64 is UploadPortfolioImages for base64 images
+func (s *S3Service) UploadPortfolioBase64(ctx context.Context, ownerId primitive.ObjectID, base64Strs []string, key string, watermark *ImageWatermark) ([]models.Image, error) {
+	images := make([][]byte, len(base64Strs))
+	for i, base64Str := range base64Strs {
+		imageData, err := s.decodeBase64Image(base64Str)
+		if err != nil {
+			return nil, err
+		}
+		images[i] = imageData
+	}
+	return s.UploadPortfolioImages(ctx, ownerId, images, key, watermark)
+}
+
+// UploadPortfolioImage is UploadPortfolioImages for a single image
+func (s *S3Service) UploadPortfolioImage(ctx context.Context, ownerId primitive.ObjectID, imageData []byte, key string, watermark *ImageWatermark) (*models.Image, error) {
+	items, err := s.UploadPortfolioImages(ctx, ownerId, [][]byte{imageData}, key, watermark)
+	if err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// UploadPortfolioImages keeps the originals privately under OriginalPrefix and uploads the watermarked renditions,
+// so the renditions can be generated again when the watermark changes. The perceptual hash of each original is kept
+// to find copied photos. Every image is processed before anything is stored, so the quota is checked once against
+// the bytes the originals and their renditions take, and either every image is stored or none
+func (s *S3Service) UploadPortfolioImages(ctx context.Context, ownerId primitive.ObjectID, images [][]byte, key string, watermark *ImageWatermark) ([]models.Image, error) {
+	processed := make([]processedImage, len(images))
+	var size int64
+	for i, imageData := range images {
+		hash, err := PerceptualHash(imageData)
+		if err != nil {
+			return nil, err
+		}
+		renditions, err := ProcessImage(imageData, watermark)
+		if err != nil {
+			return nil, err
+		}
+		processed[i] = processedImage{original: imageData, hash: hash, renditions: renditions}
+		size += int64(len(imageData)) + renditionsSize(renditions)
+	}
+	if err := s.CheckQuota(ctx, ownerId, size, len(images)); err != nil {
+		return nil, err
+	}
+
+	items := []models.Image{}
+	for _, image := range processed {
+		item, err := s.putPortfolioImage(&image, key)
+		if err != nil {
+			for _, uploaded := range items {
+				_ = s.DeleteImage(&uploaded)
+			}
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+// processedImage is a portfolio image with its renditions, before it is stored
+type processedImage struct {
+	original   []byte
+	hash       string
+	renditions []ImageRendition
+}
+
+func (s *S3Service) putPortfolioImage(image *processedImage, key string) (*models.Image, error) {
+	id := primitive.NewObjectID()
+	genKey := key + "_" + id.Hex()
+	originalKey := OriginalPrefix + genKey
+	if err := s.Repo.PutObject(originalKey, image.original, http.DetectContentType(image.original)); err != nil {
+		return nil, err
+	}
+	item, err := s.putRenditions(image.renditions, genKey, &models.Image{ID: id})
+	if err != nil {
+		_ = s.Repo.DeleteObject(originalKey)
+		return nil, err
+	}
+	item.OriginalKey = originalKey
+	item.PerceptualHash = image.hash
+	item.Size += int64(len(image.original))
+	return item, nil
+}
+
+// CheckQuota refuses size more bytes and photos of the owner when they go over the quota, uploads without
+// an owner are not checked
+func (s *S3Service) CheckQuota(ctx context.Context, ownerId primitive.ObjectID, size int64, photos int) error {
+	if s.Quota == nil || ownerId.IsZero() {
+		return nil
+	}
+	return s.Quota.CheckQuota(ctx, ownerId, size, photos)
+}
+
+// RenderPortfolioImage generates the renditions of the image again from its original, under new keys so the
+// old renditions are not served from a cache, the old renditions are left for the caller to delete
+func (s *S3Service) RenderPortfolioImage(item *models.Image, watermark *ImageWatermark) (*models.Image, error) {
+	imageData, err := s.Repo.GetObject(item.OriginalKey)
+	if err != nil {
+		return nil, err
+	}
+	renditions, err := ProcessImage(imageData, watermark)
+	if err != nil {
+		return nil, err
+	}
+	genKey := strings.TrimPrefix(item.OriginalKey, OriginalPrefix) + "_" + primitive.NewObjectID().Hex()
+	return s.putRenditions(renditions, genKey, &models.Image{ID: item.ID, Caption: item.Caption, OriginalKey: item.OriginalKey, PerceptualHash: item.PerceptualHash})
+}
+
+// renditionsSize is the bytes the renditions take once they are stored
+func renditionsSize(renditions []ImageRendition) int64 {
+	var size int64
+	for _, rendition := range renditions {
+		size += int64(len(rendition.Data))
+	}
+	return size
+}
+
+func (s *S3Service) putRenditions(renditions []ImageRendition, genKey string, item *models.Image) (*models.Image, error) {
+	for _, rendition := range renditions {
+		renditionKey := fmt.Sprintf("%s_%s.%s", genKey, rendition.Name, rendition.Ext)
+		if err := s.Repo.PutObject(renditionKey, rendition.Data, rendition.ContentType); err != nil {
+			// Do not leave a partial set of renditions behind
+			_ = s.DeleteRenditions(item)
+			return nil, err
+		}
+
+		item.Size += int64(len(rendition.Data))
+		switch {
+		case rendition.Ext == "webp":
+			item.WebP = "/" + renditionKey
+		case rendition.Name == "thumbnail":
+			item.Thumbnail = "/" + renditionKey
+		case rendition.Name == "medium":
+			item.Medium = "/" + renditionKey
+		case rendition.Name == "large":
+			item.Large = "/" + renditionKey
+			item.Width, item.Height = rendition.Width, rendition.Height
+		}
+	}
+	return item, nil
+}
+
+// DeleteImage removes every rendition of the image and its original
+func (s *S3Service) DeleteImage(item *models.Image) error {
+	if err := s.DeleteRenditions(item); err != nil {
+		return err
+	}
+	if item.OriginalKey != "" {
+		return s.Repo.DeleteObject(item.OriginalKey)
+	}
+	return nil
+}
+
+// DeleteRenditions removes the renditions of the image and keeps its original
+func (s *S3Service) DeleteRenditions(item *models.Image) error {
+	for _, key := range item.Keys() {
+		if err := s.Repo.DeleteObject(strings.TrimPrefix(key, "/")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *S3Service) DeleteObject(key string) error {
 	return s.Repo.DeleteObject(key)
 }
 
+func (s *S3Service) GetObject(key string) ([]byte, error) {
+	return s.Repo.GetObject(key)
+}
+
+func (s *S3Service) OpenObject(key string) (io.ReadCloser, error) {
+	return s.Repo.OpenObject(key)
+}
+
+func (s *S3Service) PutObjectStream(key string, body io.Reader, contentType string) error {
+	return s.Repo.PutObjectStream(key, body, contentType)
+}
+
+func (s *S3Service) PresignGet(key string, expires time.Duration, downloadName string) (string, error) {
+	return s.Repo.PresignGet(key, expires, downloadName)
+}
+
+func (s *S3Service) PresignPut(key string, contentType string, size int64, expires time.Duration) (string, error) {
+	return s.Repo.PresignPut(key, contentType, size, expires)
+}
+
+// VerifyBase64 checks the data URL header and that the payload is really an image
 func (s *S3Service) VerifyBase64(base64Str string) error {
-	_, _, err := s.DetectMimeType(base64Str)
+	imageData, err := s.decodeBase64Image(base64Str)
+	if err != nil {
+		return err
+	}
+	_, err = VerifyImage(imageData)
 	return err
 }
 
+func (s *S3Service) decodeBase64Image(base64Str string) ([]byte, error) {
+	if _, _, err := s.DetectMimeType(base64Str); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(strings.SplitN(base64Str, ",", 2)[1])
+}
+
 func (s *S3Service) VerifyMultipleBase64(base64Strs []string) error {
 	for _, base64Str := range base64Strs {
 		if err := s.VerifyBase64(base64Str); err != nil {
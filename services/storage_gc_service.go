@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+)
+
+const (
+	// DefaultStorageGCGracePeriod keeps recent objects, a package may still be created from them
+	DefaultStorageGCGracePeriod = 24 * time.Hour
+	// DefaultStorageGCQuarantineRetention is how long quarantined objects are kept before they are purged
+	DefaultStorageGCQuarantineRetention = 7 * 24 * time.Hour
+	StorageGCQuarantinePrefix           = "quarantine/"
+)
+
+// StorageGCPrefixes are the storage prefixes whose objects must be referenced by a package, a user or a held photo
+var StorageGCPrefixes = []string{"package/", "profile/", "original/", "watermark/"}
+
+type StorageGCMode string
+
+const (
+	StorageGCDelete     StorageGCMode = "delete"
+	StorageGCQuarantine StorageGCMode = "quarantine"
+)
+
+type StorageGCOptions struct {
+	Mode                StorageGCMode
+	DryRun              bool
+	GracePeriod         time.Duration
+	QuarantineRetention time.Duration
+}
+
+type StorageGCService struct {
+	S3Service      *S3Service
+	PackageRepo    *repositories.PackageRepository
+	UserRepo       *repositories.UserRepository
+	ModerationRepo *repositories.PhotoModerationRepository
+}
+
+func NewStorageGCService(s3Service *S3Service, packageRepo *repositories.PackageRepository, userRepo *repositories.UserRepository, moderationRepo *repositories.PhotoModerationRepository) *StorageGCService {
+	return &StorageGCService{S3Service: s3Service, PackageRepo: packageRepo, UserRepo: userRepo, ModerationRepo: moderationRepo}
+}
+
+// Run lists the objects under the GC prefixes and removes or quarantines the ones no document references,
+// nothing is changed in dry-run mode and the report lists what would be done
+func (s *StorageGCService) Run(ctx context.Context, opts StorageGCOptions) (*dto.StorageGCReport, error) {
+	now := time.Now()
+	report := &dto.StorageGCReport{
+		Mode:        string(opts.Mode),
+		DryRun:      opts.DryRun,
+		StartedTime: now,
+		Orphans:     []models.StorageObject{},
+		Errors:      []string{},
+	}
+
+	// List the objects before the references, so an object uploaded meanwhile is either recent or referenced
+	objects := []models.StorageObject{}
+	for _, prefix := range StorageGCPrefixes {
+		items, err := s.S3Service.Repo.ListObjects(prefix)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, items...)
+	}
+	referenced, err := s.referencedKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Scanned = len(objects)
+	report.Orphans, report.Recent = FindOrphanedObjects(objects, referenced, now.Add(-opts.GracePeriod))
+	report.Referenced = report.Scanned - len(report.Orphans) - report.Recent
+	for _, object := range report.Orphans {
+		report.OrphanBytes += object.Size
+	}
+
+	if opts.Mode == StorageGCQuarantine {
+		purged, err := s.purgeQuarantine(opts, now, report)
+		if err != nil {
+			return nil, err
+		}
+		report.Purged = purged
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	for _, object := range report.Orphans {
+		var err error
+		if opts.Mode == StorageGCQuarantine {
+			err = s.quarantine(object.Key)
+		} else {
+			err = s.S3Service.DeleteObject(object.Key)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", object.Key, err))
+			continue
+		}
+		report.Collected++
+	}
+	return report, nil
+}
+
+// RunScheduled runs the GC from the auto update job and logs the report
+func (s *StorageGCService) RunScheduled(ctx context.Context, opts StorageGCOptions) {
+	report, err := s.Run(ctx, opts)
+	if err != nil {
+		log.Println("Storage GC failed:", err)
+		return
+	}
+	log.Printf("Storage GC (%s, dry-run=%t): scanned %d, orphaned %d (%d bytes), collected %d, purged %d, errors %d\n",
+		report.Mode, report.DryRun, report.Scanned, len(report.Orphans), report.OrphanBytes, report.Collected, report.Purged, len(report.Errors))
+}
+
+// FindOrphanedObjects returns the objects that are not referenced and older than the cutoff,
+// and the number of unreferenced objects kept because they are newer
+func FindOrphanedObjects(objects []models.StorageObject, referenced map[string]bool, cutoff time.Time) ([]models.StorageObject, int) {
+	orphans := []models.StorageObject{}
+	recent := 0
+	for _, object := range objects {
+		if referenced[object.Key] {
+			continue
+		}
+		if object.LastModified.After(cutoff) {
+			recent++
+			continue
+		}
+		orphans = append(orphans, object)
+	}
+	return orphans, recent
+}
+
+func (s *StorageGCService) referencedKeys(ctx context.Context) (map[string]bool, error) {
+	referenced := map[string]bool{}
+	add := func(key string) {
+		if key != "" {
+			referenced[strings.TrimPrefix(key, "/")] = true
+		}
+	}
+
+	pkgs, err := s.PackageRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		for _, photo := range pkg.PhotoUrls {
+			add(photo)
+		}
+		for _, image := range pkg.Images {
+			for _, key := range image.Keys() {
+				add(key)
+			}
+			add(image.OriginalKey)
+		}
+	}
+
+	users, err := s.UserRepo.FindAllWithProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		add(user.Profile)
+		if user.ProfileImage != nil {
+			for _, key := range user.ProfileImage.Keys() {
+				add(key)
+			}
+		}
+		if user.Watermark != nil {
+			add(user.Watermark.LogoKey)
+		}
+	}
+
+	held, err := s.ModerationRepo.GetMany(ctx, models.PhotoModerationPending, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range held {
+		for _, key := range item.Image.Keys() {
+			add(key)
+		}
+		add(item.Image.OriginalKey)
+	}
+	return referenced, nil
+}
+
+// quarantine moves the object under the quarantine prefix, so it can be restored until it is purged
+func (s *StorageGCService) quarantine(key string) error {
+	data, err := s.S3Service.GetObject(key)
+	if err != nil {
+		return err
+	}
+	if err := s.S3Service.Repo.PutObject(StorageGCQuarantinePrefix+key, data, mime.TypeByExtension(path.Ext(key))); err != nil {
+		return err
+	}
+	return s.S3Service.DeleteObject(key)
+}
+
+func (s *StorageGCService) purgeQuarantine(opts StorageGCOptions, now time.Time, report *dto.StorageGCReport) (int, error) {
+	objects, err := s.S3Service.Repo.ListObjects(StorageGCQuarantinePrefix)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	cutoff := now.Add(-opts.QuarantineRetention)
+	for _, object := range objects {
+		if object.LastModified.After(cutoff) {
+			continue
+		}
+		if !opts.DryRun {
+			if err := s.S3Service.DeleteObject(object.Key); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", object.Key, err))
+				continue
+			}
+		}
+		purged++
+	}
+	return purged, nil
+}
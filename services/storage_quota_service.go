@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StorageQuota is the most a photographer can store, a zero field is unlimited
+type StorageQuota struct {
+	Bytes  int64
+	Photos int
+}
+
+var DefaultStorageQuota = StorageQuota{Bytes: 10 << 30, Photos: 5000}
+
+// StorageQuotaService computes the storage usage of the users from their documents, so it never drifts
+// from what is stored, and refuses the uploads of photographers over the quota
+type StorageQuotaService struct {
+	UserRepo       *repositories.UserRepository
+	PackageRepo    *repositories.PackageRepository
+	GalleryRepo    *repositories.GalleryRepository
+	ModerationRepo *repositories.PhotoModerationRepository
+	Quota          StorageQuota
+}
+
+func NewStorageQuotaService(userRepo *repositories.UserRepository, packageRepo *repositories.PackageRepository, galleryRepo *repositories.GalleryRepository, moderationRepo *repositories.PhotoModerationRepository, quota StorageQuota) *StorageQuotaService {
+	return &StorageQuotaService{UserRepo: userRepo, PackageRepo: packageRepo, GalleryRepo: galleryRepo, ModerationRepo: moderationRepo, Quota: quota}
+}
+
+// QuotaOf returns the quota of the user, only photographers store photos so the other roles are unlimited
+func (s *StorageQuotaService) QuotaOf(user *models.User) StorageQuota {
+	if user.Role != models.Photographer {
+		return StorageQuota{}
+	}
+	return s.Quota
+}
+
+// GetUsage returns the usage of the user with their quota
+func (s *StorageQuotaService) GetUsage(ctx context.Context, user *models.User) (*dto.StorageUsageResponse, error) {
+	res, err := s.usage(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	quota := s.QuotaOf(user)
+	res.QuotaBytes, res.QuotaPhotos = quota.Bytes, quota.Photos
+	return res, nil
+}
+
+// CheckQuota refuses size more bytes and photos when the owner would go over the quota
+func (s *StorageQuotaService) CheckQuota(ctx context.Context, ownerId primitive.ObjectID, size int64, photos int) error {
+	user, err := s.UserRepo.FindUserByID(ctx, ownerId)
+	if err != nil {
+		return err
+	}
+	quota := s.QuotaOf(user)
+	if quota == (StorageQuota{}) {
+		return nil
+	}
+	usage, err := s.usage(ctx, user)
+	if err != nil {
+		return err
+	}
+	if ExceedsStorageQuota(quota, usage, size, photos) {
+		return apperrors.ErrStorageQuotaExceeded
+	}
+	return nil
+}
+
+func (s *StorageQuotaService) usage(ctx context.Context, user *models.User) (*dto.StorageUsageResponse, error) {
+	packages, err := s.PackageRepo.GetByOwnerId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	galleries, err := s.GalleryRepo.GetByPhotographerId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	held, err := s.ModerationRepo.GetPendingByOwnerId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return StorageUsageOf(user, packages, galleries, held), nil
+}
+
+// StorageUsageOf sums the package photos, the photos held for review, the photos and proofs of the galleries
+// that are not archived, and the bytes of the profile picture. Photos stored before their size was kept count no bytes
+func StorageUsageOf(user *models.User, packages []models.Package, galleries []models.Gallery, held []models.PhotoModeration) *dto.StorageUsageResponse {
+	usage := &dto.StorageUsageResponse{}
+	for _, pkg := range packages {
+		for _, image := range pkg.Images {
+			usage.UsedBytes += image.Size
+			usage.UsedPhotos++
+		}
+	}
+	for _, item := range held {
+		usage.UsedBytes += item.Image.Size
+		usage.UsedPhotos++
+	}
+	for _, gallery := range galleries {
+		// Archived photos are kept by the platform in the colder storage
+		if gallery.Status == models.GalleryArchived {
+			continue
+		}
+		for _, photo := range gallery.Photos {
+			usage.UsedBytes += photo.Size
+			usage.UsedPhotos++
+		}
+		for _, proof := range gallery.Proofs {
+			usage.UsedBytes += proof.Size
+			usage.UsedPhotos++
+		}
+	}
+	if user.ProfileImage != nil {
+		usage.UsedBytes += user.ProfileImage.Size
+	}
+	return usage
+}
+
+// ExceedsStorageQuota tells whether storing size more bytes and photos goes over the quota
+func ExceedsStorageQuota(quota StorageQuota, usage *dto.StorageUsageResponse, size int64, photos int) bool {
+	if quota.Bytes > 0 && usage.UsedBytes+size > quota.Bytes {
+		return true
+	}
+	return quota.Photos > 0 && photos > 0 && usage.UsedPhotos+photos > quota.Photos
+}
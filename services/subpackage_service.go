@@ -6,8 +6,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
 	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
 	"github.com/Bualoi-s-Dev/backend/utils"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -18,10 +20,13 @@ type SubpackageService struct {
 	PackageRepository     *repositories.PackageRepository
 	BusyTimeRepository    *repositories.BusyTimeRepository
 	AppointmentRepository *repositories.AppointmentRepository
+	UserRepository        UserRepositoryInterface
+	Audit                 *AuditService
 }
 
-func NewSubpackageService(repository *repositories.SubpackageRepository, packageRepository *repositories.PackageRepository, busyTimeRepository *repositories.BusyTimeRepository, appointmentRepo *repositories.AppointmentRepository) *SubpackageService {
-	return &SubpackageService{Repository: repository, PackageRepository: packageRepository, BusyTimeRepository: busyTimeRepository, AppointmentRepository: appointmentRepo}
+func NewSubpackageService(repository *repositories.SubpackageRepository, packageRepository *repositories.PackageRepository, busyTimeRepository *repositories.BusyTimeRepository, appointmentRepo *repositories.AppointmentRepository,
+	userRepository UserRepositoryInterface) *SubpackageService {
+	return &SubpackageService{Repository: repository, PackageRepository: packageRepository, BusyTimeRepository: busyTimeRepository, AppointmentRepository: appointmentRepo, UserRepository: userRepository}
 }
 
 func (s *SubpackageService) GetAll(ctx context.Context) ([]models.Subpackage, error) {
@@ -33,6 +38,15 @@ func (s *SubpackageService) GetFilteredSubpackages(ctx context.Context, filters
 	if err != nil {
 		return nil, 0, err
 	}
+	// Only the subpackages of the verified photographers are listed
+	ownerIds, err := s.UserRepository.FindListedPhotographerIds(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	verified := make(map[primitive.ObjectID]bool, len(ownerIds))
+	for _, id := range ownerIds {
+		verified[id] = true
+	}
 
 	var totalCount int
 	var responses []dto.SubpackageResponse
@@ -44,7 +58,7 @@ func (s *SubpackageService) GetFilteredSubpackages(ctx context.Context, filters
 		if err != nil {
 			return nil, 0, err
 		}
-		if !s.passesFilters(pkg, item, filters) {
+		if !verified[pkg.OwnerID] || !s.passesFilters(pkg, item, filters) {
 			continue
 		}
 
@@ -104,13 +118,59 @@ func (s *SubpackageService) GetById(ctx context.Context, id string) (*models.Sub
 	return s.Repository.GetById(ctx, id)
 }
 
+// GetAuthorized returns the subpackage when the user can take the action on it, as the owner of its package
+func (s *SubpackageService) GetAuthorized(ctx context.Context, user *models.User, id string, action policies.Action) (*models.Subpackage, error) {
+	subpackage, err := s.Repository.GetById(ctx, id)
+	if isNotFound(err) {
+		return nil, apperrors.ErrSubpackageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := s.PackageRepository.GetById(ctx, subpackage.PackageID.Hex())
+	if isNotFound(err) {
+		return nil, apperrors.ErrSubpackageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := policies.Authorize(user, action, policies.Subpackage(pkg)); err != nil {
+		return nil, err
+	}
+	if action == policies.ActionRead {
+		if err := checkListed(ctx, s.UserRepository, user, pkg.OwnerID, apperrors.ErrSubpackageNotFound); err != nil {
+			return nil, err
+		}
+	}
+	return subpackage, nil
+}
+
+// AuthorizeCreate checks that the user can add a subpackage to the package
+func (s *SubpackageService) AuthorizeCreate(ctx context.Context, user *models.User, packageId string) (*models.Package, error) {
+	pkg, err := s.PackageRepository.GetById(ctx, packageId)
+	if isNotFound(err) {
+		return nil, apperrors.ErrPackageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := policies.Authorize(user, policies.ActionCreate, policies.Subpackage(pkg)); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
 func (s *SubpackageService) GetByPackageId(ctx context.Context, packageId primitive.ObjectID) ([]models.Subpackage, error) {
 	return s.Repository.GetByPackageId(ctx, packageId)
 }
 
 func (s *SubpackageService) Create(ctx context.Context, subpackage *models.Subpackage) error {
 	subpackage.ID = primitive.NewObjectID()
-	return s.Repository.Create(ctx, *subpackage)
+	if err := s.Repository.Create(ctx, *subpackage); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditSubpackage, subpackage.ID, nil, subpackage)
+	return nil
 }
 
 func (s *SubpackageService) Update(ctx context.Context, id string, subpackage *dto.SubpackageRequest) error {
@@ -118,15 +178,41 @@ func (s *SubpackageService) Update(ctx context.Context, id string, subpackage *d
 	if err != nil {
 		return err
 	}
-	return s.Repository.UpdateOne(ctx, id, bsonSubpackage)
+	before, err := s.Repository.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.Repository.UpdateOne(ctx, id, bsonSubpackage); err != nil {
+		return err
+	}
+	if after, err := s.Repository.GetById(ctx, id); err == nil {
+		s.Audit.Record(ctx, models.AuditSubpackage, before.ID, before, after)
+	}
+	return nil
 }
 
 func (s *SubpackageService) Replace(ctx context.Context, id string, subpackage *models.Subpackage) error {
-	return s.Repository.ReplaceOne(ctx, id, *subpackage)
+	before, err := s.Repository.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.Repository.ReplaceOne(ctx, id, *subpackage); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditSubpackage, before.ID, before, subpackage)
+	return nil
 }
 
 func (s *SubpackageService) Delete(ctx context.Context, id string) error {
-	return s.Repository.DeleteOne(ctx, id)
+	before, err := s.Repository.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.Repository.DeleteOne(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditSubpackage, before.ID, before, nil)
+	return nil
 }
 
 func (s *SubpackageService) VerifyStrictRequest(ctx context.Context, subpackage *dto.SubpackageRequest) error {
@@ -271,6 +357,8 @@ func (s *SubpackageService) MappedToSubpackageResponse(ctx context.Context, subp
 		AvailableEndTime:   subpackage.AvailableEndTime,
 		AvailableStartDay:  subpackage.AvailableStartDay,
 		AvailableEndDay:    subpackage.AvailableEndDay,
+		SelectionLimit:     subpackage.SelectionLimit,
+		ExtraPhotoPrice:    subpackage.ExtraPhotoPrice,
 		BusyTimes:          busyTime,
 	}, nil
 }
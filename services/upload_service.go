@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxUploadSize is the largest file accepted through a presigned upload
+	MaxUploadSize int64 = 20 << 20
+	// UploadExpireDuration is how long the presigned URL is valid, the upload must be confirmed before it
+	UploadExpireDuration = 15 * time.Minute
+)
+
+var uploadContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+type UploadService struct {
+	Repo      *repositories.UploadRepository
+	S3Service *S3Service
+}
+
+func NewUploadService(repo *repositories.UploadRepository, s3Service *S3Service) *UploadService {
+	return &UploadService{Repo: repo, S3Service: s3Service}
+}
+
+// VerifyUploadRequest checks the purpose, content type and size before a URL is signed
+func VerifyUploadRequest(req *dto.UploadRequest) error {
+	validPurpose := false
+	for _, purpose := range models.ValidUploadPurposes {
+		if purpose.Value == req.Purpose {
+			validPurpose = true
+			break
+		}
+	}
+	if !validPurpose {
+		return fmt.Errorf("invalid purpose %q", req.Purpose)
+	}
+	if !uploadContentTypes[req.ContentType] {
+		return fmt.Errorf("unsupported content type %q", req.ContentType)
+	}
+	if req.Size <= 0 || req.Size > MaxUploadSize {
+		return fmt.Errorf("size must be between 1 and %d bytes", MaxUploadSize)
+	}
+	return nil
+}
+
+// CreateUpload registers a pending upload and returns the URL the client puts the file to
+func (s *UploadService) CreateUpload(ctx context.Context, user *models.User, req *dto.UploadRequest) (*dto.UploadResponse, error) {
+	now := time.Now()
+	item := &models.Upload{
+		ID:          primitive.NewObjectID(),
+		OwnerID:     user.ID,
+		Purpose:     req.Purpose,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		FileName:    req.FileName,
+		Status:      models.UploadPending,
+		ExpireTime:  now.Add(UploadExpireDuration),
+		CreatedTime: now,
+	}
+	item.Key = "upload/" + item.ID.Hex()
+
+	// The pending uploads of the user count until they are confirmed or expire
+	pending, err := s.Repo.GetPendingByOwnerId(ctx, user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	size, photos := item.Size, uploadPhotoCount(item.Purpose)
+	for _, upload := range pending {
+		size += upload.Size
+		photos += uploadPhotoCount(upload.Purpose)
+	}
+	if err := s.S3Service.CheckQuota(ctx, user.ID, size, photos); err != nil {
+		return nil, err
+	}
+
+	url, err := s.S3Service.PresignPut(item.Key, item.ContentType, item.Size, UploadExpireDuration)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+
+	return &dto.UploadResponse{
+		ID:          item.ID,
+		URL:         url,
+		Method:      "PUT",
+		ContentType: item.ContentType,
+		ExpireTime:  item.ExpireTime,
+	}, nil
+}
+
+// Confirm turns a pending upload of the owner into image renditions under the key prefix, the staged file is removed.
+// Package photos keep their original privately and their renditions carry the watermark of the owner
+func (s *UploadService) Confirm(ctx context.Context, ownerId primitive.ObjectID, uploadId primitive.ObjectID, purpose models.UploadPurpose, key string, watermark *ImageWatermark) (*models.Image, error) {
+	upload, err := s.getPendingUpload(ctx, ownerId, uploadId, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.S3Service.GetObject(upload.Key)
+	if err != nil {
+		return nil, apperrors.ErrUploadNotFound
+	}
+	var image *models.Image
+	if purpose == models.UploadPackagePhoto {
+		image, err = s.S3Service.UploadPortfolioImage(ctx, ownerId, data, key, watermark)
+	} else {
+		image, err = s.S3Service.UploadImageBytes(ctx, ownerId, data, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Another request may have confirmed the same upload meanwhile
+	confirmed, err := s.Repo.MarkConfirmed(ctx, upload.ID)
+	if err != nil || !confirmed {
+		_ = s.S3Service.DeleteImage(image)
+		if err != nil {
+			return nil, err
+		}
+		return nil, apperrors.ErrUploadInvalid
+	}
+	if err := s.S3Service.DeleteObject(upload.Key); err != nil {
+		log.Println("Failed to delete confirmed upload", upload.ID.Hex(), err)
+	}
+	return image, nil
+}
+
+// ConfirmRaw moves a pending upload of the owner to the key as it is, for files that must stay original
+func (s *UploadService) ConfirmRaw(ctx context.Context, ownerId primitive.ObjectID, uploadId primitive.ObjectID, purpose models.UploadPurpose, key string) (*models.Upload, error) {
+	upload, err := s.getPendingUpload(ctx, ownerId, uploadId, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.S3Service.GetObject(upload.Key)
+	if err != nil {
+		return nil, apperrors.ErrUploadNotFound
+	}
+	if _, err := VerifyImage(data); err != nil {
+		return nil, err
+	}
+	if err := s.S3Service.CheckQuota(ctx, ownerId, int64(len(data)), uploadPhotoCount(purpose)); err != nil {
+		return nil, err
+	}
+	if err := s.S3Service.Repo.PutObject(key, data, upload.ContentType); err != nil {
+		return nil, err
+	}
+
+	confirmed, err := s.Repo.MarkConfirmed(ctx, upload.ID)
+	if err != nil || !confirmed {
+		_ = s.S3Service.DeleteObject(key)
+		if err != nil {
+			return nil, err
+		}
+		return nil, apperrors.ErrUploadInvalid
+	}
+	if err := s.S3Service.DeleteObject(upload.Key); err != nil {
+		log.Println("Failed to delete confirmed upload", upload.ID.Hex(), err)
+	}
+	upload.Size = int64(len(data))
+	return upload, nil
+}
+
+// uploadPhotoCount is 1 for the uploads counted as photos in the quota, profile pictures and logos are only counted in bytes
+func uploadPhotoCount(purpose models.UploadPurpose) int {
+	if purpose == models.UploadPackagePhoto || purpose == models.UploadGalleryPhoto {
+		return 1
+	}
+	return 0
+}
+
+func (s *UploadService) getPendingUpload(ctx context.Context, ownerId primitive.ObjectID, uploadId primitive.ObjectID, purpose models.UploadPurpose) (*models.Upload, error) {
+	upload, err := s.Repo.GetById(ctx, uploadId)
+	if err != nil {
+		return nil, apperrors.ErrUploadNotFound
+	}
+	if upload.OwnerID != ownerId || upload.Purpose != purpose || upload.Status != models.UploadPending {
+		return nil, apperrors.ErrUploadInvalid
+	}
+	if time.Now().After(upload.ExpireTime) {
+		return nil, apperrors.ErrUploadExpired
+	}
+	return upload, nil
+}
+
+// ConfirmMany confirms the uploads in order, the images already processed are removed if one of them fails
+func (s *UploadService) ConfirmMany(ctx context.Context, ownerId primitive.ObjectID, uploadIds []primitive.ObjectID, purpose models.UploadPurpose, key string, watermark *ImageWatermark) ([]models.Image, error) {
+	images := []models.Image{}
+	for _, uploadId := range uploadIds {
+		image, err := s.Confirm(ctx, ownerId, uploadId, purpose, key, watermark)
+		if err != nil {
+			for _, uploaded := range images {
+				_ = s.S3Service.DeleteImage(&uploaded)
+			}
+			return nil, err
+		}
+		images = append(images, *image)
+	}
+	return images, nil
+}
+
+// CleanupExpired removes the uploads that were never confirmed, together with their staged files
+func (s *UploadService) CleanupExpired(ctx context.Context) {
+	uploads, err := s.Repo.GetExpiredPending(ctx, time.Now())
+	if err != nil {
+		log.Println("Failed to fetch expired uploads", err)
+		return
+	}
+	for _, upload := range uploads {
+		if err := s.S3Service.DeleteObject(upload.Key); err != nil {
+			log.Println("Failed to delete expired upload", upload.ID.Hex(), err)
+			continue
+		}
+		if err := s.Repo.Delete(ctx, upload.ID); err != nil {
+			log.Println("Failed to delete expired upload record", upload.ID.Hex(), err)
+		}
+	}
+}
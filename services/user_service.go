@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
-	"firebase.google.com/go/auth"
+	"github.com/Bualoi-s-Dev/backend/apperrors"
 	"github.com/Bualoi-s-Dev/backend/dto"
 	"github.com/Bualoi-s-Dev/backend/models"
+	authRepo "github.com/Bualoi-s-Dev/backend/repositories/auth"
 	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
 	"github.com/jinzhu/copier"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -19,12 +22,15 @@ type UserService struct {
 	S3Service         *S3Service
 	PackageService    *PackageService
 	SubpackageService *SubpackageService
-	AuthClient        *auth.Client
+	Auth              authRepo.TokenVerifier
 	RatingService     *RatingService
+	UploadService     *UploadService
+	QuotaService      *StorageQuotaService
+	Audit             *AuditService
 }
 
-func NewUserService(repo *repositories.UserRepository, s3Service *S3Service, packageService *PackageService, subpackageService *SubpackageService, authClient *auth.Client, ratingService *RatingService) *UserService {
-	return &UserService{Repo: repo, S3Service: s3Service, PackageService: packageService, SubpackageService: subpackageService, AuthClient: authClient, RatingService: ratingService}
+func NewUserService(repo *repositories.UserRepository, s3Service *S3Service, packageService *PackageService, subpackageService *SubpackageService, auth authRepo.TokenVerifier, ratingService *RatingService, uploadService *UploadService, quotaService *StorageQuotaService) *UserService {
+	return &UserService{Repo: repo, S3Service: s3Service, PackageService: packageService, SubpackageService: subpackageService, Auth: auth, RatingService: ratingService, UploadService: uploadService, QuotaService: quotaService}
 }
 
 func (s *UserService) FindUser(ctx context.Context, email string) (*models.User, error) {
@@ -44,6 +50,15 @@ func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*dto.Us
 	return s.mappedToUserResponse(ctx, user)
 }
 
+// GetOwnProfile is the profile of the user themselves, with the storage usage
+func (s *UserService) GetOwnProfile(ctx context.Context, email string) (*dto.UserResponse, error) {
+	user, err := s.Repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return s.mappedToOwnProfile(ctx, user)
+}
+
 func (s *UserService) GetUserByID(ctx context.Context, userId primitive.ObjectID) (*dto.UserResponse, error) {
 	user, err := s.Repo.FindUserByID(ctx, userId)
 	if err != nil {
@@ -61,6 +76,9 @@ func (s *UserService) GetPhotographers(ctx context.Context) ([]dto.UserResponse,
 
 	var res []dto.UserResponse
 	for _, photographer := range photographers {
+		if !photographer.IsListed() {
+			continue
+		}
 		userRes, err := s.mappedToUserResponse(ctx, &photographer)
 		if err != nil {
 			return nil, err
@@ -103,6 +121,10 @@ func (s *UserService) GetFilteredPhotographers(ctx context.Context, filters map[
 	}
 
 	for _, photographer := range photographers {
+		// Only the verified and legacy photographers are listed
+		if !photographer.IsListed() {
+			continue
+		}
 		if filters["name"] != "" && !strings.HasPrefix(photographer.Name, filters["name"]) {
 			continue
 		}
@@ -180,6 +202,44 @@ func matchesPriceFilter(minPrice int, maxPrice int, subPkgs []models.Subpackage)
 	return false
 }
 
+// ValidateRoleChange refuses a user to make themselves an admin or to leave the admin role, the role of an admin is
+// changed by another admin in the back office
+func ValidateRoleChange(from, to models.UserRole) error {
+	if from != to && (from == models.Admin || to == models.Admin) {
+		return apperrors.ErrForbidden
+	}
+	return nil
+}
+
+// SessionError returns why the user cannot use the API with a token of a sign in at authTime, nil when they can. The
+// sessions revoked after the sign in are refused first, then the user suspended at now.
+func SessionError(user *models.User, authTime time.Time, now time.Time) error {
+	// auth_time is in seconds, a sign in in the second of the revocation is kept
+	if user.TokensValidAfter != nil && authTime.Before(user.TokensValidAfter.Truncate(time.Second)) {
+		return apperrors.ErrSessionRevoked
+	}
+	if !user.Suspension.IsActive(now) {
+		return nil
+	}
+	if user.Suspension.Type == models.SuspensionBanned {
+		return apperrors.ErrUserBanned
+	}
+	return apperrors.ErrUserSuspended
+}
+
+// LiftExpiredSuspension removes the suspension of the user once it has expired
+func (s *UserService) LiftExpiredSuspension(ctx context.Context, user *models.User) error {
+	if user.Suspension == nil || user.Suspension.IsActive(time.Now()) {
+		return nil
+	}
+	if _, err := s.Repo.UpdateUser(ctx, user.ID, bson.M{"suspension": nil}); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditUser, user.ID, bson.M{"suspension": user.Suspension}, bson.M{"suspension": nil})
+	user.Suspension = nil
+	return nil
+}
+
 func (s *UserService) CreateUser(ctx context.Context, user *models.User) error {
 	return s.Repo.CreateUser(ctx, user)
 }
@@ -190,42 +250,67 @@ func (s *UserService) UpdateUser(ctx context.Context, userId primitive.ObjectID,
 		return nil, err
 	}
 
-	// Check if the role is changed
+	// Check if the role is changed, only an admin can make another user an admin
 	roleChanged := req.Role != nil && models.UserRole(*req.Role) != item.Role
+	if roleChanged {
+		if err := ValidateRoleChange(item.Role, *req.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	bankChanged := bankDetailsChanged(item, req)
+	if item.IsVerified() && bankChanged {
+		return nil, apperrors.ErrVerifiedBankDetails
+	}
 
+	before := AuditSnapshot(item)
+	oldProfile, oldProfileImage := item.Profile, item.ProfileImage
 	if err := copier.Copy(item, req); err != nil {
 		return nil, err
 	}
+	if bankChanged {
+		// The account number is checked again as only the bank may have changed
+		item.BankAccount = models.EncryptedString(models.NormalizeBankAccount(string(item.BankAccount)))
+		if item.BankAccount != "" && !models.IsValidBankAccount(item.BankName, string(item.BankAccount)) {
+			return nil, apperrors.ErrBankAccountInvalid
+		}
+	}
 
-	if req.Profile != nil && *req.Profile != "" {
-		key := "profile/" + userId.Hex()
-		// Try to delete the existing profile picture
-		_ = s.S3Service.DeleteObject(key)
-
-		profileUrl, err := s.S3Service.UploadBase64([]byte(*req.Profile), key)
+	var profileImage *models.Image
+	key := "profile/" + userId.Hex()
+	if req.ProfileUploadId != nil {
+		profileImage, err = s.UploadService.Confirm(ctx, userId, *req.ProfileUploadId, models.UploadProfile, key, nil)
+		if err != nil {
+			return nil, err
+		}
+	} else if req.Profile != nil && *req.Profile != "" {
+		profileImage, err = s.S3Service.UploadImage(ctx, userId, *req.Profile, key)
 		if err != nil {
 			return nil, err
 		}
+	}
+	if profileImage != nil {
 
-		item.Profile = profileUrl
+		// Try to delete the existing profile picture
+		if oldProfileImage != nil {
+			_ = s.S3Service.DeleteImage(oldProfileImage)
+		} else if oldProfile != "" {
+			_ = s.S3Service.DeleteObject(strings.TrimPrefix(oldProfile, "/"))
+		}
+
+		item.Profile = profileImage.Medium
+		item.ProfileImage = profileImage
 	}
 
 	// call func change jwt role
 	if roleChanged {
 		newRole := models.UserRole(*req.Role)
 
-		// find user from firebase to get firebase UID
-		firebaseUser, err := s.AuthClient.GetUserByEmail(ctx, email)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch Firebase user: %v", err)
-		}
-		firebaseUID := firebaseUser.UID
-
-		err = s.AuthClient.SetCustomUserClaims(ctx, firebaseUID, map[string]interface{}{
+		err = s.Auth.SetCustomUserClaims(ctx, email, map[string]interface{}{
 			"role": string(newRole),
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to update Firebase role: %v", err)
+			return nil, fmt.Errorf("failed to update auth role: %v", err)
 		}
 
 		item.Role = newRole
@@ -235,15 +320,29 @@ func (s *UserService) UpdateUser(ctx context.Context, userId primitive.ObjectID,
 	if err != nil {
 		return nil, err
 	}
+	s.Audit.Record(ctx, models.AuditUser, userId, before, item)
 
 	// map to dto.response
-	res, err := s.mappedToUserResponse(ctx, item)
+	res, err := s.mappedToOwnProfile(ctx, item)
 	if err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
+// bankDetailsChanged reports whether the request sets other bank details than the ones of the user
+func bankDetailsChanged(user *models.User, req *dto.UserRequest) bool {
+	return (req.BankName != nil && *req.BankName != user.BankName) ||
+		(req.BankAccount != nil && models.NormalizeBankAccount(*req.BankAccount) != string(user.BankAccount))
+}
+
+// MaskedUser is a copy of the user with the bank account masked, for the responses to other users than themselves
+func MaskedUser(user *models.User) *models.User {
+	masked := *user
+	masked.BankAccount = models.EncryptedString(models.MaskBankAccount(string(user.BankAccount)))
+	return &masked
+}
+
 func (s *UserService) VerifyShowcase(ctx context.Context, ownerId primitive.ObjectID, checkPackages []primitive.ObjectID) (bool, error) {
 	ownedPackages, err := s.PackageService.GetByOwnerId(ctx, ownerId)
 	if err != nil {
@@ -311,21 +410,37 @@ func (s *UserService) mappedToUserResponse(ctx context.Context, user *models.Use
 		Name:             user.Name,
 		Gender:           user.Gender,
 		Profile:          user.Profile,
-		Phone:            user.Phone,
+		ProfileImage:     user.ProfileImage,
+		Phone:            string(user.Phone),
 		Location:         user.Location,
 		Role:             user.Role,
 		Description:      user.Description,
 		BankName:         user.BankName,
-		BankAccount:      user.BankAccount,
+		BankAccount:      models.MaskBankAccount(string(user.BankAccount)),
 		LineID:           user.LineID,
 		Facebook:         user.Facebook,
 		Instagram:        user.Instagram,
+		Verified:         user.IsVerified(),
+		PhoneVerified:    user.IsPhoneVerified(),
 		ShowcasePackages: showcasePackageResponse,
 		Packages:         packageResponse,
 		Ratings:          ratingResponse,
 	}, nil
 }
 
+func (s *UserService) mappedToOwnProfile(ctx context.Context, user *models.User) (*dto.UserResponse, error) {
+	res, err := s.mappedToUserResponse(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if res.StorageUsage, err = s.QuotaService.GetUsage(ctx, user); err != nil {
+		return nil, err
+	}
+	res.BankAccount = string(user.BankAccount)
+	res.VerificationStatus = user.VerificationStatus
+	return res, nil
+}
+
 func (s *UserService) GetUserRoleByID(ctx context.Context, userId primitive.ObjectID) (*models.UserRole, error) {
 	user, err := s.GetUserByID(ctx, userId)
 	if err != nil {
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// VerificationURLExpireDuration is how long a signed URL of a document photo is valid
+const VerificationURLExpireDuration = 15 * time.Minute
+
+// VerificationService keeps the identity and bank details the photographers submit and the review of the admins, only
+// an approved photographer has their packages listed and can register a payout account
+type VerificationService struct {
+	Repo                *repositories.VerificationRepository
+	UserRepo            *repositories.UserRepository
+	UploadService       *UploadService
+	S3Service           *S3Service
+	NotificationService *NotificationService
+	Audit               *AuditService
+}
+
+func NewVerificationService(repo *repositories.VerificationRepository, userRepo *repositories.UserRepository, uploadService *UploadService, s3Service *S3Service, notificationService *NotificationService) *VerificationService {
+	return &VerificationService{Repo: repo, UserRepo: userRepo, UploadService: uploadService, S3Service: s3Service, NotificationService: notificationService}
+}
+
+// Submit queues the details of the photographer for review. A verified photographer submits again to change their bank
+// details, they stay verified with the old details meanwhile.
+func (s *VerificationService) Submit(ctx context.Context, user *models.User, req *dto.VerificationRequest) (*models.PhotographerVerification, error) {
+	pending, err := s.Repo.HasPending(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, apperrors.ErrVerificationPending
+	}
+
+	item := &models.PhotographerVerification{
+		ID:              primitive.NewObjectID(),
+		UserID:          user.ID,
+		DocumentType:    req.DocumentType,
+		DocumentKeys:    []string{},
+		BankName:        req.BankName,
+		BankAccount:     models.EncryptedString(models.NormalizeBankAccount(req.BankAccount)),
+		BankAccountName: req.BankAccountName,
+		Status:          models.VerificationPending,
+		CreatedTime:     time.Now(),
+	}
+	for _, uploadId := range req.DocumentUploadIds {
+		key := fmt.Sprintf("verification/%s/%s", user.ID.Hex(), primitive.NewObjectID().Hex())
+		if _, err := s.UploadService.ConfirmRaw(ctx, user.ID, uploadId, models.UploadIdentityDocument, key); err != nil {
+			s.deleteDocuments(item)
+			return nil, err
+		}
+		item.DocumentKeys = append(item.DocumentKeys, key)
+	}
+	if err := s.Repo.Create(ctx, item); err != nil {
+		s.deleteDocuments(item)
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditVerification, item.ID, nil, item)
+
+	// A verified or legacy photographer stays listed while the submission is reviewed
+	if !user.IsListed() {
+		if err := s.setUserStatus(ctx, user, models.VerificationPending); err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+// GetLatest returns the latest submission of the user
+func (s *VerificationService) GetLatest(ctx context.Context, userId primitive.ObjectID) (*models.PhotographerVerification, error) {
+	items, err := s.Repo.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, apperrors.ErrVerificationNotFound
+	}
+	return &items[0], nil
+}
+
+// GetQueue returns the submissions with their bank accounts masked, for the admins
+func (s *VerificationService) GetQueue(ctx context.Context, status models.VerificationStatus) ([]models.PhotographerVerification, error) {
+	items, err := s.Repo.GetMany(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].BankAccount = models.EncryptedString(models.MaskBankAccount(string(items[i].BankAccount)))
+	}
+	return items, nil
+}
+
+func (s *VerificationService) GetById(ctx context.Context, id primitive.ObjectID) (*models.PhotographerVerification, error) {
+	item, err := s.Repo.GetById(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrVerificationNotFound
+	}
+	return item, err
+}
+
+// Approve verifies the photographer, the submitted bank details become the ones of the user
+func (s *VerificationService) Approve(ctx context.Context, id primitive.ObjectID, reviewer *models.User) (*models.PhotographerVerification, error) {
+	item, err := s.review(ctx, id, reviewer, models.VerificationApproved, "")
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.UserRepo.FindUserByID(ctx, item.UserID)
+	if err != nil {
+		return nil, err
+	}
+	before := bson.M{"bank_name": user.BankName, "bank_account": user.BankAccount, "verification_status": user.VerificationStatus}
+	updates := bson.M{
+		"bank_name":           item.BankName,
+		"bank_account":        item.BankAccount,
+		"verification_status": models.VerificationApproved,
+		"verified_time":       *item.ReviewedTime,
+	}
+	if _, err := s.UserRepo.UpdateUser(ctx, user.ID, updates); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, models.AuditUser, user.ID, before, updates)
+	s.NotificationService.Notify(ctx, item.UserID, models.NotificationVerificationApproved, "Verification approved",
+		"Your identity and bank details are verified, your packages are now listed", nil)
+	return item, nil
+}
+
+// Reject tells the photographer the reason, a verified photographer keeps their current bank details
+func (s *VerificationService) Reject(ctx context.Context, id primitive.ObjectID, reviewer *models.User, reason string) (*models.PhotographerVerification, error) {
+	item, err := s.review(ctx, id, reviewer, models.VerificationRejected, reason)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.UserRepo.FindUserByID(ctx, item.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsListed() {
+		if err := s.setUserStatus(ctx, user, models.VerificationRejected); err != nil {
+			return nil, err
+		}
+	}
+	s.NotificationService.Notify(ctx, item.UserID, models.NotificationVerificationRejected, "Verification rejected",
+		"Your identity and bank details were not verified: "+reason, nil)
+	return item, nil
+}
+
+func (s *VerificationService) review(ctx context.Context, id primitive.ObjectID, reviewer *models.User, status models.VerificationStatus, reason string) (*models.PhotographerVerification, error) {
+	item, err := s.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != models.VerificationPending {
+		return nil, apperrors.ErrVerificationReviewed
+	}
+
+	before := AuditSnapshot(item)
+	now := time.Now()
+	updates := bson.M{"status": status, "reviewer_id": reviewer.ID, "reviewed_time": now}
+	if reason != "" {
+		updates["reason"] = reason
+	}
+	claimed, err := s.Repo.UpdateStatus(ctx, id, models.VerificationPending, updates)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, apperrors.ErrVerificationReviewed
+	}
+	item.Status, item.Reason, item.ReviewerID, item.ReviewedTime = status, reason, &reviewer.ID, &now
+	s.Audit.Record(ctx, models.AuditVerification, item.ID, before, item)
+	return item, nil
+}
+
+// Grandfather marks the photographers created before the time who never submitted a verification as legacy, so that
+// the photographers of the platform from before the verification stay listed. They get no badge and no payouts until
+// their verification is approved. It returns how many were marked, only counting them on a dry run.
+func (s *VerificationService) Grandfather(ctx context.Context, before time.Time, dryRun bool) (int, error) {
+	users, err := s.UserRepo.FindNeverVerifiedPhotographers(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return len(users), nil
+	}
+	for i := range users {
+		if err := s.setUserStatus(ctx, &users[i], models.VerificationLegacy); err != nil {
+			return i, err
+		}
+	}
+	return len(users), nil
+}
+
+func (s *VerificationService) setUserStatus(ctx context.Context, user *models.User, status models.VerificationStatus) error {
+	if _, err := s.UserRepo.UpdateUser(ctx, user.ID, bson.M{"verification_status": status}); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, models.AuditUser, user.ID, bson.M{"verification_status": user.VerificationStatus}, bson.M{"verification_status": status})
+	user.VerificationStatus = status
+	return nil
+}
+
+// DeleteByUser removes the submissions of the user with their document photos
+func (s *VerificationService) DeleteByUser(ctx context.Context, userId primitive.ObjectID) error {
+	items, err := s.Repo.GetByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		s.deleteDocuments(&items[i])
+	}
+	return s.Repo.DeleteByUserId(ctx, userId)
+}
+
+func (s *VerificationService) deleteDocuments(item *models.PhotographerVerification) {
+	for _, key := range item.DocumentKeys {
+		_ = s.S3Service.DeleteObject(key)
+	}
+}
+
+// MappedToVerificationResponse signs the URLs of the document photos, the bank account is masked unless the viewer is
+// the photographer
+func (s *VerificationService) MappedToVerificationResponse(item *models.PhotographerVerification, viewer *models.User) (*dto.VerificationResponse, error) {
+	bankAccount := string(item.BankAccount)
+	if viewer.ID != item.UserID {
+		bankAccount = models.MaskBankAccount(bankAccount)
+	}
+	res := &dto.VerificationResponse{
+		ID:              item.ID,
+		UserID:          item.UserID,
+		DocumentType:    item.DocumentType,
+		DocumentURLs:    []string{},
+		URLExpireTime:   time.Now().Add(VerificationURLExpireDuration),
+		BankName:        item.BankName,
+		BankAccount:     bankAccount,
+		BankAccountName: item.BankAccountName,
+		Status:          item.Status,
+		Reason:          item.Reason,
+		CreatedTime:     item.CreatedTime,
+		ReviewedTime:    item.ReviewedTime,
+	}
+	for _, key := range item.DocumentKeys {
+		url, err := s.S3Service.PresignGet(key, VerificationURLExpireDuration, "")
+		if err != nil {
+			return nil, err
+		}
+		res.DocumentURLs = append(res.DocumentURLs, url)
+	}
+	return res, nil
+}
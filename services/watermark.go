@@ -0,0 +1,132 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// WatermarkLogoMaxSide is the longest side a logo is kept at in memory
+const WatermarkLogoMaxSide = 1024
+
+// ImageWatermark is a watermark setting ready to be drawn, the logo is decoded from storage
+type ImageWatermark struct {
+	Setting models.Watermark
+	// Logo replaces the text when it is set
+	Logo image.Image
+}
+
+// ProofWatermark is drawn across every proof, so proofs cannot be used instead of the edited photos
+var ProofWatermark = ImageWatermark{Setting: models.Watermark{Text: "PROOF", Position: models.WatermarkTiled, Opacity: 0.35}}
+
+// ApplyWatermark draws the watermark over the image in place
+func ApplyWatermark(dst *image.RGBA, imageWatermark *ImageWatermark) {
+	if imageWatermark == nil || imageWatermark.Setting.Opacity <= 0 {
+		return
+	}
+	watermark := &imageWatermark.Setting
+	var mark image.Image
+	if imageWatermark.Logo != nil {
+		mark = imageWatermark.Logo
+	} else if text := watermarkMask(watermark); text != nil {
+		mark = text
+	} else {
+		return
+	}
+	bounds := dst.Bounds()
+	shortest := min(bounds.Dx(), bounds.Dy())
+	margin := shortest * 3 / 100
+
+	// The mark spans a share of the image width, smaller in the corners
+	share := 4
+	switch watermark.Position {
+	case models.WatermarkCenter:
+		share = 2
+	case models.WatermarkTiled:
+		share = 3
+	}
+	width := max(bounds.Dx()/share, 1)
+	height := max(mark.Bounds().Dy()*width/max(mark.Bounds().Dx(), 1), 1)
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.BiLinear.Scale(scaled, scaled.Bounds(), mark, mark.Bounds(), draw.Src, nil)
+
+	origins := []image.Point{}
+	switch watermark.Position {
+	case models.WatermarkTopLeft:
+		origins = append(origins, image.Pt(margin, margin))
+	case models.WatermarkTopRight:
+		origins = append(origins, image.Pt(bounds.Dx()-width-margin, margin))
+	case models.WatermarkBottomLeft:
+		origins = append(origins, image.Pt(margin, bounds.Dy()-height-margin))
+	case models.WatermarkTiled:
+		for y := margin; y < bounds.Dy(); y += height * 3 {
+			// Shift every other row, so the marks do not line up in columns
+			offset := (y / (height * 3) % 2) * width / 2
+			for x := -offset; x < bounds.Dx(); x += width + width/2 {
+				origins = append(origins, image.Pt(x, y))
+			}
+		}
+	case models.WatermarkCenter:
+		origins = append(origins, image.Pt((bounds.Dx()-width)/2, (bounds.Dy()-height)/2))
+	default:
+		origins = append(origins, image.Pt(bounds.Dx()-width-margin, bounds.Dy()-height-margin))
+	}
+
+	alpha := uint8(min(watermark.Opacity, 1) * 255)
+	opacity := image.NewUniform(color.Alpha{A: alpha})
+	shadow := image.NewUniform(color.NRGBA{A: alpha / 2})
+	fill := image.NewUniform(color.NRGBA{R: 255, G: 255, B: 255, A: alpha})
+	offset := max(height/16, 1)
+	for _, origin := range origins {
+		rect := image.Rectangle{Min: origin, Max: origin.Add(scaled.Bounds().Size())}.Add(bounds.Min)
+		if imageWatermark.Logo != nil {
+			// The logo keeps its colors, faded by the opacity
+			draw.DrawMask(dst, rect, scaled, image.Point{}, opacity, image.Point{}, draw.Over)
+			continue
+		}
+		draw.DrawMask(dst, rect.Add(image.Pt(offset, offset)), shadow, image.Point{}, scaled, image.Point{}, draw.Over)
+		draw.DrawMask(dst, rect, fill, image.Point{}, scaled, image.Point{}, draw.Over)
+	}
+}
+
+// DecodeWatermarkLogo decodes an uploaded logo, it is scaled down as it is never drawn larger than half an image
+func DecodeWatermarkLogo(data []byte) (image.Image, error) {
+	if _, err := VerifyImage(data); err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > WatermarkLogoMaxSide {
+		width = max(width*WatermarkLogoMaxSide/longest, 1)
+		height = max(height*WatermarkLogoMaxSide/longest, 1)
+	}
+	// Unlike fitImage the transparent pixels stay transparent
+	logo := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(logo, logo.Bounds(), src, bounds, draw.Src, nil)
+	return logo, nil
+}
+
+// watermarkMask renders the watermark text into an alpha mask
+func watermarkMask(watermark *models.Watermark) *image.Alpha {
+	if watermark.Text == "" {
+		return nil
+	}
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, watermark.Text).Ceil()
+	metrics := face.Metrics()
+	mask := image.NewAlpha(image.Rect(0, 0, width, metrics.Height.Ceil()))
+	drawer := font.Drawer{Dst: mask, Src: image.Opaque, Face: face, Dot: fixed.P(0, metrics.Ascent.Ceil())}
+	drawer.DrawString(watermark.Text)
+	return mask
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	repositories "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WatermarkService keeps the watermark setting of the photographers and draws it over their public package photos
+type WatermarkService struct {
+	UserRepo      *repositories.UserRepository
+	PackageRepo   *repositories.PackageRepository
+	UploadService *UploadService
+	S3Service     *S3Service
+}
+
+func NewWatermarkService(userRepo *repositories.UserRepository, packageRepo *repositories.PackageRepository, uploadService *UploadService, s3Service *S3Service) *WatermarkService {
+	return &WatermarkService{UserRepo: userRepo, PackageRepo: packageRepo, UploadService: uploadService, S3Service: s3Service}
+}
+
+// GetImageWatermark returns the watermark of the user ready to be drawn, nil when the user has none
+func (s *WatermarkService) GetImageWatermark(ctx context.Context, userId primitive.ObjectID) (*ImageWatermark, error) {
+	user, err := s.UserRepo.FindUserByID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.LoadImageWatermark(user.Watermark)
+}
+
+// LoadImageWatermark decodes the logo of the setting, nil when there is no setting
+func (s *WatermarkService) LoadImageWatermark(watermark *models.Watermark) (*ImageWatermark, error) {
+	if watermark == nil {
+		return nil, nil
+	}
+	item := &ImageWatermark{Setting: *watermark}
+	if watermark.LogoKey != "" {
+		data, err := s.S3Service.GetObject(watermark.LogoKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load watermark logo, %v", err)
+		}
+		if item.Logo, err = DecodeWatermarkLogo(data); err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+// UpdateWatermark saves the setting of the photographer, the package photos are rendered again in the background
+func (s *WatermarkService) UpdateWatermark(ctx context.Context, user *models.User, req *dto.WatermarkRequest) (*models.Watermark, error) {
+	watermark := &models.Watermark{Text: req.Text, Position: req.Position, Opacity: req.Opacity}
+	oldLogoKey := ""
+	if user.Watermark != nil {
+		oldLogoKey = user.Watermark.LogoKey
+	}
+	if !req.RemoveLogo {
+		watermark.LogoKey = oldLogoKey
+	}
+	if req.LogoUploadId != nil {
+		key := fmt.Sprintf("watermark/%s/%s", user.ID.Hex(), primitive.NewObjectID().Hex())
+		if _, err := s.UploadService.ConfirmRaw(ctx, user.ID, *req.LogoUploadId, models.UploadWatermarkLogo, key); err != nil {
+			return nil, err
+		}
+		watermark.LogoKey = key
+	}
+	if watermark.Text == "" && watermark.LogoKey == "" {
+		return nil, apperrors.ErrWatermarkEmpty
+	}
+
+	if _, err := s.UserRepo.UpdateUser(ctx, user.ID, bson.M{"watermark": watermark}); err != nil {
+		return nil, err
+	}
+	if oldLogoKey != "" && oldLogoKey != watermark.LogoKey {
+		if err := s.S3Service.DeleteObject(oldLogoKey); err != nil {
+			log.Println("Failed to delete watermark logo", oldLogoKey, err)
+		}
+	}
+	user.Watermark = watermark
+
+	go s.RenderPackagePhotos(context.Background(), user.ID)
+	return watermark, nil
+}
+
+// DeleteWatermark removes the setting of the photographer, the package photos are rendered again without it
+func (s *WatermarkService) DeleteWatermark(ctx context.Context, user *models.User) error {
+	if user.Watermark == nil {
+		return nil
+	}
+	if _, err := s.UserRepo.UpdateUser(ctx, user.ID, bson.M{"watermark": nil}); err != nil {
+		return err
+	}
+	if user.Watermark.LogoKey != "" {
+		if err := s.S3Service.DeleteObject(user.Watermark.LogoKey); err != nil {
+			log.Println("Failed to delete watermark logo", user.Watermark.LogoKey, err)
+		}
+	}
+	user.Watermark = nil
+
+	go s.RenderPackagePhotos(context.Background(), user.ID)
+	return nil
+}
+
+// RenderPackagePhotos generates the renditions of every package photo of the owner again from the originals,
+// photos uploaded before the originals were kept cannot be rendered again and are left as they are
+func (s *WatermarkService) RenderPackagePhotos(ctx context.Context, ownerId primitive.ObjectID) {
+	watermark, err := s.GetImageWatermark(ctx, ownerId)
+	if err != nil {
+		log.Println("Failed to load watermark of", ownerId.Hex(), err)
+		return
+	}
+	packages, err := s.PackageRepo.GetByOwnerId(ctx, ownerId)
+	if err != nil {
+		log.Println("Failed to fetch packages of", ownerId.Hex(), err)
+		return
+	}
+	for _, pkg := range packages {
+		if err := s.renderPackage(ctx, pkg.ID, watermark); err != nil {
+			log.Println("Failed to render photos of package", pkg.ID.Hex(), err)
+		}
+	}
+}
+
+func (s *WatermarkService) renderPackage(ctx context.Context, packageId primitive.ObjectID, watermark *ImageWatermark) error {
+	pkg, err := s.PackageRepo.GetById(ctx, packageId.Hex())
+	if err != nil {
+		return err
+	}
+	rendered := map[primitive.ObjectID]models.Image{}
+	for _, image := range pkg.Images {
+		if image.OriginalKey == "" {
+			continue
+		}
+		item, err := s.S3Service.RenderPortfolioImage(&image, watermark)
+		if err != nil {
+			log.Println("Failed to render package photo", image.OriginalKey, err)
+			continue
+		}
+		rendered[image.ID] = *item
+	}
+	if len(rendered) == 0 {
+		return nil
+	}
+
+	// The package may have changed while the photos were rendered
+	pkg, err = s.PackageRepo.GetById(ctx, packageId.Hex())
+	if err != nil {
+		for _, item := range rendered {
+			_ = s.S3Service.DeleteRenditions(&item)
+		}
+		return err
+	}
+	images := make([]models.Image, len(pkg.Images))
+	replaced := []models.Image{}
+	for i, image := range pkg.Images {
+		images[i] = image
+		if item, ok := rendered[image.ID]; ok && image.OriginalKey == item.OriginalKey {
+			images[i] = item
+			replaced = append(replaced, image)
+		}
+	}
+	setPackageImages(pkg, images)
+	if _, err := s.PackageRepo.ReplaceOne(ctx, pkg.ID.Hex(), pkg); err != nil {
+		for _, item := range rendered {
+			_ = s.S3Service.DeleteRenditions(&item)
+		}
+		return err
+	}
+	for _, image := range replaced {
+		delete(rendered, image.ID)
+	}
+
+	// The old renditions, and the new ones of photos removed meanwhile
+	for _, item := range append(replaced, mapValues(rendered)...) {
+		if err := s.S3Service.DeleteRenditions(&item); err != nil {
+			log.Println("Failed to delete package photo renditions", item.ID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// MapToWatermarkResponse signs the URL of the logo, the logo is private like the originals
+func (s *WatermarkService) MapToWatermarkResponse(watermark *models.Watermark) (*dto.WatermarkResponse, error) {
+	if watermark == nil {
+		return &dto.WatermarkResponse{}, nil
+	}
+	res := &dto.WatermarkResponse{
+		Enabled:  true,
+		Text:     watermark.Text,
+		Position: watermark.Position,
+		Opacity:  watermark.Opacity,
+	}
+	if watermark.LogoKey != "" {
+		url, err := s.S3Service.PresignGet(watermark.LogoKey, GalleryURLExpireDuration, "")
+		if err != nil {
+			return nil, err
+		}
+		res.LogoURL = url
+	}
+	return res, nil
+}
+
+func mapValues(items map[primitive.ObjectID]models.Image) []models.Image {
+	values := []models.Image{}
+	for _, item := range items {
+		values = append(values, item)
+	}
+	return values
+}
@@ -10,11 +10,11 @@ Feature: Package Management
 
     Scenario: Photographer updates a package
         When the photographer updates the package details with the following data:
-            | title | type          | photos                                                                                      |
-            | A     | WEDDING_BLISS | data:image/jpeg;base64,/9j/4AAQSkZJRg==, data:image/jpeg;base64,/9j/4AAQSkZJRg==             |
+            | title | type          | photos |
+            | A     | WEDDING_BLISS | data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==, data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg== |
         Then the package information is updated with following data:
-            | title | type          | photos                                                                                      |
-            | A     | WEDDING_BLISS | data:image/jpeg;base64,/9j/4AAQSkZJRg==, data:image/jpeg;base64,/9j/4AAQSkZJRg==             |
+            | title | type          | photos |
+            | A     | WEDDING_BLISS | data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==, data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg== |
     
     Scenario: Photographer updates a package
         When the photographer updates the package details with the following data:
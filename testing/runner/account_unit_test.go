@@ -0,0 +1,45 @@
+package testing_runner
+
+import (
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestAccountExportImages(t *testing.T) {
+	pkgId, verificationId := primitive.NewObjectID(), primitive.NewObjectID()
+	export := &services.AccountExport{
+		Profile: &models.User{
+			ProfileImage: &models.Image{Large: "profile/a_large.webp", OriginalKey: "originals/a.jpg"},
+			Watermark:    &models.Watermark{LogoKey: "watermark/logo.png"},
+		},
+		Packages: []models.Package{
+			{ID: pkgId, Images: []models.Image{{Large: "package/b_large.webp"}}},
+			// Packages from before the renditions only have their keys
+			{ID: pkgId, PhotoUrls: []string{"/package/c.jpg"}},
+		},
+		Verifications: []models.PhotographerVerification{{ID: verificationId, DocumentKeys: []string{"verification/u/front"}}},
+	}
+	images := services.AccountExportImages(export)
+	assert.Equal(t, []services.AccountExportImage{
+		{Name: "images/profile/a.jpg", Key: "originals/a.jpg"},
+		{Name: "images/watermark/logo.png", Key: "watermark/logo.png"},
+		{Name: "images/verification/" + verificationId.Hex() + "/front", Key: "verification/u/front"},
+		{Name: "images/packages/" + pkgId.Hex() + "/b_large.webp", Key: "package/b_large.webp"},
+		{Name: "images/packages/" + pkgId.Hex() + "/c.jpg", Key: "package/c.jpg"},
+	}, images)
+
+	// The legacy profile key is used without the renditions, nothing is listed without images
+	assert.Equal(t, "profile/d.jpg", services.AccountExportImages(&services.AccountExport{Profile: &models.User{Profile: "/profile/d.jpg"}})[0].Key)
+	assert.Empty(t, services.AccountExportImages(&services.AccountExport{Profile: &models.User{}}))
+}
+
+func TestUnitTestHasActiveAppointment(t *testing.T) {
+	assert.False(t, services.HasActiveAppointment(nil))
+	assert.False(t, services.HasActiveAppointment([]models.Appointment{{Status: models.AppointmentCompleted}, {Status: models.AppointmentCanceled}}))
+	assert.True(t, services.HasActiveAppointment([]models.Appointment{{Status: models.AppointmentCompleted}, {Status: models.AppointmentPending}}))
+	assert.True(t, services.HasActiveAppointment([]models.Appointment{{Status: models.AppointmentAccepted}}))
+}
@@ -0,0 +1,124 @@
+package testing_runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/middleware"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
+	"github.com/Bualoi-s-Dev/backend/services"
+	validators "github.com/Bualoi-s-Dev/backend/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitTestAdminStatsOf(t *testing.T) {
+	roles := map[string]int64{"Photographer": 3, "Customer": 5, "Guest": 1, "Admin": 1}
+	statuses := map[string]int64{"Pending": 2, "Accepted": 1, "Completed": 4}
+	payments := &models.PaymentTotals{Count: 4, Amount: 40000, Fee: 4000, RefundedAmount: 1000}
+
+	stats := services.AdminStatsOf(roles, 2, 7, statuses, payments)
+	assert.Equal(t, int64(10), stats.Users.Total)
+	assert.Equal(t, int64(3), stats.Users.Photographers)
+	assert.Equal(t, int64(5), stats.Users.Customers)
+	assert.Equal(t, int64(1), stats.Users.Admins)
+	assert.Equal(t, int64(2), stats.Users.Suspended)
+	assert.Equal(t, int64(7), stats.Packages)
+	assert.Equal(t, int64(7), stats.Appointments.Total)
+	assert.Equal(t, int64(2), stats.Appointments.Pending)
+	assert.Equal(t, int64(0), stats.Appointments.Canceled)
+	assert.Equal(t, int64(4), stats.Appointments.Completed)
+	assert.Equal(t, *payments, stats.Payments)
+
+	// Nothing is counted yet on a new platform
+	empty := services.AdminStatsOf(map[string]int64{}, 0, 0, map[string]int64{}, &models.PaymentTotals{})
+	assert.Equal(t, int64(0), empty.Users.Total)
+	assert.Equal(t, int64(0), empty.Appointments.Total)
+}
+
+// adminGuardRouter serves the admin guarded routes for the user, as the auth middleware would after verifying their token
+func adminGuardRouter(user *models.User, authTime time.Time) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if !middleware.CheckSession(c, user, authTime) {
+			return
+		}
+		c.Set("user", user)
+		c.Next()
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/admin/stats", middleware.AllowRoles(nil, models.Admin), ok)
+	router.DELETE("/appointment/:id", middleware.Allow(policies.ResourceAppointment, policies.ActionDelete), ok)
+	router.GET("/user/me", ok)
+	return router
+}
+
+func serveAdminGuard(router *gin.Engine, method, path string) int {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w.Code
+}
+
+func TestUnitTestAdminRoleGuard(t *testing.T) {
+	signIn := time.Now().Add(-time.Minute)
+	for _, role := range []models.UserRole{models.Customer, models.Photographer, models.Guest} {
+		router := adminGuardRouter(&models.User{Role: role}, signIn)
+		assert.Equal(t, http.StatusForbidden, serveAdminGuard(router, http.MethodGet, "/admin/stats"), role)
+		assert.Equal(t, http.StatusForbidden, serveAdminGuard(router, http.MethodDelete, "/appointment/1"), role)
+	}
+	router := adminGuardRouter(&models.User{Role: models.Admin}, signIn)
+	assert.Equal(t, http.StatusOK, serveAdminGuard(router, http.MethodGet, "/admin/stats"))
+	assert.Equal(t, http.StatusOK, serveAdminGuard(router, http.MethodDelete, "/appointment/1"))
+
+	// Users cannot make themselves an admin nor leave the role, other role changes are theirs
+	assert.Equal(t, apperrors.ErrForbidden, services.ValidateRoleChange(models.Customer, models.Admin))
+	assert.Equal(t, apperrors.ErrForbidden, services.ValidateRoleChange(models.Admin, models.Photographer))
+	assert.NoError(t, services.ValidateRoleChange(models.Customer, models.Photographer))
+	assert.NoError(t, services.ValidateRoleChange(models.Admin, models.Admin))
+}
+
+func TestUnitTestSuspensionGuard(t *testing.T) {
+	now := time.Now()
+	signIn := now.Add(-time.Hour)
+	user := &models.User{Role: models.Admin, Suspension: &models.UserSuspension{Type: models.SuspensionSuspended, Reason: "Spam"}}
+
+	// A suspended admin keeps only their own account
+	router := adminGuardRouter(user, signIn)
+	assert.Equal(t, http.StatusForbidden, serveAdminGuard(router, http.MethodGet, "/admin/stats"))
+	assert.Equal(t, http.StatusForbidden, serveAdminGuard(router, http.MethodDelete, "/appointment/1"))
+	assert.Equal(t, http.StatusOK, serveAdminGuard(router, http.MethodGet, "/user/me"))
+
+	user.Suspension = &models.UserSuspension{Type: models.SuspensionBanned, Reason: "Fraud"}
+	assert.Equal(t, http.StatusForbidden, serveAdminGuard(router, http.MethodGet, "/admin/stats"))
+
+	// An expired suspension lets them back in
+	expired := now.Add(-time.Minute)
+	user.Suspension = &models.UserSuspension{Type: models.SuspensionSuspended, ExpiresTime: &expired}
+	assert.Equal(t, http.StatusOK, serveAdminGuard(router, http.MethodGet, "/admin/stats"))
+
+	// The sessions revoked after the sign in are refused, even the own account
+	user.Suspension = nil
+	user.TokensValidAfter = &now
+	assert.Equal(t, http.StatusUnauthorized, serveAdminGuard(router, http.MethodGet, "/user/me"))
+	assert.Equal(t, http.StatusOK, serveAdminGuard(adminGuardRouter(user, now.Add(time.Second)), http.MethodGet, "/admin/stats"))
+}
+
+func TestUnitTestUserRoleValidator(t *testing.T) {
+	v := validator.New()
+	v.SetTagName("binding")
+	validators.RegisterCustomValidators(v)
+
+	// The users cannot ask for the admin role, only the back office grants it
+	photographer, admin := models.Photographer, models.Admin
+	assert.NoError(t, v.Struct(dto.UserRequest{Role: &photographer}))
+	assert.Error(t, v.Struct(dto.UserRequest{Role: &admin}))
+	assert.NoError(t, v.Struct(dto.AdminUserRoleRequest{Role: models.Admin}))
+	assert.Error(t, v.Struct(dto.AdminUserRoleRequest{Role: "Owner"}))
+}
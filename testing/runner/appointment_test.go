@@ -13,7 +13,7 @@ func TestAppointmentFeatures(t *testing.T) {
 	defer cleanUpAppointmentFeature()
 	server := GetTestServer()
 
-	scenario := &scenarios.AppointmentScenario{Server: server}
+	scenario := &scenarios.AppointmentScenario{Server: server, DB: GetTestMongoDB()}
 	testSuite := utils.SetupGodog("appointment.feature", scenario.InitializeScenario)
 	status := testSuite.Run()
 	if status != 0 {
@@ -0,0 +1,127 @@
+package testing_runner
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/encryption"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestAuditDiff(t *testing.T) {
+	before := &models.Rating{ID: primitive.NewObjectID(), Rating: 3, Review: "Good"}
+	after := *before
+	after.Rating = 5
+
+	changes := services.AuditDiff(services.AuditSnapshot(before), services.AuditSnapshot(&after))
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "rating", changes[0].Field)
+	assert.EqualValues(t, 3, changes[0].Before)
+	assert.EqualValues(t, 5, changes[0].After)
+
+	// A creation lists every field with no value before, sorted by name
+	created := services.AuditDiff(nil, services.AuditSnapshot(before))
+	assert.NotEmpty(t, created)
+	for i, change := range created {
+		assert.Nil(t, change.Before)
+		if i > 0 {
+			assert.Less(t, created[i-1].Field, change.Field)
+		}
+	}
+
+	// Nothing changed
+	assert.Empty(t, services.AuditDiff(services.AuditSnapshot(before), services.AuditSnapshot(before)))
+}
+
+func TestUnitTestAuditSnapshot(t *testing.T) {
+	var rating *models.Rating
+	assert.Nil(t, services.AuditSnapshot(nil))
+	assert.Nil(t, services.AuditSnapshot(rating))
+
+	// The snapshot is a copy, later changes of the entity are not in it
+	rating = &models.Rating{Rating: 4}
+	snapshot := services.AuditSnapshot(rating)
+	rating.Rating = 1
+	assert.EqualValues(t, 4, snapshot["rating"])
+}
+
+func TestUnitTestAuditRequest(t *testing.T) {
+	_, ok := services.AuditRequestFrom(context.Background())
+	assert.False(t, ok)
+
+	actorId := primitive.NewObjectID()
+	ctx := services.WithAuditRequest(context.Background(), models.AuditRequest{ActorID: &actorId, ActorRole: models.Admin, RequestID: "abc"})
+	request, ok := services.AuditRequestFrom(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, actorId, *request.ActorID)
+	assert.Equal(t, "abc", request.RequestID)
+
+	// Recording without a repository does nothing
+	var audit *services.AuditService
+	audit.Record(ctx, models.AuditRating, actorId, nil, &models.Rating{})
+}
+
+func TestUnitTestAuditEncryptedFields(t *testing.T) {
+	keyring, err := encryption.NewKeyring([]encryption.Key{{ID: "v1", Material: []byte("0123456789abcdef0123456789abcdef")}})
+	assert.NoError(t, err)
+	encryption.SetFieldKeyring(keyring)
+	defer encryption.SetFieldKeyring(nil)
+
+	before := &models.User{ID: primitive.NewObjectID(), Email: "user@example.com", Phone: "0812345678", BankAccount: "1234567890", Description: "Old"}
+	after := *before
+	after.Description = "New"
+
+	// Every encryption of the same value differs, only the changed field is listed
+	changes := services.AuditDiff(services.AuditSnapshot(before), services.AuditSnapshot(&after))
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "description", changes[0].Field)
+
+	after.BankAccount = "0987654321"
+	changes = services.AuditDiff(services.AuditSnapshot(before), services.AuditSnapshot(&after))
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "bank_account", changes[0].Field)
+
+	// The stored change has neither the plain text nor a ciphertext
+	data, err := bson.Marshal(models.AuditLog{Changes: changes})
+	assert.NoError(t, err)
+	var stored models.AuditLog
+	assert.NoError(t, bson.Unmarshal(data, &stored))
+	assert.Equal(t, services.AuditRedacted, stored.Changes[0].Before)
+	assert.Equal(t, services.AuditRedacted, stored.Changes[0].After)
+	assert.NotContains(t, string(data), "1234567890")
+	assert.NotContains(t, string(data), "0987654321")
+	assert.NotContains(t, string(data), "enc:")
+
+	// The plain text values stored before the fields were encrypted are redacted too
+	encryption.SetFieldKeyring(nil)
+	created := services.AuditDiff(nil, services.AuditSnapshot(before))
+	for _, change := range created {
+		if change.Field == "phone" || change.Field == "bank_account" {
+			data, err := json.Marshal(change.After)
+			assert.NoError(t, err)
+			assert.Equal(t, `"`+services.AuditRedacted+`"`, string(data))
+		}
+	}
+}
+
+func TestUnitTestAuditEncryptedValues(t *testing.T) {
+	// The partial documents of the changed fields are redacted by the type of their values
+	before := bson.M{"phone": models.EncryptedString("0812345678"), "verified_phone": models.DeterministicString(""), "bank_name": models.BangkokBank}
+	after := bson.M{"phone": models.EncryptedString("0812345678"), "verified_phone": models.DeterministicString("0812345678"), "bank_name": models.BangkokBank}
+
+	changes := services.AuditDiff(services.AuditSnapshot(before), services.AuditSnapshot(after))
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "verified_phone", changes[0].Field)
+	assert.Equal(t, models.DeterministicString(""), changes[0].Before)
+	data, err := json.Marshal(changes[0].After)
+	assert.NoError(t, err)
+	assert.Equal(t, `"`+services.AuditRedacted+`"`, string(data))
+
+	// The document of the caller is not changed
+	assert.Equal(t, models.EncryptedString("0812345678"), before["phone"])
+}
@@ -0,0 +1,143 @@
+package testing_runner
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	authRepo "github.com/Bualoi-s-Dev/backend/repositories/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitTestLocalVerifierHMAC(t *testing.T) {
+	ctx := context.Background()
+	verifier := authRepo.NewHMACVerifier([]byte("secret"))
+
+	token, err := verifier.MintToken("user@example.com", time.Hour)
+	assert.NoError(t, err)
+	claims, err := verifier.VerifyIDToken(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", claims["email"])
+	assert.Equal(t, verifier.UID("user@example.com"), claims["sub"])
+	authTime, ok := authRepo.ClaimTime(claims, "auth_time")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now(), authTime, time.Minute)
+
+	// The custom claims are in the tokens minted afterwards, the reserved claims cannot be replaced
+	assert.NoError(t, verifier.SetCustomUserClaims(ctx, "user@example.com", map[string]interface{}{"role": "Photographer", "email": "other@example.com"}))
+	token, err = verifier.MintToken("user@example.com", 0)
+	assert.NoError(t, err)
+	claims, err = verifier.VerifyIDToken(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, "Photographer", claims["role"])
+	assert.Equal(t, "user@example.com", claims["email"])
+
+	// Another secret does not accept the token
+	_, err = authRepo.NewHMACVerifier([]byte("other")).VerifyIDToken(ctx, token)
+	assert.ErrorIs(t, err, authRepo.ErrInvalidToken)
+
+	providers, err := verifier.GetProviders(ctx, "user@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{authRepo.LocalProviderID}, providers)
+	providers, err = verifier.GetProviders(ctx, "missing@example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, providers)
+}
+
+func TestUnitTestLocalVerifierRSA(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	verifier := authRepo.NewRSAVerifier(key)
+
+	token, err := verifier.MintToken("user@example.com", time.Hour)
+	assert.NoError(t, err)
+	claims, err := verifier.VerifyIDToken(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", claims["email"])
+
+	// A token cannot switch to HMAC signed with the public key
+	hmacToken, err := authRepo.NewHMACVerifier(key.PublicKey.N.Bytes()).MintToken("user@example.com", time.Hour)
+	assert.NoError(t, err)
+	_, err = verifier.VerifyIDToken(ctx, hmacToken)
+	assert.ErrorIs(t, err, authRepo.ErrInvalidToken)
+}
+
+func TestUnitTestLocalVerifierRejectsInvalidTokens(t *testing.T) {
+	ctx := context.Background()
+	verifier := authRepo.NewHMACVerifier([]byte("secret"))
+	now := time.Now()
+
+	expired, err := verifier.Sign(map[string]interface{}{
+		"iss": authRepo.LocalTokenIssuer, "sub": "uid", "email": "user@example.com",
+		"iat": now.Add(-2 * time.Hour).Unix(), "exp": now.Add(-time.Hour).Unix(),
+	})
+	assert.NoError(t, err)
+	_, err = verifier.VerifyIDToken(ctx, expired)
+	assert.ErrorIs(t, err, authRepo.ErrTokenExpired)
+
+	otherIssuer, err := verifier.Sign(map[string]interface{}{
+		"iss": "someone", "sub": "uid", "email": "user@example.com",
+		"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+	})
+	assert.NoError(t, err)
+	_, err = verifier.VerifyIDToken(ctx, otherIssuer)
+	assert.ErrorIs(t, err, authRepo.ErrInvalidToken)
+
+	noExpiry, err := verifier.Sign(map[string]interface{}{
+		"iss": authRepo.LocalTokenIssuer, "sub": "uid", "email": "user@example.com", "iat": now.Unix(),
+	})
+	assert.NoError(t, err)
+	_, err = verifier.VerifyIDToken(ctx, noExpiry)
+	assert.ErrorIs(t, err, authRepo.ErrInvalidToken)
+
+	// Changing the payload breaks the signature
+	token, err := verifier.MintToken("user@example.com", time.Hour)
+	assert.NoError(t, err)
+	parts := strings.Split(token, ".")
+	other, err := verifier.MintToken("admin@example.com", time.Hour)
+	assert.NoError(t, err)
+	tampered := parts[0] + "." + strings.Split(other, ".")[1] + "." + parts[2]
+	_, err = verifier.VerifyIDToken(ctx, tampered)
+	assert.ErrorIs(t, err, authRepo.ErrInvalidToken)
+
+	// Unsigned tokens are refused
+	unsigned := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0." + parts[1] + "."
+	_, err = verifier.VerifyIDToken(ctx, unsigned)
+	assert.ErrorIs(t, err, authRepo.ErrInvalidToken)
+
+	_, err = verifier.VerifyIDToken(ctx, "not-a-token")
+	assert.ErrorIs(t, err, authRepo.ErrInvalidToken)
+}
+
+func TestUnitTestLocalVerifierSignIn(t *testing.T) {
+	ctx := context.Background()
+	verifier := authRepo.NewHMACVerifier([]byte("secret"))
+
+	// An email without an account or a password cannot sign in
+	_, err := verifier.SignIn("user@example.com", "password")
+	assert.ErrorIs(t, err, authRepo.ErrInvalidCredentials)
+	verifier.UID("user@example.com")
+	_, err = verifier.SignIn("user@example.com", "")
+	assert.ErrorIs(t, err, authRepo.ErrInvalidCredentials)
+
+	uid, err := verifier.Register("user@example.com", "password")
+	assert.NoError(t, err)
+	assert.Equal(t, verifier.UID("user@example.com"), uid)
+	_, err = verifier.Register("user@example.com", "other")
+	assert.ErrorIs(t, err, authRepo.ErrEmailExists)
+
+	_, err = verifier.SignIn("user@example.com", "wrong")
+	assert.ErrorIs(t, err, authRepo.ErrInvalidCredentials)
+	_, err = verifier.SignIn("admin@example.com", "password")
+	assert.ErrorIs(t, err, authRepo.ErrInvalidCredentials)
+
+	token, err := verifier.SignIn("user@example.com", "password")
+	assert.NoError(t, err)
+	claims, err := verifier.VerifyIDToken(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, uid, claims["sub"])
+}
@@ -0,0 +1,94 @@
+package testing_runner
+
+import (
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/encryption"
+	"github.com/Bualoi-s-Dev/backend/models"
+	validators "github.com/Bualoi-s-Dev/backend/validator"
+	"github.com/go-playground/validator/v10"
+	"github.com/jinzhu/copier"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestUnitTestIsValidBankAccount(t *testing.T) {
+	assert.True(t, models.IsValidBankAccount(models.KasikornBank, "1234567890"))
+	assert.True(t, models.IsValidBankAccount(models.KasikornBank, "123-4-56789-0"))
+	assert.True(t, models.IsValidBankAccount(models.GovernmentSavingsBank, "123456789012"))
+	assert.True(t, models.IsValidBankAccount(models.StandardChartered, "12345678901"))
+	assert.True(t, models.IsValidBankAccount("", "123456789012"))
+
+	assert.False(t, models.IsValidBankAccount(models.KasikornBank, "123456789012"))
+	assert.False(t, models.IsValidBankAccount(models.GovernmentSavingsBank, "1234567890"))
+	assert.False(t, models.IsValidBankAccount(models.KasikornBank, "12345678ab"))
+	assert.False(t, models.IsValidBankAccount("", "123456789"))
+}
+
+func TestUnitTestValidateBankAccount(t *testing.T) {
+	v := validator.New()
+	v.SetTagName("binding")
+	validators.RegisterCustomValidators(v)
+
+	bank, number := models.SiamCommercialBank, "1234567890"
+	assert.NoError(t, v.Struct(dto.UserRequest{BankName: &bank, BankAccount: &number}))
+	assert.NoError(t, v.Struct(dto.UserRequest{BankAccount: &number}))
+	assert.NoError(t, v.Struct(dto.UserRequest{}))
+	bank = models.GovernmentSavingsBank
+	assert.Error(t, v.Struct(dto.UserRequest{BankName: &bank, BankAccount: &number}))
+
+	req := dto.VerificationRequest{BankName: models.StandardChartered, BankAccount: "1234567890"}
+	assert.Error(t, v.StructPartial(req, "BankAccount"))
+	req.BankAccount = "123-456789-01"
+	assert.NoError(t, v.StructPartial(req, "BankAccount"))
+}
+
+func TestUnitTestMaskBankAccount(t *testing.T) {
+	assert.Equal(t, "xxxxxx7890", models.MaskBankAccount("1234567890"))
+	assert.Equal(t, "xxx", models.MaskBankAccount("123"))
+	assert.Equal(t, "", models.MaskBankAccount(""))
+}
+
+func TestUnitTestEncryptedString(t *testing.T) {
+	keyring, err := encryption.NewKeyring([]encryption.Key{{ID: "v1", Material: []byte("0123456789abcdef0123456789abcdef")}})
+	assert.NoError(t, err)
+	encryption.SetFieldKeyring(keyring)
+	defer encryption.SetFieldKeyring(nil)
+
+	data, err := bson.Marshal(models.User{BankAccount: "1234567890"})
+	assert.NoError(t, err)
+	var raw bson.M
+	assert.NoError(t, bson.Unmarshal(data, &raw))
+	assert.True(t, encryption.IsEncrypted(raw["bank_account"].(string)))
+	assert.NotContains(t, raw["bank_account"], "1234567890")
+
+	var user models.User
+	assert.NoError(t, bson.Unmarshal(data, &user))
+	assert.Equal(t, models.EncryptedString("1234567890"), user.BankAccount)
+
+	// A number stored before the encryption is read as it is
+	data, _ = bson.Marshal(bson.M{"bank_account": "0987654321"})
+	assert.NoError(t, bson.Unmarshal(data, &user))
+	assert.Equal(t, models.EncryptedString("0987654321"), user.BankAccount)
+
+	// An empty number is not stored
+	data, _ = bson.Marshal(models.User{})
+	raw = bson.M{}
+	assert.NoError(t, bson.Unmarshal(data, &raw))
+	assert.NotContains(t, raw, "bank_account")
+
+	// A number encrypted with another key is not read
+	encrypted, _ := keyring.Encrypt("1234567890", false)
+	other, _ := encryption.NewKeyring([]encryption.Key{{ID: "v1", Material: []byte("fedcba9876543210fedcba9876543210")}})
+	encryption.SetFieldKeyring(other)
+	data, _ = bson.Marshal(bson.M{"bank_account": encrypted})
+	assert.Error(t, bson.Unmarshal(data, &user))
+}
+
+func TestUnitTestCopyBankAccount(t *testing.T) {
+	number := "1234567890"
+	user := models.User{}
+	assert.NoError(t, copier.Copy(&user, &dto.UserRequest{BankAccount: &number}))
+	assert.Equal(t, models.EncryptedString(number), user.BankAccount)
+}
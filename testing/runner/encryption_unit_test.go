@@ -0,0 +1,130 @@
+package testing_runner
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/encryption"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	oldFieldKey = []byte("0123456789abcdef0123456789abcdef")
+	newFieldKey = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func TestUnitTestParseKeys(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(oldFieldKey)
+	keys, err := encryption.ParseKeys(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, []encryption.Key{{ID: encryption.DefaultKeyID, Material: oldFieldKey}}, keys)
+
+	keys, err = encryption.ParseKeys("v2:" + base64.StdEncoding.EncodeToString(newFieldKey) + ",\nv1:" + encoded + "\n")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.Equal(t, "v2", keys[0].ID)
+	assert.Equal(t, newFieldKey, keys[0].Material)
+	assert.Equal(t, "v1", keys[1].ID)
+
+	keys, err = encryption.ParseKeys("")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = encryption.ParseKeys(encoded + "," + encoded)
+	assert.Error(t, err)
+	_, err = encryption.ParseKeys("v1:not base64!")
+	assert.Error(t, err)
+	_, err = encryption.NewKeyring([]encryption.Key{{ID: "v1", Material: []byte("short")}})
+	assert.Error(t, err)
+	_, err = encryption.NewKeyring([]encryption.Key{{ID: "v1", Material: oldFieldKey}, {ID: "v1", Material: newFieldKey}})
+	assert.Error(t, err)
+}
+
+func TestUnitTestKeyring(t *testing.T) {
+	old, _ := encryption.NewKeyring([]encryption.Key{{ID: "v1", Material: oldFieldKey}})
+	rotated, _ := encryption.NewKeyring([]encryption.Key{{ID: "v2", Material: newFieldKey}, {ID: "v1", Material: oldFieldKey}})
+
+	randomized, err := old.Encrypt("+66812345678", false)
+	assert.NoError(t, err)
+	again, _ := old.Encrypt("+66812345678", false)
+	assert.NotEqual(t, randomized, again)
+	assert.True(t, strings.HasPrefix(randomized, "enc:v1:"))
+
+	deterministic, _ := old.Encrypt("+66812345678", true)
+	again, _ = old.Encrypt("+66812345678", true)
+	assert.Equal(t, deterministic, again)
+	other, _ := old.Encrypt("+66899999999", true)
+	assert.NotEqual(t, deterministic, other)
+
+	// The new primary key still decrypts the values of the old one
+	for _, value := range []string{randomized, deterministic} {
+		plaintext, err := rotated.Decrypt(value)
+		assert.NoError(t, err)
+		assert.Equal(t, "+66812345678", plaintext)
+		assert.True(t, old.IsCurrent(value))
+		assert.False(t, rotated.IsCurrent(value))
+	}
+	encrypted, _ := rotated.Encrypt("+66812345678", false)
+	_, err = old.Decrypt(encrypted)
+	assert.ErrorIs(t, err, encryption.ErrUnknownKey)
+
+	plaintext, err := old.Decrypt("+66812345678")
+	assert.NoError(t, err)
+	assert.Equal(t, "+66812345678", plaintext)
+
+	lookup, err := rotated.Lookup("+66812345678")
+	assert.NoError(t, err)
+	assert.Contains(t, lookup, "+66812345678")
+	assert.Contains(t, lookup, deterministic)
+	assert.Len(t, lookup, 3)
+}
+
+func TestUnitTestEncryptedFields(t *testing.T) {
+	assert.Equal(t, map[string]bool{
+		"phone":              false,
+		"verified_phone":     true,
+		"bank_account":       false,
+		"stripe_customer_id": false,
+		"stripe_account_id":  false,
+	}, services.EncryptedFields(models.User{}))
+	assert.Equal(t, map[string]bool{"bank_account": false}, services.EncryptedFields(models.PhotographerVerification{}))
+}
+
+func TestUnitTestRotatedFields(t *testing.T) {
+	old, _ := encryption.NewKeyring([]encryption.Key{{ID: "v1", Material: oldFieldKey}})
+	rotated, _ := encryption.NewKeyring([]encryption.Key{{ID: "v2", Material: newFieldKey}, {ID: "v1", Material: oldFieldKey}})
+	fields := services.EncryptedFields(models.User{})
+
+	phone, _ := old.Encrypt("+66812345678", false)
+	verifiedPhone, _ := old.Encrypt("+66812345678", true)
+	current, _ := rotated.Encrypt("1234567890", false)
+	doc := bson.M{"phone": phone, "verified_phone": verifiedPhone, "bank_account": current, "stripe_account_id": "acct_123", "name": "Meen"}
+
+	expected, updates, err := services.RotatedFields(rotated, doc, fields)
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"phone": phone, "verified_phone": verifiedPhone, "stripe_account_id": "acct_123"}, expected)
+	assert.Len(t, updates, 3)
+	for field, value := range updates {
+		assert.True(t, rotated.IsCurrent(value.(string)), field)
+	}
+	expectedPhone, _ := rotated.Encrypt("+66812345678", true)
+	assert.Equal(t, expectedPhone, updates["verified_phone"])
+	stripeAccountId, _ := rotated.Decrypt(updates["stripe_account_id"].(string))
+	assert.Equal(t, "acct_123", stripeAccountId)
+
+	// Nothing is left once every field is rotated
+	for field, value := range updates {
+		doc[field] = value
+	}
+	_, updates, err = services.RotatedFields(rotated, doc, fields)
+	assert.NoError(t, err)
+	assert.Empty(t, updates)
+
+	unknown, _ := rotated.Encrypt("1234567890", false)
+	_, _, err = services.RotatedFields(old, bson.M{"bank_account": unknown}, fields)
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package testing_runner
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	storage "github.com/Bualoi-s-Dev/backend/repositories/storage"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestGalleryFileName(t *testing.T) {
+	assert.Equal(t, "IMG_0001.jpg", services.GalleryFileName("IMG_0001.jpg", 1, "image/jpeg"))
+	assert.Equal(t, "IMG_0002.png", services.GalleryFileName("C:\\Users\\me\\IMG_0002.png", 2, "image/png"))
+	assert.Equal(t, "IMG_0003.jpg", services.GalleryFileName("../../IMG_0003.jpg", 3, "image/jpeg"))
+	assert.Equal(t, "photo_004.webp", services.GalleryFileName("", 4, "image/webp"))
+}
+
+func TestUnitTestExtraSelectionCount(t *testing.T) {
+	assert.Equal(t, 0, services.ExtraSelectionCount(15, 20))
+	assert.Equal(t, 0, services.ExtraSelectionCount(20, 20))
+	assert.Equal(t, 3, services.ExtraSelectionCount(23, 20))
+	// No limit on the subpackage
+	assert.Equal(t, 0, services.ExtraSelectionCount(100, 0))
+}
+
+func TestUnitTestGalleryArchiveEntryNames(t *testing.T) {
+	photos := []models.GalleryPhoto{
+		{FileName: "IMG_0001.jpg", ContentType: "image/jpeg"},
+		{FileName: "img_0001.JPG", ContentType: "image/jpeg"},
+		{FileName: "IMG_0001.jpg", ContentType: "image/jpeg"},
+		{FileName: "", ContentType: "image/png"},
+	}
+	assert.Equal(t, []string{"IMG_0001.jpg", "img_0001 (2).JPG", "IMG_0001 (3).jpg", "photo_004.png"}, services.GalleryArchiveEntryNames(photos))
+}
+
+func TestUnitTestGalleryFingerprint(t *testing.T) {
+	photos := []models.GalleryPhoto{
+		{ID: primitive.NewObjectID(), Key: "gallery/a/1"},
+		{ID: primitive.NewObjectID(), Key: "gallery/a/2"},
+	}
+	fingerprint := services.GalleryFingerprint(photos)
+	assert.Equal(t, fingerprint, services.GalleryFingerprint(append([]models.GalleryPhoto{}, photos...)))
+	assert.NotEqual(t, fingerprint, services.GalleryFingerprint(photos[:1]))
+	assert.NotEqual(t, fingerprint, services.GalleryFingerprint([]models.GalleryPhoto{photos[1], photos[0]}))
+}
+
+func TestUnitTestIsLargeGallery(t *testing.T) {
+	small := &models.Gallery{Photos: make([]models.GalleryPhoto, services.GalleryPrebuildPhotoCount-1)}
+	assert.False(t, services.IsLargeGallery(small))
+
+	many := &models.Gallery{Photos: make([]models.GalleryPhoto, services.GalleryPrebuildPhotoCount)}
+	assert.True(t, services.IsLargeGallery(many))
+
+	heavy := &models.Gallery{Photos: []models.GalleryPhoto{{Size: services.GalleryPrebuildSize / 2}, {Size: services.GalleryPrebuildSize / 2}}}
+	assert.True(t, services.IsLargeGallery(heavy))
+}
+
+func TestUnitTestWriteGalleryArchive(t *testing.T) {
+	repo, err := storage.NewLocalRepository(t.TempDir(), "/storage", []byte("secret"))
+	assert.NoError(t, err)
+	service := &services.GalleryService{S3Service: services.NewS3Service(repo)}
+
+	gallery := &models.Gallery{Photos: []models.GalleryPhoto{
+		{ID: primitive.NewObjectID(), Key: "gallery/a/1", FileName: "IMG_0001.jpg", ContentType: "image/jpeg"},
+		{ID: primitive.NewObjectID(), Key: "gallery/a/2", FileName: "IMG_0001.jpg", ContentType: "image/jpeg"},
+	}}
+	assert.NoError(t, repo.PutObject("gallery/a/1", []byte("first"), "image/jpeg"))
+	assert.NoError(t, repo.PutObject("gallery/a/2", []byte("second"), "image/jpeg"))
+
+	var buf bytes.Buffer
+	assert.NoError(t, service.WriteArchive(&buf, gallery))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	contents := map[string]string{}
+	for _, file := range archive.File {
+		body, err := file.Open()
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(body)
+		body.Close()
+		contents[file.Name] = string(data)
+	}
+	assert.Equal(t, map[string]string{"IMG_0001.jpg": "first", "IMG_0001 (2).jpg": "second"}, contents)
+
+	// A missing photo fails the archive
+	gallery.Photos = append(gallery.Photos, models.GalleryPhoto{Key: "gallery/a/3"})
+	assert.Error(t, service.WriteArchive(io.Discard, gallery))
+}
+
+func TestUnitTestCreditExtraPhotoPayment(t *testing.T) {
+	current, replaced := primitive.NewObjectID(), primitive.NewObjectID()
+	newSelection := func() *models.ProofSelection {
+		return &models.ProofSelection{ExtraPhotoPrice: 100, ExtraCount: 3, Status: models.ProofSelectionAwaitingPayment, ExtraPaymentID: &current}
+	}
+
+	// The current checkout pays every extra photo
+	selection := newSelection()
+	assert.True(t, services.CreditExtraPhotoPayment(selection, &models.Payment{ID: current, Amount: 300}))
+	assert.Equal(t, 3, selection.PaidExtraCount)
+
+	// A replaced checkout paid for fewer photos leaves the rest to pay
+	selection = newSelection()
+	assert.False(t, services.CreditExtraPhotoPayment(selection, &models.Payment{ID: replaced, Amount: 200}))
+	assert.Equal(t, 2, selection.PaidExtraCount)
+	assert.True(t, services.CreditExtraPhotoPayment(selection, &models.Payment{ID: primitive.NewObjectID(), Amount: 100}))
+	assert.Equal(t, 3, selection.PaidExtraCount)
+
+	// A replaced checkout for the same photos pays them all
+	selection = newSelection()
+	assert.True(t, services.CreditExtraPhotoPayment(selection, &models.Payment{ID: replaced, Amount: 300}))
+	assert.Equal(t, 3, selection.PaidExtraCount)
+}
@@ -0,0 +1,101 @@
+package testing_runner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// jpegWithExif encodes a width x height JPEG carrying an EXIF segment with the orientation and a GPS pointer
+func jpegWithExif(t *testing.T, width, height int, orientation uint16) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil))
+	encoded := buf.Bytes()
+
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	ifd := make([]byte, 2+2*12+4)
+	binary.LittleEndian.PutUint16(ifd[0:], 2)
+	// Orientation, SHORT
+	binary.LittleEndian.PutUint16(ifd[2:], 0x0112)
+	binary.LittleEndian.PutUint16(ifd[4:], 3)
+	binary.LittleEndian.PutUint32(ifd[6:], 1)
+	binary.LittleEndian.PutUint16(ifd[10:], orientation)
+	// GPS IFD pointer, LONG
+	binary.LittleEndian.PutUint16(ifd[14:], 0x8825)
+	binary.LittleEndian.PutUint16(ifd[16:], 4)
+	binary.LittleEndian.PutUint32(ifd[18:], 1)
+	payload := append([]byte("Exif\x00\x00"), append(tiff, ifd...)...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, encoded[:2]...)
+	out = append(out, segment...)
+	return append(out, encoded[2:]...)
+}
+
+func TestUnitTestProcessImage(t *testing.T) {
+	data := jpegWithExif(t, 3000, 1500, 6)
+
+	renditions, err := services.ProcessImage(data, nil)
+	assert.NoError(t, err)
+	assert.Len(t, renditions, 4)
+
+	sizes := map[string][2]int{}
+	for _, rendition := range renditions {
+		assert.NotContains(t, string(rendition.Data), "Exif", rendition.Name)
+		if rendition.Ext == "jpg" {
+			sizes[rendition.Name] = [2]int{rendition.Width, rendition.Height}
+		}
+	}
+	// Orientation 6 turns the landscape original into a portrait image
+	assert.Equal(t, [2]int{160, 320}, sizes["thumbnail"])
+	assert.Equal(t, [2]int{512, 1024}, sizes["medium"])
+	assert.Equal(t, [2]int{1024, 2048}, sizes["large"])
+}
+
+func TestUnitTestVerifyImage(t *testing.T) {
+	_, err := services.VerifyImage([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0x10, 'J', 'F', 'I', 'F'})
+	assert.Error(t, err)
+
+	_, err = services.VerifyImage([]byte("<svg></svg>"))
+	assert.Error(t, err)
+
+	format, err := services.VerifyImage(jpegWithExif(t, 10, 10, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+}
+
+func TestUnitTestProcessProofImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 3000, 2000))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, src, nil))
+
+	proof, err := services.ProcessProofImage(buf.Bytes(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1024, proof.Width)
+	assert.Equal(t, 682, proof.Height)
+
+	img, err := jpeg.Decode(bytes.NewReader(proof.Data))
+	assert.NoError(t, err)
+	// The black original is covered by the light watermark somewhere
+	watermarked := false
+	for y := 0; y < img.Bounds().Dy() && !watermarked; y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r > 0x4000 {
+				watermarked = true
+				break
+			}
+		}
+	}
+	assert.True(t, watermarked)
+}
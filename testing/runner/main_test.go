@@ -10,6 +10,7 @@ import (
 	"github.com/Bualoi-s-Dev/backend/bootstrap"
 	"github.com/Bualoi-s-Dev/backend/configs"
 	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -26,6 +27,18 @@ func TestMain(m *testing.M) {
 	}
 
 	configs.LoadEnv()
+	// The test environment wins over .env, so the suite runs with the local verifier and storage instead of
+	// Firebase and S3 even when .env points to them
+	if err := godotenv.Overload(".env.test"); err != nil {
+		log.Println("WARNING: No .env.test file found")
+	}
+	// The suite never writes to the bucket, the files go to a directory removed after the run
+	os.Setenv("STORAGE_BACKEND", "local")
+	storageDir, err := os.MkdirTemp("", "photomatch-storage-")
+	if err != nil {
+		log.Fatalf("Failed to create storage directory: %v", err)
+	}
+	os.Setenv("LOCAL_STORAGE_DIR", storageDir)
 	// Start test server
 	testServer = startTestServer()
 
@@ -33,6 +46,7 @@ func TestMain(m *testing.M) {
 
 	// Stop the test server after tests
 	stopTestServer(testServer)
+	os.RemoveAll(storageDir)
 	os.Exit(code)
 }
 
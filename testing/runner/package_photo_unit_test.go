@@ -0,0 +1,74 @@
+package testing_runner
+
+import (
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestReorderImages(t *testing.T) {
+	a, b, c := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	images := []models.Image{{ID: a, Large: "/a"}, {ID: b, Large: "/b"}, {ID: c, Large: "/c"}}
+
+	tests := []struct {
+		name          string
+		photoIds      []primitive.ObjectID
+		expected      []string
+		expectedError error
+	}{
+		{name: "new order", photoIds: []primitive.ObjectID{c, a, b}, expected: []string{"/c", "/a", "/b"}},
+		{name: "same order", photoIds: []primitive.ObjectID{a, b, c}, expected: []string{"/a", "/b", "/c"}},
+		{name: "missing photo", photoIds: []primitive.ObjectID{a, b}, expectedError: apperrors.ErrPackagePhotoOrder},
+		{name: "duplicated photo", photoIds: []primitive.ObjectID{a, a, b}, expectedError: apperrors.ErrPackagePhotoOrder},
+		{name: "unknown photo", photoIds: []primitive.ObjectID{a, b, primitive.NewObjectID()}, expectedError: apperrors.ErrPackagePhotoOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := services.ReorderImages(images, tt.photoIds)
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError != nil {
+				return
+			}
+			urls := []string{}
+			for _, image := range ordered {
+				urls = append(urls, image.Large)
+			}
+			assert.Equal(t, tt.expected, urls)
+		})
+	}
+}
+
+func TestUnitTestNormalizePackageImages(t *testing.T) {
+	stored, packageId := primitive.NewObjectID(), primitive.NewObjectID()
+	load := func(id primitive.ObjectID) *models.Package {
+		return &models.Package{
+			ID:        id,
+			PhotoUrls: []string{"/a", "/b", "/c"},
+			Images:    []models.Image{{ID: stored, Large: "/a"}, {OriginalKey: "original/b", Large: "/b"}},
+		}
+	}
+	pkg := load(packageId)
+	services.NormalizePackageImages(pkg)
+	assert.Len(t, pkg.Images, 3)
+	assert.Equal(t, stored, pkg.Images[0].ID)
+	assert.Equal(t, "/c", pkg.Images[2].Large)
+
+	// Another request for the same package sees the same ids
+	again := load(packageId)
+	services.NormalizePackageImages(again)
+	for i := range pkg.Images {
+		assert.False(t, pkg.Images[i].ID.IsZero())
+		assert.Equal(t, pkg.Images[i].ID, again.Images[i].ID)
+	}
+	assert.NotEqual(t, pkg.Images[1].ID, pkg.Images[2].ID)
+
+	// The same photo in another package has another id
+	other := load(primitive.NewObjectID())
+	services.NormalizePackageImages(other)
+	assert.NotEqual(t, pkg.Images[2].ID, other.Images[2].ID)
+}
@@ -0,0 +1,110 @@
+package testing_runner
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestPaymentExportRow(t *testing.T) {
+	appointment := models.Appointment{
+		ID:     primitive.NewObjectID(),
+		Status: models.AppointmentCompleted,
+		Price:  1350,
+		Discount: &models.AppointmentDiscount{
+			Code:          "SUMMER10",
+			OriginalPrice: 1500,
+			Amount:        150,
+		},
+	}
+
+	t.Run("appointment payment with refund", func(t *testing.T) {
+		row := services.ToPaymentExportRow(models.PaymentWithAppointment{
+			Payment: models.Payment{
+				ID:       primitive.NewObjectID(),
+				Type:     models.PaymentAppointment,
+				Amount:   1350,
+				Fee:      5,
+				Customer: models.CustomerPayment{Status: models.Paid, RefundedAmount: 100},
+			},
+			Appointment: appointment,
+		})
+		assert.Equal(t, 1500, row.OriginalPrice)
+		assert.Equal(t, "SUMMER10", row.PromoCode)
+		assert.Equal(t, 150, row.Discount)
+		assert.Equal(t, 1245, row.Net)
+	})
+
+	t.Run("legacy payment falls back to appointment price", func(t *testing.T) {
+		row := services.ToPaymentExportRow(models.PaymentWithAppointment{
+			Payment:     models.Payment{ID: primitive.NewObjectID()},
+			Appointment: appointment,
+		})
+		assert.Equal(t, models.PaymentAppointment, row.Type)
+		assert.Equal(t, 1350, row.Amount)
+	})
+
+	t.Run("tip ignores appointment discount", func(t *testing.T) {
+		row := services.ToPaymentExportRow(models.PaymentWithAppointment{
+			Payment:     models.Payment{ID: primitive.NewObjectID(), Type: models.PaymentTip, Amount: 100},
+			Appointment: appointment,
+		})
+		assert.Equal(t, 100, row.OriginalPrice)
+		assert.Equal(t, "", row.PromoCode)
+		assert.Equal(t, 100, row.Net)
+	})
+}
+
+func TestUnitTestPaymentExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	writer := services.NewPaymentExportCSVWriter(&buf)
+	assert.NoError(t, writer.WriteRow(dto.PaymentExportRow{PaymentID: "abc", Type: models.PaymentTip, Amount: 100, Net: 100}))
+	assert.NoError(t, writer.WriteRow(dto.PaymentExportRow{PaymentID: "def", Type: models.PaymentAppointment, Amount: 1000, Net: 950}))
+	assert.NoError(t, writer.Flush())
+	assert.NoError(t, writer.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "Payment ID", records[0][0])
+	assert.Equal(t, "abc", records[1][0])
+	assert.Equal(t, "def", records[2][0])
+	assert.Equal(t, len(records[0]), len(records[1]))
+
+	// An export without payments still has its header
+	buf.Reset()
+	writer = services.NewPaymentExportCSVWriter(&buf)
+	assert.NoError(t, writer.Flush())
+	records, err = csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestUnitTestPaymentExportXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := services.NewPaymentExportXLSXWriter(&buf)
+	assert.NoError(t, err)
+	defer writer.Close()
+	for _, id := range []string{"abc", "def"} {
+		assert.NoError(t, writer.WriteRow(dto.PaymentExportRow{PaymentID: id, Type: models.PaymentTip, Amount: 100, Net: 100}))
+	}
+	// Nothing is written before the workbook is complete
+	assert.Zero(t, buf.Len())
+	assert.NoError(t, writer.Flush())
+
+	file, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	defer file.Close()
+	rows, err := file.GetRows("Sheet1")
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "Payment ID", rows[0][0])
+	assert.Equal(t, "def", rows[2][0])
+}
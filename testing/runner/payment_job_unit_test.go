@@ -0,0 +1,28 @@
+package testing_runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitTestPaymentJobBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 0, expected: time.Minute},
+		{attempts: 1, expected: time.Minute},
+		{attempts: 2, expected: 2 * time.Minute},
+		{attempts: 4, expected: 8 * time.Minute},
+		{attempts: 9, expected: 256 * time.Minute},
+		{attempts: 10, expected: 6 * time.Hour},
+		{attempts: 50, expected: 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, services.PaymentJobBackoff(tt.attempts), "attempts %d", tt.attempts)
+	}
+}
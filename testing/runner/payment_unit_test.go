@@ -0,0 +1,119 @@
+package testing_runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	database "github.com/Bualoi-s-Dev/backend/repositories/database"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestUnitTestExpireCheckout(t *testing.T) {
+	service := &services.PaymentService{}
+
+	// A paid checkout cannot be closed, the callers tell what was paid
+	paid := &models.Payment{Customer: models.CustomerPayment{Status: models.Paid}}
+	assert.ErrorIs(t, service.ExpireCheckout(context.Background(), paid), apperrors.ErrCheckoutCompleted)
+	// Nothing to close without a checkout
+	assert.NoError(t, service.ExpireCheckout(context.Background(), &models.Payment{}))
+}
+
+func TestUnitTestCheckTippable(t *testing.T) {
+	account := models.EncryptedString("acct_123")
+	customer := &models.User{ID: primitive.NewObjectID(), Role: models.Customer}
+	newPhotographer := func() *models.User {
+		return &models.User{ID: primitive.NewObjectID(), Role: models.Photographer, VerificationStatus: models.VerificationApproved, StripeAccountID: &account}
+	}
+	newAppointment := func(photographer *models.User) *models.Appointment {
+		return &models.Appointment{ID: primitive.NewObjectID(), CustomerID: customer.ID, PhotographerID: photographer.ID, Status: models.AppointmentCompleted}
+	}
+
+	tests := []struct {
+		name          string
+		setup         func(tipper **models.User, appointment *models.Appointment, photographer *models.User)
+		expectedError error
+	}{
+		{name: "completed appointment", setup: func(**models.User, *models.Appointment, *models.User) {}},
+		{
+			name: "appointment not completed",
+			setup: func(_ **models.User, appointment *models.Appointment, _ *models.User) {
+				appointment.Status = models.AppointmentAccepted
+			},
+			expectedError: apperrors.ErrTipAppointmentNotCompleted,
+		},
+		{
+			name: "another customer",
+			setup: func(tipper **models.User, _ *models.Appointment, _ *models.User) {
+				*tipper = &models.User{ID: primitive.NewObjectID(), Role: models.Customer}
+			},
+			expectedError: apperrors.ErrAppointmentNotFound,
+		},
+		{
+			name:          "the photographer",
+			setup:         func(tipper **models.User, _ *models.Appointment, photographer *models.User) { *tipper = photographer },
+			expectedError: apperrors.ErrForbidden,
+		},
+		{
+			name: "photographer not verified",
+			setup: func(_ **models.User, _ *models.Appointment, photographer *models.User) {
+				photographer.VerificationStatus = models.VerificationLegacy
+			},
+			expectedError: apperrors.ErrPhotographerNotVerified,
+		},
+		{
+			name: "photographer without payout account",
+			setup: func(_ **models.User, _ *models.Appointment, photographer *models.User) {
+				photographer.StripeAccountID = nil
+			},
+			expectedError: apperrors.ErrTipPhotographerNoAccount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			photographer := newPhotographer()
+			appointment := newAppointment(photographer)
+			tipper := customer
+			tt.setup(&tipper, appointment, photographer)
+			assert.Equal(t, tt.expectedError, services.CheckTippable(tipper, appointment, photographer))
+		})
+	}
+}
+
+func TestUnitTestTipCharge(t *testing.T) {
+	// The photographer gets the whole tip
+	price, applicationFee := services.TipCharge(150)
+	assert.Equal(t, int64(15000), price)
+	assert.Zero(t, applicationFee)
+}
+
+func TestUnitTestPaymentByAppointmentExcludesTips(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("find", func(mt *mtest.T) {
+		appointmentId := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.Payment", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "appointment_id", Value: appointmentId},
+		}))
+		repo := database.NewPaymentRepository(mt.Coll, nil)
+
+		_, err := repo.GetByAppointmentID(context.Background(), appointmentId)
+		assert.NoError(mt, err)
+
+		// Only the payment of the appointment itself or a payment from before the types is matched
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		types := filter.Lookup("type", "$in").Array()
+		values, err := types.Values()
+		assert.NoError(mt, err)
+		assert.Len(mt, values, 2)
+		assert.Equal(mt, string(models.PaymentAppointment), values[0].StringValue())
+		assert.Equal(mt, bson.TypeNull, values[1].Type)
+	})
+}
@@ -0,0 +1,59 @@
+package testing_runner
+
+import (
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	sms "github.com/Bualoi-s-Dev/backend/repositories/sms"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestNormalizePhone(t *testing.T) {
+	valid := map[string]string{
+		"0812345678":      "+66812345678",
+		"081-234-5678":    "+66812345678",
+		"+66 81 234 5678": "+66812345678",
+		"021234567":       "+6621234567",
+		"+14155552671":    "+14155552671",
+	}
+	for input, expected := range valid {
+		phone, err := services.NormalizePhone(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, phone, input)
+	}
+
+	for _, input := range []string{"", "812345678", "08123", "+66812345678901", "08123456ab", "+0812345678", "0081234567"} {
+		_, err := services.NormalizePhone(input)
+		assert.ErrorIs(t, err, apperrors.ErrPhoneInvalid, input)
+	}
+}
+
+func TestUnitTestPhoneOTPHash(t *testing.T) {
+	secret := []byte("secret")
+	id := primitive.NewObjectID()
+	hash := services.PhoneOTPHash(secret, id, "+66812345678", "123456")
+
+	assert.Equal(t, hash, services.PhoneOTPHash(secret, id, "+66812345678", "123456"))
+	assert.NotEqual(t, hash, services.PhoneOTPHash(secret, id, "+66812345678", "123457"))
+	assert.NotEqual(t, hash, services.PhoneOTPHash(secret, id, "+66812345679", "123456"))
+	assert.NotEqual(t, hash, services.PhoneOTPHash(secret, primitive.NewObjectID(), "+66812345678", "123456"))
+	assert.NotEqual(t, hash, services.PhoneOTPHash([]byte("other"), id, "+66812345678", "123456"))
+}
+
+func TestUnitTestUserIsPhoneVerified(t *testing.T) {
+	user := &models.User{}
+	assert.False(t, user.IsPhoneVerified())
+	user.Phone, user.VerifiedPhone = "+66812345678", "+66812345678"
+	assert.True(t, user.IsPhoneVerified())
+	user.Phone = "+66899999999"
+	assert.False(t, user.IsPhoneVerified())
+}
+
+func TestUnitTestPhoneServiceEnabled(t *testing.T) {
+	assert.True(t, services.NewPhoneService(nil, nil, sms.NewLogSender(), []byte("secret")).Enabled())
+	assert.False(t, services.NewPhoneService(nil, nil, sms.NewLogSender(), nil).Enabled())
+	assert.False(t, services.NewPhoneService(nil, nil, nil, []byte("secret")).Enabled())
+}
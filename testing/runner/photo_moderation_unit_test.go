@@ -0,0 +1,100 @@
+package testing_runner
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	xdraw "golang.org/x/image/draw"
+)
+
+// blockImage fills the image with a grid of random gray blocks, the seed picks the picture
+func blockImage(width, height int, seed int64) *image.RGBA {
+	random := rand.New(rand.NewSource(seed))
+	levels := make([]uint8, 12*9)
+	for i := range levels {
+		levels[i] = uint8(random.Intn(256))
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			level := levels[(y*9/height)*12+x*12/width]
+			img.Set(x, y, color.RGBA{R: level, G: level / 2, B: 255 - level, A: 255})
+		}
+	}
+	return img
+}
+
+func TestUnitTestPerceptualHash(t *testing.T) {
+	original := blockImage(1200, 900, 1)
+	var pngData bytes.Buffer
+	assert.NoError(t, png.Encode(&pngData, original))
+	hash, err := services.PerceptualHash(pngData.Bytes())
+	assert.NoError(t, err)
+	assert.Len(t, hash, 16)
+
+	// A smaller recompressed copy keeps nearly the same hash
+	resized := image.NewRGBA(image.Rect(0, 0, 600, 450))
+	xdraw.CatmullRom.Scale(resized, resized.Bounds(), original, original.Bounds(), xdraw.Src, nil)
+	var jpegData bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&jpegData, resized, &jpeg.Options{Quality: 60}))
+	copyHash, err := services.PerceptualHash(jpegData.Bytes())
+	assert.NoError(t, err)
+	distance, err := services.PerceptualHashDistance(hash, copyHash)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, distance, services.PhotoDuplicateDistance)
+
+	// Another picture is far away
+	var otherData bytes.Buffer
+	assert.NoError(t, png.Encode(&otherData, blockImage(1200, 900, 2)))
+	otherHash, err := services.PerceptualHash(otherData.Bytes())
+	assert.NoError(t, err)
+	distance, err = services.PerceptualHashDistance(hash, otherHash)
+	assert.NoError(t, err)
+	assert.Greater(t, distance, services.PhotoDuplicateDistance)
+
+	// A plain image has no hash to compare
+	var plainData bytes.Buffer
+	assert.NoError(t, png.Encode(&plainData, image.NewRGBA(image.Rect(0, 0, 100, 100))))
+	plainHash, err := services.PerceptualHash(plainData.Bytes())
+	assert.NoError(t, err)
+	assert.Empty(t, plainHash)
+}
+
+func TestUnitTestPerceptualHashBands(t *testing.T) {
+	hash := "f0e1d2c3b4a59687"
+	bands, err := services.PerceptualHashBands(hash)
+	assert.NoError(t, err)
+	assert.Len(t, bands, 8)
+
+	// Flipping one bit in six different bytes still leaves two bytes equal
+	var value uint64
+	fmt.Sscanf(hash, "%x", &value)
+	for i := 0; i < services.PhotoDuplicateDistance; i++ {
+		value ^= 1 << (8*i + i)
+	}
+	near := fmt.Sprintf("%016x", value)
+	distance, err := services.PerceptualHashDistance(hash, near)
+	assert.NoError(t, err)
+	assert.Equal(t, services.PhotoDuplicateDistance, distance)
+
+	nearBands, err := services.PerceptualHashBands(near)
+	assert.NoError(t, err)
+	shared := 0
+	for i := range bands {
+		if bands[i] == nearBands[i] {
+			shared++
+		}
+	}
+	assert.Equal(t, 2, shared)
+
+	_, err = services.PerceptualHashBands("not a hash")
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package testing_runner
+
+import (
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/policies"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestPolicyPackages(t *testing.T) {
+	owner := &models.User{ID: primitive.NewObjectID(), Role: models.Photographer}
+	other := &models.User{ID: primitive.NewObjectID(), Role: models.Photographer}
+	customer := &models.User{ID: primitive.NewObjectID(), Role: models.Customer}
+	pkg := &models.Package{ID: primitive.NewObjectID(), OwnerID: owner.ID}
+
+	assert.NoError(t, policies.Authorize(owner, policies.ActionUpdate, policies.Package(pkg)))
+	assert.NoError(t, policies.Authorize(owner, policies.ActionCreate, policies.Subpackage(pkg)))
+	assert.NoError(t, policies.Authorize(customer, policies.ActionRead, policies.Package(pkg)))
+
+	// Packages are public, so the others are forbidden rather than told it does not exist
+	assert.ErrorIs(t, policies.Authorize(other, policies.ActionDelete, policies.Package(pkg)), apperrors.ErrForbidden)
+	assert.ErrorIs(t, policies.Authorize(other, policies.ActionUpdate, policies.Subpackage(pkg)), apperrors.ErrForbidden)
+	assert.ErrorIs(t, policies.Authorize(customer, policies.ActionUpdate, policies.Package(pkg)), apperrors.ErrForbidden)
+	assert.ErrorIs(t, policies.Authorize(&models.User{Role: models.Guest}, policies.ActionRead, policies.Package(pkg)), apperrors.ErrPackageNotFound)
+}
+
+func TestUnitTestPolicyAppointments(t *testing.T) {
+	customer := &models.User{ID: primitive.NewObjectID(), Role: models.Customer}
+	photographer := &models.User{ID: primitive.NewObjectID(), Role: models.Photographer}
+	stranger := &models.User{ID: primitive.NewObjectID(), Role: models.Customer}
+	admin := &models.User{ID: primitive.NewObjectID(), Role: models.Admin}
+	appointment := &models.Appointment{ID: primitive.NewObjectID(), CustomerID: customer.ID, PhotographerID: photographer.ID}
+
+	assert.NoError(t, policies.Authorize(customer, policies.ActionRead, policies.Appointment(appointment)))
+	assert.NoError(t, policies.Authorize(photographer, policies.ActionAccept, policies.Appointment(appointment)))
+	assert.NoError(t, policies.Authorize(customer, policies.ActionCancel, policies.Appointment(appointment)))
+	assert.NoError(t, policies.Authorize(customer, policies.ActionTip, policies.Appointment(appointment)))
+	assert.NoError(t, policies.Authorize(admin, policies.ActionDelete, policies.Appointment(appointment)))
+
+	// A party of the appointment is forbidden, anyone else does not see it
+	assert.ErrorIs(t, policies.Authorize(customer, policies.ActionAccept, policies.Appointment(appointment)), apperrors.ErrForbidden)
+	assert.ErrorIs(t, policies.Authorize(photographer, policies.ActionTip, policies.Appointment(appointment)), apperrors.ErrForbidden)
+	assert.ErrorIs(t, policies.Authorize(stranger, policies.ActionRead, policies.Appointment(appointment)), apperrors.ErrAppointmentNotFound)
+	assert.ErrorIs(t, policies.Authorize(stranger, policies.ActionCancel, policies.Appointment(appointment)), apperrors.ErrAppointmentNotFound)
+	assert.ErrorIs(t, policies.Authorize(stranger, policies.ActionRead, policies.Payment(appointment)), apperrors.ErrPaymentNotFound)
+	assert.NoError(t, policies.Authorize(photographer, policies.ActionRead, policies.Payment(appointment)))
+
+	assert.Equal(t, policies.ActionAccept, policies.AppointmentStatusAction(models.AppointmentAccepted))
+	assert.Equal(t, policies.ActionCancel, policies.AppointmentStatusAction(models.AppointmentCanceled))
+}
+
+func TestUnitTestPolicyRatings(t *testing.T) {
+	author := &models.User{ID: primitive.NewObjectID(), Role: models.Customer}
+	other := &models.User{ID: primitive.NewObjectID(), Role: models.Customer}
+	photographer := &models.User{ID: primitive.NewObjectID(), Role: models.Photographer}
+	rating := &models.Rating{ID: primitive.NewObjectID(), CustomerID: author.ID, PhotographerID: photographer.ID}
+
+	assert.NoError(t, policies.Authorize(author, policies.ActionUpdate, policies.Rating(rating)))
+	assert.NoError(t, policies.Authorize(other, policies.ActionRead, policies.Rating(rating)))
+	assert.ErrorIs(t, policies.Authorize(other, policies.ActionDelete, policies.Rating(rating)), apperrors.ErrForbidden)
+	// The rated photographer cannot change the review
+	assert.ErrorIs(t, policies.Authorize(photographer, policies.ActionUpdate, policies.Rating(rating)), apperrors.ErrForbidden)
+}
+
+func TestUnitTestPolicyRules(t *testing.T) {
+	policy := policies.NewPolicy(
+		policies.Rule{Resource: policies.ResourcePackage, Action: policies.ActionRead, Roles: []models.UserRole{models.Customer}, Relation: policies.RelationAny},
+	)
+	subject := policies.Subject{ID: primitive.NewObjectID(), Role: models.Customer}
+	object := policies.Object{Resource: policies.ResourcePackage}
+
+	assert.True(t, policy.AllowsRole(models.Customer, policies.ResourcePackage, policies.ActionUpdate, policies.ActionRead))
+	assert.False(t, policy.AllowsRole(models.Photographer, policies.ResourcePackage, policies.ActionRead))
+	assert.NoError(t, policy.Authorize(subject, policies.ActionRead, object))
+	assert.ErrorIs(t, policy.Authorize(subject, policies.ActionUpdate, object), apperrors.ErrForbidden)
+
+	// Without a rule nothing is allowed, a user without an id is never related to an object
+	assert.ErrorIs(t, policies.NewPolicy().Authorize(subject, policies.ActionRead, object), apperrors.ErrPackageNotFound)
+	assert.False(t, policies.Default.Allows(policies.SubjectOf(nil), policies.ActionRead, policies.Object{Resource: policies.ResourceAppointment}))
+	assert.True(t, policies.Default.AllowsRole(models.Admin, policies.ResourceAppointment, policies.ActionDelete))
+}
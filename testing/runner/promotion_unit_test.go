@@ -0,0 +1,144 @@
+package testing_runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestPromotionDiscount(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion *models.Promotion
+		price     int
+		expected  int
+	}{
+		{
+			name:      "percentage discount",
+			promotion: &models.Promotion{DiscountType: models.DiscountPercentage, DiscountValue: 10},
+			price:     1500,
+			expected:  150,
+		},
+		{
+			name:      "percentage discount capped by max discount",
+			promotion: &models.Promotion{DiscountType: models.DiscountPercentage, DiscountValue: 50, MaxDiscount: 300},
+			price:     1500,
+			expected:  300,
+		},
+		{
+			name:      "fixed discount",
+			promotion: &models.Promotion{DiscountType: models.DiscountFixed, DiscountValue: 200},
+			price:     1500,
+			expected:  200,
+		},
+		{
+			name:      "fixed discount larger than price",
+			promotion: &models.Promotion{DiscountType: models.DiscountFixed, DiscountValue: 2000},
+			price:     1500,
+			expected:  1500,
+		},
+		{
+			name:      "platform discount capped by the platform fee",
+			promotion: &models.Promotion{Scope: models.PromotionPlatform, DiscountType: models.DiscountPercentage, DiscountValue: 10},
+			price:     1500,
+			expected:  services.PlatformFee,
+		},
+		{
+			name:      "platform discount below the platform fee",
+			promotion: &models.Promotion{Scope: models.PromotionPlatform, DiscountType: models.DiscountFixed, DiscountValue: 3},
+			price:     1500,
+			expected:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, services.CalculateDiscount(tt.promotion, tt.price))
+		})
+	}
+}
+
+func TestUnitTestPromotionApplicable(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	ownerId := primitive.NewObjectID()
+	pkg := &models.Package{ID: primitive.NewObjectID(), OwnerID: ownerId}
+	newPromotion := func() *models.Promotion {
+		return &models.Promotion{
+			OwnerID:   ownerId,
+			Scope:     models.PromotionPhotographer,
+			StartTime: now.Add(-24 * time.Hour),
+			EndTime:   now.Add(24 * time.Hour),
+			IsActive:  true,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		setup         func(p *models.Promotion)
+		price         int
+		expectedError error
+	}{
+		{name: "valid promotion", setup: func(p *models.Promotion) {}, price: 1000, expectedError: nil},
+		{name: "inactive promotion", setup: func(p *models.Promotion) { p.IsActive = false }, price: 1000, expectedError: apperrors.ErrPromotionInactive},
+		{name: "not started yet", setup: func(p *models.Promotion) { p.StartTime = now.Add(time.Hour) }, price: 1000, expectedError: apperrors.ErrPromotionExpired},
+		{name: "already ended", setup: func(p *models.Promotion) { p.EndTime = now.Add(-time.Hour) }, price: 1000, expectedError: apperrors.ErrPromotionExpired},
+		{name: "other photographer package", setup: func(p *models.Promotion) { p.OwnerID = primitive.NewObjectID() }, price: 1000, expectedError: apperrors.ErrPromotionNotApplicable},
+		{name: "platform promotion on any package", setup: func(p *models.Promotion) {
+			p.OwnerID = primitive.NewObjectID()
+			p.Scope = models.PromotionPlatform
+		}, price: 1000, expectedError: nil},
+		{name: "package not in the list", setup: func(p *models.Promotion) { p.PackageIDs = []primitive.ObjectID{primitive.NewObjectID()} }, price: 1000, expectedError: apperrors.ErrPromotionNotApplicable},
+		{name: "price below minimum", setup: func(p *models.Promotion) { p.MinPrice = 2000 }, price: 1000, expectedError: apperrors.ErrPromotionNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promotion := newPromotion()
+			tt.setup(promotion)
+			err := services.CheckPromotionApplicable(promotion, pkg, tt.price, now)
+			assert.Equal(t, tt.expectedError, err)
+		})
+	}
+}
+
+func TestUnitTestPromotionPhotographerEarnings(t *testing.T) {
+	promotions := []*models.Promotion{
+		{Scope: models.PromotionPlatform, DiscountType: models.DiscountPercentage, DiscountValue: 50},
+		{Scope: models.PromotionPlatform, DiscountType: models.DiscountFixed, DiscountValue: 2},
+		{Scope: models.PromotionPlatform, DiscountType: models.DiscountFixed, DiscountValue: 5000},
+	}
+	for _, promotion := range promotions {
+		discount := services.CalculateDiscount(promotion, 1500)
+		appointment := &models.Appointment{
+			Price:    1500 - discount,
+			Discount: &models.AppointmentDiscount{Scope: promotion.Scope, OriginalPrice: 1500, Amount: discount},
+		}
+		// The platform funds its own promotions, the photographer gets the same transfer as without them
+		assert.Equal(t, 1500-services.PlatformFee, appointment.Price-services.CalculateApplicationFee(appointment))
+	}
+}
+
+func TestUnitTestCheckPlatformDiscount(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion *models.Promotion
+		expected  error
+	}{
+		{name: "fixed within the fee", promotion: &models.Promotion{DiscountType: models.DiscountFixed, DiscountValue: services.PlatformFee}},
+		{name: "fixed over the fee", promotion: &models.Promotion{DiscountType: models.DiscountFixed, DiscountValue: 200}, expected: apperrors.ErrPromotionOverPlatformFee},
+		{name: "percentage bounded by the fee", promotion: &models.Promotion{DiscountType: models.DiscountPercentage, DiscountValue: 20, MaxDiscount: 3}},
+		{name: "percentage without bound", promotion: &models.Promotion{DiscountType: models.DiscountPercentage, DiscountValue: 20}, expected: apperrors.ErrPromotionOverPlatformFee},
+		{name: "percentage bounded over the fee", promotion: &models.Promotion{DiscountType: models.DiscountPercentage, DiscountValue: 20, MaxDiscount: 100}, expected: apperrors.ErrPromotionOverPlatformFee},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.promotion.Scope = models.PromotionPlatform
+			assert.Equal(t, tt.expected, services.CheckPlatformDiscount(tt.promotion))
+		})
+	}
+}
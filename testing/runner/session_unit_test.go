@@ -0,0 +1,48 @@
+package testing_runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitTestSessionError(t *testing.T) {
+	now := time.Now()
+	signIn := now.Add(-time.Hour)
+	user := &models.User{}
+	assert.NoError(t, services.SessionError(user, signIn, now))
+
+	// Revoked after the sign in, a new sign in is accepted again
+	revoked := now.Add(-time.Minute)
+	user.TokensValidAfter = &revoked
+	assert.Equal(t, apperrors.ErrSessionRevoked, services.SessionError(user, signIn, now))
+	assert.NoError(t, services.SessionError(user, revoked, now))
+	assert.NoError(t, services.SessionError(user, now, now))
+
+	expires := now.Add(time.Hour)
+	user.Suspension = &models.UserSuspension{Type: models.SuspensionSuspended, ExpiresTime: &expires}
+	assert.Equal(t, apperrors.ErrUserSuspended, services.SessionError(user, now, now))
+	assert.NoError(t, services.SessionError(user, now, expires))
+
+	user.Suspension = &models.UserSuspension{Type: models.SuspensionBanned}
+	assert.Equal(t, apperrors.ErrUserBanned, services.SessionError(user, now, now.Add(24*365*time.Hour)))
+
+	// Suspensions from before the types never expire
+	user.Suspension = &models.UserSuspension{}
+	assert.Equal(t, apperrors.ErrUserSuspended, services.SessionError(user, now, now))
+}
+
+func TestUnitTestValidateSuspension(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	assert.NoError(t, services.ValidateSuspension(&models.UserSuspension{Type: models.SuspensionSuspended}, now))
+	assert.NoError(t, services.ValidateSuspension(&models.UserSuspension{Type: models.SuspensionSuspended, ExpiresTime: &future}, now))
+	assert.NoError(t, services.ValidateSuspension(&models.UserSuspension{Type: models.SuspensionBanned}, now))
+	assert.Equal(t, apperrors.ErrSuspensionExpiry, services.ValidateSuspension(&models.UserSuspension{Type: models.SuspensionSuspended, ExpiresTime: &past}, now))
+	assert.Equal(t, apperrors.ErrSuspensionExpiry, services.ValidateSuspension(&models.UserSuspension{Type: models.SuspensionBanned, ExpiresTime: &future}, now))
+}
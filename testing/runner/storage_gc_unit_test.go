@@ -0,0 +1,26 @@
+package testing_runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitTestFindOrphanedObjects(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-48 * time.Hour)
+	objects := []models.StorageObject{
+		{Key: "package/a_large.jpg", LastModified: old},
+		{Key: "package/b_large.jpg", LastModified: old},
+		{Key: "profile/c_medium.jpg", LastModified: now.Add(-time.Hour)},
+		{Key: "profile/d_medium.jpg", LastModified: old},
+	}
+	referenced := map[string]bool{"package/a_large.jpg": true, "profile/d_medium.jpg": true}
+
+	orphans, recent := services.FindOrphanedObjects(objects, referenced, now.Add(-services.DefaultStorageGCGracePeriod))
+	assert.Equal(t, []models.StorageObject{objects[1]}, orphans)
+	assert.Equal(t, 1, recent)
+}
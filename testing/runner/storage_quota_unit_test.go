@@ -0,0 +1,123 @@
+package testing_runner
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/apperrors"
+	"github.com/Bualoi-s-Dev/backend/dto"
+	"github.com/Bualoi-s-Dev/backend/models"
+	storage "github.com/Bualoi-s-Dev/backend/repositories/storage"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestStorageUsageOf(t *testing.T) {
+	user := &models.User{ProfileImage: &models.Image{Size: 10}}
+	packages := []models.Package{
+		{Images: []models.Image{{Size: 100}, {Size: 200}}},
+		// Photos stored before their size was kept
+		{Images: []models.Image{{}}},
+	}
+	galleries := []models.Gallery{
+		{Status: models.GalleryDelivered, Photos: []models.GalleryPhoto{{Size: 1000}}, Proofs: []models.GalleryProof{{Size: 500}}},
+		{Status: models.GalleryArchived, Photos: []models.GalleryPhoto{{Size: 5000}}},
+	}
+	held := []models.PhotoModeration{{Image: models.Image{Size: 50}}}
+
+	usage := services.StorageUsageOf(user, packages, galleries, held)
+	assert.Equal(t, int64(1860), usage.UsedBytes)
+	assert.Equal(t, 6, usage.UsedPhotos)
+}
+
+func TestUnitTestExceedsStorageQuota(t *testing.T) {
+	quota := services.StorageQuota{Bytes: 1000, Photos: 10}
+	usage := &dto.StorageUsageResponse{UsedBytes: 900, UsedPhotos: 9}
+
+	assert.False(t, services.ExceedsStorageQuota(quota, usage, 100, 1))
+	assert.True(t, services.ExceedsStorageQuota(quota, usage, 101, 1))
+	assert.True(t, services.ExceedsStorageQuota(quota, usage, 10, 2))
+	// A profile picture is not counted as a photo
+	assert.False(t, services.ExceedsStorageQuota(services.StorageQuota{Bytes: 1000, Photos: 9}, usage, 10, 0))
+	// Unlimited
+	assert.False(t, services.ExceedsStorageQuota(services.StorageQuota{}, usage, 1<<40, 1000))
+}
+
+type fakeQuotaChecker struct {
+	size   int64
+	photos int
+	err    error
+}
+
+func (f *fakeQuotaChecker) CheckQuota(ctx context.Context, ownerId primitive.ObjectID, size int64, photos int) error {
+	f.size, f.photos = size, photos
+	return f.err
+}
+
+func TestUnitTestUploadPortfolioImageQuota(t *testing.T) {
+	repo, err := storage.NewLocalRepository(t.TempDir(), "/storage", []byte("secret"))
+	assert.NoError(t, err)
+	checker := &fakeQuotaChecker{}
+	s3Service := services.NewS3Service(repo)
+	s3Service.Quota = checker
+
+	var data bytes.Buffer
+	assert.NoError(t, png.Encode(&data, blockImage(400, 300, 1)))
+	ownerId := primitive.NewObjectID()
+
+	image, err := s3Service.UploadPortfolioImage(context.Background(), ownerId, data.Bytes(), "package/quota", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, checker.photos)
+
+	// The quota is checked against every stored object of the image, as the usage counts them
+	objects, err := repo.ListObjects("")
+	assert.NoError(t, err)
+	var stored int64
+	for _, object := range objects {
+		stored += object.Size
+	}
+	assert.Equal(t, stored, image.Size)
+	assert.Equal(t, stored, checker.size)
+	assert.Greater(t, checker.size, int64(data.Len()))
+
+	// Nothing is stored over the quota
+	checker.err = apperrors.ErrStorageQuotaExceeded
+	_, err = s3Service.UploadPortfolioImage(context.Background(), ownerId, data.Bytes(), "package/quota", nil)
+	assert.ErrorIs(t, err, apperrors.ErrStorageQuotaExceeded)
+	after, err := repo.ListObjects("")
+	assert.NoError(t, err)
+	assert.Len(t, after, len(objects))
+}
+
+func TestUnitTestUploadPortfolioImagesQuota(t *testing.T) {
+	repo, err := storage.NewLocalRepository(t.TempDir(), "/storage", []byte("secret"))
+	assert.NoError(t, err)
+	checker := &fakeQuotaChecker{}
+	s3Service := services.NewS3Service(repo)
+	s3Service.Quota = checker
+
+	var first, second bytes.Buffer
+	assert.NoError(t, png.Encode(&first, blockImage(400, 300, 1)))
+	assert.NoError(t, png.Encode(&second, blockImage(300, 400, 2)))
+	ownerId := primitive.NewObjectID()
+
+	// The whole batch is checked at once
+	images, err := s3Service.UploadPortfolioImages(context.Background(), ownerId, [][]byte{first.Bytes(), second.Bytes()}, "package/quota", nil)
+	assert.NoError(t, err)
+	assert.Len(t, images, 2)
+	assert.Equal(t, images[0].Size+images[1].Size, checker.size)
+	assert.Equal(t, 2, checker.photos)
+
+	// A batch over the quota stores none of its images
+	checker.err = apperrors.ErrStorageQuotaExceeded
+	objects, err := repo.ListObjects("")
+	assert.NoError(t, err)
+	_, err = s3Service.UploadPortfolioImages(context.Background(), ownerId, [][]byte{first.Bytes(), second.Bytes()}, "package/quota", nil)
+	assert.ErrorIs(t, err, apperrors.ErrStorageQuotaExceeded)
+	after, err := repo.ListObjects("")
+	assert.NoError(t, err)
+	assert.Len(t, after, len(objects))
+}
@@ -0,0 +1,141 @@
+package testing_runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bualoi-s-Dev/backend/controllers"
+	storage "github.com/Bualoi-s-Dev/backend/repositories/storage"
+	"github.com/Bualoi-s-Dev/backend/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitTestLocalStorage(t *testing.T) {
+	baseDir := t.TempDir()
+	repo, err := storage.NewLocalRepository(baseDir, "/storage", []byte("secret"))
+	assert.NoError(t, err)
+
+	key, err := repo.UploadBase64([]byte("image"), "package/123", "png")
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(key)))
+	assert.NoError(t, err)
+	assert.Equal(t, "image", string(content))
+
+	assert.NoError(t, repo.DeleteObject(key[1:]))
+	_, err = os.Stat(filepath.Join(baseDir, filepath.FromSlash(key)))
+	assert.True(t, os.IsNotExist(err))
+
+	// Deleting a missing object is not an error, like S3
+	assert.NoError(t, repo.DeleteObject("package/missing"))
+
+	// Keys cannot escape the base directory
+	_, err = repo.UploadBase64([]byte("image"), "../../outside", "png")
+	assert.NoError(t, err)
+	entries, err := os.ReadDir(filepath.Dir(baseDir))
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), "outside_")
+	}
+}
+
+func TestUnitTestLocalStoragePresignPut(t *testing.T) {
+	repo, err := storage.NewLocalRepository(t.TempDir(), "/storage", []byte("secret"))
+	assert.NoError(t, err)
+
+	signed, err := repo.PresignPut("upload/123", "image/png", 100, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signed, "/storage/upload/123?"))
+	parsed, err := url.Parse(signed)
+	assert.NoError(t, err)
+	query := parsed.Query()
+
+	assert.NoError(t, repo.VerifyPresignedPut("/upload/123", query, "image/png", 100))
+	assert.Error(t, repo.VerifyPresignedPut("/upload/456", query, "image/png", 100))
+	assert.Error(t, repo.VerifyPresignedPut("/upload/123", query, "image/jpeg", 100))
+	assert.Error(t, repo.VerifyPresignedPut("/upload/123", query, "image/png", 101))
+
+	tampered := url.Values{}
+	for k, v := range query {
+		tampered[k] = v
+	}
+	tampered.Set("size", "1000")
+	assert.Error(t, repo.VerifyPresignedPut("/upload/123", tampered, "image/png", 1000))
+
+	expired, err := repo.PresignPut("upload/123", "image/png", 100, -time.Minute)
+	assert.NoError(t, err)
+	parsed, _ = url.Parse(expired)
+	assert.Error(t, repo.VerifyPresignedPut("/upload/123", parsed.Query(), "image/png", 100))
+}
+
+func TestUnitTestLocalStoragePresignGet(t *testing.T) {
+	repo, err := storage.NewLocalRepository(t.TempDir(), "/storage", []byte("secret"))
+	assert.NoError(t, err)
+
+	signed, err := repo.PresignGet("gallery/1/2", time.Minute, "IMG_0001.jpg")
+	assert.NoError(t, err)
+	parsed, err := url.Parse(signed)
+	assert.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "IMG_0001.jpg", query.Get("download"))
+
+	assert.NoError(t, repo.VerifyPresignedGet("/gallery/1/2", query))
+	assert.Error(t, repo.VerifyPresignedGet("/gallery/1/3", query))
+
+	// A download URL cannot be used to upload
+	query.Set("contentType", "IMG_0001.jpg")
+	query.Set("size", "0")
+	assert.Error(t, repo.VerifyPresignedPut("/gallery/1/2", query, "IMG_0001.jpg", 0))
+
+	assert.True(t, storage.IsPrivateKey("/gallery/1/2"))
+	assert.True(t, storage.IsPrivateKey("archive/gallery/1/2"))
+	assert.False(t, storage.IsPrivateKey("/package/1_large.jpg"))
+}
+
+func TestUnitTestLocalStorageCleanKey(t *testing.T) {
+	key, err := storage.CleanKey("/gallery/secret.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, "gallery/secret.jpg", key)
+
+	for _, raw := range []string{"//gallery/secret.jpg", "/./gallery/secret.jpg", "/a/../gallery/secret.jpg", "/gallery//secret.jpg", "/../gallery/secret.jpg", "/", "/gallery/"} {
+		_, err := storage.CleanKey(raw)
+		assert.ErrorIs(t, err, storage.ErrInvalidKey, raw)
+	}
+}
+
+func TestUnitTestLocalStoragePrivateKeyBypass(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	baseDir := t.TempDir()
+	repo, err := storage.NewLocalRepository(baseDir, "/storage", []byte("secret"))
+	assert.NoError(t, err)
+	assert.NoError(t, repo.PutObject("gallery/secret.jpg", []byte("private"), "image/jpeg"))
+	assert.NoError(t, repo.PutObject("package/public.jpg", []byte("public"), "image/jpeg"))
+
+	router := gin.New()
+	routes.StorageRoutes(router, controllers.NewStorageController(repo))
+	get := func(target string) int {
+		w := httptest.NewRecorder()
+		req := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: target}, Header: http.Header{}}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("/storage/package/public.jpg"))
+	assert.Equal(t, http.StatusForbidden, get("/storage/gallery/secret.jpg"))
+	for _, target := range []string{"/storage//gallery/secret.jpg", "/storage/./gallery/secret.jpg", "/storage/a/../gallery/secret.jpg", "/storage//original/x.jpg"} {
+		assert.NotEqual(t, http.StatusOK, get(target), target)
+	}
+
+	signed, err := repo.PresignGet("gallery/secret.jpg", time.Minute, "")
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, signed, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "private", w.Body.String())
+}
@@ -16,7 +16,7 @@ func TestUnitTest(t *testing.T) {
 	ctx := context.Background()
 	userRepo := &repositories_mock.MockUserRepository{}
 	// Pass nil for other repos, assuming they're not used in FilterPackage
-	service := services.NewPackageService(nil, nil, nil, userRepo)
+	service := services.NewPackageService(nil, nil, nil, userRepo, nil, nil, nil)
 
 	mockOwnerId, _ := primitive.ObjectIDFromHex("123")
 
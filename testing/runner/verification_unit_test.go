@@ -0,0 +1,57 @@
+package testing_runner
+
+import (
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnitTestListedPackages(t *testing.T) {
+	verified, unverified := primitive.NewObjectID(), primitive.NewObjectID()
+	pkgs := []models.Package{
+		{Title: "A", OwnerID: verified},
+		{Title: "B", OwnerID: unverified},
+		{Title: "C", OwnerID: verified},
+	}
+
+	listed := services.ListedPackages(pkgs, []primitive.ObjectID{verified})
+	assert.Len(t, listed, 2)
+	assert.Equal(t, "A", listed[0].Title)
+	assert.Equal(t, "C", listed[1].Title)
+	assert.Empty(t, services.ListedPackages(pkgs, nil))
+}
+
+func TestUnitTestUserIsVerified(t *testing.T) {
+	user := &models.User{}
+	assert.False(t, user.IsVerified())
+	for _, status := range []models.VerificationStatus{models.VerificationPending, models.VerificationRejected} {
+		user.VerificationStatus = status
+		assert.False(t, user.IsVerified())
+	}
+	user.VerificationStatus = models.VerificationApproved
+	assert.True(t, user.IsVerified())
+}
+
+func TestUnitTestIsListedFor(t *testing.T) {
+	owner := &models.User{ID: primitive.NewObjectID(), Role: models.Photographer, VerificationStatus: models.VerificationPending}
+	customer := &models.User{ID: primitive.NewObjectID(), Role: models.Customer}
+	admin := &models.User{ID: primitive.NewObjectID(), Role: models.Admin}
+
+	// The packages of an unverified photographer are only seen by them and the admins
+	assert.False(t, services.IsListedFor(customer, owner))
+	assert.False(t, services.IsListedFor(nil, owner))
+	assert.True(t, services.IsListedFor(owner, owner))
+	assert.True(t, services.IsListedFor(admin, owner))
+
+	owner.VerificationStatus = models.VerificationApproved
+	assert.True(t, services.IsListedFor(customer, owner))
+	assert.True(t, services.IsListedFor(nil, owner))
+
+	// A photographer from before the verification stays listed without being verified
+	owner.VerificationStatus = models.VerificationLegacy
+	assert.True(t, services.IsListedFor(customer, owner))
+	assert.False(t, owner.IsVerified())
+}
@@ -0,0 +1,67 @@
+package testing_runner
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/Bualoi-s-Dev/backend/models"
+	"github.com/Bualoi-s-Dev/backend/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func blackImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	return img
+}
+
+// markedArea returns the bounds of the pixels the watermark changed
+func markedArea(img *image.RGBA) image.Rectangle {
+	area := image.Rectangle{}
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r+g+b > 0 {
+				area = area.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return area
+}
+
+func TestUnitTestApplyWatermarkText(t *testing.T) {
+	img := blackImage(800, 600)
+	services.ApplyWatermark(img, &services.ImageWatermark{
+		Setting: models.Watermark{Text: "Meen Studio", Position: models.WatermarkBottomRight, Opacity: 0.5},
+	})
+	area := markedArea(img)
+	assert.False(t, area.Empty())
+	assert.GreaterOrEqual(t, area.Min.X, 400)
+	assert.GreaterOrEqual(t, area.Min.Y, 300)
+
+	// Without opacity nothing is drawn
+	img = blackImage(800, 600)
+	services.ApplyWatermark(img, &services.ImageWatermark{Setting: models.Watermark{Text: "Meen Studio", Opacity: 0}})
+	assert.True(t, markedArea(img).Empty())
+}
+
+func TestUnitTestApplyWatermarkLogo(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	draw.Draw(logo, logo.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+
+	img := blackImage(800, 600)
+	services.ApplyWatermark(img, &services.ImageWatermark{
+		Setting: models.Watermark{Text: "ignored", Position: models.WatermarkTopLeft, Opacity: 1},
+		Logo:    logo,
+	})
+	area := markedArea(img)
+	assert.LessOrEqual(t, area.Max.X, 400)
+	assert.LessOrEqual(t, area.Max.Y, 300)
+
+	// The logo keeps its colors
+	r, g, b, _ := img.At(area.Min.X+area.Dx()/2, area.Min.Y+area.Dy()/2).RGBA()
+	assert.Greater(t, r, uint32(0xF000))
+	assert.Zero(t, g)
+	assert.Zero(t, b)
+}
@@ -2,6 +2,7 @@ package testing_scenarios
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,11 +14,14 @@ import (
 	"github.com/Bualoi-s-Dev/backend/models"
 	"github.com/Bualoi-s-Dev/backend/utils"
 	"github.com/cucumber/godog"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AppointmentScenario struct {
 	Server         *httptest.Server
+	DB             *mongo.Database
 	Token          string
 	Package        *dto.PackageResponse
 	Subpackage     *dto.SubpackageResponse
@@ -129,7 +133,9 @@ func (s *AppointmentScenario) thePhotographerHasPackageAndSubpackage() error {
 	}
 	s.PhotographerID = userProfile.ID
 
-	return nil
+	// Customers only book verified photographers
+	_, err = s.DB.Collection("User").UpdateByID(context.Background(), s.PhotographerID, bson.M{"$set": bson.M{"verification_status": models.VerificationApproved}})
+	return err
 }
 
 func (s *AppointmentScenario) theCustomerIsLoggedIn() error {
@@ -110,6 +110,13 @@ func SafeString(str *string) string {
 	return *str
 }
 
+func SafeInt(value *int) int {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
 func TimeToMinutes(t string) int {
 	if t == "" {
 		return 0
@@ -1,6 +1,7 @@
 package validators
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/Bualoi-s-Dev/backend/dto"
@@ -36,9 +37,16 @@ func ValidateBankName(fl validator.FieldLevel) bool {
 	return false
 }
 
-// ValidateBankname checks if the UserRole is valid
+// ValidateUserRole checks if the UserRole is valid and one the users can take themselves, Admin is only granted in
+// the back office
 func ValidateUserRole(fl validator.FieldLevel) bool {
 	value := fl.Field().Interface().(models.UserRole)
+	return value != models.Admin && ValidateAnyUserRole(fl)
+}
+
+// ValidateAnyUserRole checks if the UserRole is valid, Admin included
+func ValidateAnyUserRole(fl validator.FieldLevel) bool {
+	value := fl.Field().Interface().(models.UserRole)
 
 	// Check if the value exists in the validBankNames slice
 	for _, validRole := range models.ValidUserRoles {
@@ -112,6 +120,86 @@ func ValidateBusyTimeType(fl validator.FieldLevel) bool {
 	return false
 }
 
+// ValidateDiscountType checks if the DiscountType is valid
+func ValidateDiscountType(fl validator.FieldLevel) bool {
+	value := fl.Field().Interface().(models.DiscountType)
+
+	for _, validType := range models.ValidDiscountTypes {
+		if value == validType.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidatePromotionScope checks if the PromotionScope is valid
+func ValidatePromotionScope(fl validator.FieldLevel) bool {
+	value := fl.Field().Interface().(models.PromotionScope)
+
+	for _, validScope := range models.ValidPromotionScopes {
+		if value == validScope.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateWatermarkPosition checks if the WatermarkPosition is valid
+func ValidateWatermarkPosition(fl validator.FieldLevel) bool {
+	value := fl.Field().Interface().(models.WatermarkPosition)
+
+	for _, validPosition := range models.ValidWatermarkPositions {
+		if value == validPosition.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateSuspensionType checks if the SuspensionType is valid
+func ValidateSuspensionType(fl validator.FieldLevel) bool {
+	value := fl.Field().Interface().(models.SuspensionType)
+
+	for _, validType := range models.ValidSuspensionTypes {
+		if value == validType.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateIdentityDocumentType checks if the IdentityDocumentType is valid
+func ValidateIdentityDocumentType(fl validator.FieldLevel) bool {
+	value := fl.Field().Interface().(models.IdentityDocumentType)
+
+	for _, validType := range models.ValidIdentityDocumentTypes {
+		if value == validType.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateBankAccount checks the account number against the format of the BankName of the same struct, any Thai bank
+// when it has none
+func ValidateBankAccount(fl validator.FieldLevel) bool {
+	var bank models.BankName
+	if field := fl.Parent().FieldByName("BankName"); field.IsValid() {
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.String {
+			bank = models.BankName(field.String())
+		}
+	}
+	return models.IsValidBankAccount(bank, fl.Field().String())
+}
+
 // Custom validation function
 func IsInfRule(fl validator.FieldLevel) bool {
 	req, ok := fl.Parent().Interface().(dto.SubpackageRequest)
@@ -139,10 +227,17 @@ func RegisterCustomValidators(v *validator.Validate) {
 	v.RegisterValidation("package_type", ValidatePackageType)
 	v.RegisterValidation("bank_name", ValidateBankName)
 	v.RegisterValidation("user_role", ValidateUserRole)
+	v.RegisterValidation("any_user_role", ValidateAnyUserRole)
 	v.RegisterValidation("appointment_status", ValidateAppointmentStatus)
 	v.RegisterValidation("day_names", ValidateDayNames)
 	v.RegisterValidation("time_format", ValidateTime)
 	v.RegisterValidation("date_format", ValidateDate)
 	v.RegisterValidation("busy_time_type", ValidateBusyTimeType)
 	v.RegisterValidation("isInf_rule", IsInfRule)
+	v.RegisterValidation("discount_type", ValidateDiscountType)
+	v.RegisterValidation("promotion_scope", ValidatePromotionScope)
+	v.RegisterValidation("watermark_position", ValidateWatermarkPosition)
+	v.RegisterValidation("suspension_type", ValidateSuspensionType)
+	v.RegisterValidation("identity_document_type", ValidateIdentityDocumentType)
+	v.RegisterValidation("bank_account", ValidateBankAccount)
 }